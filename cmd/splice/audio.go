@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// audioPlayer is a command playing raw 16-bit little-endian mono PCM
+// from its stdin.
+type audioPlayer struct {
+	name string
+	// args returns arguments playing at the sample rate on the device,
+	// or the default one if device is empty
+	args func(rate int, device string) []string
+	// list is the command listing devices, nil if the player can't
+	// select them
+	list []string
+}
+
+// audioPlayers are players looked for in order, so ALSA is preferred
+// on Linux and SoX elsewhere.
+var audioPlayers = []audioPlayer{
+	{
+		name: "aplay",
+		args: func(rate int, device string) []string {
+			args := []string{"-q", "-t", "raw", "-f", "S16_LE", "-c", "1", "-r", strconv.Itoa(rate)}
+			if device != "" {
+				args = append(args, "-D", device)
+			}
+			return args
+		},
+		list: []string{"aplay", "-L"},
+	},
+	{
+		name: "paplay",
+		args: func(rate int, device string) []string {
+			args := []string{"--raw", "--format=s16le", "--channels=1", "--rate=" + strconv.Itoa(rate)}
+			if device != "" {
+				args = append(args, "--device="+device)
+			}
+			return args
+		},
+		list: []string{"pactl", "list", "short", "sinks"},
+	},
+	{
+		name: "play",
+		args: func(rate int, _ string) []string {
+			return []string{"-q", "-t", "raw", "-e", "signed", "-b", "16", "-c", "1", "-r", strconv.Itoa(rate), "-"}
+		},
+	},
+}
+
+// findAudioPlayer returns the first audio player installed.
+func findAudioPlayer() (audioPlayer, error) {
+	for _, player := range audioPlayers {
+		if _, err := exec.LookPath(player.name); err == nil {
+			return player, nil
+		}
+	}
+
+	return audioPlayer{}, errors.New("no audio player found, install aplay, paplay or SoX")
+}
+
+// listAudioDevices prints devices of the first audio player installed.
+func listAudioDevices() error {
+	player, err := findAudioPlayer()
+	if err != nil {
+		return err
+	}
+	if player.list == nil {
+		return fmt.Errorf("%s can't select devices", player.name)
+	}
+
+	cmd := exec.Command(player.list[0], player.list[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// audioDevice is a running audio player.
+type audioDevice struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+// openAudioDevice starts the first audio player installed, playing
+// at the sample rate on the device, or the default one if empty.
+func openAudioDevice(rate int, device string) (*audioDevice, error) {
+	player, err := findAudioPlayer()
+	if err != nil {
+		return nil, err
+	}
+	if device != "" && player.list == nil {
+		return nil, fmt.Errorf("%s can't select devices", player.name)
+	}
+
+	cmd := exec.Command(player.name, player.args(rate, device)...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong starting %s - %v", player.name, err)
+	}
+
+	return &audioDevice{stdin, cmd}, nil
+}
+
+// Close closes the player's input and waits for it to play the rest.
+func (d *audioDevice) Close() error {
+	err := d.WriteCloser.Close()
+	if err := d.cmd.Wait(); err != nil {
+		return fmt.Errorf("something went wrong playing audio - %v", err)
+	}
+
+	return err
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func clusterCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("cluster", flag.ExitOnError)
+	k := flags.Int("k", 8, "maximum `number` of clusters")
+	seed := flags.Int64("seed", 1, "`seed` of the initial choice of clusters")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice cluster [-k number] [-seed seed] [-output format] dir...")
+		fmt.Fprintln(flags.Output(), "Groups files of the directories into clusters of similar patterns.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		dirs := parseInterspersed(flags)
+		if len(dirs) == 0 {
+			flags.Usage()
+			return errors.New("expected at least one directory")
+		}
+		if *k <= 0 {
+			return fmt.Errorf("invalid number of clusters %d", *k)
+		}
+
+		paths, patterns, err := decodeLibrary(dirs)
+		if err != nil {
+			return err
+		}
+
+		clusters := drum.Cluster(patterns, *k, *seed)
+
+		switch *output {
+		case outputJSON:
+			type cluster struct {
+				Exemplar string   `json:"exemplar"`
+				Members  []string `json:"members"`
+			}
+			out := []cluster{}
+			for _, c := range clusters {
+				members := make([]string, len(c.Members))
+				for i, m := range c.Members {
+					members[i] = paths[m]
+				}
+				out = append(out, cluster{paths[c.Exemplar], members})
+			}
+			return printJSON(out)
+		case outputTable:
+			table := newTable()
+			fmt.Fprintf(table, "CLUSTER\tPATH\tEXEMPLAR\n")
+			for i, c := range clusters {
+				for _, m := range c.Members {
+					exemplar := ""
+					if m == c.Exemplar {
+						exemplar = "*"
+					}
+					fmt.Fprintf(table, "%d\t%s\t%s\n", i+1, paths[m], exemplar)
+				}
+			}
+			return table.Flush()
+		default:
+			for i, c := range clusters {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("cluster %d, exemplar %s\n", i+1, paths[c.Exemplar])
+				for _, m := range c.Members {
+					fmt.Printf("  %s\n", paths[m])
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// decodeLibrary decodes all files found in dirs, returning their paths
+// and patterns sorted by path within each directory.
+func decodeLibrary(dirs []string) ([]string, []*drum.Pattern, error) {
+	var paths []string
+	var patterns []*drum.Pattern
+
+	for _, dir := range dirs {
+		decoded, err := drum.DecodeDirFS(os.DirFS(dir), ".")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var names []string
+		for name := range decoded {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+			patterns = append(patterns, decoded[name])
+		}
+	}
+
+	return paths, patterns, nil
+}
+
+// parseInterspersed parses flags following positional arguments, e.g.
+// splice cluster ./library -k 8, and returns the positional arguments.
+func parseInterspersed(flags *flag.FlagSet) []string {
+	var args []string
+	for flags.NArg() > 0 {
+		args = append(args, flags.Arg(0))
+		flags.Parse(flags.Args()[1:])
+	}
+
+	return args
+}
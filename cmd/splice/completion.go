@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// completionFlag describes a flag for shell completions.
+type completionFlag struct {
+	name  string
+	usage string
+	// arg is the name of the flag's argument, e.g. "path"
+	arg     string
+	bool    bool
+	choices []string
+}
+
+func completionCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("completion", flag.ExitOnError)
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice completion bash|zsh|fish")
+		fmt.Fprintln(flags.Output(), "       splice completion -output json|table")
+		fmt.Fprintln(flags.Output(), "Prints a completion script, e.g. to load it in bash:")
+		fmt.Fprintln(flags.Output(), "  source <(splice completion bash)")
+		fmt.Fprintln(flags.Output(), "Other formats describe commands and their flags for other tools.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if *output != outputText {
+			if flags.NArg() != 0 {
+				flags.Usage()
+				return errors.New("unexpected arguments")
+			}
+			return printCommands(*output)
+		}
+
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a shell name")
+		}
+
+		switch flags.Arg(0) {
+		case "bash":
+			writeBashCompletion(os.Stdout)
+		case "zsh":
+			writeZshCompletion(os.Stdout)
+		case "fish":
+			writeFishCompletion(os.Stdout)
+		default:
+			return fmt.Errorf("unsupported shell %q", flags.Arg(0))
+		}
+
+		return nil
+	}
+}
+
+// completionFlags returns flags of the command.
+func completionFlags(cmd command) []completionFlag {
+	var flags []completionFlag
+
+	fs, _ := cmd.setup()
+	fs.VisitAll(func(f *flag.Flag) {
+		arg, usage := flag.UnquoteUsage(f)
+
+		cf := completionFlag{name: f.Name, usage: usage, arg: arg}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+			cf.bool = b.IsBoolFlag()
+		}
+		if c, ok := f.Value.(choiceValue); ok {
+			cf.choices = c.choices
+		}
+
+		flags = append(flags, cf)
+	})
+
+	return flags
+}
+
+// printCommands writes commands and their flags in the output format.
+func printCommands(output string) error {
+	type flagSpec struct {
+		Name    string   `json:"name"`
+		Usage   string   `json:"usage"`
+		Arg     string   `json:"arg,omitempty"`
+		Bool    bool     `json:"bool,omitempty"`
+		Choices []string `json:"choices,omitempty"`
+	}
+	type commandSpec struct {
+		Name  string     `json:"name"`
+		Usage string     `json:"usage"`
+		Flags []flagSpec `json:"flags"`
+	}
+
+	specs := []commandSpec{}
+	for _, cmd := range commands {
+		spec := commandSpec{Name: cmd.name, Usage: cmd.usage, Flags: []flagSpec{}}
+		for _, f := range completionFlags(cmd) {
+			spec.Flags = append(spec.Flags, flagSpec{f.name, f.usage, f.arg, f.bool, f.choices})
+		}
+		specs = append(specs, spec)
+	}
+
+	if output == outputJSON {
+		return printJSON(specs)
+	}
+
+	table := newTable()
+	fmt.Fprintf(table, "COMMAND\tFLAG\tARG\tCHOICES\n")
+	for _, spec := range specs {
+		for _, f := range spec.Flags {
+			fmt.Fprintf(table, "%s\t-%s\t%s\t%s\n", spec.Name, f.Name, f.Arg, strings.Join(f.Choices, ","))
+		}
+	}
+
+	return table.Flush()
+}
+
+func commandNames() string {
+	var names []string
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+
+	return strings.Join(names, " ")
+}
+
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprintf(w, `_splice() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+
+	if [ "$COMP_CWORD" -eq 1 ]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		return
+	fi
+
+	local flags
+	case "${COMP_WORDS[1]} $prev" in
+`, commandNames())
+
+	for _, cmd := range commands {
+		for _, f := range completionFlags(cmd) {
+			if len(f.choices) > 0 {
+				fmt.Fprintf(w, "\t\"%s -%s\") COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return;;\n",
+					cmd.name, f.name, strings.Join(f.choices, " "))
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\tesac\n\n\tcase ${COMP_WORDS[1]} in\n")
+
+	for _, cmd := range commands {
+		var names []string
+		for _, f := range completionFlags(cmd) {
+			names = append(names, "-"+f.name)
+		}
+		fmt.Fprintf(w, "\t%s) flags=\"%s\";;\n", cmd.name, strings.Join(names, " "))
+	}
+
+	fmt.Fprint(w, `	esac
+
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -f -- "$cur"))
+	fi
+}
+
+complete -o filenames -F _splice splice
+`)
+}
+
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprint(w, "#compdef splice\n\n_splice() {\n\tlocal -a commands\n\tcommands=(\n")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t\t'%s:%s'\n", cmd.name, zshQuote(cmd.usage))
+	}
+
+	fmt.Fprint(w, `	)
+
+	if (( CURRENT == 2 )); then
+		_describe 'command' commands
+		return
+	fi
+
+	case $words[2] in
+`)
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t%s)\n\t\t_arguments -s \\\n", cmd.name)
+
+		for _, f := range completionFlags(cmd) {
+			spec := fmt.Sprintf("-%s[%s]", f.name, zshQuote(f.usage))
+			switch {
+			case f.bool:
+			case len(f.choices) > 0:
+				spec += fmt.Sprintf(":%s:(%s)", f.arg, strings.Join(f.choices, " "))
+			case f.arg == "path":
+				spec += fmt.Sprintf(":%s:_files", f.arg)
+			default:
+				spec += fmt.Sprintf(":%s: ", f.arg)
+			}
+			fmt.Fprintf(w, "\t\t\t'%s' \\\n", spec)
+		}
+
+		fmt.Fprint(w, "\t\t\t'*:file:_files'\n\t\t;;\n")
+	}
+
+	fmt.Fprint(w, "\tesac\n}\n\n_splice \"$@\"\n")
+}
+
+// zshQuote escapes text for use in a single-quoted _arguments spec.
+func zshQuote(s string) string {
+	return strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
+
+func writeFishCompletion(w io.Writer) {
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c splice -f -n __fish_use_subcommand -a %s -d %s\n", cmd.name, fishQuote(cmd.usage))
+	}
+
+	for _, cmd := range commands {
+		for _, f := range completionFlags(cmd) {
+			fmt.Fprintf(w, "complete -c splice -n '__fish_seen_subcommand_from %s' -o %s", cmd.name, f.name)
+			switch {
+			case f.bool:
+			case len(f.choices) > 0:
+				fmt.Fprintf(w, " -x -a %s", fishQuote(strings.Join(f.choices, " ")))
+			case f.arg == "path":
+				fmt.Fprint(w, " -r")
+			default:
+				fmt.Fprint(w, " -x")
+			}
+			fmt.Fprintf(w, " -d %s\n", fishQuote(f.usage))
+		}
+	}
+}
+
+// fishQuote returns s as a single-quoted fish string.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// extensionFormats maps output file extensions to exporter names.
+var extensionFormats = map[string]string{
+	".md":  "markdown",
+	".txt": "text",
+}
+
+func convertCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	from := choiceFlag(flags, "from", "", "input `format`, detected if not set", drum.ImporterNames()...)
+	to := choiceFlag(flags, "to", "", "output `format`, guessed from -o or text if not set", drum.ExporterNames()...)
+	target := flags.String("o", "", "write output to `path` instead of standard output")
+	groove := flags.String("groove", "", "lock timing of MIDI output to the groove of the MIDI template or WAV recording at `path`")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice convert [-from format] [-to format [-o path] [-groove path] | -output format] file")
+		fmt.Fprintln(flags.Output(), "The file may be - to read standard input. -output prints the converted pattern like inspect.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a single file")
+		}
+
+		var data []byte
+		var err error
+		if flags.Arg(0) == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(flags.Arg(0))
+		}
+		if err != nil {
+			return err
+		}
+
+		if *from == "" {
+			var ok bool
+			*from, ok = drum.DetectFormat(data)
+			if !ok {
+				return errors.New("unknown input format, set it with -from")
+			}
+		}
+
+		set := map[string]bool{}
+		flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if set["output"] && (*to != "" || *target != "" || *groove != "") {
+			return errors.New("-output can't be used with -to, -o or -groove")
+		}
+
+		if *to == "" {
+			*to = outputFormat(*target)
+		}
+
+		importer, _ := drum.LookupImporter(*from)
+		exporter, ok := drum.LookupExporter(*to)
+		if !ok {
+			return fmt.Errorf("unknown output format %q", *to)
+		}
+
+		p, err := importer.Import(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("reading %s: %v", *from, err)
+		}
+
+		if set["output"] {
+			return printPattern(*output, p)
+		}
+
+		if *groove != "" {
+			if *to != "midi" {
+				return errors.New("-groove supports only midi output")
+			}
+
+			g, err := readGroove(*groove, p.BPM())
+			if err != nil {
+				return err
+			}
+			exporter = drum.ExporterFunc(func(w io.Writer, p *drum.Pattern) error {
+				return drum.ExportMIDI(w, p, drum.WithGroove(g))
+			})
+		}
+
+		if *target == "" {
+			return exporter.Export(os.Stdout, p)
+		}
+
+		f, err := os.Create(*target)
+		if err != nil {
+			return err
+		}
+
+		err = exporter.Export(f, p)
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		return f.Close()
+	}
+}
+
+// outputFormat guesses the output format from the path's extension.
+func outputFormat(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	if format, ok := extensionFormats[ext]; ok {
+		return format
+	}
+	if _, ok := drum.LookupExporter(strings.TrimPrefix(ext, ".")); ok {
+		return strings.TrimPrefix(ext, ".")
+	}
+
+	return "text"
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func dedupeCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	threshold := flags.Float64("threshold", 0.95, "minimum similarity of near-duplicates, from 0 to 1, or above 1 for exact duplicates only")
+	action := choiceFlag(flags, "action", "", "`action` taken on exact duplicates, keeping the first file", "hardlink", "delete")
+	apply := flags.Bool("apply", false, "take the -action, instead of only printing it")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice dedupe [-threshold similarity] [-action hardlink|delete [-apply]] [-output format] dir...")
+		fmt.Fprintln(flags.Output(), "Reports exact and near-duplicate files of the directories.")
+		fmt.Fprintln(flags.Output(), "Actions are only taken on exact duplicates with the same bytes, and")
+		fmt.Fprintln(flags.Output(), "the same metadata sidecars when deleting, and only with -apply.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		dirs := parseInterspersed(flags)
+		if len(dirs) == 0 {
+			flags.Usage()
+			return errors.New("expected at least one directory")
+		}
+
+		paths, patterns, err := decodeLibrary(dirs)
+		if err != nil {
+			return err
+		}
+
+		duplicates := drum.FindDuplicates(patterns, *threshold)
+
+		err = printDuplicates(*output, paths, duplicates)
+		if err != nil {
+			return err
+		}
+
+		if *action == "" {
+			return nil
+		}
+
+		// Each exact duplicate is replaced by the first file of its group
+		keep := map[int]int{}
+		for _, d := range duplicates {
+			if !d.Exact {
+				continue
+			}
+			if _, ok := keep[d.B]; ok {
+				continue
+			}
+
+			kept, ok := keep[d.A]
+			if !ok {
+				kept = d.A
+			}
+			keep[d.B] = kept
+		}
+
+		actions := 0
+		for i := range paths {
+			kept, ok := keep[i]
+			if !ok {
+				continue
+			}
+
+			acted, err := dedupe(*action, paths[kept], paths[i], *apply)
+			if err != nil {
+				return err
+			}
+			if acted {
+				actions++
+			}
+		}
+
+		if !*apply && actions > 0 {
+			fmt.Fprintln(os.Stderr, "splice dedupe: dry run, use -apply to take the actions")
+		}
+
+		return nil
+	}
+}
+
+// printDuplicates writes duplicates of files at paths in the output format.
+func printDuplicates(output string, paths []string, duplicates []drum.Duplicate) error {
+	switch output {
+	case outputJSON:
+		type duplicate struct {
+			A          string  `json:"a"`
+			B          string  `json:"b"`
+			Exact      bool    `json:"exact"`
+			Similarity float64 `json:"similarity"`
+		}
+		out := []duplicate{}
+		for _, d := range duplicates {
+			out = append(out, duplicate{paths[d.A], paths[d.B], d.Exact, d.Similarity})
+		}
+		return printJSON(out)
+	case outputTable:
+		table := newTable()
+		fmt.Fprintf(table, "A\tB\tEXACT\tSIMILARITY\n")
+		for _, d := range duplicates {
+			fmt.Fprintf(table, "%s\t%s\t%v\t%.2f\n", paths[d.A], paths[d.B], d.Exact, d.Similarity)
+		}
+		return table.Flush()
+	default:
+		for _, d := range duplicates {
+			if d.Exact {
+				fmt.Printf("%s == %s\n", paths[d.A], paths[d.B])
+			} else {
+				fmt.Printf("%s ~ %s (%.2f)\n", paths[d.A], paths[d.B], d.Similarity)
+			}
+		}
+		return nil
+	}
+}
+
+// dedupe replaces the duplicate by a hard link to the kept file, or deletes
+// it with its metadata sidecar. Exact duplicates only have the same
+// canonical form, so files that aren't byte-identical, or whose sidecars
+// differ when deleting, are skipped. The action is printed to standard
+// error and only taken if applied. It returns false if there's nothing
+// to do.
+func dedupe(action, kept, duplicate string, apply bool) (bool, error) {
+	if action == "hardlink" {
+		ki, err := os.Stat(kept)
+		if err != nil {
+			return false, err
+		}
+		di, err := os.Stat(duplicate)
+		if err != nil {
+			return false, err
+		}
+		if os.SameFile(ki, di) {
+			return false, nil
+		}
+	}
+
+	same, err := identicalFiles(kept, duplicate)
+	if err != nil {
+		return false, err
+	}
+	if !same {
+		fmt.Fprintf(os.Stderr, "skipping %s: not byte-identical to %s\n", duplicate, kept)
+		return false, nil
+	}
+	if action == "delete" {
+		same, err = identicalFiles(drum.SidecarPath(kept), drum.SidecarPath(duplicate))
+		if err != nil {
+			return false, err
+		}
+		if !same {
+			fmt.Fprintf(os.Stderr, "skipping %s: metadata sidecar differs from the one of %s\n", duplicate, kept)
+			return false, nil
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %s (duplicate of %s)\n", action, duplicate, kept)
+	if !apply {
+		return true, nil
+	}
+
+	switch action {
+	case "hardlink":
+		// The link replaces the duplicate at once, so it's never missing
+		tmp := duplicate + ".dedupe"
+		err := os.Link(kept, tmp)
+		if err != nil {
+			return true, err
+		}
+		err = os.Rename(tmp, duplicate)
+		if err != nil {
+			os.Remove(tmp)
+		}
+		return true, err
+	default:
+		err := os.Remove(duplicate)
+		if err != nil {
+			return true, err
+		}
+		err = os.Remove(drum.SidecarPath(duplicate))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return true, err
+	}
+}
+
+// identicalFiles returns true if files at both paths have the same bytes,
+// or neither exists.
+func identicalFiles(a, b string) (bool, error) {
+	da, errA := os.ReadFile(a)
+	db, errB := os.ReadFile(b)
+	if os.IsNotExist(errA) && os.IsNotExist(errB) {
+		return true, nil
+	}
+	if os.IsNotExist(errA) || os.IsNotExist(errB) {
+		return false, nil
+	}
+	if errA != nil {
+		return false, errA
+	}
+	if errB != nil {
+		return false, errB
+	}
+
+	return bytes.Equal(da, db), nil
+}
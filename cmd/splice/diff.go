@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// diffCommand exits with status 0 if the files are the same, 1 if they
+// differ and 2 if they couldn't be compared, like diff(1).
+func diffCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	quiet := flags.Bool("q", false, "only set the exit status, don't print differences")
+	output := outputFlag(flags)
+	report := flags.String("html", "", "also write an HTML report showing both grids side by side to the `path`")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice diff [-q] [-output format] [-html path] a.splice b.splice")
+		fmt.Fprintln(flags.Output(), "Exits with 0 if files are the same, 1 if they differ and 2 on errors.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 2 {
+			flags.Usage()
+			return exitStatus(2)
+		}
+
+		var patterns [2]*drum.Pattern
+		for i := range patterns {
+			p, err := drum.DecodeFile(flags.Arg(i))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "splice diff: %v\n", err)
+				return exitStatus(2)
+			}
+			patterns[i] = p
+		}
+
+		diffs := drum.Diff(patterns[0], patterns[1])
+
+		if *report != "" {
+			err := writeDiffReport(*report, flags.Arg(0), flags.Arg(1), patterns[0], patterns[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "splice diff: %v\n", err)
+				return exitStatus(2)
+			}
+		}
+
+		if !*quiet {
+			err := printDiff(*output, flags.Arg(0), flags.Arg(1), diffs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "splice diff: %v\n", err)
+				return exitStatus(2)
+			}
+		}
+
+		if len(diffs) > 0 {
+			return exitStatus(1)
+		}
+
+		return nil
+	}
+}
+
+// printDiff writes differences between files a and b in the output format.
+func printDiff(output, a, b string, diffs []drum.Difference) error {
+	switch output {
+	case outputJSON:
+		if diffs == nil {
+			diffs = []drum.Difference{}
+		}
+		return printJSON(diffs)
+	case outputTable:
+		if len(diffs) == 0 {
+			return nil
+		}
+		table := newTable()
+		fmt.Fprintf(table, "KIND\tTRACK\tSTEP\tOLD\tNEW\n")
+		for _, d := range diffs {
+			track, step := "", ""
+			switch d.Kind {
+			case drum.DiffVersion, drum.DiffTempo:
+			case drum.DiffTempoChange:
+				step = fmt.Sprint(d.Step + 1)
+			case drum.DiffStep:
+				track, step = fmt.Sprint(d.TrackID), fmt.Sprint(d.Step+1)
+			default:
+				track = fmt.Sprint(d.TrackID)
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", d.Kind, track, step, d.Old, d.New)
+		}
+		return table.Flush()
+	default:
+		if len(diffs) == 0 {
+			return nil
+		}
+		fmt.Printf("--- %s\n+++ %s\n", a, b)
+		for _, d := range diffs {
+			fmt.Println(d)
+		}
+		return nil
+	}
+}
+
+// writeDiffReport writes the HTML report of differences between files
+// a and b to the path.
+func writeDiffReport(path, a, b string, pa, pb *drum.Pattern) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = drum.ExportDiffHTML(f, pa, pb, drum.WithDiffLabels(a, b))
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestDiffExitStatus(t *testing.T) {
+	for _, tt := range []struct {
+		args   []string
+		status int
+	}{
+		{[]string{fixture("pattern_1.splice"), fixture("pattern_1.splice")}, 0},
+		{[]string{fixture("pattern_1.splice"), fixture("pattern_2.splice")}, 1},
+		{[]string{"-q", fixture("pattern_1.splice"), fixture("pattern_2.splice")}, 1},
+		{[]string{fixture("pattern_1.splice"), fixture("missing.splice")}, 2},
+		{[]string{fixture("pattern_1.splice")}, 2},
+	} {
+		_, err := runCommand(t, "diff", tt.args...)
+		if got := status(err); got != tt.status {
+			t.Errorf("diff %v: expected status %d, got %d (%v)", tt.args, tt.status, got, err)
+		}
+	}
+}
+
+func TestDiffTempoChanges(t *testing.T) {
+	dir := library(t, "pattern_1.splice")
+	a, b := filepath.Join(dir, "pattern_1.splice"), filepath.Join(dir, "changed.splice")
+
+	p, err := drum.DecodeFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetTempoChange(8, 140)
+	if err := drum.EncodeFile(p, b); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCommand(t, "diff", "-output", "table", a, b)
+	if status(err) != 1 {
+		t.Fatalf("expected status 1 for differing tempo changes, got %v", err)
+	}
+	if !strings.Contains(out, "tempo-change") {
+		t.Errorf("expected a tempo-change row, got:\n%s", out)
+	}
+}
+
+func TestDiffOutput(t *testing.T) {
+	out, err := runCommand(t, "diff", "-output", "json", fixture("pattern_1.splice"), fixture("pattern_1.splice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "[]\n" {
+		t.Errorf("expected an empty JSON list for same files, got %q", out)
+	}
+
+	out, _ = runCommand(t, "diff", "-output", "json", fixture("pattern_1.splice"), fixture("pattern_2.splice"))
+	var diffs []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &diffs); err != nil {
+		t.Fatalf("%v in:\n%s", err, out)
+	}
+	if len(diffs) == 0 {
+		t.Error("expected differences")
+	}
+
+	out, _ = runCommand(t, "diff", "-q", fixture("pattern_1.splice"), fixture("pattern_2.splice"))
+	if out != "" {
+		t.Errorf("expected no output with -q, got:\n%s", out)
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// gitAttributes are lines of .gitattributes making git use splice for
+// .splice files.
+var gitAttributes = []string{"*.splice diff=splice", "*.splice merge=splice"}
+
+func gitTextconvCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("git-textconv", flag.ExitOnError)
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice git-textconv [-output format] file.splice")
+		fmt.Fprintln(flags.Output(), "Prints the file in the canonical text format, for git diff.")
+		fmt.Fprintln(flags.Output(), "See splice install-gitconfig.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a single file")
+		}
+
+		p, err := drum.DecodeFile(flags.Arg(0))
+		if err != nil && *output != outputText {
+			return err
+		}
+		if err != nil {
+			// Failing would make git diff fail, so the error is shown
+			// in the diff instead
+			fmt.Printf("# can't decode pattern - %v\n", err)
+			return nil
+		}
+
+		if *output != outputText {
+			return printPattern(*output, p)
+		}
+
+		fmt.Print(drum.FormatCanonical(p))
+		return nil
+	}
+}
+
+func gitMergeCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("git-merge", flag.ExitOnError)
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice git-merge [-output format] base ours theirs")
+		fmt.Fprintln(flags.Output(), "Merges changes of ours and theirs since base into ours, as a git merge driver")
+		fmt.Fprintln(flags.Output(), "called with %O %A %B. Exits with 1 on conflicts, leaving ours in the canonical")
+		fmt.Fprintln(flags.Output(), "text format with conflict markers. Once resolved, convert it back with:")
+		fmt.Fprintln(flags.Output(), "  splice convert -to splice -o file.splice file.splice")
+		fmt.Fprintln(flags.Output(), "Conflicts are printed to standard error, or to standard output in other formats.")
+		fmt.Fprintln(flags.Output(), "See splice install-gitconfig.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 3 {
+			flags.Usage()
+			return exitStatus(2)
+		}
+
+		var patterns [3]*drum.Pattern
+		for i := range patterns {
+			p, err := drum.DecodeFile(flags.Arg(i))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "splice git-merge: %v\n", err)
+				return exitStatus(2)
+			}
+			patterns[i] = p
+		}
+
+		result := drum.Merge3(patterns[0], patterns[1], patterns[2])
+		ours := flags.Arg(1)
+
+		err := printConflicts(*output, ours, result.Conflicts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "splice git-merge: %v\n", err)
+			return exitStatus(2)
+		}
+
+		if len(result.Conflicts) > 0 {
+
+			err := os.WriteFile(ours, []byte(result.Text()), 0644)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "splice git-merge: %v\n", err)
+				return exitStatus(2)
+			}
+			return exitStatus(1)
+		}
+
+		// Sidecars aren't written, as git merges files in temporary
+		// paths next to the merged one
+		data, _, err := drum.EncodeBytes(result.Pattern)
+		if err == nil {
+			err = os.WriteFile(ours, data, 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "splice git-merge: %v\n", err)
+			return exitStatus(2)
+		}
+
+		return nil
+	}
+}
+
+// printConflicts writes merge conflicts of the file in the output format.
+func printConflicts(output, path string, conflicts []drum.MergeConflict) error {
+	switch output {
+	case outputJSON:
+		if conflicts == nil {
+			conflicts = []drum.MergeConflict{}
+		}
+		return printJSON(struct {
+			Path      string               `json:"path"`
+			Conflicts []drum.MergeConflict `json:"conflicts"`
+		}{path, conflicts})
+	case outputTable:
+		table := newTable()
+		fmt.Fprintf(table, "PATH\tCONFLICT\n")
+		for _, c := range conflicts {
+			fmt.Fprintf(table, "%s\t%s\n", path, c)
+		}
+		return table.Flush()
+	default:
+		for _, c := range conflicts {
+			fmt.Fprintf(os.Stderr, "splice git-merge: conflict in %s\n", c)
+		}
+		return nil
+	}
+}
+
+func installGitconfigCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("install-gitconfig", flag.ExitOnError)
+	global := flags.Bool("global", false, "configure git for all repositories of the user, instead of the current one")
+	splice := flags.String("splice", "splice", "`command` git runs to call splice")
+	dryRun := flags.Bool("n", false, "only print the changes, don't make them")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice install-gitconfig [-global] [-splice command] [-n] [-output format]")
+		fmt.Fprintln(flags.Output(), "Configures git to show .splice files in the canonical text format in diffs")
+		fmt.Fprintln(flags.Output(), "and to merge them with splice git-merge.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 0 {
+			flags.Usage()
+			return errors.New("unexpected arguments")
+		}
+
+		scope := "--local"
+		if *global {
+			scope = "--global"
+		}
+
+		type setting struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+		config := []setting{
+			{"diff.splice.textconv", *splice + " git-textconv"},
+			{"merge.splice.name", "splice pattern merge"},
+			{"merge.splice.driver", *splice + " git-merge %O %A %B"},
+		}
+		for _, c := range config {
+			if *output == outputText {
+				fmt.Printf("git config %s %s %q\n", scope, c.Key, c.Value)
+			}
+			if *dryRun {
+				continue
+			}
+
+			_, err := git("config", scope, c.Key, c.Value)
+			if err != nil {
+				return err
+			}
+		}
+
+		attributes, err := gitAttributesPath(*global)
+		if err != nil {
+			return err
+		}
+
+		added, err := addLines(attributes, gitAttributes, *dryRun)
+		if err != nil {
+			return err
+		}
+
+		switch *output {
+		case outputJSON:
+			if added == nil {
+				added = []string{}
+			}
+			return printJSON(struct {
+				Scope          string    `json:"scope"`
+				DryRun         bool      `json:"dryRun"`
+				Config         []setting `json:"config"`
+				AttributesFile string    `json:"attributesFile"`
+				Attributes     []string  `json:"attributes"`
+			}{strings.TrimPrefix(scope, "--"), *dryRun, config, attributes, added})
+		case outputTable:
+			table := newTable()
+			fmt.Fprintf(table, "FILE\tSETTING\n")
+			for _, c := range config {
+				fmt.Fprintf(table, "git config %s\t%s = %s\n", scope, c.Key, c.Value)
+			}
+			for _, line := range added {
+				fmt.Fprintf(table, "%s\t%s\n", attributes, line)
+			}
+			return table.Flush()
+		default:
+			for _, line := range added {
+				fmt.Printf("echo %q >> %s\n", line, attributes)
+			}
+			return nil
+		}
+	}
+}
+
+// git runs git with the arguments and returns its trimmed output.
+func git(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitAttributesPath returns the path of the attributes file of the current
+// repository, or the user's global one.
+func gitAttributesPath(global bool) (string, error) {
+	if !global {
+		root, err := git("rev-parse", "--show-toplevel")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(root, ".gitattributes"), nil
+	}
+
+	// git config fails if the option isn't set
+	if path, err := git("config", "--global", "--path", "core.attributesFile"); err == nil && path != "" {
+		return path, nil
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "git", "attributes"), nil
+}
+
+// addLines appends lines missing from the file to it, creating the file
+// if needed, and returns them.
+func addLines(path string, lines []string, dryRun bool) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	existing := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, line := range lines {
+		if !existing[line] {
+			missing = append(missing, line)
+		}
+	}
+	if len(missing) == 0 || dryRun {
+		return missing, nil
+	}
+
+	text := strings.Join(missing, "\n") + "\n"
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		text = "\n" + text
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = f.WriteString(text)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return missing, f.Close()
+}
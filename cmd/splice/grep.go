@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func grepCommand() (*flag.FlagSet, func() error) {
+	var query drum.Query
+
+	flags := flag.NewFlagSet("grep", flag.ExitOnError)
+	flags.StringVar(&query.Track, "track", "", "match patterns with a track whose name matches `glob`")
+	tempo := flags.String("tempo", "", "match patterns with tempo in `range`, e.g. 118-128 or 120")
+	flags.StringVar(&query.Tag, "tag", "", "match patterns with a track tagged `tag`")
+	flags.Float64Var(&query.MinDensity, "min-density", 0, "minimum `ratio` of hits of the matching track, or the whole pattern")
+	sortBy := choiceFlag(flags, "sort", "path", "order matches by `key`", "path", "density", "syncopation", "complexity")
+	keyPath := flags.String("key", "", "verify signatures against the public key at `path` rather than keys stored with them")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice grep [-track glob] [-tag tag] [-tempo range] [-min-density ratio] [-sort key] [-key path] [-output format] dir...")
+		fmt.Fprintln(flags.Output(), "Lists matching files with the status of their signatures: unsigned, valid, self-signed")
+		fmt.Fprintln(flags.Output(), "without -key, unknown signer or invalid.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() == 0 {
+			flags.Usage()
+			return errors.New("expected at least one directory")
+		}
+
+		var pub ed25519.PublicKey
+		if *keyPath != "" {
+			key, err := readKey(*keyPath, ed25519.PublicKeySize)
+			if err != nil {
+				return err
+			}
+			pub = key
+		}
+
+		return grep(flags.Args(), query, pub, *tempo, *sortBy, *output)
+	}
+}
+
+// grep prints files found in dirs matching the query, sorted by the key,
+// with signatures verified against pub, or keys stored with them if nil.
+// Scores are sorted in descending order.
+func grep(dirs []string, query drum.Query, pub ed25519.PublicKey, tempo, sortBy, output string) error {
+	type match struct {
+		Path        string  `json:"path"`
+		Version     string  `json:"version"`
+		Tempo       float32 `json:"tempo"`
+		Tracks      int     `json:"tracks"`
+		Density     float64 `json:"density"`
+		Syncopation float64 `json:"syncopation"`
+		Complexity  float64 `json:"complexity"`
+		Signature   string  `json:"signature"`
+		Signer      string  `json:"signer,omitempty"`
+	}
+	matches := []match{}
+
+	if tempo != "" {
+		var err error
+		query.MinTempo, query.MaxTempo, err = parseTempoRange(tempo)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Files that can't be decoded are reported and skipped
+	failed := 0
+	for _, dir := range dirs {
+		err := drum.WalkDirFS(os.DirFS(dir), ".", func(path string, p *drum.Pattern, err error) error {
+			path = filepath.Join(dir, filepath.FromSlash(path))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "splice grep: %s: %v\n", path, err)
+				failed++
+				return nil
+			}
+
+			ok, err := query.Match(p)
+			if err != nil || !ok {
+				return err
+			}
+
+			m := match{
+				Path:        path,
+				Version:     p.Version,
+				Tempo:       p.Tempo,
+				Tracks:      len(p.Tracks),
+				Density:     p.Density(),
+				Syncopation: p.Syncopation(),
+				Complexity:  p.Complexity(),
+				Signature:   signatureStatus(path, p, pub),
+			}
+			if p.Signature != nil {
+				m.Signer = hex.EncodeToString(p.Signature.PublicKey)
+			}
+			matches = append(matches, m)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		switch sortBy {
+		case "density":
+			return matches[i].Density > matches[j].Density
+		case "syncopation":
+			return matches[i].Syncopation > matches[j].Syncopation
+		case "complexity":
+			return matches[i].Complexity > matches[j].Complexity
+		default:
+			return false
+		}
+	})
+
+	var err error
+	switch output {
+	case outputJSON:
+		err = printJSON(matches)
+	case outputTable:
+		table := newTable()
+		fmt.Fprintf(table, "PATH\tVERSION\tTEMPO\tTRACKS\tDENSITY\tSYNCOPATION\tCOMPLEXITY\tSIGNATURE\n")
+		for _, m := range matches {
+			fmt.Fprintf(table, "%s\t%s\t%v\t%d\t%.2f\t%.2f\t%.2f\t%s\n",
+				m.Path, m.Version, m.Tempo, m.Tracks, m.Density, m.Syncopation, m.Complexity, m.Signature)
+		}
+		err = table.Flush()
+	default:
+		for _, m := range matches {
+			fmt.Printf("%s\t%s\n", m.Path, m.Signature)
+		}
+	}
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("%d files couldn't be decoded", failed)
+	}
+
+	return err
+}
+
+// parseTempoRange parses "min-max" or a single tempo.
+func parseTempoRange(s string) (float32, float32, error) {
+	min, max, found := strings.Cut(s, "-")
+	if !found {
+		max = min
+	}
+
+	lo, err := strconv.ParseFloat(min, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid tempo range %q", s)
+	}
+	hi, err := strconv.ParseFloat(max, 32)
+	if err != nil || hi < lo {
+		return 0, 0, fmt.Errorf("invalid tempo range %q", s)
+	}
+
+	return float32(lo), float32(hi), nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// library returns a directory with copies of the fixtures.
+func library(t *testing.T, names ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, name := range names {
+		data, err := os.ReadFile(fixture(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestGrep(t *testing.T) {
+	dir := library(t, "pattern_1.splice", "pattern_2.splice", "pattern_3.splice")
+
+	for _, tt := range []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{dir}, []string{"pattern_1.splice", "pattern_2.splice", "pattern_3.splice"}},
+		{[]string{"-tempo", "120", dir}, []string{"pattern_1.splice"}},
+		{[]string{"-tempo", "100-130", dir}, []string{"pattern_1.splice", "pattern_3.splice"}},
+		{[]string{"-track", "Lo*", dir}, []string{"pattern_3.splice"}},
+		{[]string{"-tempo", "200-300", dir}, nil},
+	} {
+		out, err := runCommand(t, "grep", tt.args...)
+		if err != nil {
+			t.Fatalf("grep %v: %v", tt.args, err)
+		}
+
+		var expected string
+		for _, name := range tt.expected {
+			expected += filepath.Join(dir, name) + "\tunsigned\n"
+		}
+		if out != expected {
+			t.Errorf("grep %v: expected:\n%s\ngot:\n%s", tt.args, expected, out)
+		}
+	}
+}
+
+func TestGrepOutput(t *testing.T) {
+	dir := library(t, "pattern_1.splice")
+
+	out, err := runCommand(t, "grep", "-output", "json", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var matches []struct {
+		Path  string  `json:"path"`
+		Tempo float32 `json:"tempo"`
+	}
+	if err := json.Unmarshal([]byte(out), &matches); err != nil {
+		t.Fatalf("%v in:\n%s", err, out)
+	}
+	if len(matches) != 1 || matches[0].Tempo != 120 {
+		t.Errorf("expected pattern_1 at 120 BPM, got %+v", matches)
+	}
+
+	out, err = runCommand(t, "grep", "-output", "table", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "PATH") {
+		t.Errorf("expected a header and a row, got:\n%s", out)
+	}
+}
+
+func TestGrepUndecodable(t *testing.T) {
+	dir := library(t, "pattern_1.splice")
+	if err := os.WriteFile(filepath.Join(dir, "broken.splice"), []byte("SPLOCE"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Matches are printed, but the command fails
+	out, err := runCommand(t, "grep", dir)
+	if err == nil {
+		t.Error("expected an error for the undecodable file")
+	}
+	if out != filepath.Join(dir, "pattern_1.splice")+"\tunsigned\n" {
+		t.Errorf("expected pattern_1 to match, got:\n%s", out)
+	}
+}
+
+func TestGrepSignatures(t *testing.T) {
+	dir := library(t, "pattern_1.splice", "pattern_2.splice", "pattern_3.splice")
+
+	pub, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pattern_2 is changed after signing
+	for _, name := range []string{"pattern_1.splice", "pattern_2.splice"} {
+		path := filepath.Join(dir, name)
+		p, err := drum.DecodeFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := drum.Sign(p, private); err != nil {
+			t.Fatal(err)
+		}
+		if err := drum.WriteSidecar(p, path); err != nil {
+			t.Fatal(err)
+		}
+		if name != "pattern_2.splice" {
+			continue
+		}
+
+		p.Tempo++
+		if err := drum.EncodeFile(p, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeKey := func(key ed25519.PublicKey) string {
+		path := filepath.Join(t.TempDir(), "key")
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(key)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	for _, tt := range []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{dir}, []string{"self-signed", "invalid", "unsigned"}},
+		{[]string{"-key", writeKey(pub), dir}, []string{"valid", "invalid", "unsigned"}},
+		{[]string{"-key", writeKey(other), dir}, []string{"unknown signer", "unknown signer", "unsigned"}},
+	} {
+		out, err := runCommand(t, "grep", append([]string{"-output", "json"}, tt.args...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var matches []struct {
+			Signature string `json:"signature"`
+			Signer    string `json:"signer"`
+		}
+		if err := json.Unmarshal([]byte(out), &matches); err != nil {
+			t.Fatalf("%v in:\n%s", err, out)
+		}
+
+		var statuses []string
+		for _, m := range matches {
+			statuses = append(statuses, m.Signature)
+		}
+		if !reflect.DeepEqual(statuses, tt.expected) {
+			t.Errorf("grep %v: expected %v, got %v", tt.args, tt.expected, statuses)
+		}
+		if matches[0].Signer != hex.EncodeToString(pub) || matches[2].Signer != "" {
+			t.Errorf("grep %v: expected the signer of pattern_1 only, got %+v", tt.args, matches)
+		}
+	}
+}
+
+func TestParseTempoRange(t *testing.T) {
+	for _, tt := range []struct {
+		s        string
+		min, max float32
+		valid    bool
+	}{
+		{"120", 120, 120, true},
+		{"118-128", 118, 128, true},
+		{"98.5-99", 98.5, 99, true},
+		{"128-118", 0, 0, false},
+		{"fast", 0, 0, false},
+		{"120-", 0, 0, false},
+		{"", 0, 0, false},
+	} {
+		min, max, err := parseTempoRange(tt.s)
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid %v, got %v", tt.s, tt.valid, err)
+			continue
+		}
+		if min != tt.min || max != tt.max {
+			t.Errorf("%q: expected %v-%v, got %v-%v", tt.s, tt.min, tt.max, min, max)
+		}
+	}
+}
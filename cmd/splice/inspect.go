@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func inspectCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	hex := flags.Bool("hex", false, "print annotated hex dump of the file")
+	var opts drum.RenderOptions
+	theme := themeFlag(flags)
+	flags.BoolVar(&opts.Align, "align", false, "align steps using spaces, aware of wide characters in track names")
+	flags.IntVar(&opts.MaxLabelWidth, "label-width", 0, "truncate track labels to `columns`, implies -align")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice inspect [-hex] [-theme name] [-align] [-label-width columns] [-output format] file.splice")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a single file")
+		}
+
+		data, err := ioutil.ReadFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		if *hex {
+			if *output != outputText {
+				return errors.New("hex dump supports only text output")
+			}
+			return drum.AnnotateHex(os.Stdout, data)
+		}
+
+		p := &drum.Pattern{}
+		err = p.UnmarshalBinary(data)
+		if err != nil {
+			return err
+		}
+
+		if *theme != "" || opts.Align || opts.MaxLabelWidth > 0 {
+			if *output != outputText {
+				return errors.New("render options support only text output")
+			}
+
+			if *theme != "" {
+				t, err := loadTheme(*theme)
+				if err != nil {
+					return err
+				}
+				opts.Theme = &t
+			}
+
+			fmt.Print(p.Render(opts))
+			return nil
+		}
+
+		return printPattern(*output, p)
+	}
+}
@@ -0,0 +1,98 @@
+// Command splice is a tool for working with .splice drum machine files.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// exitStatus is returned by commands to exit with the status
+// without printing an error.
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
+// command is a single splice subcommand.
+type command struct {
+	name  string
+	usage string
+	// setup defines the command's flags and returns them with a function
+	// running the command once they're parsed
+	setup func() (*flag.FlagSet, func() error)
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"cluster", "group a library of files into similar patterns", clusterCommand},
+		{"completion", "print shell completion script", completionCommand},
+		{"convert", "convert a file between formats", convertCommand},
+		{"dedupe", "report duplicate files of a library", dedupeCommand},
+		{"diff", "print differences between two files", diffCommand},
+		{"git-merge", "merge files as a git merge driver", gitMergeCommand},
+		{"git-textconv", "print a file as text for git diff", gitTextconvCommand},
+		{"grep", "list files of a library matching a query", grepCommand},
+		{"inspect", "print a decoded file or its annotated hex dump", inspectCommand},
+		{"install-gitconfig", "configure git to diff files as text", installGitconfigCommand},
+		{"pack", "archive a directory of files with attribution", packCommand},
+		{"play", "play a file, printing triggered tracks", playCommand},
+		{"push", "transfer a file to a drum machine over MIDI", pushCommand},
+		{"render", "render a file to a WAV file", renderCommand},
+		{"repair", "fix common corruptions of a file", repairCommand},
+		{"sign", "sign a file to prove its authorship", signCommand},
+		{"stats", "print statistics of a library of files", statsCommand},
+		{"transform", "apply transforms to a file", transformCommand},
+		{"verify", "print verification status of signed files", verifyCommand},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			flags, run := cmd.setup()
+			flags.Parse(os.Args[2:])
+
+			err := run()
+
+			var status exitStatus
+			if errors.As(err, &status) {
+				os.Exit(int(status))
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "splice %s: %v\n", cmd.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "splice: unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: splice <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+
+	width := 0
+	for _, cmd := range commands {
+		if len(cmd.name) > width {
+			width = len(cmd.name)
+		}
+	}
+
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-*s %s\n", width, cmd.name, cmd.usage)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixture returns the path of a fixture of the drum package.
+func fixture(name string) string {
+	return filepath.Join("..", "..", "drum", "fixtures", name)
+}
+
+// runCommand runs the command with the arguments, returning its standard
+// output and error.
+func runCommand(t *testing.T, name string, args ...string) (string, error) {
+	t.Helper()
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		flags, run := cmd.setup()
+		flags.SetOutput(io.Discard)
+		if err := flags.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.CreateTemp(t.TempDir(), "stdout")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		stdout := os.Stdout
+		os.Stdout = f
+		err = run()
+		os.Stdout = stdout
+
+		out, readErr := os.ReadFile(f.Name())
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+
+		return string(out), err
+	}
+
+	t.Fatalf("no command %q", name)
+	return "", nil
+}
+
+// status returns the exit status the command's error results in.
+func status(err error) int {
+	var s exitStatus
+	switch {
+	case errors.As(err, &s):
+		return int(s)
+	case err != nil:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func TestCommandsOutputFlag(t *testing.T) {
+	for _, cmd := range commands {
+		flags, _ := cmd.setup()
+		if flags.Lookup("output") == nil {
+			t.Errorf("%s has no -output flag", cmd.name)
+		}
+	}
+}
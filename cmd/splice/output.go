@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// Formats of the -output flag.
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputTable = "table"
+	outputHTML  = "html"
+)
+
+// choiceValue is a flag value restricted to a set of choices,
+// which are also offered by shell completions.
+type choiceValue struct {
+	value   *string
+	choices []string
+}
+
+func (c choiceValue) String() string {
+	if c.value == nil {
+		return ""
+	}
+
+	return *c.value
+}
+
+func (c choiceValue) Set(s string) error {
+	for _, choice := range c.choices {
+		if s == choice {
+			*c.value = s
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected one of: %s", strings.Join(c.choices, ", "))
+}
+
+// choiceFlag defines a flag accepting only the given choices.
+func choiceFlag(flags *flag.FlagSet, name, value, usage string, choices ...string) *string {
+	p := &value
+	flags.Var(choiceValue{p, choices}, name, usage+", one of: "+strings.Join(choices, ", "))
+
+	return p
+}
+
+// outputFlag defines the -output flag selecting the output format.
+func outputFlag(flags *flag.FlagSet) *string {
+	return choiceFlag(flags, "output", outputText, "output `format`", outputText, outputJSON, outputTable)
+}
+
+// printJSON writes v to standard output as indented JSON.
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// newTable returns a writer aligning tab-separated columns on standard output.
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+}
+
+// printPattern writes the pattern to standard output in the output format.
+func printPattern(output string, p *drum.Pattern) error {
+	switch output {
+	case outputJSON:
+		return printJSON(p)
+	case outputTable:
+		table := newTable()
+		fmt.Fprintf(table, "ID\tNAME\tSTEPS\tDENSITY\n")
+		for _, track := range p.Tracks {
+			fmt.Fprintf(table, "%d\t%s\t%d\t%.2f\n", track.ID, track.Name, track.Len(), track.Density())
+		}
+		return table.Flush()
+	default:
+		fmt.Print(p)
+		return nil
+	}
+}
+
+// themeFlag defines the -theme flag selecting a registered theme
+// or a theme file.
+func themeFlag(flags *flag.FlagSet) *string {
+	return flags.String("theme", "", "render with theme `name` ("+strings.Join(drum.ThemeNames(), ", ")+") or path of a JSON theme file")
+}
+
+// loadTheme returns the registered theme with the name, or reads it
+// from the file at name.
+func loadTheme(name string) (drum.Theme, error) {
+	if theme, ok := drum.LookupTheme(name); ok {
+		return theme, nil
+	}
+
+	return drum.LoadTheme(name)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// urlsFlag is a flag value collecting URLs of repeated flags.
+type urlsFlag []string
+
+func (f *urlsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *urlsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func packCommand() (*flag.FlagSet, func() error) {
+	var overrides drum.PackManifest
+	var urls urlsFlag
+
+	flags := flag.NewFlagSet("pack", flag.ExitOnError)
+	target := flags.String("o", "", "write the pack to `path`")
+	flags.StringVar(&overrides.Name, "name", "", "`name` of the pack")
+	flags.StringVar(&overrides.Author, "author", "", "`author` of the pack")
+	flags.StringVar(&overrides.License, "license", "", "`license` of the pack, e.g. CC-BY-4.0")
+	flags.Var(&urls, "url", "`URL` of the pack's homepage or sources, may be repeated")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice pack [-name name] [-author author] [-license license] [-url URL]... [-output format] -o pack.zip dir")
+		fmt.Fprintln(flags.Output(), "Archives patterns of the directory with a manifest of their attribution.")
+		fmt.Fprintf(flags.Output(), "Per-file credits are kept from the directory's %s, if present.\n", drum.PackManifestName)
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a single directory")
+		}
+		if *target == "" {
+			flags.Usage()
+			return errors.New("-o is required")
+		}
+
+		fsys := os.DirFS(flags.Arg(0))
+
+		var m drum.PackManifest
+		data, err := fs.ReadFile(fsys, drum.PackManifestName)
+		if err == nil {
+			err = json.Unmarshal(data, &m)
+			if err != nil {
+				return fmt.Errorf("%s: %v", drum.PackManifestName, err)
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		if overrides.Name != "" {
+			m.Name = overrides.Name
+		}
+		if overrides.Author != "" {
+			m.Author = overrides.Author
+		}
+		if overrides.License != "" {
+			m.License = overrides.License
+		}
+		if len(urls) > 0 {
+			m.URLs = urls
+		}
+
+		m, err = drum.BuildPackManifest(fsys, m)
+		if err != nil {
+			return err
+		}
+
+		err = m.Validate(fsys)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(*target)
+		if err != nil {
+			return err
+		}
+
+		err = drum.WritePack(f, fsys, m)
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+
+		signed := 0
+		for _, file := range m.Files {
+			if file.Signer != "" {
+				signed++
+			}
+		}
+
+		switch *output {
+		case outputJSON:
+			return printJSON(struct {
+				Path     string `json:"path"`
+				Name     string `json:"name"`
+				Patterns int    `json:"patterns"`
+				Signed   int    `json:"signed"`
+			}{*target, m.Name, len(m.Files), signed})
+		case outputTable:
+			table := newTable()
+			fmt.Fprintf(table, "PATH\tNAME\tPATTERNS\tSIGNED\n")
+			fmt.Fprintf(table, "%s\t%s\t%d\t%d\n", *target, m.Name, len(m.Files), signed)
+			return table.Flush()
+		default:
+			fmt.Printf("packed %d patterns, %d with valid signatures\n", len(m.Files), signed)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+	"github.com/m110/go-challenge-1/drum/audio"
+	"github.com/m110/go-challenge-1/drum/dmx"
+	"github.com/m110/go-challenge-1/drum/gpio"
+	"github.com/m110/go-challenge-1/drum/pubsub"
+	"github.com/m110/go-challenge-1/drum/sequencer"
+)
+
+func playCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("play", flag.ExitOnError)
+	loop := flags.Bool("loop", false, "play in a loop until interrupted")
+	count := flags.Int("count", 1, "play the pattern `n` times")
+	tempo := flags.Float64("tempo", 0, "override the pattern's tempo with `bpm`")
+	countIn := flags.Int("count-in", 0, "count in for `n` bars before playing")
+	speed := flags.Float64("speed", 1, "scale the tempo by `factor`, e.g. 0.5 for half speed")
+	section := flags.String("section", "", "play only steps `from-to`, e.g. 5-8")
+	scene := flags.String("scene", "", "play the scene `name` instead of the active one")
+	chain := flags.String("scene-chain", "", "switch scenes each loop following the `chain`, e.g. \"AABB ABAC\"")
+	weights := flags.String("scene-weights", "", "switch scenes each loop at random with `weights`, e.g. A=3,B=1")
+	seed := flags.Int64("seed", 0, "`seed` of random scene switching")
+	playAudio := flags.Bool("audio", false, "also play audio through aplay, paplay or SoX, whichever is installed first")
+	device := flags.String("device", "", "play audio on the `device`, or list devices with \"list\"; implies -audio")
+	kit := flags.String("kit", "", "play audio with WAV samples in `dir` named after tracks, e.g. kick.wav; implies -audio")
+	pins := flags.String("gpio", "", "also pulse GPIO pins on hits of tracks mapped by `pins`, e.g. kick=17,snare=27")
+	pulse := flags.Duration("pulse", gpio.DefaultPulseWidth, "`width` of GPIO pulses of accented hits, scaled by velocities of other hits")
+	artnet := flags.String("artnet", "", "also flash lights mapped by -dmx over Art-Net to the node at `host`")
+	lights := flags.String("dmx", "", "DMX `channels` of tracks' lights, e.g. kick=1,snare=2:3:4")
+	universe := flags.Uint("universe", 0, "Art-Net `universe` of the lights")
+	decay := flags.Duration("decay", dmx.DefaultDecay, "`time` flashes of lights fade out over")
+	publish := flags.String("publish", "", "also publish triggers as JSON to the broker and topic at `URL`, e.g. mqtt://host/drums/hits or nats://host/drums.hits")
+	midiIn := flags.String("midi-in", "", "follow clock, start, stop and song position messages and MMC commands of the raw MIDI port at `path`")
+	mmcID := flags.Uint("mmc-id", 0, "MMC device `ID` of the player")
+	sessionPath := flags.String("session", "", "resume the session saved in the `file` and save it there once stopped")
+	latencies := flags.String("latency", "", "`latencies` of outputs to compensate for, e.g. dmx=25ms,publish=40ms")
+	routing := flags.String("route", "", "route only some tracks to outputs with `routes`, e.g. dmx=kick:snare")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice play [-loop] [-count n] [-tempo bpm] [-count-in n] [-speed factor] [-section from-to]")
+		fmt.Fprintln(flags.Output(), "       [-scene name | -scene-chain chain | -scene-weights weights [-seed seed]]")
+		fmt.Fprintln(flags.Output(), "       [-audio] [-device device] [-kit dir]")
+		fmt.Fprintln(flags.Output(), "       [-gpio pins [-pulse width]] [-artnet host -dmx channels [-universe universe] [-decay time]]")
+		fmt.Fprintln(flags.Output(), "       [-publish URL] [-latency latencies] [-route routes] [-midi-in path [-mmc-id ID]]")
+		fmt.Fprintln(flags.Output(), "       [-session file] [-output format] [file.splice]")
+		fmt.Fprintln(flags.Output(), "Triggered tracks are printed as they're played, one JSON object per line with json output.")
+		fmt.Fprintln(flags.Output(), "Outputs of -latency and -route are print, audio, gpio, dmx and publish. Outputs faster than")
+		fmt.Fprintln(flags.Output(), "the slowest one are delayed, so all land in sync. Tracks without samples in the kit are")
+		fmt.Fprintln(flags.Output(), "synthesized.")
+		fmt.Fprintln(flags.Output(), "Interrupting stops at the end of the bar. With -midi-in, playback waits for a start or continue.")
+		fmt.Fprintln(flags.Output(), "Sessions keep the pattern, scene, mutes, -tempo, speed, outputs' latencies and routes, loop and")
+		fmt.Fprintln(flags.Output(), "position, overridden by flags. The pattern of the session is played if no file is given, and")
+		fmt.Fprintln(flags.Output(), "sessions of other patterns are started anew.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if *device == "list" {
+			return listAudioDevices()
+		}
+		if flags.NArg() > 1 || flags.NArg() == 0 && *sessionPath == "" {
+			flags.Usage()
+			return errors.New("expected a single file")
+		}
+		if *count < 1 {
+			return fmt.Errorf("invalid count %d", *count)
+		}
+		if *tempo < 0 {
+			return fmt.Errorf("invalid tempo %v", *tempo)
+		}
+
+		set := map[string]bool{}
+		flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+		var session sequencer.Session
+		resumed := false
+		if *sessionPath != "" {
+			var err error
+			session, err = sequencer.LoadSession(*sessionPath)
+			resumed = err == nil
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		path := flags.Arg(0)
+		if path == "" {
+			path = session.Pattern
+		}
+		if resumed && filepath.Clean(path) != filepath.Clean(session.Pattern) {
+			fmt.Fprintf(os.Stderr, "splice play: session %s is of %s, starting a new one\n", *sessionPath, session.Pattern)
+			session, resumed = sequencer.Session{}, false
+		}
+		if path == "" {
+			return fmt.Errorf("no pattern in session %s", *sessionPath)
+		}
+
+		p, err := drum.DecodeFile(path)
+		if err != nil {
+			return err
+		}
+
+		if resumed {
+			err = session.Apply(p)
+			if err != nil {
+				return err
+			}
+		}
+		if *tempo > 0 {
+			p.Tempo = float32(*tempo)
+			session.Tempo = drum.BPM(*tempo)
+		}
+		if p.Tempo <= 0 {
+			return fmt.Errorf("can't play at tempo %v", p.Tempo)
+		}
+		if *scene != "" {
+			p.SetScene(*scene)
+		}
+
+		loops := *count
+		if *loop {
+			loops = 0
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		practice := sequencer.Practice{
+			CountIn: *countIn,
+			Click:   clickPrinter(*output),
+			Speed:   *speed,
+		}
+		if resumed && !set["speed"] && session.Speed > 0 {
+			practice.Speed = session.Speed
+		}
+		if *section != "" {
+			_, err := fmt.Sscanf(*section, "%d-%d", &practice.LoopStart, &practice.LoopEnd)
+			if err != nil {
+				return fmt.Errorf("invalid section %q - %v", *section, err)
+			}
+			practice.LoopStart--
+		}
+		if *speed <= 0 {
+			return fmt.Errorf("invalid speed %v", *speed)
+		}
+		if *mmcID > 0x7f {
+			return fmt.Errorf("invalid MMC device ID %d", *mmcID)
+		}
+
+		sinks := map[string]sequencer.Sink{"print": eventPrinter(*output, p)}
+		if *playAudio || *device != "" || *kit != "" {
+			var samples audio.Kit
+			if *kit != "" {
+				samples, err = audio.LoadKit(*kit, audio.DefaultSampleRate)
+				if err != nil {
+					return err
+				}
+			}
+
+			out, err := openAudioDevice(audio.DefaultSampleRate, *device)
+			if err != nil {
+				return err
+			}
+
+			player := audio.NewPlayer(out, p, samples, audio.Options{})
+			defer func() {
+				if err := player.Close(); err != nil {
+					fmt.Fprintf(os.Stderr, "splice play: %v\n", err)
+				}
+				if err := out.Close(); err != nil {
+					fmt.Fprintf(os.Stderr, "splice play: %v\n", err)
+				}
+			}()
+			sinks["audio"] = player
+		}
+		if *pins != "" {
+			outputs, err := openPins(p, *pins, *pulse)
+			if err != nil {
+				return err
+			}
+
+			pulsed := gpio.NewSink(outputs)
+			defer func() {
+				if err := pulsed.Close(); err != nil {
+					fmt.Fprintf(os.Stderr, "splice play: %v\n", err)
+				}
+				closePins(outputs)
+			}()
+			sinks["gpio"] = pulsed
+		}
+		if *artnet != "" {
+			mapped, err := parseLights(p, *lights, *decay)
+			if err != nil {
+				return err
+			}
+
+			conn, err := dmx.DialArtNet(*artnet)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			flashed := dmx.NewSink(conn, uint16(*universe), mapped, 0)
+			defer func() {
+				if err := flashed.Close(); err != nil {
+					fmt.Fprintf(os.Stderr, "splice play: %v\n", err)
+				}
+			}()
+			sinks["dmx"] = flashed
+		}
+		if *publish != "" {
+			publisher, topic, err := pubsub.Dial(*publish)
+			if err != nil {
+				return err
+			}
+			if topic == "" {
+				publisher.Close()
+				return fmt.Errorf("no topic in %s", *publish)
+			}
+
+			published := pubsub.NewSink(p, publisher, topic)
+			defer func() {
+				if err := published.Close(); err != nil {
+					fmt.Fprintf(os.Stderr, "splice play: %v\n", err)
+				}
+			}()
+			sinks["publish"] = published
+		}
+
+		if session.Outputs == nil {
+			session.Outputs = map[string]sequencer.SessionOutput{}
+		}
+		err = parseOutputs(p, session.Outputs, sinks, *latencies, *routing)
+		if err != nil {
+			return err
+		}
+		routes, err := session.Routes(p, sinks)
+		if err != nil {
+			return err
+		}
+		router := sequencer.NewRouter(routes...)
+		defer router.Close()
+
+		clock := sequencer.RealClock()
+		var midiClock *sequencer.MIDIClock
+		if *midiIn != "" {
+			midiClock = sequencer.NewMIDIClock()
+			clock = midiClock
+		}
+
+		s := sequencer.New(p, clock, router)
+		err = s.SetPractice(practice)
+		if err != nil {
+			return err
+		}
+
+		if resumed {
+			if set["section"] {
+				session.LoopStart, session.LoopEnd = practice.LoopStart, practice.LoopEnd
+			}
+			session.Speed = practice.Speed
+
+			err = session.Restore(s)
+			if err != nil {
+				return err
+			}
+		}
+
+		if *midiIn != "" {
+			port, err := os.Open(*midiIn)
+			if err != nil {
+				return err
+			}
+			defer port.Close()
+
+			listener := &sequencer.MIDIListener{OnClock: midiClock.Pulse, DeviceID: byte(*mmcID)}
+			listener.ControlTransport(s.Transport(), midiClock)
+			s.Transport().Pause()
+
+			go func() {
+				if err := listener.Listen(port); err != nil {
+					fmt.Fprintf(os.Stderr, "splice play: %v\n", err)
+				}
+			}()
+		}
+
+		if *chain != "" || *weights != "" {
+			switching := sequencer.SceneSwitching{
+				Chain: sequencer.ParseChain(*chain),
+				Seed:  *seed,
+			}
+			if *weights != "" {
+				switching.Weights, err = parseSceneWeights(*weights)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = s.SetSceneSwitching(switching)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.RunLoops(ctx, loops)
+		if err != nil || *sessionPath == "" {
+			return err
+		}
+
+		saved := sequencer.NewSession(path, s)
+		saved.Tempo = session.Tempo
+		saved.Outputs = session.Outputs
+
+		return saved.Save(*sessionPath)
+	}
+}
+
+// clickPrinter returns a function printing beats of the count-in
+// in the output format.
+func clickPrinter(output string) func(beat int) {
+	return func(beat int) {
+		switch output {
+		case outputJSON:
+			fmt.Printf("{\"countIn\":%d}\n", beat)
+		case outputTable:
+			fmt.Printf("%-6s%-14s\n", "-", fmt.Sprintf("count %d", beat))
+		default:
+			fmt.Printf("    count %d\n", beat)
+		}
+	}
+}
+
+// eventPrinter returns a sink printing triggered events in the output format.
+// Rows are printed as events come, so tables use fixed column widths.
+func eventPrinter(output string, p *drum.Pattern) sequencer.Sink {
+	if output == outputTable {
+		fmt.Printf("%-6s%-14s%s\n", "STEP", "TRACK", "VELOCITY")
+	}
+
+	return sequencer.SinkFunc(func(e drum.Event) {
+		track := p.Tracks[e.TrackIndex]
+
+		switch output {
+		case outputJSON:
+			// Not indented, to keep a single event per line
+			data, _ := json.Marshal(struct {
+				Step     int    `json:"step"`
+				Track    string `json:"track"`
+				Velocity byte   `json:"velocity"`
+				Flam     bool   `json:"flam"`
+			}{e.Step + 1, track.Name, e.Velocity, e.Flam})
+			fmt.Printf("%s\n", data)
+		case outputTable:
+			fmt.Printf("%-6d%-14s%d\n", e.Step+1, track.Name, e.Velocity)
+		default:
+			fmt.Printf("%2d  %-12s velocity %d\n", e.Step+1, track.Name, e.Velocity)
+		}
+	})
+}
+
+// openPins opens sysfs GPIO pins mapped to tracks of the pattern by
+// names or IDs, e.g. "kick=17,2=27".
+func openPins(p *drum.Pattern, mapping string, width time.Duration) (map[int]gpio.Output, error) {
+	outputs := map[int]gpio.Output{}
+
+	for _, field := range strings.Split(mapping, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		number, err := strconv.Atoi(value)
+		if !ok || err != nil {
+			closePins(outputs)
+			return nil, fmt.Errorf("invalid GPIO mapping %q", field)
+		}
+
+		track, err := trackIndex(p, name)
+		if err != nil {
+			closePins(outputs)
+			return nil, err
+		}
+
+		pin, err := gpio.OpenSysfs(number)
+		if err != nil {
+			closePins(outputs)
+			return nil, err
+		}
+		outputs[track] = gpio.Output{Pin: pin, Width: width, ScaleWidth: true}
+	}
+
+	return outputs, nil
+}
+
+// closePins closes sysfs pins of the outputs opened by openPins.
+func closePins(outputs map[int]gpio.Output) {
+	for _, output := range outputs {
+		output.Pin.(*gpio.SysfsPin).Close()
+	}
+}
+
+// parseLights parses DMX channels of lights mapped to tracks of the pattern
+// by names or IDs, e.g. "kick=1,snare=2:3:4".
+func parseLights(p *drum.Pattern, mapping string, decay time.Duration) (map[int]dmx.Light, error) {
+	if mapping == "" {
+		return nil, errors.New("no lights mapped with -dmx")
+	}
+
+	lights := map[int]dmx.Light{}
+	for _, field := range strings.Split(mapping, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid DMX mapping %q", field)
+		}
+
+		track, err := trackIndex(p, name)
+		if err != nil {
+			return nil, err
+		}
+
+		light := dmx.Light{Decay: decay}
+		for _, channel := range strings.Split(value, ":") {
+			c, err := strconv.Atoi(channel)
+			if err != nil || c < 1 || c > dmx.Channels {
+				return nil, fmt.Errorf("invalid DMX channel %q", channel)
+			}
+			light.Channels = append(light.Channels, c)
+		}
+		lights[track] = light
+	}
+
+	return lights, nil
+}
+
+// trackIndex returns the index of the pattern's track with the name,
+// matched case-insensitively, or the ID.
+func trackIndex(p *drum.Pattern, name string) (int, error) {
+	for i, t := range p.Tracks {
+		if strings.EqualFold(t.Name, name) || strconv.Itoa(int(t.ID)) == name {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no track %q", name)
+}
+
+// parseOutputs sets latencies and tracks of outputs mapped by names of
+// outputs, e.g. "dmx=25ms" and "dmx=kick:snare".
+func parseOutputs(p *drum.Pattern, outputs map[string]sequencer.SessionOutput, sinks map[string]sequencer.Sink, latencies, routing string) error {
+	if latencies != "" {
+		for _, field := range strings.Split(latencies, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+			latency, err := time.ParseDuration(value)
+			if err != nil || latency < 0 {
+				return fmt.Errorf("invalid latency %q", field)
+			}
+			if sinks[name] == nil {
+				return fmt.Errorf("no output %q", name)
+			}
+
+			output := outputs[name]
+			output.LatencyMs = float64(latency) / float64(time.Millisecond)
+			outputs[name] = output
+		}
+	}
+
+	if routing != "" {
+		for _, field := range strings.Split(routing, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+			if !ok {
+				return fmt.Errorf("invalid route %q", field)
+			}
+			if sinks[name] == nil {
+				return fmt.Errorf("no output %q", name)
+			}
+
+			output := outputs[name]
+			output.Tracks = nil
+			routed := map[int]bool{}
+			for _, track := range strings.Split(value, ":") {
+				i, err := trackIndex(p, track)
+				if err != nil {
+					return err
+				}
+				if !routed[i] {
+					routed[i] = true
+					output.Tracks = append(output.Tracks, p.Tracks[i].Name)
+				}
+			}
+			outputs[name] = output
+		}
+	}
+
+	return nil
+}
+
+// parseSceneWeights parses weights of scenes, e.g. "A=3,B=1".
+func parseSceneWeights(s string) (map[string]float64, error) {
+	weights := map[string]float64{}
+
+	for _, field := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		weight, err := strconv.ParseFloat(value, 64)
+		if !ok || name == "" || err != nil {
+			return nil, fmt.Errorf("invalid scene weight %q", field)
+		}
+		weights[name] = weight
+	}
+
+	return weights, nil
+}
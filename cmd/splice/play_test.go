@@ -0,0 +1,98 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum"
+	"github.com/m110/go-challenge-1/drum/sequencer"
+)
+
+func TestPlayFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{fixture("pattern_1.splice"), fixture("pattern_2.splice")},
+		{"-count", "0", fixture("pattern_1.splice")},
+		{"-tempo", "-1", fixture("pattern_1.splice")},
+		{"-section", "five-eight", fixture("pattern_1.splice")},
+		{"-scene-weights", "A=x", fixture("pattern_1.splice")},
+		{"-latency", "print=soon", fixture("pattern_1.splice")},
+		{"-route", "print=cowbell:tabla", fixture("pattern_1.splice")},
+		{"-session", filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		if _, err := runCommand(t, "play", args...); err == nil {
+			t.Errorf("play %v: expected an error", args)
+		}
+	}
+}
+
+func TestParseSceneWeights(t *testing.T) {
+	weights, err := parseSceneWeights("A=3, B=1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]float64{"A": 3, "B": 1.5}; !reflect.DeepEqual(weights, expected) {
+		t.Errorf("expected %v, got %v", expected, weights)
+	}
+
+	for _, s := range []string{"A", "=3", "A=three", "A=3,,B=1"} {
+		if _, err := parseSceneWeights(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestParseLights(t *testing.T) {
+	p, err := drum.DecodeFile(fixture("pattern_1.splice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lights, err := parseLights(p, "kick=1,1=2:3:4", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lights[0].Channels, []int{1}) || !reflect.DeepEqual(lights[1].Channels, []int{2, 3, 4}) {
+		t.Errorf("expected kick on 1 and snare on 2:3:4, got %+v", lights)
+	}
+
+	for _, mapping := range []string{"", "kick", "kick=0", "kick=513", "tabla=1"} {
+		if _, err := parseLights(p, mapping, 0); err == nil {
+			t.Errorf("%q: expected an error", mapping)
+		}
+	}
+}
+
+func TestParseOutputs(t *testing.T) {
+	p, err := drum.DecodeFile(fixture("pattern_1.splice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sink := sequencer.SinkFunc(func(drum.Event) {})
+	sinks := map[string]sequencer.Sink{"print": sink, "dmx": sink}
+	outputs := map[string]sequencer.SessionOutput{}
+
+	err = parseOutputs(p, outputs, sinks, "dmx=25ms", "print=kick:snare:kick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs["dmx"].LatencyMs != 25 {
+		t.Errorf("expected dmx latency of 25ms, got %v", outputs["dmx"].LatencyMs)
+	}
+	if tracks := outputs["print"].Tracks; !reflect.DeepEqual(tracks, []string{"kick", "snare"}) {
+		t.Errorf("expected print routed to kick and snare, got %v", tracks)
+	}
+
+	for _, tt := range [][2]string{
+		{"dmx=-1ms", ""},
+		{"gpio=1ms", ""},
+		{"", "dmx"},
+		{"", "dmx=tabla"},
+	} {
+		if err := parseOutputs(p, outputs, sinks, tt[0], tt[1]); err == nil {
+			t.Errorf("%q %q: expected an error", tt[0], tt[1])
+		}
+	}
+}
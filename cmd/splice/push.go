@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/m110/go-challenge-1/drum"
+	"github.com/m110/go-challenge-1/drum/sysex"
+)
+
+func pushCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("push", flag.ExitOnError)
+	device := choiceFlag(flags, "device", "", "target `device`", sysex.Devices()...)
+	port := flags.String("port", "", "raw MIDI port `path` to write to, e.g. /dev/snd/midiC1D0")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice push -device name -port path [-output format] file.splice")
+		fmt.Fprintln(flags.Output(), "Transfers the pattern to a drum machine over MIDI system exclusive messages, once it's")
+		fmt.Fprintln(flags.Output(), "checked to fit the device. filedump sends it as a MIDI file with the MIDI File Dump.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a single file")
+		}
+		if *device == "" || *port == "" {
+			flags.Usage()
+			return errors.New("both -device and -port are required")
+		}
+
+		encoder, _ := sysex.Lookup(*device)
+
+		p, err := drum.DecodeFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		if profiler, ok := encoder.(sysex.Profiler); ok {
+			violations := drum.CheckDeviceProfile(p, profiler.Profile())
+			for _, v := range violations {
+				fmt.Fprintf(os.Stderr, "splice push: %v\n", v)
+			}
+			if len(violations) > 0 {
+				return fmt.Errorf("pattern doesn't fit %s", *device)
+			}
+		}
+
+		messages, err := encoder.Encode(p)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.OpenFile(*port, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+
+		size := 0
+		for _, msg := range messages {
+			_, err = f.Write(msg)
+			if err != nil {
+				f.Close()
+				return err
+			}
+			size += len(msg)
+		}
+
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+
+		switch *output {
+		case outputJSON:
+			return printJSON(struct {
+				Device   string `json:"device"`
+				Port     string `json:"port"`
+				Messages int    `json:"messages"`
+				Bytes    int    `json:"bytes"`
+			}{*device, *port, len(messages), size})
+		case outputTable:
+			table := newTable()
+			fmt.Fprintf(table, "DEVICE\tPORT\tMESSAGES\tBYTES\n")
+			fmt.Fprintf(table, "%s\t%s\t%d\t%d\n", *device, *port, len(messages), size)
+			return table.Flush()
+		default:
+			fmt.Printf("pushed %d messages (%d bytes) to %s\n", len(messages), size, *device)
+			return nil
+		}
+	}
+}
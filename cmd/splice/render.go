@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+	"github.com/m110/go-challenge-1/drum/audio"
+)
+
+func renderCommand() (*flag.FlagSet, func() error) {
+	var opts audio.Options
+
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	target := flags.String("o", "", "write WAV file to `path`")
+	flags.IntVar(&opts.SampleRate, "rate", audio.DefaultSampleRate, "sample `rate` in Hz")
+	flags.IntVar(&opts.Loops, "loops", 1, "play the pattern `n` times")
+	flags.IntVar(&opts.TailBeats, "tail", 0, "render `beats` of ringing sounds after the last loop")
+	flags.DurationVar(&opts.Crossfade, "crossfade", 0, "fold ringing sounds into the beginning over `duration`, making the render loop seamlessly")
+	lufs := flags.Float64("lufs", 0, "normalize loudness to `target` LUFS, e.g. -14")
+	ceiling := flags.Float64("ceiling", 0, "limit peaks to `dBFS`, e.g. -1")
+	flags.BoolVar(&opts.Bus.TruePeak, "true-peak", false, "limit inter-sample peaks too")
+	groove := flags.String("groove", "", "lock timing to the groove of the MIDI template or WAV recording at `path`")
+	scene := flags.String("scene", "", "render the scene `name` instead of the active one")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice render -o path [-rate hz] [-loops n] [-tail beats | -crossfade duration] [-lufs target] [-ceiling dBFS] [-true-peak] [-groove path] [-scene name] [-output format] file.splice")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 || *target == "" {
+			flags.Usage()
+			return errors.New("expected a single file and -o")
+		}
+
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "lufs":
+				opts.Bus.Normalize, opts.Bus.TargetLUFS = true, *lufs
+			case "ceiling":
+				opts.Bus.Limit, opts.Bus.Ceiling = true, *ceiling
+			}
+		})
+		if opts.SampleRate <= 0 {
+			return errors.New("-rate must be positive")
+		}
+		if opts.Bus.TruePeak && !opts.Bus.Limit {
+			return errors.New("-true-peak requires -ceiling")
+		}
+
+		p, err := drum.DecodeFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		if *scene != "" {
+			p.SetScene(*scene)
+		}
+
+		if *groove != "" {
+			g, err := readGroove(*groove, p.BPM())
+			if err != nil {
+				return err
+			}
+			opts.Groove = &g
+		}
+
+		f, err := os.Create(*target)
+		if err != nil {
+			return err
+		}
+
+		samples := audio.Render(p, opts)
+		err = audio.WriteWAV(f, samples, opts.SampleRate)
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+
+		duration := time.Duration(len(samples)) * time.Second / time.Duration(opts.SampleRate)
+		switch *output {
+		case outputJSON:
+			return printJSON(struct {
+				Path       string  `json:"path"`
+				SampleRate int     `json:"sampleRate"`
+				Samples    int     `json:"samples"`
+				Duration   float64 `json:"duration"`
+			}{*target, opts.SampleRate, len(samples), duration.Seconds()})
+		case outputTable:
+			table := newTable()
+			fmt.Fprintf(table, "PATH\tSAMPLE RATE\tSAMPLES\tDURATION\n")
+			fmt.Fprintf(table, "%s\t%d\t%d\t%v\n", *target, opts.SampleRate, len(samples), duration)
+			return table.Flush()
+		default:
+			fmt.Printf("rendered %v to %s\n", duration, *target)
+			return nil
+		}
+	}
+}
+
+// readGroove reads a groove from a template MIDI file, or a WAV file
+// recorded at the tempo.
+func readGroove(path string, tempo drum.BPM) (drum.Groove, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return drum.Groove{}, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		return audio.ReadGrooveWAV(f, tempo)
+	}
+
+	return drum.ReadGrooveMIDI(f)
+}
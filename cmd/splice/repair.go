@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func repairCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("repair", flag.ExitOnError)
+	target := flags.String("o", "", "write repaired file to `path` instead of overwriting the input")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice repair [-o path] [-output format] file.splice")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a single file")
+		}
+
+		path := flags.Arg(0)
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		repaired, report, err := drum.Repair(data)
+		if err != nil {
+			return err
+		}
+
+		err = printReport(*output, path, report)
+		if err != nil {
+			return err
+		}
+
+		if *target == "" {
+			if !report.Changed() {
+				return nil
+			}
+			*target = path
+		}
+
+		return ioutil.WriteFile(*target, repaired, 0644)
+	}
+}
+
+// printReport writes the repair report in the output format.
+func printReport(output, path string, report drum.RepairReport) error {
+	switch output {
+	case outputJSON:
+		return printJSON(struct {
+			Path              string `json:"path"`
+			Changed           bool   `json:"changed"`
+			DeclaredLength    uint64 `json:"declaredLength"`
+			Length            uint64 `json:"length"`
+			VersionTerminated bool   `json:"versionTerminated"`
+			ClampedSteps      int    `json:"clampedSteps"`
+		}{path, report.Changed(), report.DeclaredLength, report.Length, report.VersionTerminated, report.ClampedSteps})
+	case outputTable:
+		table := newTable()
+		fmt.Fprintf(table, "PATH\tCHANGED\tDECLARED LENGTH\tLENGTH\tVERSION TERMINATED\tCLAMPED STEPS\n")
+		fmt.Fprintf(table, "%s\t%v\t%d\t%d\t%v\t%d\n", path, report.Changed(), report.DeclaredLength,
+			report.Length, report.VersionTerminated, report.ClampedSteps)
+		return table.Flush()
+	default:
+		fmt.Printf("%s: %s\n", path, report)
+		return nil
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func signCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := flags.String("key", "", "`path` of the private key, a hex encoded ed25519 seed")
+	generate := flags.Bool("generate", false, "generate a new private key at the -key path")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice sign -key path [-generate] [-output format] file.splice")
+		fmt.Fprintln(flags.Output(), "Signs the pattern, storing the signature in its metadata sidecar.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a single file")
+		}
+		if *keyPath == "" {
+			flags.Usage()
+			return errors.New("-key is required")
+		}
+
+		if *generate {
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				return err
+			}
+
+			err = os.WriteFile(*keyPath, []byte(hex.EncodeToString(key.Seed())+"\n"), 0600)
+			if err != nil {
+				return err
+			}
+		}
+
+		key, err := readKey(*keyPath, ed25519.SeedSize)
+		if err != nil {
+			return err
+		}
+		private := ed25519.NewKeyFromSeed(key)
+
+		p, err := drum.DecodeFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		err = drum.Sign(p, private)
+		if err != nil {
+			return err
+		}
+
+		err = drum.WriteSidecar(p, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		public := hex.EncodeToString(private.Public().(ed25519.PublicKey))
+		switch *output {
+		case outputJSON:
+			return printJSON(struct {
+				Path      string `json:"path"`
+				PublicKey string `json:"publicKey"`
+			}{flags.Arg(0), public})
+		case outputTable:
+			table := newTable()
+			fmt.Fprintf(table, "PATH\tPUBLIC KEY\n")
+			fmt.Fprintf(table, "%s\t%s\n", flags.Arg(0), public)
+			return table.Flush()
+		default:
+			fmt.Printf("signed with public key %s\n", public)
+			return nil
+		}
+	}
+}
+
+func verifyCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	keyPath := flags.String("key", "", "`path` of the signer's public key, hex encoded")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice verify [-key path] [-output format] file.splice...")
+		fmt.Fprintln(flags.Output(), "Prints verification status of signatures of files, exiting with status 1 unless all are valid and decodable.")
+		fmt.Fprintln(flags.Output(), "Without -key, signatures are checked against keys stored with them and reported as self-signed, proving integrity only.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() == 0 {
+			flags.Usage()
+			return errors.New("expected files")
+		}
+
+		var pub ed25519.PublicKey
+		if *keyPath != "" {
+			key, err := readKey(*keyPath, ed25519.PublicKeySize)
+			if err != nil {
+				return err
+			}
+			pub = key
+		}
+
+		results := []verification{}
+		var status error
+		for _, path := range flags.Args() {
+			v := verification{Path: path}
+
+			p, err := drum.DecodeFile(path)
+			if err == nil {
+				v = verify(path, p, pub)
+			} else {
+				v.Status = err.Error()
+			}
+			if !v.Valid {
+				status = exitStatus(1)
+			}
+
+			results = append(results, v)
+		}
+
+		var err error
+		switch *output {
+		case outputJSON:
+			err = printJSON(results)
+		case outputTable:
+			table := newTable()
+			fmt.Fprintf(table, "PATH\tVALID\tSELF-SIGNED\tPUBLIC KEY\tSTATUS\n")
+			for _, v := range results {
+				fmt.Fprintf(table, "%s\t%v\t%v\t%s\t%s\n", v.Path, v.Valid, v.SelfSigned, v.PublicKey, v.Status)
+			}
+			err = table.Flush()
+		default:
+			for _, v := range results {
+				fmt.Printf("%s: %s\n", v.Path, v.Status)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		return status
+	}
+}
+
+// verification is the verification status of a file's signature.
+type verification struct {
+	Path       string `json:"path"`
+	Valid      bool   `json:"valid"`
+	SelfSigned bool   `json:"selfSigned"`
+	PublicKey  string `json:"publicKey,omitempty"`
+	// Status describes the result, e.g. why the signature isn't valid
+	Status string `json:"status"`
+
+	err error
+}
+
+// verify checks the signature of the pattern decoded from path against
+// pub, or against the key stored with the signature if pub is nil.
+func verify(path string, p *drum.Pattern, pub ed25519.PublicKey) verification {
+	v := verification{Path: path, SelfSigned: pub == nil && p.Signature != nil}
+
+	key := pub
+	if key == nil && p.Signature != nil {
+		key = p.Signature.PublicKey
+	}
+	v.PublicKey = hex.EncodeToString(key)
+
+	v.err = drum.Verify(p, key)
+	switch {
+	case v.err != nil:
+		v.Status = v.err.Error()
+	case v.SelfSigned:
+		v.Valid = true
+		v.Status = fmt.Sprintf("valid self-signed signature by %s", v.PublicKey)
+	default:
+		v.Valid = true
+		v.Status = fmt.Sprintf("valid signature by %s", v.PublicKey)
+	}
+
+	return v
+}
+
+// signatureStatus returns a short status of the pattern's signature for
+// listings: unsigned, valid, self-signed if checked against the key stored
+// with it as pub is nil, unknown signer or invalid.
+func signatureStatus(path string, p *drum.Pattern, pub ed25519.PublicKey) string {
+	if p.Signature == nil {
+		return "unsigned"
+	}
+
+	v := verify(path, p, pub)
+	switch {
+	case v.Valid && v.SelfSigned:
+		return "self-signed"
+	case v.Valid:
+		return "valid"
+	case errors.Is(v.err, drum.ErrUnknownSigner):
+		return "unknown signer"
+	default:
+		return "invalid"
+	}
+}
+
+// readKey reads a hex encoded key of size bytes from the file at path.
+func readKey(path string, size int) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in %s - %v", path, err)
+	}
+	if len(key) != size {
+		return nil, fmt.Errorf("key in %s is %d bytes long, expected %d", path, len(key), size)
+	}
+
+	return key, nil
+}
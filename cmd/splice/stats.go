@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func statsCommand() (*flag.FlagSet, func() error) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	output := choiceFlag(flags, "output", outputText, "output `format`", outputText, outputJSON, outputHTML)
+	theme := themeFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice stats [-output format] [-theme name] dir...")
+		fmt.Fprintln(flags.Output(), "Prints statistics of all files of the directories.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() == 0 {
+			flags.Usage()
+			return errors.New("expected at least one directory")
+		}
+
+		_, patterns, err := decodeLibrary(flags.Args())
+		if err != nil {
+			return err
+		}
+
+		report := drum.AnalyzeCorpus(patterns)
+
+		switch *output {
+		case outputJSON:
+			return report.WriteJSON(os.Stdout)
+		case outputHTML:
+			var opts []drum.ExportOption
+			if *theme != "" {
+				t, err := loadTheme(*theme)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, drum.WithTheme(t))
+			}
+			return report.WriteHTML(os.Stdout, opts...)
+		default:
+			return report.WriteText(os.Stdout)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/m110/go-challenge-1/drum"
+	"github.com/m110/go-challenge-1/drum/plugin"
+)
+
+// transformFlag collects transforms in the order of their flags.
+type transformFlag struct {
+	pipeline *drum.Pipeline
+	make     func(value string) (drum.Transform, error)
+}
+
+func (f transformFlag) String() string {
+	return ""
+}
+
+func (f transformFlag) Set(value string) error {
+	t, err := f.make(value)
+	if err != nil {
+		return err
+	}
+
+	*f.pipeline = append(*f.pipeline, t)
+	return nil
+}
+
+func transformCommand() (*flag.FlagSet, func() error) {
+	var pipeline drum.Pipeline
+
+	builtin := drum.Transforms()
+	var names []string
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	flags := flag.NewFlagSet("transform", flag.ExitOnError)
+	target := flags.String("o", "", "write transformed pattern to `path` instead of printing it")
+	flags.Var(transformFlag{&pipeline, func(name string) (drum.Transform, error) {
+		t, ok := builtin[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %q", name)
+		}
+		return t, nil
+	}}, "t", "apply built-in transform `name`, one of: "+strings.Join(names, ", "))
+	flags.Var(transformFlag{&pipeline, func(command string) (drum.Transform, error) {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil, errors.New("empty command")
+		}
+		return drum.Command(fields[0], fields[1:]...), nil
+	}}, "exec", "apply `command` exchanging the pattern as JSON on stdin and stdout")
+	flags.Var(transformFlag{&pipeline, plugin.Load}, "plugin", "apply transform loaded from Go plugin at `path`")
+	output := outputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: splice transform [-t name] [-exec command] [-plugin path] [-o path | -output format] file.splice")
+		fmt.Fprintln(flags.Output(), "Transforms are applied in the order of flags.")
+		flags.PrintDefaults()
+	}
+
+	return flags, func() error {
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return errors.New("expected a single file")
+		}
+
+		p, err := drum.DecodeFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		p, err = pipeline.Apply(p)
+		if err != nil {
+			return err
+		}
+
+		if *target == "" {
+			return printPattern(*output, p)
+		}
+
+		return drum.EncodeFile(p, *target)
+	}
+}
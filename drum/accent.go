@@ -0,0 +1,95 @@
+package drum
+
+import "strings"
+
+// accentTrackName is the name of a track boosting velocity of other tracks.
+const accentTrackName = "accent"
+
+// Velocities of hits.
+const (
+	DefaultVelocity byte = 100
+	AccentVelocity  byte = maxVelocity
+)
+
+// IsAccent returns true if the track is an accent track, i.e. it's named
+// "accent". It doesn't sound on its own, but its hits boost velocity of
+// coincident hits on all other tracks. As it's a regular track otherwise,
+// it's preserved by all formats, including .splice files.
+func (t Track) IsAccent() bool {
+	return strings.EqualFold(t.Name, accentTrackName)
+}
+
+// Velocity returns velocity of the step of the track at the given indexes,
+// as it should be played: 0 for steps that aren't hits, for muted tracks
+// and for the accent track, AccentVelocity for hits coinciding with an
+// accent and DefaultVelocity for all other hits. Track offsets are taken
+// into account, as well as the velocity curve and the track's velocity
+// scale.
+func (p *Pattern) Velocity(track, step int) byte {
+	p.ensureTracks()
+
+	velocity := p.baseVelocity(track, step)
+	if velocity == 0 {
+		return 0
+	}
+
+	return p.Tracks[track].scaleVelocity(p.velocityCurve.apply(velocity))
+}
+
+// trackVelocities returns velocities of all steps of the track at the
+// index, like Velocity does, computing shifted steps only once.
+func (p *Pattern) trackVelocities(track int) []byte {
+	t := p.Tracks[track]
+	velocities := make([]byte, t.Len())
+	if t.IsAccent() || t.Muted {
+		return velocities
+	}
+
+	var accents [][]byte
+	for _, other := range p.Tracks {
+		if other.IsAccent() && !other.Muted {
+			accents = append(accents, other.ShiftedSteps())
+		}
+	}
+
+	for step, value := range t.ShiftedSteps() {
+		if !isHit(value) {
+			continue
+		}
+
+		velocity := DefaultVelocity
+		for _, accent := range accents {
+			if step < len(accent) && isHit(accent[step]) {
+				velocity = AccentVelocity
+				break
+			}
+		}
+		velocities[step] = t.scaleVelocity(p.velocityCurve.apply(velocity))
+	}
+
+	return velocities
+}
+
+// baseVelocity returns velocity of the step before any dynamics processing.
+func (p *Pattern) baseVelocity(track, step int) byte {
+	t := p.Tracks[track]
+	if t.IsAccent() || t.Muted {
+		return 0
+	}
+
+	if step >= t.Len() || !isHit(t.shiftedStep(step)) {
+		return 0
+	}
+
+	for _, other := range p.Tracks {
+		if !other.IsAccent() || other.Muted || step >= other.Len() {
+			continue
+		}
+
+		if isHit(other.shiftedStep(step)) {
+			return AccentVelocity
+		}
+	}
+
+	return DefaultVelocity
+}
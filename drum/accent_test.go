@@ -0,0 +1,51 @@
+package drum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVelocity(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0, 1, 0}},
+			{ID: 1, Name: "hh-close", Steps: []byte{1, 1, 1, 1}, Offset: 1},
+			{ID: 2, Name: "Accent", Steps: []byte{0, 0, 1, 0}},
+		},
+	}
+
+	expected := [][]byte{
+		{DefaultVelocity, 0, AccentVelocity, 0},
+		{DefaultVelocity, DefaultVelocity, AccentVelocity, DefaultVelocity},
+		{0, 0, 0, 0},
+	}
+
+	for track := range p.Tracks {
+		for step := range p.Tracks[track].Steps {
+			if v := p.Velocity(track, step); v != expected[track][step] {
+				t.Errorf("track %d step %d: expected velocity %d, got %d", track, step, expected[track][step], v)
+			}
+		}
+	}
+
+	for track := range p.Tracks {
+		if v := p.trackVelocities(track); !bytes.Equal(v, expected[track]) {
+			t.Errorf("track %d: expected velocities %v, got %v", track, expected[track], v)
+		}
+	}
+
+	// The accent track is preserved by the binary format
+	encoded, _, err := EncodeBytes(p, WithEncodedStepWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(bytes.NewReader(encoded), WithStepWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := decoded.Velocity(0, 2); v != AccentVelocity {
+		t.Errorf("accent wasn't preserved after round trip, velocity %d", v)
+	}
+}
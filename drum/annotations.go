@@ -0,0 +1,111 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Note is a free-text comment attached to a step of a track,
+// e.g. "ghost note here". Notes are stored in the metadata sidecar.
+type Note struct {
+	// Step is the index in the track's steps, before its offset is applied
+	Step int    `json:"step"`
+	Text string `json:"text"`
+}
+
+// Annotate attaches text to the step, replacing its previous note.
+// Empty text removes the note. Notes are kept sorted by step.
+func (t *Track) Annotate(step int, text string) {
+	i := sort.Search(len(t.Notes), func(i int) bool {
+		return t.Notes[i].Step >= step
+	})
+
+	exists := i < len(t.Notes) && t.Notes[i].Step == step
+
+	switch {
+	case text == "" && exists:
+		t.Notes = append(t.Notes[:i], t.Notes[i+1:]...)
+	case text == "":
+	case exists:
+		t.Notes[i].Text = text
+	default:
+		t.Notes = append(t.Notes, Note{})
+		copy(t.Notes[i+1:], t.Notes[i:])
+		t.Notes[i] = Note{Step: step, Text: text}
+	}
+}
+
+// NoteAt returns the text attached to the step, if any.
+func (t Track) NoteAt(step int) (string, bool) {
+	for _, note := range t.Notes {
+		if note.Step == step {
+			return note.Text, true
+		}
+	}
+
+	return "", false
+}
+
+// footnote is a note numbered in the order of tracks and steps,
+// as shown below rendered patterns.
+type footnote struct {
+	Number int
+	// Index of the track in the pattern
+	TrackIndex int
+	TrackID    byte
+	Track      string
+	// Position is the index of the step as displayed, with offset applied
+	Position int
+	Text     string
+}
+
+// Label returns the footnote's reference to its track and step,
+// e.g. "(1) snare, step 6 (2e)".
+func (f footnote) Label() string {
+	return fmt.Sprintf("(%d) %s, step %d (%s)", f.TrackID, f.Track, f.Position+1, beatPosition(f.Position))
+}
+
+// footnotes returns notes of all tracks, numbered from 1.
+// Notes of steps out of the track's range are skipped.
+func (p *Pattern) footnotes() []footnote {
+	var footnotes []footnote
+
+	for i, track := range p.Tracks {
+		n := track.Len()
+
+		for _, note := range track.Notes {
+			if note.Step < 0 || note.Step >= n {
+				continue
+			}
+
+			footnotes = append(footnotes, footnote{
+				Number:     len(footnotes) + 1,
+				TrackIndex: i,
+				TrackID:    track.ID,
+				Track:      track.Name,
+				Position:   ((note.Step+track.Offset)%n + n) % n,
+				Text:       note.Text,
+			})
+		}
+	}
+
+	return footnotes
+}
+
+// beatPosition returns the step's position within beats, e.g. "2e".
+func beatPosition(i int) string {
+	return fmt.Sprintf("%d%s", i/beatSteps+1, beatLabels[i%beatSteps])
+}
+
+// writeFootnotes writes a list of footnotes below a rendered pattern.
+func writeFootnotes(buffer *bytes.Buffer, footnotes []footnote) {
+	if len(footnotes) == 0 {
+		return
+	}
+
+	buffer.WriteString("\nNotes:\n")
+	for _, f := range footnotes {
+		buffer.WriteString(fmt.Sprintf("[%d] %s: %s\n", f.Number, f.Label(), f.Text))
+	}
+}
@@ -0,0 +1,110 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnnotate(t *testing.T) {
+	track := Track{Name: "snare", Steps: make([]byte, 16)}
+
+	track.Annotate(6, "drag this hit")
+	track.Annotate(2, "ghost note here")
+	track.Annotate(6, "drag")
+	track.Annotate(9, "")
+
+	expected := []Note{{2, "ghost note here"}, {6, "drag"}}
+	if !reflect.DeepEqual(track.Notes, expected) {
+		t.Fatalf("unexpected notes %v, expected %v", track.Notes, expected)
+	}
+
+	track.Annotate(2, "")
+	if text, ok := track.NoteAt(2); ok {
+		t.Errorf("note %q wasn't removed", text)
+	}
+	if text, _ := track.NoteAt(6); text != "drag" {
+		t.Errorf("unexpected note %q", text)
+	}
+}
+
+func annotatedPattern() *Pattern {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: stepsFromString("x---x---")},
+			{ID: 1, Name: "snare", Steps: stepsFromString("--x---x-"), Offset: 1},
+		},
+	}
+	p.Tracks[0].Annotate(4, "drag this hit")
+	p.Tracks[1].Annotate(1, "ghost note here")
+
+	return p
+}
+
+func TestRenderNotes(t *testing.T) {
+	expected := "\nNotes:\n" +
+		"[1] (0) kick, step 5 (2): drag this hit\n" +
+		"[2] (1) snare, step 3 (1&): ghost note here\n"
+
+	if out := annotatedPattern().String(); !strings.HasSuffix(out, expected) {
+		t.Fatalf("notes aren't rendered as footnotes:\n%s", out)
+	}
+}
+
+func TestExportMarkdownNotes(t *testing.T) {
+	out := ExportMarkdown(annotatedPattern())
+
+	for _, expected := range []string{
+		"| (0) kick | ✅ | ▫️ | ▫️ | ▫️ | ✅[^1] |",
+		"| (1) snare | ▫️ | ▫️ | ▫️[^2] | ✅ |",
+		"\n[^1]: (0) kick, step 5 (2): drag this hit\n",
+		"[^2]: (1) snare, step 3 (1&): ghost note here\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("exported Markdown doesn't contain %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestExportHTMLNotes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportHTML(&buf, annotatedPattern()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`"notes":[{"number":2,"step":2,"text":"ghost note here"}]`,
+		`<li value="1">(0) kick, step 5 (2): drag this hit</li>`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("exported HTML doesn't contain %q", expected)
+		}
+	}
+}
+
+func TestNotesPersistence(t *testing.T) {
+	p := annotatedPattern()
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmarshaled := &Pattern{}
+	if err := json.Unmarshal(data, unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarshaled.Tracks[1].Notes, p.Tracks[1].Notes) {
+		t.Fatalf("notes weren't unmarshaled: %v", unmarshaled.Tracks[1].Notes)
+	}
+
+	restored := &Pattern{Tracks: []Track{{ID: 0}, {ID: 1}}}
+	restored.ApplyMetadata(p.Metadata())
+	if !reflect.DeepEqual(restored.Tracks[0].Notes, p.Tracks[0].Notes) {
+		t.Fatalf("notes weren't restored from metadata: %v", restored.Tracks[0].Notes)
+	}
+}
@@ -0,0 +1,165 @@
+// Package audio renders drum patterns to audio, playing every track
+// with a voice synthesized for its instrument.
+package audio
+
+import (
+	"math"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+const (
+	// DefaultSampleRate is used if Options.SampleRate isn't set.
+	DefaultSampleRate = 44100
+
+	// defaultFlamSpacing is the time between a flam's grace note
+	// and its main hit.
+	defaultFlamSpacing = 25 * time.Millisecond
+	// graceVelocity is the fraction of velocity a grace note is played with.
+	graceVelocity = 0.5
+	// beatSteps is the number of steps in a single beat.
+	beatSteps = 4
+)
+
+// Options configure rendering. Zero values select defaults.
+type Options struct {
+	// SampleRate in Hz, DefaultSampleRate by default
+	SampleRate int
+	// Loops is the number of times the pattern is played, once by default
+	Loops int
+	// FlamSpacing is the time between a flam's grace note and its main hit
+	FlamSpacing time.Duration
+
+	// TailBeats is the number of beats rendered after the last loop, so
+	// sounds ringing past its end are captured instead of being cut off
+	TailBeats int
+	// Crossfade makes the render seamlessly loopable: sounds ringing past
+	// the end of the last loop are faded out over Crossfade and mixed into
+	// the beginning, like when the loop is played again. The render is
+	// then exactly as long as the loops and TailBeats is ignored.
+	Crossfade time.Duration
+
+	// Groove changes timing and velocities of hits, if set
+	Groove *drum.Groove
+
+	// Bus processes the mix of all tracks
+	Bus Bus
+}
+
+// Render returns mono samples in range from -1 to 1 of the pattern played
+// with synthesized voices.
+func Render(p *drum.Pattern, opts Options) []float64 {
+	p.LoadTracks()
+	opts = opts.withDefaults()
+
+	loop := loopDuration(p)
+	length := toSamples(time.Duration(opts.Loops)*loop, opts.SampleRate)
+
+	tail := 0
+	if opts.Crossfade > 0 {
+		tail = toSamples(opts.Crossfade, opts.SampleRate)
+	} else if opts.TailBeats > 0 {
+		// Sounds keep ringing at the tempo of the last step
+		step := p.StepDurationAt(loopSteps(p) - 1)
+		tail = toSamples(time.Duration(opts.TailBeats*beatSteps)*step, opts.SampleRate)
+	}
+
+	samples := make([]float64, length+tail)
+
+	voices := make([]voice, len(p.Tracks))
+	for i, track := range p.Tracks {
+		voices[i] = voiceFor(track.Name)
+	}
+
+	noise := newNoise()
+	events := p.Events()
+	if opts.Groove != nil {
+		events = drum.ApplyGroove(p, *opts.Groove)
+	}
+
+	for l := 0; l < opts.Loops; l++ {
+		for _, e := range events {
+			at := time.Duration(l)*loop + e.Time
+			velocity := float64(e.Velocity) / 127
+
+			if e.Flam {
+				grace := at - opts.FlamSpacing
+				if grace < 0 {
+					grace = 0
+				}
+				start := toSamples(grace, opts.SampleRate)
+				voices[e.TrackIndex].play(samples, start, noise, int64(start), opts.SampleRate, velocity*graceVelocity)
+			}
+
+			start := toSamples(at, opts.SampleRate)
+			voices[e.TrackIndex].play(samples, start, noise, int64(start), opts.SampleRate, velocity)
+		}
+	}
+
+	if opts.Crossfade > 0 {
+		samples = wrapTail(samples, length)
+	}
+
+	opts.Bus.process(samples, opts.SampleRate)
+
+	return samples
+}
+
+// wrapTail fades out samples past length and mixes them into the
+// beginning, returning samples cut to length.
+func wrapTail(samples []float64, length int) []float64 {
+	tail := samples[length:]
+
+	for i, sample := range tail {
+		if i >= length {
+			break
+		}
+
+		fade := 1 - float64(i)/float64(len(tail))
+		samples[i] += sample * fade
+	}
+
+	return samples[:length]
+}
+
+func (o Options) withDefaults() Options {
+	if o.SampleRate <= 0 {
+		o.SampleRate = DefaultSampleRate
+	}
+	if o.Loops <= 0 {
+		o.Loops = 1
+	}
+	if o.FlamSpacing <= 0 {
+		o.FlamSpacing = defaultFlamSpacing
+	}
+
+	return o
+}
+
+// loopSteps returns the number of steps in a single loop of the pattern.
+func loopSteps(p *drum.Pattern) int {
+	steps := 0
+	for _, track := range p.Tracks {
+		if track.Len() > steps {
+			steps = track.Len()
+		}
+	}
+
+	return steps
+}
+
+// loopDuration returns duration of a single loop of the pattern.
+func loopDuration(p *drum.Pattern) time.Duration {
+	var d time.Duration
+	for i := 0; i < loopSteps(p); i++ {
+		d += p.StepDurationAt(i)
+	}
+
+	return d
+}
+
+// toSamples returns the number of samples lasting d.
+func toSamples(d time.Duration, sampleRate int) int {
+	return int(math.Round(d.Seconds() * float64(sampleRate)))
+}
@@ -0,0 +1,212 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+	"github.com/m110/go-challenge-1/drum/metrics"
+)
+
+func loadPattern(t *testing.T) *drum.Pattern {
+	t.Helper()
+
+	p, err := drum.DecodeFile(path.Join("..", "fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func TestRender(t *testing.T) {
+	p := loadPattern(t)
+
+	samples := Render(p, Options{SampleRate: 8000, Loops: 2})
+
+	// 16 steps of 125ms at 120 BPM, twice
+	if len(samples) != 2*16000 {
+		t.Fatalf("expected %d samples, got %d", 2*16000, len(samples))
+	}
+
+	// The kick on the first step starts right away
+	if Peak(samples[:80]) == 0 {
+		t.Fatal("expected sound at the beginning")
+	}
+
+	// Renders are reproducible
+	if again := Render(p, Options{SampleRate: 8000, Loops: 2}); !equal(again, samples) {
+		t.Fatal("rendering isn't deterministic")
+	}
+}
+
+func TestRenderTail(t *testing.T) {
+	p := loadPattern(t)
+
+	plain := Render(p, Options{SampleRate: 8000})
+	tailed := Render(p, Options{SampleRate: 8000, TailBeats: 2})
+
+	// 2 beats of 4 steps of 125ms
+	if len(tailed) != len(plain)+8000 {
+		t.Fatalf("expected %d samples, got %d", len(plain)+8000, len(tailed))
+	}
+	if !equal(tailed[:len(plain)], plain) {
+		t.Fatal("tail changed the loop")
+	}
+}
+
+func TestRenderCrossfade(t *testing.T) {
+	p := loadPattern(t)
+
+	plain := Render(p, Options{SampleRate: 8000})
+	tailed := Render(p, Options{SampleRate: 8000, TailBeats: 1})
+	looped := Render(p, Options{SampleRate: 8000, Crossfade: 100 * time.Millisecond})
+
+	if len(looped) != len(plain) {
+		t.Fatalf("expected %d samples, got %d", len(plain), len(looped))
+	}
+
+	// The ringing tail is mixed into the beginning, fading out
+	tail := tailed[len(plain):]
+	if looped[0] != plain[0]+tail[0] {
+		t.Fatalf("expected tail mixed in at the beginning, got %v", looped[0])
+	}
+	if !equal(looped[800:], plain[800:]) {
+		t.Fatal("crossfade changed samples past its end")
+	}
+}
+
+func TestWriteWAV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, []float64{0, 0.5, -1, 2}, 8000); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	if len(data) != 44+4*2 {
+		t.Fatalf("unexpected WAV size %d", len(data))
+	}
+	if string(data[:4]) != "RIFF" || string(data[8:16]) != "WAVEfmt " || string(data[36:40]) != "data" {
+		t.Fatalf("invalid WAV header % x", data[:44])
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:]); rate != 8000 {
+		t.Fatalf("unexpected sample rate %d", rate)
+	}
+
+	pcm := make([]int16, 4)
+	binary.Read(bytes.NewReader(data[44:]), binary.LittleEndian, pcm)
+	expected := []int16{0, 16384, -32767, 32767}
+	for i := range pcm {
+		if pcm[i] != expected[i] {
+			t.Fatalf("unexpected samples %v, expected %v", pcm, expected)
+		}
+	}
+}
+
+func equal(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestStream(t *testing.T) {
+	p := loadPattern(t)
+
+	// Steps are exactly 1000 samples long at 8000 Hz
+	rendered := Render(p, Options{SampleRate: 8000, Loops: 2})
+
+	s := NewStream(p, Options{SampleRate: 8000})
+	var streamed []float64
+	for len(streamed) < len(rendered) {
+		buf := make([]float64, 441)
+		s.Process(buf)
+		streamed = append(streamed, buf...)
+	}
+
+	if !equal(streamed[:len(rendered)], rendered) {
+		t.Fatal("streamed samples differ from the render")
+	}
+}
+
+func TestStreamFlams(t *testing.T) {
+	p := &drum.Pattern{
+		Tempo:  120,
+		Tracks: []drum.Track{{Name: "snare", Steps: make([]byte, 16)}},
+	}
+	for i := range p.Tracks[0].Steps {
+		p.Tracks[0].Steps[i] = drum.StepFlam
+	}
+
+	// Hits of steps 1000 samples apart land right after buffers start,
+	// e.g. at frame 31 of the 10th buffer, with grace notes 200 before
+	rendered := Render(p, Options{SampleRate: 8000, Loops: 2})
+
+	s := NewStream(p, Options{SampleRate: 8000})
+	var streamed []float64
+	for len(streamed) < len(rendered) {
+		buf := make([]float64, 441)
+		s.Process(buf)
+		streamed = append(streamed, buf...)
+	}
+
+	// The stream keeps looping, playing the grace note of the next loop
+	end := len(rendered) - 200
+	if !equal(streamed[:end], rendered[:end]) {
+		t.Fatal("streamed flams differ from the render")
+	}
+}
+
+func TestStreamAllocs(t *testing.T) {
+	s := NewStream(loadPattern(t), Options{SampleRate: 8000})
+	buf := make([]float64, 4096)
+
+	if allocs := testing.AllocsPerRun(100, func() { s.Process(buf) }); allocs > 0 {
+		t.Errorf("expected no allocations processing a buffer, got %v", allocs)
+	}
+}
+
+func TestStreamMetrics(t *testing.T) {
+	r := metrics.Prometheus("")
+	SetMetrics(r)
+	defer SetMetrics(metrics.Nop())
+
+	s := NewStream(loadPattern(t), Options{SampleRate: 8000})
+	buf := make([]float64, 80)
+
+	// Buffers of 10ms, the second one taking 20ms to process
+	now := time.Now()
+	for _, took := range []time.Duration{time.Millisecond, 20 * time.Millisecond} {
+		began := true
+		s.now = func() time.Time {
+			if began {
+				began = false
+				return now
+			}
+			return now.Add(took)
+		}
+		s.Process(buf)
+	}
+
+	var b strings.Builder
+	r.WriteTo(&b)
+	for _, expected := range []string{
+		"stream_buffers_total 2\n",
+		"stream_underruns_total 1\n",
+		"stream_process_duration_seconds_count 2\n",
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, b.String())
+		}
+	}
+}
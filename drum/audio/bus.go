@@ -0,0 +1,240 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// limiterLookahead is how early the limiter starts reducing gain
+	// before a peak.
+	limiterLookahead = 1500 * time.Microsecond
+	// limiterRelease is the time constant of gain recovering after a peak.
+	limiterRelease = 50 * time.Millisecond
+	// truePeakOversampling is the number of points per sample checked
+	// when estimating true peaks.
+	truePeakOversampling = 4
+)
+
+// Bus processes the mix of all tracks: first normalizing loudness,
+// then limiting peaks.
+type Bus struct {
+	// Normalize sets the gain so integrated loudness reaches TargetLUFS
+	Normalize  bool
+	TargetLUFS float64
+
+	// Limit keeps peaks under Ceiling, in dBFS, e.g. -1
+	Limit   bool
+	Ceiling float64
+	// TruePeak makes the limiter keep inter-sample peaks under the ceiling
+	TruePeak bool
+}
+
+// process applies the bus to samples in place.
+func (b Bus) process(samples []float64, sampleRate int) {
+	if b.Normalize {
+		loudness := Loudness(samples, sampleRate)
+
+		if !math.IsInf(loudness, 0) {
+			gain := math.Pow(10, (b.TargetLUFS-loudness)/20)
+			for i := range samples {
+				samples[i] *= gain
+			}
+		}
+	}
+
+	if b.Limit {
+		limit(samples, sampleRate, math.Pow(10, b.Ceiling/20), b.TruePeak)
+	}
+}
+
+// limit reduces gain of samples, so their peaks don't exceed ceiling.
+// Gain is reduced ahead of peaks and recovers smoothly after them.
+func limit(samples []float64, sampleRate int, ceiling float64, truePeak bool) {
+	lookahead := toSamples(limiterLookahead, sampleRate)
+	release := math.Exp(-1 / (limiterRelease.Seconds() * float64(sampleRate)))
+
+	// Gain required to keep every sample under the ceiling
+	required := make([]float64, len(samples))
+	for i, sample := range samples {
+		peak := math.Abs(sample)
+		if truePeak {
+			peak = interSamplePeak(samples, i)
+		}
+
+		required[i] = 1
+		if peak > ceiling {
+			required[i] = ceiling / peak
+		}
+	}
+
+	gain := 1.0
+	for i := range samples {
+		target := 1.0
+		for _, r := range required[i:min(i+lookahead+1, len(required))] {
+			target = math.Min(target, r)
+		}
+
+		if target < gain {
+			gain = target
+		} else {
+			gain = target + (gain-target)*release
+		}
+
+		samples[i] *= gain
+	}
+}
+
+// Peak returns the highest absolute value of samples.
+func Peak(samples []float64) float64 {
+	peak := 0.0
+	for _, sample := range samples {
+		peak = math.Max(peak, math.Abs(sample))
+	}
+
+	return peak
+}
+
+// TruePeak returns an estimate of the highest absolute value of the signal
+// represented by samples, including peaks between samples, found by
+// oversampling with cubic interpolation.
+func TruePeak(samples []float64) float64 {
+	peak := 0.0
+	for i := range samples {
+		peak = math.Max(peak, interSamplePeak(samples, i))
+	}
+
+	return peak
+}
+
+// interSamplePeak returns the highest absolute value of the signal
+// from sample i up to the next one.
+func interSamplePeak(samples []float64, i int) float64 {
+	at := func(j int) float64 {
+		if j < 0 || j >= len(samples) {
+			return 0
+		}
+		return samples[j]
+	}
+
+	y0, y1, y2, y3 := at(i-1), at(i), at(i+1), at(i+2)
+	peak := math.Abs(y1)
+
+	for k := 1; k < truePeakOversampling; k++ {
+		t := float64(k) / truePeakOversampling
+
+		// Catmull-Rom spline through the neighbouring samples
+		v := 0.5 * (2*y1 + (y2-y0)*t + (2*y0-5*y1+4*y2-y3)*t*t + (3*y1-y0-3*y2+y3)*t*t*t)
+		peak = math.Max(peak, math.Abs(v))
+	}
+
+	return peak
+}
+
+// Loudness returns integrated loudness of mono samples in LUFS, measured
+// as specified by ITU-R BS.1770: K-weighted and gated over 400ms blocks.
+// It returns negative infinity for signals too short or too quiet to
+// measure.
+func Loudness(samples []float64, sampleRate int) float64 {
+	weighted := kWeighting(samples, float64(sampleRate))
+
+	block := toSamples(400*time.Millisecond, sampleRate)
+	step := block / 4
+
+	var powers []float64
+	for start := 0; start+block <= len(weighted); start += step {
+		sum := 0.0
+		for _, s := range weighted[start : start+block] {
+			sum += s * s
+		}
+
+		power := sum / float64(block)
+		// Absolute gate at -70 LUFS
+		if loudness(power) > -70 {
+			powers = append(powers, power)
+		}
+	}
+
+	if len(powers) == 0 {
+		return math.Inf(-1)
+	}
+
+	// Relative gate 10 LU below loudness of blocks over the absolute gate
+	gate := loudness(mean(powers)) - 10
+
+	var gated []float64
+	for _, power := range powers {
+		if loudness(power) > gate {
+			gated = append(gated, power)
+		}
+	}
+
+	return loudness(mean(gated))
+}
+
+// loudness converts mean square of K-weighted samples to LUFS.
+func loudness(power float64) float64 {
+	return -0.691 + 10*math.Log10(power)
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum / float64(len(values))
+}
+
+// kWeighting returns samples filtered by the K-weighting filter of
+// BS.1770: a high shelf modelling the head, followed by a high-pass.
+// Coefficients are derived for the sample rate.
+func kWeighting(samples []float64, rate float64) []float64 {
+	// High shelf
+	k := math.Tan(math.Pi * 1681.974450955533 / rate)
+	vh := math.Pow(10, 3.999843853973347/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	q := 0.7071752369554196
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	// High-pass
+	k = math.Tan(math.Pi * 38.13547087602444 / rate)
+	q = 0.5003270373238773
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	return highPass.filter(shelf.filter(samples))
+}
+
+// biquad is a second order IIR filter with normalized coefficients.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+// filter returns samples filtered by the biquad.
+func (f biquad) filter(samples []float64) []float64 {
+	out := make([]float64, len(samples))
+	var x1, x2, y1, y2 float64
+
+	for i, x := range samples {
+		y := f.b0*x + f.b1*x1 + f.b2*x2 - f.a1*y1 - f.a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		out[i] = y
+	}
+
+	return out
+}
@@ -0,0 +1,64 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// sine returns a sine wave of the frequency and amplitude.
+func sine(freq, amplitude float64, seconds float64, sampleRate int) []float64 {
+	samples := make([]float64, int(seconds*float64(sampleRate)))
+	for i := range samples {
+		samples[i] = amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate))
+	}
+
+	return samples
+}
+
+func TestLoudness(t *testing.T) {
+	// BS.1770 calibration: a full scale 997 Hz sine measures -3.01 LUFS
+	for _, rate := range []int{44100, 48000} {
+		loudness := Loudness(sine(997, 1, 2, rate), rate)
+		if math.Abs(loudness+3.01) > 0.05 {
+			t.Errorf("%d Hz: full scale sine measured %.3f LUFS", rate, loudness)
+		}
+	}
+
+	if loudness := Loudness(make([]float64, 44100), 44100); !math.IsInf(loudness, -1) {
+		t.Errorf("silence measured %v LUFS", loudness)
+	}
+}
+
+func TestBusNormalize(t *testing.T) {
+	samples := sine(997, 0.1, 2, 44100)
+
+	Bus{Normalize: true, TargetLUFS: -14}.process(samples, 44100)
+
+	if loudness := Loudness(samples, 44100); math.Abs(loudness+14) > 0.05 {
+		t.Fatalf("normalized to %.3f LUFS, expected -14", loudness)
+	}
+}
+
+func TestBusLimit(t *testing.T) {
+	p := loadPattern(t)
+	// Way too loud, so hits clip
+	loud := Bus{Normalize: true, TargetLUFS: 0}
+
+	clipping := Render(p, Options{Bus: loud})
+	if Peak(clipping) <= 1 {
+		t.Fatalf("expected clipping render, peak is %v", Peak(clipping))
+	}
+
+	ceiling := math.Pow(10, -1.0/20)
+
+	limited := loud
+	limited.Limit, limited.Ceiling = true, -1
+	if peak := Peak(Render(p, Options{Bus: limited})); peak > ceiling+1e-9 {
+		t.Fatalf("limited peak %v exceeds ceiling %v", peak, ceiling)
+	}
+
+	limited.TruePeak = true
+	if peak := TruePeak(Render(p, Options{Bus: limited})); peak > ceiling*1.01 {
+		t.Fatalf("limited true peak %v exceeds ceiling %v", peak, ceiling)
+	}
+}
@@ -0,0 +1,183 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+const (
+	// onsetHop is the interval the envelope of audio is measured at.
+	onsetHop = 5 * time.Millisecond
+	// onsetHistory is the number of hops a new onset must be louder than.
+	onsetHistory = 10
+	// onsetRise is how many times louder than the preceding hops an onset is.
+	onsetRise = 2
+	// onsetThreshold is the level of onsets relative to the loudest one.
+	onsetThreshold = 0.1
+	// onsetGap is the shortest time between onsets, so decays of hits
+	// aren't detected as new ones.
+	onsetGap = 50 * time.Millisecond
+	// onsetPeak is the time after an onset its velocity is measured over.
+	onsetPeak = 30 * time.Millisecond
+)
+
+// ReadWAV reads samples of a 16-bit PCM WAV file, mixing channels to mono.
+func ReadWAV(r io.Reader) (samples []float64, sampleRate int, err error) {
+	var header [12]byte
+	_, err = io.ReadFull(r, header[:])
+	if err != nil {
+		return nil, 0, err
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return nil, 0, errors.New("not a WAV file")
+	}
+
+	channels := 0
+	for {
+		var chunk [8]byte
+		_, err = io.ReadFull(r, chunk[:])
+		if err != nil {
+			return nil, 0, fmt.Errorf("something went wrong reading WAV chunk - %v", err)
+		}
+
+		size := binary.LittleEndian.Uint32(chunk[4:])
+		data := make([]byte, size+size%2)
+		_, err = io.ReadFull(r, data)
+		if err == io.ErrUnexpectedEOF && string(chunk[:4]) == "data" {
+			// Tolerate files cut off or written with a wrong size
+			err = nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("something went wrong reading WAV chunk - %v", err)
+		}
+
+		switch string(chunk[:4]) {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, errors.New("invalid WAV format chunk")
+			}
+			format := binary.LittleEndian.Uint16(data)
+			channels = int(binary.LittleEndian.Uint16(data[2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(data[4:]))
+			bits := binary.LittleEndian.Uint16(data[14:])
+			if format != 1 || bits != 16 || channels == 0 || sampleRate == 0 {
+				return nil, 0, fmt.Errorf("unsupported WAV format %d with %d bits and %d channels", format, bits, channels)
+			}
+		case "data":
+			if channels == 0 {
+				return nil, 0, errors.New("WAV data precedes its format")
+			}
+
+			frames := len(data) / 2 / channels
+			samples = make([]float64, frames)
+			for i := range samples {
+				for c := 0; c < channels; c++ {
+					sample := int16(binary.LittleEndian.Uint16(data[(i*channels+c)*2:]))
+					samples[i] += float64(sample) / math.MaxInt16 / float64(channels)
+				}
+			}
+
+			return samples, sampleRate, nil
+		}
+	}
+}
+
+// ReadGrooveWAV reads a groove from a recording of a reference song in
+// a 16-bit PCM WAV file, see ExtractGroove.
+func ReadGrooveWAV(r io.Reader, tempo drum.BPM) (drum.Groove, error) {
+	samples, sampleRate, err := ReadWAV(r)
+	if err != nil {
+		return drum.Groove{}, err
+	}
+
+	return ExtractGroove(samples, sampleRate, tempo)
+}
+
+// ExtractGroove returns the groove of audio played at the tempo, starting
+// on a downbeat. Onsets of hits are detected as sudden rises of loudness,
+// then their offsets from the nearest steps and peaks relative to the
+// average one become the feel of the steps, see drum.GrooveFromOnsets.
+func ExtractGroove(samples []float64, sampleRate int, tempo drum.BPM) (drum.Groove, error) {
+	if tempo <= 0 {
+		return drum.Groove{}, fmt.Errorf("invalid tempo %v", tempo)
+	}
+
+	times, peaks := detectOnsets(samples, sampleRate)
+	if len(times) == 0 {
+		return drum.Groove{}, errors.New("no hits found in audio")
+	}
+
+	average := 0.0
+	for _, peak := range peaks {
+		average += peak / float64(len(peaks))
+	}
+
+	step := tempo.StepDuration()
+	onsets := make([]drum.GrooveOnset, len(times))
+	for i, t := range times {
+		onsets[i] = drum.GrooveOnset{
+			Position: float64(t) / float64(step),
+			Velocity: peaks[i] / average,
+		}
+	}
+
+	return drum.GrooveFromOnsets(onsets), nil
+}
+
+// detectOnsets returns times and peak levels of onsets of hits.
+func detectOnsets(samples []float64, sampleRate int) ([]time.Duration, []float64) {
+	hop := max(toSamples(onsetHop, sampleRate), 1)
+
+	envelope := make([]float64, (len(samples)+hop-1)/hop)
+	loudest := 0.0
+	for i, sample := range samples {
+		level := math.Abs(sample)
+		envelope[i/hop] = math.Max(envelope[i/hop], level)
+		loudest = math.Max(loudest, level)
+	}
+	if loudest == 0 {
+		return nil, nil
+	}
+
+	var times []time.Duration
+	var peaks []float64
+	last := -len(envelope)
+	gap := toSamples(onsetGap, sampleRate) / hop
+
+	for k, level := range envelope {
+		if level < loudest*onsetThreshold || k-last < gap {
+			continue
+		}
+
+		history := 0.0
+		for j := max(k-onsetHistory, 0); j < k; j++ {
+			history = math.Max(history, envelope[j])
+		}
+		if level < history*onsetRise {
+			continue
+		}
+
+		// The onset is the first sample reaching half of the hop's level
+		start := k * hop
+		for start < len(samples) && math.Abs(samples[start]) < level/2 {
+			start++
+		}
+
+		peak := 0.0
+		for _, sample := range samples[start:min(start+toSamples(onsetPeak, sampleRate), len(samples))] {
+			peak = math.Max(peak, math.Abs(sample))
+		}
+
+		times = append(times, time.Duration(start)*time.Second/time.Duration(sampleRate))
+		peaks = append(peaks, peak)
+		last = k
+	}
+
+	return times, peaks
+}
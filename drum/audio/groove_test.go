@@ -0,0 +1,81 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestReadGrooveWAV(t *testing.T) {
+	const sampleRate = 8000
+
+	// Clicks on every step of a bar at 120 BPM, every second one late
+	// by a third of a step and louder
+	samples := make([]float64, 2*sampleRate)
+	stepSamples := sampleRate / 8
+	for step := 0; step < 16; step++ {
+		start, level := step*stepSamples, 0.5
+		if step%2 == 1 {
+			start += stepSamples / 3
+			level = 1
+		}
+
+		for i := 0; i < sampleRate/50; i++ {
+			samples[start+i] = level * math.Exp(-float64(i)/20) * math.Cos(float64(i))
+		}
+	}
+
+	var wav bytes.Buffer
+	if err := WriteWAV(&wav, samples, sampleRate); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := ReadGrooveWAV(&wav, 120)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(g.Steps) != 16 {
+		t.Fatalf("expected a bar of steps, got %d", len(g.Steps))
+	}
+	for i, step := range g.Steps {
+		offset, velocity := 0.0, 2.0/3
+		if i%2 == 1 {
+			offset, velocity = 1.0/3, 4.0/3
+		}
+
+		if math.Abs(step.Offset-offset) > 0.02 || math.Abs(step.Velocity-velocity) > 0.02 {
+			t.Fatalf("unexpected feel %+v of step %d, expected offset %.2f and velocity %.2f", step, i, offset, velocity)
+		}
+	}
+
+	if _, err := ExtractGroove(make([]float64, sampleRate), sampleRate, 120); err == nil {
+		t.Fatal("expected error extracting groove of silence")
+	}
+}
+
+func TestReadWAV(t *testing.T) {
+	samples := []float64{0, 0.5, -0.5, 1}
+
+	var wav bytes.Buffer
+	if err := WriteWAV(&wav, samples, 22050); err != nil {
+		t.Fatal(err)
+	}
+
+	read, sampleRate, err := ReadWAV(&wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sampleRate != 22050 || len(read) != len(samples) {
+		t.Fatalf("unexpected %d samples at %d Hz", len(read), sampleRate)
+	}
+	for i := range samples {
+		if math.Abs(read[i]-samples[i]) > 1e-4 {
+			t.Fatalf("unexpected sample %d: %v", i, read[i])
+		}
+	}
+
+	if _, _, err := ReadWAV(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00WAVX"))); err == nil {
+		t.Fatal("expected error reading invalid WAV")
+	}
+}
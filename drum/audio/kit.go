@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kit holds samples of tracks by their lower-case names, played instead
+// of synthesized voices.
+type Kit map[string][]float64
+
+// LoadKit reads 16-bit PCM WAV files in the directory as samples of
+// tracks named like the files, e.g. kick.wav for the kick track.
+// Samples are resampled to the sample rate.
+func LoadKit(dir string, sampleRate int) (Kit, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.[wW][aA][vV]"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no WAV files in kit %s", dir)
+	}
+
+	kit := Kit{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		samples, rate, err := ReadWAV(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("something went wrong reading sample %s - %v", path, err)
+		}
+
+		name := strings.ToLower(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		kit[name] = resample(samples, rate, sampleRate)
+	}
+
+	return kit, nil
+}
+
+// sound returns samples of the track with the name at full velocity,
+// from the kit or synthesized if the kit has none.
+func (k Kit) sound(name string, sampleRate int) []float64 {
+	if samples, ok := k[strings.ToLower(name)]; ok {
+		return samples
+	}
+
+	v := voiceFor(name)
+	samples := make([]float64, v.length(sampleRate))
+	v.play(samples, 0, newNoise(), 0, sampleRate, 1)
+
+	return samples
+}
+
+// resample converts samples from one sample rate to another, interpolating
+// linearly between them.
+func resample(samples []float64, from, to int) []float64 {
+	if from == to || len(samples) == 0 {
+		return samples
+	}
+
+	ratio := float64(from) / float64(to)
+	resampled := make([]float64, int(float64(len(samples))/ratio))
+	for i := range resampled {
+		at := float64(i) * ratio
+		j := int(at)
+		if j+1 >= len(samples) {
+			resampled[i] = samples[len(samples)-1]
+			continue
+		}
+
+		frac := at - math.Floor(at)
+		resampled[i] = samples[j]*(1-frac) + samples[j+1]*frac
+	}
+
+	return resampled
+}
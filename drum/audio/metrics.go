@@ -0,0 +1,56 @@
+package audio
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum/metrics"
+)
+
+// processBuckets are upper bounds of buffer processing time buckets
+// in seconds.
+var processBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05}
+
+// streamMetrics holds the *streamStats set by SetMetrics.
+var streamMetrics atomic.Pointer[streamStats]
+
+func init() {
+	SetMetrics(metrics.Nop())
+}
+
+// streamStats holds metrics reported by streams.
+type streamStats struct {
+	buffers   metrics.Counter
+	underruns metrics.Counter
+	latency   metrics.Histogram
+}
+
+func newStreamStats(r metrics.Registerer) *streamStats {
+	return &streamStats{
+		buffers:   r.Counter("stream_buffers_total"),
+		underruns: r.Counter("stream_underruns_total"),
+		latency:   r.Histogram("stream_process_duration_seconds", processBuckets),
+	}
+}
+
+// SetMetrics sets the Registerer used to report metrics of streams:
+// buffers processed, their processing time and underruns, when
+// processing a buffer takes longer than playing it. It's safe to call
+// while streams are playing.
+func SetMetrics(r metrics.Registerer) {
+	streamMetrics.Store(newStreamStats(r))
+}
+
+// observe records processing of a buffer lasting length, which took
+// elapsed time. It returns true for underruns.
+func (s *streamStats) observe(elapsed, length time.Duration) bool {
+	s.buffers.Add(1)
+	s.latency.Observe(elapsed.Seconds())
+
+	underrun := elapsed > length
+	if underrun {
+		s.underruns.Add(1)
+	}
+
+	return underrun
+}
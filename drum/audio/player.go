@@ -0,0 +1,136 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// playerBlock is the duration of blocks of samples written to the device.
+const playerBlock = 10 * time.Millisecond
+
+// Player plays hits as they're triggered, e.g. by a sequencer, writing
+// 16-bit little-endian mono PCM to an audio device, like the stdin of
+// aplay. Writes are expected to block while the device plays, which
+// paces the player. Hits are heard after the device's buffer, so its
+// latency should be compensated like other outputs'.
+type Player struct {
+	w           io.Writer
+	sounds      [][]float64
+	sampleRate  int
+	flamSpacing int
+
+	mu sync.Mutex
+	// Mix of sounds not written yet
+	pending []float64
+	closing bool
+	err     error
+
+	done chan struct{}
+}
+
+// NewPlayer returns a player of the pattern's tracks with samples of the
+// kit, or synthesized voices of tracks without samples. Only the sample
+// rate and flam spacing options are used.
+func NewPlayer(w io.Writer, p *drum.Pattern, kit Kit, opts Options) *Player {
+	opts = opts.withDefaults()
+	p.LoadTracks()
+
+	pl := &Player{
+		w:           w,
+		sounds:      make([][]float64, len(p.Tracks)),
+		sampleRate:  opts.SampleRate,
+		flamSpacing: toSamples(opts.FlamSpacing, opts.SampleRate),
+		done:        make(chan struct{}),
+	}
+	for i, track := range p.Tracks {
+		pl.sounds[i] = kit.sound(track.Name, opts.SampleRate)
+	}
+
+	go pl.run()
+
+	return pl
+}
+
+// Trigger plays the event's hit at once. Main hits of flams are played
+// after their grace notes.
+func (pl *Player) Trigger(e drum.Event) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	velocity := float64(e.Velocity) / 127
+	if e.Flam {
+		pl.mix(e.TrackIndex, 0, velocity*graceVelocity)
+		pl.mix(e.TrackIndex, pl.flamSpacing, velocity)
+		return
+	}
+
+	pl.mix(e.TrackIndex, 0, velocity)
+}
+
+// Close waits for sounds still ringing to be played and stops the player.
+// It returns the first error writing to the device.
+func (pl *Player) Close() error {
+	pl.mu.Lock()
+	pl.closing = true
+	pl.mu.Unlock()
+
+	<-pl.done
+
+	return pl.err
+}
+
+// mix adds the track's sound with the velocity to pending samples at
+// the offset.
+func (pl *Player) mix(track, offset int, velocity float64) {
+	sound := pl.sounds[track]
+
+	if length := offset + len(sound); length > len(pl.pending) {
+		pl.pending = append(pl.pending, make([]float64, length-len(pl.pending))...)
+	}
+
+	for i, sample := range sound {
+		pl.pending[offset+i] += sample * velocity
+	}
+}
+
+// run writes blocks of pending samples, or silence, until the player is
+// closed and no sounds are pending.
+func (pl *Player) run() {
+	defer close(pl.done)
+
+	block := make([]float64, toSamples(playerBlock, pl.sampleRate))
+	pcm := make([]byte, 2*len(block))
+
+	for {
+		pl.mu.Lock()
+		if pl.closing && len(pl.pending) == 0 {
+			pl.mu.Unlock()
+			return
+		}
+
+		n := copy(block, pl.pending)
+		for i := n; i < len(block); i++ {
+			block[i] = 0
+		}
+		pl.pending = append(pl.pending[:0], pl.pending[n:]...)
+		pl.mu.Unlock()
+
+		for i, sample := range block {
+			sample = math.Round(math.Max(-1, math.Min(1, sample)) * math.MaxInt16)
+			binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(sample)))
+		}
+
+		_, err := pl.w.Write(pcm)
+		if err != nil {
+			pl.mu.Lock()
+			pl.err = err
+			pl.mu.Unlock()
+			return
+		}
+	}
+}
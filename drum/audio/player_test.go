@@ -0,0 +1,93 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestPlayer(t *testing.T) {
+	p := &drum.Pattern{Tracks: []drum.Track{{Name: "kick", Steps: []byte{1}}}}
+	r, w := io.Pipe()
+	pl := NewPlayer(w, p, Kit{"kick": {0.5, -0.25}}, Options{})
+	pl.Trigger(drum.Event{TrackIndex: 0, Velocity: 127})
+
+	// Silence is played until the hit
+	var pcm []int16
+	for len(pcm) < 3 {
+		var sample int16
+		if err := binary.Read(r, binary.LittleEndian, &sample); err != nil {
+			t.Fatal(err)
+		}
+		if sample != 0 || len(pcm) > 0 {
+			pcm = append(pcm, sample)
+		}
+	}
+	expected := []int16{16384, -8192, 0}
+	for i := range pcm {
+		if pcm[i] != expected[i] {
+			t.Fatalf("unexpected samples %v, expected %v", pcm, expected)
+		}
+	}
+
+	go io.Copy(io.Discard, r)
+	if err := pl.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlayerError(t *testing.T) {
+	r, w := io.Pipe()
+	r.CloseWithError(errors.New("device unplugged"))
+
+	p := &drum.Pattern{Tracks: []drum.Track{{Name: "kick", Steps: []byte{1}}}}
+	pl := NewPlayer(w, p, nil, Options{})
+	pl.Trigger(drum.Event{TrackIndex: 0, Velocity: 127})
+	if err := pl.Close(); err == nil || err.Error() != "device unplugged" {
+		t.Fatalf("expected the device's error, got %v", err)
+	}
+}
+
+func TestLoadKit(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "Kick.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteWAV(f, []float64{0, 0.5, 1}, 22050); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	kit, err := LoadKit(dir, 44100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Samples are doubled and interpolated
+	samples := kit["kick"]
+	expected := []float64{0, 0.25, 0.5, 0.75, 1, 1}
+	if len(samples) != len(expected) {
+		t.Fatalf("unexpected samples %v, expected %v", samples, expected)
+	}
+	for i := range samples {
+		if math.Abs(samples[i]-expected[i]) > 1e-4 {
+			t.Fatalf("unexpected samples %v, expected %v", samples, expected)
+		}
+	}
+
+	// Tracks without samples are synthesized
+	if len(kit.sound("snare", 44100)) == 0 {
+		t.Fatal("no synthesized sound of a track missing in the kit")
+	}
+
+	if _, err := LoadKit(t.TempDir(), 44100); err == nil {
+		t.Fatal("expected an error loading an empty kit")
+	}
+}
@@ -0,0 +1,122 @@
+package audio
+
+import (
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+	"github.com/m110/go-challenge-1/drum/sequencer"
+)
+
+// Stream plays a pattern live, in a loop, filling buffers requested by
+// an audio callback. Events are scheduled on exact samples, so timing
+// doesn't depend on when the callback runs, and the output matches
+// Render's. The bus isn't applied, as it needs the whole render.
+type Stream struct {
+	scheduler   *sequencer.Scheduler
+	voices      []voice
+	noise       *rand.Rand
+	sampleRate  int
+	flamSpacing int
+	logger      *slog.Logger
+
+	// Samples written to buffers so far
+	written int64
+	// Sounds ringing past the last buffer, starting at sample written
+	pending []float64
+
+	now func() time.Time
+}
+
+// NewStream returns a stream playing the pattern. Loops, tail and bus
+// options are ignored.
+func NewStream(p *drum.Pattern, opts Options) *Stream {
+	opts = opts.withDefaults()
+	p.LoadTracks()
+
+	s := &Stream{
+		scheduler:   sequencer.NewScheduler(p, opts.SampleRate),
+		voices:      make([]voice, len(p.Tracks)),
+		noise:       newNoise(),
+		sampleRate:  opts.SampleRate,
+		flamSpacing: toSamples(opts.FlamSpacing, opts.SampleRate),
+		logger:      p.Logger(),
+		now:         time.Now,
+	}
+
+	longest := 0
+	for i, track := range p.Tracks {
+		s.voices[i] = voiceFor(track.Name)
+		if length := s.voices[i].length(opts.SampleRate); length > longest {
+			longest = length
+		}
+	}
+	// Room for a second of buffers, so the callback doesn't allocate
+	s.pending = make([]float64, 0, longest+s.flamSpacing+opts.SampleRate)
+
+	return s
+}
+
+// Sequencer returns the sequencer played by the stream, e.g. to set its
+// practice options or control its transport.
+func (s *Stream) Sequencer() *sequencer.Sequencer {
+	return s.scheduler.Sequencer()
+}
+
+// Process fills buf with the following samples of the pattern.
+// It's meant to be called from the audio callback. Buffers processed
+// slower than they play are reported as underruns, see SetMetrics.
+func (s *Stream) Process(buf []float64) {
+	began := s.now()
+
+	// Events are scheduled ahead of the buffer by the flam spacing,
+	// so grace notes are played before their hits as in the render
+	frames := len(buf)
+	if s.scheduler.Elapsed() == 0 {
+		frames += s.flamSpacing
+	}
+
+	start := s.scheduler.Elapsed()
+	for _, e := range s.scheduler.Process(frames) {
+		at := start + int64(e.Frame)
+		velocity := float64(e.Velocity) / 127
+
+		if e.Flam {
+			// Only flams at the very start have no room for grace notes
+			grace := at - int64(s.flamSpacing)
+			if grace < 0 {
+				grace = 0
+			}
+			s.play(e.TrackIndex, grace, velocity*graceVelocity)
+		}
+
+		s.play(e.TrackIndex, at, velocity)
+	}
+
+	n := copy(buf, s.pending)
+	for i := n; i < len(buf); i++ {
+		buf[i] = 0
+	}
+	s.pending = append(s.pending[:0], s.pending[n:]...)
+	s.written += int64(len(buf))
+
+	length := time.Duration(len(buf)) * time.Second / time.Duration(s.sampleRate)
+	took := s.now().Sub(began)
+	if streamMetrics.Load().observe(took, length) && s.logger != nil {
+		s.logger.Debug("buffer underrun", "sample", s.written, "took", took, "length", length)
+	}
+}
+
+// play adds the sound of the track's voice to pending samples, starting
+// at the sample at since the start.
+func (s *Stream) play(track int, at int64, velocity float64) {
+	v := s.voices[track]
+	frame := int(at - s.written)
+
+	for length := frame + v.length(s.sampleRate); len(s.pending) < length; {
+		s.pending = append(s.pending, 0)
+	}
+
+	v.play(s.pending, frame, s.noise, at, s.sampleRate, velocity)
+}
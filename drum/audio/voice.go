@@ -0,0 +1,103 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// voice synthesizes a single drum sound.
+type voice struct {
+	// Pitch of the tone in Hz, sweeping from start to end
+	start, end float64
+	// Decay of the tone and noise in seconds, a zero decay mutes the part
+	toneDecay, noiseDecay float64
+	// bright noise is high-passed, like hi-hats and cymbals
+	bright bool
+}
+
+// voices by General MIDI percussion notes
+var voices = map[byte]voice{
+	36: {start: 150, end: 45, toneDecay: 0.35},
+	37: {start: 800, end: 800, toneDecay: 0.02, noiseDecay: 0.02, bright: true},
+	38: {start: 220, end: 180, toneDecay: 0.08, noiseDecay: 0.15},
+	39: {noiseDecay: 0.12},
+	42: {noiseDecay: 0.05, bright: true},
+	46: {noiseDecay: 0.35, bright: true},
+	43: {start: 110, end: 80, toneDecay: 0.3},
+	45: {start: 130, end: 95, toneDecay: 0.28},
+	47: {start: 160, end: 120, toneDecay: 0.25},
+	50: {start: 200, end: 150, toneDecay: 0.22},
+	49: {noiseDecay: 1.2, bright: true},
+	51: {start: 3000, end: 3000, toneDecay: 0.4, noiseDecay: 0.6, bright: true},
+	54: {noiseDecay: 0.1, bright: true},
+	56: {start: 560, end: 560, toneDecay: 0.15},
+	60: {start: 400, end: 380, toneDecay: 0.12},
+	63: {start: 330, end: 310, toneDecay: 0.18},
+	64: {start: 220, end: 200, toneDecay: 0.2},
+	70: {noiseDecay: 0.06, bright: true},
+	75: {start: 2500, end: 2500, toneDecay: 0.05},
+}
+
+// defaultVoice plays tracks with no known instrument.
+var defaultVoice = voice{start: 440, end: 440, toneDecay: 0.1}
+
+// voiceFor returns the voice playing a track with the given name.
+func voiceFor(name string) voice {
+	if note, ok := drum.GMNote(name); ok {
+		if v, ok := voices[note]; ok {
+			return v
+		}
+	}
+
+	return defaultVoice
+}
+
+// play adds the sound with the velocity to samples, starting at index at.
+// The sound is cut at the end of samples. Noise is reseeded with seed,
+// so renders are reproducible, and reused between hits.
+func (v voice) play(samples []float64, at int, noise *rand.Rand, seed int64, sampleRate int, velocity float64) {
+	noise.Seed(seed)
+	rate := float64(sampleRate)
+
+	length := v.length(sampleRate)
+	phase, last := 0.0, 0.0
+
+	for i := 0; i < length && at+i < len(samples); i++ {
+		if at+i < 0 {
+			continue
+		}
+
+		t := float64(i) / rate
+		sample := 0.0
+
+		if v.toneDecay > 0 {
+			// Exponential pitch sweep from start to end
+			freq := v.end + (v.start-v.end)*math.Exp(-t/0.03)
+			phase += 2 * math.Pi * freq / rate
+			sample += math.Sin(phase) * math.Exp(-t/v.toneDecay)
+		}
+
+		if v.noiseDecay > 0 {
+			n := noise.Float64()*2 - 1
+			if v.bright {
+				// First difference is a simple high-pass filter
+				n, last = (n-last)/2, n
+			}
+			sample += n * math.Exp(-t/v.noiseDecay)
+		}
+
+		samples[at+i] += sample * velocity * 0.5
+	}
+}
+
+// newNoise returns a source of noise for voices.
+func newNoise() *rand.Rand {
+	return rand.New(rand.NewSource(0))
+}
+
+// length returns the number of samples the sound lasts.
+func (v voice) length(sampleRate int) int {
+	return int(math.Max(v.toneDecay, v.noiseDecay) * 5 * float64(sampleRate))
+}
@@ -0,0 +1,48 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// WriteWAV writes mono samples as a 16-bit PCM WAV file.
+// Samples outside the range from -1 to 1 are clipped.
+func WriteWAV(w io.Writer, samples []float64, sampleRate int) error {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+		blockAlign    = channels * bitsPerSample / 8
+	)
+
+	dataSize := uint32(len(samples) * blockAlign)
+
+	var buffer bytes.Buffer
+	buffer.WriteString("RIFF")
+	binary.Write(&buffer, binary.LittleEndian, 36+dataSize)
+	buffer.WriteString("WAVE")
+
+	buffer.WriteString("fmt ")
+	binary.Write(&buffer, binary.LittleEndian, struct {
+		Size          uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+	}{16, 1, channels, uint32(sampleRate), uint32(sampleRate * blockAlign), blockAlign, bitsPerSample})
+
+	buffer.WriteString("data")
+	binary.Write(&buffer, binary.LittleEndian, dataSize)
+
+	pcm := make([]int16, len(samples))
+	for i, sample := range samples {
+		pcm[i] = int16(math.Round(math.Max(-1, math.Min(1, sample)) * math.MaxInt16))
+	}
+	binary.Write(&buffer, binary.LittleEndian, pcm)
+
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
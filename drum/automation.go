@@ -0,0 +1,154 @@
+package drum
+
+import (
+	"sort"
+	"strings"
+)
+
+// Lane is automation of a sound parameter of a track, e.g. its decay,
+// with values set on steps, like parameter locks of drum machines.
+// Lanes are stored in the metadata sidecar.
+type Lane struct {
+	Parameter string `json:"parameter"`
+	// CC is the MIDI control change number the lane is exported as.
+	// If neither CC nor NRPN is set, a default of the parameter is used.
+	CC int `json:"cc,omitempty"`
+	// NRPN is the non-registered parameter number the lane is exported
+	// as, with 14-bit values, instead of CC
+	NRPN   int     `json:"nrpn,omitempty"`
+	Points []Point `json:"points"`
+}
+
+// Point is a value of an automated parameter set on a step.
+type Point struct {
+	// Step is the index in the track's steps, before its offset is applied
+	Step  int `json:"step"`
+	Value int `json:"value"`
+}
+
+// defaultLaneCCs are MIDI control changes of common parameters,
+// following General MIDI sound controllers where there are ones.
+var defaultLaneCCs = map[string]int{
+	"volume": 7,
+	"pan":    10,
+	"decay":  72,
+	"attack": 73,
+	"filter": 74,
+	"pitch":  75,
+	"tune":   75,
+	"reverb": 91,
+	"delay":  94,
+}
+
+// Automate sets the value of the parameter on the step, adding a lane
+// for the parameter if there isn't one. Points are kept sorted by step.
+func (t *Track) Automate(parameter string, step, value int) {
+	lane := t.Lane(parameter)
+	if lane == nil {
+		t.Automation = append(t.Automation, Lane{Parameter: parameter})
+		lane = &t.Automation[len(t.Automation)-1]
+	}
+
+	i := sort.Search(len(lane.Points), func(i int) bool {
+		return lane.Points[i].Step >= step
+	})
+
+	if i < len(lane.Points) && lane.Points[i].Step == step {
+		lane.Points[i].Value = value
+		return
+	}
+
+	lane.Points = append(lane.Points, Point{})
+	copy(lane.Points[i+1:], lane.Points[i:])
+	lane.Points[i] = Point{Step: step, Value: value}
+}
+
+// Lane returns the track's lane of the parameter, matched case
+// insensitively, or nil if the parameter isn't automated.
+func (t *Track) Lane(parameter string) *Lane {
+	for i := range t.Automation {
+		if strings.EqualFold(t.Automation[i].Parameter, parameter) {
+			return &t.Automation[i]
+		}
+	}
+
+	return nil
+}
+
+// ValueAt returns the value set on the step, if any.
+func (l Lane) ValueAt(step int) (int, bool) {
+	i := sort.Search(len(l.Points), func(i int) bool {
+		return l.Points[i].Step >= step
+	})
+
+	if i < len(l.Points) && l.Points[i].Step == step {
+		return l.Points[i].Value, true
+	}
+
+	return 0, false
+}
+
+// Controller returns the lane's MIDI control change or NRPN number
+// and whether it's an NRPN. It returns false if the lane can't be mapped.
+func (l Lane) Controller() (number int, nrpn bool, ok bool) {
+	switch {
+	case l.NRPN > 0:
+		return l.NRPN, true, true
+	case l.CC > 0:
+		return l.CC, false, true
+	}
+
+	cc, ok := defaultLaneCCs[strings.ToLower(l.Parameter)]
+	return cc, false, ok
+}
+
+// controlMessages returns MIDI messages setting the lane's controller
+// to value on the channel: a control change with the value limited to
+// 0-127, or an NRPN sequence with the value limited to 0-16383.
+func (l Lane) controlMessages(channel byte, value int) [][]byte {
+	number, nrpn, ok := l.Controller()
+	if !ok {
+		return nil
+	}
+
+	status := smfControl | channel
+	if !nrpn {
+		return [][]byte{{status, byte(number & 0x7f), byte(max(min(value, 0x7f), 0))}}
+	}
+
+	value = max(min(value, 0x3fff), 0)
+	return [][]byte{
+		{status, 99, byte(number >> 7 & 0x7f)},
+		{status, 98, byte(number & 0x7f)},
+		{status, 6, byte(value >> 7)},
+		{status, 38, byte(value & 0x7f)},
+	}
+}
+
+// applyAutomation sets points and MIDI mappings of lanes on the track.
+func applyAutomation(t *Track, lanes []Lane) {
+	for _, lane := range lanes {
+		for _, point := range lane.Points {
+			t.Automate(lane.Parameter, point.Step, point.Value)
+		}
+
+		if l := t.Lane(lane.Parameter); l != nil {
+			l.CC, l.NRPN = lane.CC, lane.NRPN
+		}
+	}
+}
+
+// cloneAutomation returns a deep copy of lanes.
+func cloneAutomation(lanes []Lane) []Lane {
+	if lanes == nil {
+		return nil
+	}
+
+	c := make([]Lane, len(lanes))
+	for i, lane := range lanes {
+		c[i] = lane
+		c[i].Points = append([]Point(nil), lane.Points...)
+	}
+
+	return c
+}
@@ -0,0 +1,102 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAutomate(t *testing.T) {
+	track := Track{Name: "snare", Steps: make([]byte, 16)}
+
+	track.Automate("decay", 8, 40)
+	track.Automate("Decay", 2, 90)
+	track.Automate("decay", 8, 20)
+	track.Automate("pitch", 0, 64)
+
+	if len(track.Automation) != 2 {
+		t.Fatalf("expected 2 lanes, got %+v", track.Automation)
+	}
+
+	expected := []Point{{2, 90}, {8, 20}}
+	if points := track.Lane("decay").Points; !reflect.DeepEqual(points, expected) {
+		t.Fatalf("unexpected points %v, expected %v", points, expected)
+	}
+
+	if value, ok := track.Lane("decay").ValueAt(8); !ok || value != 20 {
+		t.Errorf("unexpected value %d at step 8", value)
+	}
+	if _, ok := track.Lane("decay").ValueAt(3); ok {
+		t.Errorf("unexpected value at step 3")
+	}
+	if track.Lane("filter") != nil {
+		t.Errorf("unexpected lane of a parameter that isn't automated")
+	}
+}
+
+func TestAutomationPersistence(t *testing.T) {
+	p := &Pattern{Tempo: 120, Tracks: []Track{{ID: 1, Name: "snare", Steps: make([]byte, 16)}}}
+	p.Tracks[0].Automate("cutoff", 4, 1000)
+	p.Tracks[0].Lane("cutoff").NRPN = 300
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmarshaled := &Pattern{}
+	if err := json.Unmarshal(data, unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarshaled.Tracks[0].Automation, p.Tracks[0].Automation) {
+		t.Fatalf("automation wasn't unmarshaled: %+v", unmarshaled.Tracks[0].Automation)
+	}
+
+	restored := &Pattern{Tracks: []Track{{ID: 1}}}
+	restored.ApplyMetadata(p.Metadata())
+	if !reflect.DeepEqual(restored.Tracks[0].Automation, p.Tracks[0].Automation) {
+		t.Fatalf("automation wasn't restored from metadata: %+v", restored.Tracks[0].Automation)
+	}
+}
+
+func TestExportMIDIAutomation(t *testing.T) {
+	p := &Pattern{
+		Tempo: 120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: stepsFromString("x---x---")},
+			{ID: 1, Name: "snare", Steps: stepsFromString("--x---x-")},
+		},
+	}
+	p.Tracks[1].Automate("decay", 2, 100)
+	p.Tracks[1].Automate("cutoff", 6, 1000)
+	p.Tracks[1].Lane("cutoff").NRPN = 300
+
+	var buf bytes.Buffer
+	if err := ExportMIDI(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range [][]byte{
+		// 500000µs per quarter note
+		{smfMeta, smfTempo, 3, 0x07, 0xa1, 0x20},
+		// Decay at the default CC 72, right before the snare's note on
+		{0xb9, 72, 100, 0x00, 0x99, 38},
+		// NRPN 300 set to 1000
+		{0xb9, 99, 2, 0x00, 0xb9, 98, 44, 0x00, 0xb9, 6, 7, 0x00, 0xb9, 38, 104},
+	} {
+		if !bytes.Contains(buf.Bytes(), expected) {
+			t.Errorf("exported MIDI doesn't contain % x", expected)
+		}
+	}
+
+	notes, _, err := readSMF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []smfNote{{0, 36, 100}, {48, 38, 100}, {96, 36, 100}, {144, 38, 100}}
+	if !reflect.DeepEqual(notes, expected) {
+		t.Fatalf("unexpected notes %v, expected %v", notes, expected)
+	}
+}
@@ -0,0 +1,92 @@
+package drum
+
+import "math/bits"
+
+// maxBitmaskSteps is the number of steps a Bitmask holds.
+const maxBitmaskSteps = 64
+
+// Bitmask holds hits of up to 64 steps of a track, step i being bit i.
+// It allows fast analysis of large catalogs, operating on all steps
+// at once rather than step by step.
+type Bitmask uint64
+
+// Bitmask returns hits of the track's first 64 steps. Flams are hits.
+// The track's offset isn't applied.
+func (t Track) Bitmask() Bitmask {
+	if t.IsPacked() {
+		var mask Bitmask
+		for i := len(t.packed) - 1; i >= 0; i-- {
+			mask = mask<<8 | Bitmask(t.packed[i])
+		}
+		if t.packedLen < maxBitmaskSteps {
+			mask &= 1<<uint(t.packedLen) - 1
+		}
+		return mask
+	}
+
+	var mask Bitmask
+	for i, step := range t.Steps {
+		if i == maxBitmaskSteps {
+			break
+		}
+		if isHit(step) {
+			mask |= 1 << uint(i)
+		}
+	}
+
+	return mask
+}
+
+// Hits returns the number of hits.
+func (m Bitmask) Hits() int {
+	return bits.OnesCount64(uint64(m))
+}
+
+// Density returns the ratio of hits to the given number of steps,
+// like Track.Density.
+func (m Bitmask) Density(steps int) float64 {
+	if steps <= 0 {
+		return 0
+	}
+
+	return float64(m.Hits()) / float64(min(steps, maxBitmaskSteps))
+}
+
+// And returns hits present in both masks.
+func (m Bitmask) And(o Bitmask) Bitmask {
+	return m & o
+}
+
+// Or returns hits present in either mask.
+func (m Bitmask) Or(o Bitmask) Bitmask {
+	return m | o
+}
+
+// Xor returns hits present in exactly one of the masks.
+func (m Bitmask) Xor(o Bitmask) Bitmask {
+	return m ^ o
+}
+
+// Similarity returns the Jaccard similarity of the masks: the number of
+// shared hits divided by the number of hits in either. Two masks without
+// hits are equal.
+func (m Bitmask) Similarity(o Bitmask) float64 {
+	union := m.Or(o).Hits()
+	if union == 0 {
+		return 1
+	}
+
+	return float64(m.And(o).Hits()) / float64(union)
+}
+
+// Steps returns the mask as n steps of StepOn and StepOff.
+func (m Bitmask) Steps(n int) []byte {
+	steps := make([]byte, n)
+	for i := 0; i < n && i < maxBitmaskSteps; i++ {
+		if m&(1<<uint(i)) != 0 {
+			steps[i] = StepOn
+		}
+	}
+
+	return steps
+}
@@ -0,0 +1,52 @@
+package drum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBitmask(t *testing.T) {
+	kick := Track{Steps: stepsFromString("x---x---x---x---")}
+	other := Track{Steps: stepsFromString("x-x-x-x-x-x-x-x-")}
+	snare := Track{Steps: stepsFromString("----x-------x---")}
+
+	mask := kick.Bitmask()
+	if mask != 0x1111 {
+		t.Fatalf("unexpected mask %x", mask)
+	}
+	if mask.Density(16) != kick.Density() {
+		t.Errorf("density %v differs from track's %v", mask.Density(16), kick.Density())
+	}
+
+	if hits := mask.And(snare.Bitmask()).Hits(); hits != 2 {
+		t.Errorf("unexpected shared hits %d", hits)
+	}
+	if hits := mask.Or(other.Bitmask()).Hits(); hits != 8 {
+		t.Errorf("unexpected hits of either track %d", hits)
+	}
+	if steps := mask.Xor(snare.Bitmask()).Steps(16); !bytes.Equal(steps, stepsFromString("x-------x-------")) {
+		t.Errorf("unexpected steps %v", steps)
+	}
+
+	if s := mask.Similarity(other.Bitmask()); s != 0.5 {
+		t.Errorf("unexpected similarity %v", s)
+	}
+	if s := Bitmask(0).Similarity(0); s != 1 {
+		t.Errorf("unexpected similarity %v of empty masks", s)
+	}
+
+	long := Track{Steps: make([]byte, 80)}
+	long.Steps[63], long.Steps[64] = StepOn, StepOn
+	if m := long.Bitmask(); m != 1<<63 {
+		t.Errorf("unexpected mask %x of a long track", m)
+	}
+}
+
+func BenchmarkBitmaskSimilarity(b *testing.B) {
+	kick := Track{Steps: stepsFromString("x---x---x---x---")}.Bitmask()
+	other := Track{Steps: stepsFromString("x-x-x-x-x-x-x-x-")}.Bitmask()
+
+	for i := 0; i < b.N; i++ {
+		kick.Similarity(other)
+	}
+}
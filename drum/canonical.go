@@ -0,0 +1,69 @@
+package drum
+
+import (
+	"crypto/sha256"
+	"sort"
+	"strings"
+)
+
+// Canonicalize brings the pattern to a canonical form, so patterns saved
+// by different tools can be compared: whitespace is trimmed from track names,
+// the version is trimmed, lowercased and stripped of a "v" prefix, and tracks
+// are sorted by instrument class (kick, snare, toms, hi-hats, cymbals,
+// percussion, others), then by name and ID.
+func (p *Pattern) Canonicalize() {
+	p.ensureTracks()
+
+	p.Version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(p.Version)), "v")
+
+	for i := range p.Tracks {
+		p.Tracks[i].Name = strings.TrimSpace(p.Tracks[i].Name)
+	}
+
+	sort.SliceStable(p.Tracks, func(i, j int) bool {
+		a, b := p.Tracks[i], p.Tracks[j]
+
+		if ra, rb := instrumentRank(a.Name), instrumentRank(b.Name); ra != rb {
+			return ra < rb
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+
+		return a.ID < b.ID
+	})
+}
+
+// canonicalText returns a canonicalized copy of the pattern in the
+// canonical text format.
+func (p *Pattern) canonicalText() string {
+	c := p.Clone()
+	c.Canonicalize()
+
+	return FormatCanonical(c)
+}
+
+// Hash returns a SHA-256 hash of the pattern in its canonical form, see
+// Canonicalize and FormatCanonical, so patterns differing only in track
+// order, whitespace of names or formatting of the version have the same
+// hash. The pattern isn't changed.
+func (p *Pattern) Hash() [sha256.Size]byte {
+	return sha256.Sum256([]byte(p.canonicalText()))
+}
+
+// Equal returns true if the patterns have the same canonical form,
+// see Hash.
+func (p *Pattern) Equal(other *Pattern) bool {
+	return p.canonicalText() == other.canonicalText()
+}
+
+// instrumentRank returns the position of a track's instrument class
+// in canonical ordering, with unknown instruments last.
+func instrumentRank(name string) int {
+	class := classify(name)
+	if class == otherInstrument {
+		return int(percussionInstrument) + 1
+	}
+
+	return int(class)
+}
@@ -0,0 +1,64 @@
+package drum
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	p := &Pattern{
+		Version: " V0.808-Alpha ",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 5, Name: "cowbell", Steps: []byte{0}},
+			{ID: 9, Name: "  vox ", Steps: []byte{0}},
+			{ID: 3, Name: "hh-open", Steps: []byte{0}},
+			{ID: 4, Name: "hh-close", Steps: []byte{0}},
+			{ID: 1, Name: "snare ", Steps: []byte{0}},
+			{ID: 0, Name: "kick", Steps: []byte{0}},
+		},
+	}
+
+	p.Canonicalize()
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(0) kick	|-|
+(1) snare	|-|
+(4) hh-close	|-|
+(3) hh-open	|-|
+(5) cowbell	|-|
+(9) vox	|-|
+`
+	if p.String() != expected {
+		t.Fatalf("wrong canonical form.\nGot:\n%s\nExpected:\n%s", p, expected)
+	}
+}
+
+func TestHashEqual(t *testing.T) {
+	a := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0}},
+			{ID: 1, Name: "snare", Steps: []byte{0, 1}},
+		},
+	}
+	b := &Pattern{
+		Version: " V0.808-Alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 1, Name: "snare ", Steps: []byte{0, 1}},
+			{ID: 0, Name: "kick", Steps: []byte{1, 0}},
+		},
+	}
+
+	if !a.Equal(b) || a.Hash() != b.Hash() {
+		t.Fatal("expected patterns differing only in formatting to be equal")
+	}
+	if b.Version != " V0.808-Alpha" || b.Tracks[0].ID != 1 {
+		t.Fatal("comparing changed the pattern")
+	}
+
+	b.SetTempoChange(1, 140)
+	if a.Equal(b) || a.Hash() == b.Hash() {
+		t.Fatal("expected patterns with different tempo changes to differ")
+	}
+}
@@ -0,0 +1,180 @@
+package drum
+
+import (
+	"math"
+	"math/rand"
+)
+
+// maxClusterIterations limits iterations of k-means, which usually
+// converges much sooner.
+const maxClusterIterations = 100
+
+// PatternCluster is a group of similar patterns found by Cluster.
+type PatternCluster struct {
+	// Members are indexes of the clustered patterns
+	Members []int `json:"members"`
+	// Exemplar is the index of the member closest to the cluster's center,
+	// representing the cluster
+	Exemplar int `json:"exemplar"`
+	// Centroid is the mean of standardized features of members,
+	// see FeatureNames
+	Centroid []float64 `json:"centroid"`
+}
+
+// Cluster groups the patterns into at most k clusters of similar ones
+// with k-means over their features, see Featurize. Features are
+// standardized first, so all of them weigh the same. The seed makes the
+// initial choice of clusters reproducible. Empty clusters are dropped
+// and clusters are ordered by their first member.
+func Cluster(patterns []*Pattern, k int, seed int64) []PatternCluster {
+	for _, p := range patterns {
+		p.ensureTracks()
+	}
+
+	k = min(k, len(patterns))
+	if k <= 0 {
+		return nil
+	}
+
+	points := FeaturizeAll(patterns, 0)
+	standardize(points)
+
+	centroids := initCentroids(points, k, rand.New(rand.NewSource(seed)))
+	assignments := make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+
+	for iteration := 0; iteration < maxClusterIterations; iteration++ {
+		changed := false
+		for i, point := range points {
+			nearest := nearestCentroid(point, centroids)
+			if nearest != assignments[i] {
+				assignments[i] = nearest
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		for c := range centroids {
+			var members [][]float64
+			for i, point := range points {
+				if assignments[i] == c {
+					members = append(members, point)
+				}
+			}
+			if len(members) > 0 {
+				centroids[c] = featureMean(members)
+			}
+		}
+	}
+
+	var clusters []PatternCluster
+	index := map[int]int{}
+	for i, c := range assignments {
+		if _, ok := index[c]; !ok {
+			index[c] = len(clusters)
+			clusters = append(clusters, PatternCluster{Exemplar: i, Centroid: centroids[c]})
+		}
+
+		cluster := &clusters[index[c]]
+		cluster.Members = append(cluster.Members, i)
+		if featureDistance(points[i], centroids[c]) < featureDistance(points[cluster.Exemplar], centroids[c]) {
+			cluster.Exemplar = i
+		}
+	}
+
+	return clusters
+}
+
+// standardize scales features of points to zero mean and unit variance.
+// Features without variance become zeros.
+func standardize(points [][]float64) {
+	if len(points) == 0 {
+		return
+	}
+
+	center := featureMean(points)
+	for f := range center {
+		variance := 0.0
+		for _, point := range points {
+			variance += (point[f] - center[f]) * (point[f] - center[f]) / float64(len(points))
+		}
+		deviation := math.Sqrt(variance)
+
+		for _, point := range points {
+			point[f] -= center[f]
+			if deviation > 0 {
+				point[f] /= deviation
+			}
+		}
+	}
+}
+
+// initCentroids picks k initial centroids among points with k-means++,
+// preferring points far from those already picked.
+func initCentroids(points [][]float64, k int, r *rand.Rand) [][]float64 {
+	centroids := [][]float64{append([]float64(nil), points[r.Intn(len(points))]...)}
+
+	for len(centroids) < k {
+		weights := make([]float64, len(points))
+		total := 0.0
+		for i, point := range points {
+			d := featureDistance(point, centroids[nearestCentroid(point, centroids)])
+			weights[i] = d * d
+			total += weights[i]
+		}
+
+		next := r.Intn(len(points))
+		if total > 0 {
+			x := r.Float64() * total
+			for i, weight := range weights {
+				next = i
+				x -= weight
+				if x < 0 && weight > 0 {
+					break
+				}
+			}
+		}
+
+		centroids = append(centroids, append([]float64(nil), points[next]...))
+	}
+
+	return centroids
+}
+
+// nearestCentroid returns the index of the centroid nearest to the point.
+func nearestCentroid(point []float64, centroids [][]float64) int {
+	nearest := 0
+	for c := range centroids {
+		if featureDistance(point, centroids[c]) < featureDistance(point, centroids[nearest]) {
+			nearest = c
+		}
+	}
+
+	return nearest
+}
+
+// featureDistance returns the Euclidean distance between points.
+func featureDistance(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+
+	return math.Sqrt(sum)
+}
+
+// featureMean returns the mean of points.
+func featureMean(points [][]float64) []float64 {
+	m := make([]float64, len(points[0]))
+	for _, point := range points {
+		for i, value := range point {
+			m[i] += value / float64(len(points))
+		}
+	}
+
+	return m
+}
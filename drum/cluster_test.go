@@ -0,0 +1,49 @@
+package drum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCluster(t *testing.T) {
+	var patterns []*Pattern
+	for _, steps := range []string{
+		"x---x---x---x---",
+		"x---x---x---x-x-",
+		"x---x---x-x-x---",
+		"xxxxxxxxxxxxxxxx",
+		"xxxxxxxxxxxxxxx-",
+		"xxxxxxx-xxxxxxxx",
+	} {
+		patterns = append(patterns, &Pattern{Tempo: 120, Tracks: []Track{
+			{ID: 0, Name: "hihat", Steps: stepsFromString(steps)},
+		}})
+	}
+
+	clusters := Cluster(patterns, 2, 1)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+
+	if !reflect.DeepEqual(clusters[0].Members, []int{0, 1, 2}) || !reflect.DeepEqual(clusters[1].Members, []int{3, 4, 5}) {
+		t.Errorf("unexpected clusters %v and %v", clusters[0].Members, clusters[1].Members)
+	}
+	for _, c := range clusters {
+		if len(c.Centroid) != len(FeatureNames) {
+			t.Errorf("expected %d values of centroid, got %d", len(FeatureNames), len(c.Centroid))
+		}
+		if c.Exemplar < c.Members[0] || c.Exemplar > c.Members[len(c.Members)-1] {
+			t.Errorf("exemplar %d isn't a member of %v", c.Exemplar, c.Members)
+		}
+	}
+
+	if !reflect.DeepEqual(Cluster(patterns, 2, 1), clusters) {
+		t.Errorf("clusters with the same seed differ")
+	}
+	if clusters := Cluster(patterns, 10, 1); len(clusters) > len(patterns) {
+		t.Errorf("expected at most %d clusters, got %d", len(patterns), len(clusters))
+	}
+	if clusters := Cluster(nil, 3, 1); clusters != nil {
+		t.Errorf("expected no clusters, got %v", clusters)
+	}
+}
@@ -0,0 +1,197 @@
+package drum
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// corpusTempoBucket is the width of tempo histogram buckets, in BPM.
+	corpusTempoBucket = 10
+	// corpusTopNames is the number of track names listed in corpus reports.
+	corpusTopNames = 20
+)
+
+//go:embed corpus.html
+var corpusHTML string
+
+var corpusHTMLTemplate = template.Must(template.New("corpus.html").Parse(corpusHTML))
+
+// CorpusReport holds statistics of a collection of patterns.
+type CorpusReport struct {
+	Patterns int `json:"patterns"`
+	Tracks   int `json:"tracks"`
+	// Tempos is the histogram of tempos, in buckets of 10 BPM
+	Tempos []TempoBucket `json:"tempos"`
+	// TrackNames are the most common track names, lowercased, counted
+	// once per pattern
+	TrackNames []NameCount `json:"trackNames"`
+	// Versions counts patterns by their hardware version
+	Versions []NameCount `json:"versions"`
+	// AverageDensity is the mean density of patterns, see Pattern.Density
+	AverageDensity float64 `json:"averageDensity"`
+}
+
+// TempoBucket is a bucket of a tempo histogram, counting patterns with
+// tempo from Min up to, but not including, Max.
+type TempoBucket struct {
+	Min   BPM `json:"min"`
+	Max   BPM `json:"max"`
+	Count int `json:"count"`
+}
+
+// NameCount is the number of occurrences of a name.
+type NameCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// AnalyzeCorpus returns statistics of the patterns, e.g. of a whole
+// archive of files. Nil patterns are skipped.
+func AnalyzeCorpus(patterns []*Pattern) CorpusReport {
+	for _, p := range patterns {
+		p.ensureTracks()
+	}
+
+	var r CorpusReport
+
+	tempos := map[int]int{}
+	names := map[string]int{}
+	versions := map[string]int{}
+	density := 0.0
+
+	for _, p := range patterns {
+		if p == nil {
+			continue
+		}
+
+		r.Patterns++
+		r.Tracks += len(p.Tracks)
+		tempos[int(math.Floor(float64(p.Tempo)/corpusTempoBucket))]++
+		versions[p.Version]++
+		density += p.Density()
+
+		seen := map[string]bool{}
+		for _, track := range p.Tracks {
+			name := strings.ToLower(strings.TrimSpace(track.Name))
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names[name]++
+			}
+		}
+	}
+
+	if r.Patterns > 0 {
+		r.AverageDensity = density / float64(r.Patterns)
+	}
+
+	for bucket, count := range tempos {
+		r.Tempos = append(r.Tempos, TempoBucket{
+			Min:   BPM(bucket * corpusTempoBucket),
+			Max:   BPM((bucket + 1) * corpusTempoBucket),
+			Count: count,
+		})
+	}
+	sort.Slice(r.Tempos, func(i, j int) bool {
+		return r.Tempos[i].Min < r.Tempos[j].Min
+	})
+
+	r.TrackNames = sortedCounts(names)
+	if len(r.TrackNames) > corpusTopNames {
+		r.TrackNames = r.TrackNames[:corpusTopNames]
+	}
+	r.Versions = sortedCounts(versions)
+
+	return r
+}
+
+// sortedCounts returns the counts sorted from the most common name,
+// then by name.
+func sortedCounts(counts map[string]int) []NameCount {
+	sorted := make([]NameCount, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, NameCount{name, count})
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
+
+// WriteText writes the report as aligned plain text.
+func (r CorpusReport) WriteText(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(table, "Patterns:\t%d\n", r.Patterns)
+	fmt.Fprintf(table, "Tracks:\t%d\n", r.Tracks)
+	fmt.Fprintf(table, "Average density:\t%.2f\n", r.AverageDensity)
+
+	fmt.Fprintf(table, "\nTempo\tPatterns\n")
+	for _, b := range r.Tempos {
+		fmt.Fprintf(table, "%v-%v\t%d\t%s\n", b.Min, b.Max, b.Count, r.bar(b.Count))
+	}
+
+	fmt.Fprintf(table, "\nTrack name\tPatterns\n")
+	for _, n := range r.TrackNames {
+		fmt.Fprintf(table, "%s\t%d\n", n.Name, n.Count)
+	}
+
+	fmt.Fprintf(table, "\nVersion\tPatterns\n")
+	for _, v := range r.Versions {
+		fmt.Fprintf(table, "%s\t%d\n", v.Name, v.Count)
+	}
+
+	return table.Flush()
+}
+
+// bar returns a histogram bar of the count, relative to all patterns.
+func (r CorpusReport) bar(count int) string {
+	return strings.Repeat("#", int(math.Ceil(40*float64(count)/float64(r.Patterns))))
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r CorpusReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+// WriteHTML writes the report as a standalone HTML page, with the tempo
+// histogram drawn as bars.
+func (r CorpusReport) WriteHTML(w io.Writer, opts ...ExportOption) error {
+	config := newExportConfig(opts)
+
+	type bar struct {
+		TempoBucket
+		// Width of the bar, in percent of the widest one
+		Width float64
+	}
+
+	most := 0
+	for _, b := range r.Tempos {
+		most = max(most, b.Count)
+	}
+	bars := make([]bar, len(r.Tempos))
+	for i, b := range r.Tempos {
+		bars[i] = bar{b, 100 * float64(b.Count) / float64(most)}
+	}
+
+	return corpusHTMLTemplate.Execute(w, struct {
+		Report CorpusReport
+		Tempos []bar
+		Theme  Theme
+	}{r, bars, config.theme})
+}
@@ -0,0 +1,57 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeCorpus(t *testing.T) {
+	var patterns []*Pattern
+	for _, exp := range tData {
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		patterns = append(patterns, decoded)
+	}
+
+	r := AnalyzeCorpus(append(patterns, nil))
+
+	if r.Patterns != 5 {
+		t.Errorf("expected 5 patterns, got %d", r.Patterns)
+	}
+
+	expected := []TempoBucket{{90, 100, 1}, {110, 120, 1}, {120, 130, 1}, {240, 250, 1}, {990, 1000, 1}}
+	if !reflect.DeepEqual(r.Tempos, expected) {
+		t.Errorf("expected tempos %v, got %v", expected, r.Tempos)
+	}
+	if !reflect.DeepEqual(r.TrackNames[0], NameCount{"kick", 5}) {
+		t.Errorf("expected kick to be the most common name, got %v", r.TrackNames[0])
+	}
+	if !reflect.DeepEqual(r.Versions[0], NameCount{"0.808-alpha", 3}) {
+		t.Errorf("unexpected versions %v", r.Versions)
+	}
+	if r.AverageDensity <= 0 || r.AverageDensity >= 1 {
+		t.Errorf("unexpected average density %v", r.AverageDensity)
+	}
+
+	var text, html bytes.Buffer
+	if err := r.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Patterns:         5\n", "990-1000  1  ########\n", "kick        5\n"} {
+		if !strings.Contains(text.String(), expected) {
+			t.Errorf("text report doesn't contain %q:\n%s", expected, text.String())
+		}
+	}
+	if !strings.Contains(html.String(), `<tr><td>kick</td><td>5</td></tr>`) {
+		t.Errorf("HTML report doesn't list track names:\n%s", html.String())
+	}
+}
@@ -0,0 +1,144 @@
+package drum
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ExportCSV writes the pattern as comma-separated values: version and
+// tempo rows followed by a header row and a row per track, with a column
+// per step.
+func ExportCSV(w io.Writer, p *Pattern) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	return exportDelimited(w, p, ',')
+}
+
+// ExportTSV writes the pattern like ExportCSV, but separated with tabs.
+func ExportTSV(w io.Writer, p *Pattern) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	return exportDelimited(w, p, '\t')
+}
+
+// ImportCSV reads a pattern written by ExportCSV.
+func ImportCSV(r io.Reader) (*Pattern, error) {
+	return importDelimited(r, ',')
+}
+
+// ImportTSV reads a pattern written by ExportTSV.
+func ImportTSV(r io.Reader) (*Pattern, error) {
+	return importDelimited(r, '\t')
+}
+
+func exportDelimited(w io.Writer, p *Pattern, comma rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	steps := 0
+	for _, track := range p.Tracks {
+		if track.Len() > steps {
+			steps = track.Len()
+		}
+	}
+
+	header := []string{"id", "name"}
+	for i := 1; i <= steps; i++ {
+		header = append(header, strconv.Itoa(i))
+	}
+
+	writer.Write([]string{"version", p.Version})
+	writer.Write([]string{"tempo", formatTempo(p.Tempo)})
+	writer.Write(header)
+
+	for _, track := range p.Tracks {
+		record := []string{strconv.Itoa(int(track.ID)), track.Name}
+		for _, step := range track.ShiftedSteps() {
+			record = append(record, strconv.Itoa(int(step)))
+		}
+
+		writer.Write(record)
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func importDelimited(r io.Reader, comma rune) (*Pattern, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pattern{}
+
+	for i, record := range records {
+		line := i + 1
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected at least 2 fields, got %d", line, len(record))
+		}
+
+		switch record[0] {
+		case "version":
+			p.Version = record[1]
+		case "tempo":
+			tempo, err := strconv.ParseFloat(record[1], 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid tempo %q", line, record[1])
+			}
+			p.Tempo = float32(tempo)
+		case "id":
+			// Header row
+		default:
+			track, err := parseTrackRecord(record)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			p.Tracks = append(p.Tracks, track)
+		}
+	}
+
+	return p, nil
+}
+
+// parseTrackRecord parses a track from its id, name and step fields.
+// Steps may be written as 1/0, x/- or left empty, flams as 2 or f.
+func parseTrackRecord(record []string) (Track, error) {
+	id, err := strconv.ParseUint(record[0], 10, 8)
+	if err != nil {
+		return Track{}, fmt.Errorf("invalid track ID %q", record[0])
+	}
+
+	track := Track{
+		ID:    byte(id),
+		Name:  record[1],
+		Steps: make([]byte, len(record)-2),
+	}
+
+	for i, field := range record[2:] {
+		switch field {
+		case "1", "x", "X":
+			track.Steps[i] = StepOn
+		case "2", "f", "F":
+			track.Steps[i] = StepFlam
+		case "0", "-", "":
+			track.Steps[i] = StepOff
+		default:
+			return Track{}, fmt.Errorf("invalid step %d value %q in track %q", i+1, field, track.Name)
+		}
+	}
+
+	return track, nil
+}
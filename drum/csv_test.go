@@ -0,0 +1,74 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	for _, exp := range tData {
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, format := range []struct {
+			export func(*bytes.Buffer, *Pattern) error
+			imp    func(*bytes.Buffer) (*Pattern, error)
+		}{
+			{
+				func(b *bytes.Buffer, p *Pattern) error { return ExportCSV(b, p) },
+				func(b *bytes.Buffer) (*Pattern, error) { return ImportCSV(b) },
+			},
+			{
+				func(b *bytes.Buffer, p *Pattern) error { return ExportTSV(b, p) },
+				func(b *bytes.Buffer) (*Pattern, error) { return ImportTSV(b) },
+			},
+		} {
+			var buf bytes.Buffer
+			if err := format.export(&buf, decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			imported, err := format.imp(&buf)
+			if err != nil {
+				t.Fatalf("something went wrong importing %s - %v", exp.path, err)
+			}
+
+			if fmt.Sprint(imported) != exp.output {
+				t.Fatalf("%s wasn't imported as expected.\nGot:\n%s\nExpected:\n%s",
+					exp.path, imported, exp.output)
+			}
+		}
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	input := `version,0.909
+tempo,98.4
+id,name,1,2,3,4
+7,"kick, hard",x,-,,1
+`
+	imported, err := ImportCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: 0.909
+Tempo: 98.4
+(7) kick, hard	|x--x|
+`
+	if imported.String() != expected {
+		t.Fatalf("wrong import.\nGot:\n%s\nExpected:\n%s", imported, expected)
+	}
+
+	if _, err := ImportCSV(strings.NewReader("1,kick,1,3\n")); err == nil {
+		t.Error("expected error for invalid step value")
+	}
+	if _, err := ImportCSV(strings.NewReader("tempo,fast\n")); err == nil {
+		t.Error("expected error for invalid tempo")
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
+	"time"
 )
 
 const (
@@ -18,6 +20,8 @@ const (
 	spliceHeader = "SPLICE"
 )
 
+var errInvalidHeader = errors.New("invalid header")
+
 // Pattern is the high level representation of the
 // drum pattern contained in a .splice file.
 type Pattern struct {
@@ -25,28 +29,172 @@ type Pattern struct {
 	Tempo   float32
 	Tracks  []Track
 
+	// TempoChanges, ordered by step, override Tempo from their steps on.
+	// They're stored in the metadata sidecar.
+	TempoChanges []TempoChange
+	// Signature proves authorship of the pattern, see Sign. It's stored
+	// in the metadata sidecar.
+	Signature *Signature
+
 	lastErr error
-	buffer  io.ReadSeeker
+	// Recoverable problems found while decoding WithAllErrors
+	problems      []error
+	buffer        io.ReadSeeker
+	config        decodeConfig
+	velocityCurve Curve
+	// Name of the active scene, empty for DefaultScene
+	scene string
+	// Tracks left to parse by LoadTracks
+	pending *pendingTracks
+
+	// Reused by patterns decoded from a DecoderPool
+	reader  bytes.Reader
+	scratch []byte
+	names   map[string]string
+	number  [8]byte
 }
 
 // Track is the representation of a single track in the pattern.
 type Track struct {
 	ID    byte
 	Name  string
-	Steps [trackSteps]byte
+	Steps []byte
+
+	// Offset shifts the track by whole steps when it's played or exported,
+	// without changing its steps. Positive values delay the track.
+	Offset int
+	// Display holds presentation hints, stored in the metadata sidecar
+	Display Display
+	// Notes are comments attached to steps, stored in the metadata sidecar
+	Notes []Note
+	// Automation holds lanes of sound parameters set per step, stored
+	// in the metadata sidecar
+	Automation []Lane
+	// Tags group tracks, see Tag. They're stored in the metadata sidecar.
+	Tags []string
+	// Muted tracks have zero velocity, so they aren't played and have no
+	// Events, but keep their steps in encodings and step-based exports.
+	// It's stored in the metadata sidecar.
+	Muted bool
+	// Scenes hold steps of scenes other than the active one, see
+	// Pattern.SetScene. They're stored in the metadata sidecar.
+	Scenes map[string][]byte
+
+	velocityScale float64
+	// Name as stored in the decoded file, if transliterated
+	rawName string
+
+	// Raw steps as stored in the decoded file, if any of them is invalid
+	rawSteps []byte
+
+	// Steps packed into bits, see Pack
+	packed    []byte
+	packedLen int
+}
+
+// Clone returns a deep copy of the pattern's data.
+func (p *Pattern) Clone() *Pattern {
+	p.ensureTracks()
+
+	c := &Pattern{
+		Version:       p.Version,
+		Tempo:         p.Tempo,
+		Tracks:        make([]Track, len(p.Tracks)),
+		TempoChanges:  append([]TempoChange(nil), p.TempoChanges...),
+		velocityCurve: p.velocityCurve,
+		scene:         p.scene,
+		config:        p.config,
+	}
+
+	if p.Signature != nil {
+		signature := *p.Signature
+		c.Signature = &signature
+	}
+
+	if p.pending != nil {
+		pending := *p.pending
+		pending.metadata = append([]Metadata(nil), p.pending.metadata...)
+		c.pending = &pending
+	}
+
+	for i, track := range p.Tracks {
+		c.Tracks[i] = track
+		c.Tracks[i].Steps = append([]byte(nil), track.Steps...)
+		if track.IsPacked() {
+			c.Tracks[i].packed = append([]byte(nil), track.packed...)
+		}
+		c.Tracks[i].Notes = append([]Note(nil), track.Notes...)
+		c.Tracks[i].Automation = cloneAutomation(track.Automation)
+		c.Tracks[i].Tags = append([]string(nil), track.Tags...)
+		c.Tracks[i].Scenes = cloneScenes(track.Scenes)
+		if track.rawSteps != nil {
+			c.Tracks[i].rawSteps = append([]byte(nil), track.rawSteps...)
+		}
+		if track.packed != nil {
+			c.Tracks[i].packed = append([]byte(nil), track.packed...)
+		}
+	}
+
+	return c
 }
 
 // DecodeFile decodes the drum machine file found at the provided path
 // and returns a pointer to a parsed pattern which is the entry point to the
-// rest of the data.
-func DecodeFile(path string) (*Pattern, error) {
+// rest of the data. Metadata is read from the file's sidecar, if present.
+func DecodeFile(path string, opts ...Option) (*Pattern, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
+	p, err := decode(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.readSidecar(ioutil.ReadFile, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Decode decodes the drum machine file read from r.
+func Decode(r io.Reader, opts ...Option) (*Pattern, error) {
+	var config decodeConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.progress != nil {
+		r = &progressReader{r: r, progress: config.progress}
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(data, opts)
+}
+
+// decode returns a pattern parsed from data with provided options.
+func decode(data []byte, opts []Option) (*Pattern, error) {
 	p := &Pattern{}
-	err = p.UnmarshalBinary(data)
+	for _, opt := range opts {
+		opt(&p.config)
+	}
+
+	if IsEncrypted(data) {
+		var err error
+		data, err = decrypt(data, p.config.passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := p.UnmarshalBinary(data)
 	if err != nil {
 		return nil, err
 	}
@@ -56,23 +204,76 @@ func DecodeFile(path string) (*Pattern, error) {
 
 // UnmarshalBinary loads pattern attributes from data.
 func (p *Pattern) UnmarshalBinary(data []byte) error {
-	p.buffer = bytes.NewReader(data)
+	defer func(start time.Time) {
+		decodeMetrics.Load().observe(start, p.lastErr)
+	}(time.Now())
+
+	p.reader.Reset(data)
+	p.buffer = &p.reader
+	p.problems = nil
 
 	p.checkHeader()
 
 	length := p.readLength()
 	maxOffset := p.currentOffset() + length
+	p.debug("header parsed", "length", length)
 
 	p.readVersion()
 	p.readTempo()
+	p.debug("version and tempo parsed", "version", p.Version, "tempo", p.Tempo)
+	p.reportProgress(uint64(len(data)))
+
+	if p.lastErr == nil {
+		offset := p.currentOffset()
+		count := scanTrackCount(data, offset, maxOffset, p.config.stepWidthOrDefault())
+		p.debug("tracks counted", "count", count)
+
+		if p.config.lazy {
+			p.pending = &pendingTracks{
+				data:   data,
+				offset: offset,
+				end:    maxOffset,
+				count:  count,
+				config: p.config,
+			}
+		} else {
+			p.preallocateTracks(count)
+			p.readTracks(maxOffset, uint64(len(data)))
+		}
+	}
 
-	for p.currentOffset() < maxOffset {
-		p.readTrack()
+	if p.config.strict && p.lastErr == nil && maxOffset < uint64(len(data)) {
+		p.problem(fmt.Errorf("%d bytes of trailing data", uint64(len(data))-maxOffset))
+	}
+	p.joinProblems()
+
+	if p.lastErr != nil {
+		p.debug("decoding failed", "error", p.lastErr)
 	}
 
 	return p.lastErr
 }
 
+// readTracks reads tracks from internal buffer up to maxOffset,
+// reporting progress against total bytes.
+func (p *Pattern) readTracks(maxOffset, total uint64) {
+	for p.lastErr == nil {
+		offset := p.currentOffset()
+		if offset >= maxOffset {
+			break
+		}
+
+		p.checkTrackCount()
+		p.readTrack()
+
+		if p.lastErr == nil {
+			track := p.Tracks[len(p.Tracks)-1]
+			p.debug("track parsed", "offset", offset, "id", track.ID, "name", track.Name)
+			p.reportProgress(total)
+		}
+	}
+}
+
 // currentOffset returns current offset of internal buffer.
 func (p *Pattern) currentOffset() uint64 {
 	if p.lastErr != nil {
@@ -89,6 +290,33 @@ func (p *Pattern) currentOffset() uint64 {
 
 // read reads binary data from internal buffer into data.
 func (p *Pattern) read(data interface{}) {
+	// Common types are read without binary.Read's allocations
+	switch d := data.(type) {
+	case []byte:
+		p.readFull(d)
+		return
+	case *byte:
+		if b := p.readFull(p.number[:1]); b != nil {
+			*d = b[0]
+		}
+		return
+	case *uint32:
+		if b := p.readFull(p.number[:4]); b != nil {
+			*d = binary.BigEndian.Uint32(b)
+		}
+		return
+	case *uint64:
+		if b := p.readFull(p.number[:8]); b != nil {
+			*d = binary.BigEndian.Uint64(b)
+		}
+		return
+	case *float32:
+		if b := p.readFull(p.number[:4]); b != nil {
+			*d = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		}
+		return
+	}
+
 	var order binary.ByteOrder
 
 	switch data.(type) {
@@ -104,17 +332,29 @@ func (p *Pattern) read(data interface{}) {
 	}
 }
 
+// readFull reads len(b) bytes from internal buffer into b and returns it,
+// or nil on error.
+func (p *Pattern) readFull(b []byte) []byte {
+	_, err := io.ReadFull(p.buffer, b)
+	if err != nil {
+		p.lastErr = err
+		return nil
+	}
+
+	return b
+}
+
 // checkHeader reads header from internal buffer and checks if it is correct.
 func (p *Pattern) checkHeader() {
 	if p.lastErr != nil {
 		return
 	}
 
-	header := make([]byte, headerLength)
+	header := p.scratchBytes(headerLength)
 	p.read(header)
 
 	if !bytes.Equal(header, []byte(spliceHeader)) {
-		p.lastErr = errors.New("invalid header")
+		p.lastErr = errInvalidHeader
 	}
 }
 
@@ -136,12 +376,21 @@ func (p *Pattern) readVersion() {
 		return
 	}
 
-	version := make([]byte, versionMaxLength)
+	version := p.scratchBytes(versionMaxLength)
 	p.read(version)
 
 	// Save version up to null byte
-	n := bytes.Index(version, []byte{0})
-	p.Version = string(version[:n])
+	n := bytes.IndexByte(version, 0)
+	if n < 0 {
+		if p.config.strict {
+			p.problem(errors.New("unterminated version"))
+			if p.lastErr != nil {
+				return
+			}
+		}
+		n = len(version)
+	}
+	p.Version = p.intern(version[:n])
 }
 
 // readTempo reads pattern tempo from internal buffer.
@@ -166,43 +415,117 @@ func (p *Pattern) readTrack() {
 	// Name's length
 	var length uint32
 	p.read(&length)
+	if p.lastErr != nil {
+		return
+	}
+
+	if remaining := p.reader.Len(); uint64(length) > uint64(remaining) {
+		p.lastErr = fmt.Errorf("name of track %d is %d bytes long, more than the remaining %d bytes", len(p.Tracks), length, remaining)
+		return
+	}
 
 	// Track's name
-	name := make([]byte, length)
+	name := p.scratchBytes(int(length))
 	p.read(name)
-	track.Name = string(name)
+	track.Name = p.intern(name)
 
-	// Track's steps
-	steps := make([]byte, trackSteps)
-	p.read(steps)
-	copy(track.Steps[:], steps)
+	if p.config.strict && p.lastErr == nil {
+		if err := ValidateName(track.Name); err != nil {
+			p.problem(fmt.Errorf("invalid name %q of track %d - %v", track.Name, len(p.Tracks), err))
+			if p.lastErr != nil {
+				return
+			}
+		}
+	}
+	if p.config.transliterate {
+		track.transliterateName()
+	}
+
+	// Track's steps, reusing those of a reset pattern
+	track.Steps = p.reusedSteps(p.config.stepWidthOrDefault())
+	p.read(track.Steps)
+
+	track.rawSteps = nil
+	for i, step := range track.Steps {
+		if step <= StepOn {
+			continue
+		}
+
+		if track.rawSteps == nil {
+			track.rawSteps = append([]byte(nil), track.Steps...)
+		}
+		if p.config.strict && p.lastErr == nil {
+			p.problem(fmt.Errorf("invalid value %d of step %d in track %q", step, i, track.Name))
+			if p.lastErr != nil {
+				return
+			}
+		}
+	}
+	decodedSteps(track.Steps)
+
+	if p.config.packed {
+		track.Pack()
+	}
 
 	p.Tracks = append(p.Tracks, track)
 }
 
+// String returns the pattern rendered with default options.
 func (p *Pattern) String() string {
-	var buffer bytes.Buffer
+	return p.Render(RenderOptions{})
+}
+
+// scratchBytes returns a temporary buffer of n bytes, valid until the
+// next call.
+func (p *Pattern) scratchBytes(n int) []byte {
+	if cap(p.scratch) < n {
+		p.scratch = make([]byte, n)
+	}
 
-	buffer.WriteString(fmt.Sprintf("Saved with HW Version: %s\n", p.Version))
-	buffer.WriteString(fmt.Sprintf("Tempo: %v\n", p.Tempo))
+	return p.scratch[:n]
+}
 
-	for _, track := range p.Tracks {
-		buffer.WriteString(fmt.Sprintf("(%d) %s\t", track.ID, track.Name))
+// intern returns b as a string, reusing strings of previous decodes
+// of a pooled pattern.
+func (p *Pattern) intern(b []byte) string {
+	if p.names == nil {
+		return string(b)
+	}
 
-		for i, step := range track.Steps {
-			if i%4 == 0 {
-				buffer.WriteString("|")
-			}
+	if s, ok := p.names[string(b)]; ok {
+		return s
+	}
 
-			if step == 1 {
-				buffer.WriteString("x")
-			} else {
-				buffer.WriteString("-")
-			}
-		}
+	s := string(b)
+	if len(p.names) < maxInternedNames {
+		p.names[s] = s
+	}
 
-		buffer.WriteString("|\n")
+	return s
+}
+
+// maxInternedNames limits the number of strings kept by a pooled pattern.
+const maxInternedNames = 256
+
+// reusedSteps returns n steps for the next track, reusing steps of
+// a track at the same index before the pattern was reset.
+func (p *Pattern) reusedSteps(n int) []byte {
+	if i := len(p.Tracks); i < cap(p.Tracks) {
+		if steps := p.Tracks[:i+1][i].Steps; cap(steps) >= n {
+			return steps[:n]
+		}
 	}
 
-	return buffer.String()
+	return make([]byte, n)
+}
+
+// Reset clears the pattern, keeping its allocated memory for reuse by
+// the next decode into it. Tracks and steps of the pattern mustn't be
+// used anymore.
+func (p *Pattern) Reset() {
+	*p = Pattern{
+		Tracks:  p.Tracks[:0],
+		scratch: p.scratch,
+		names:   p.names,
+	}
 }
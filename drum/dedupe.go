@@ -0,0 +1,126 @@
+package drum
+
+import (
+	"encoding/hex"
+	"math"
+	"strings"
+)
+
+// Duplicate is a pair of duplicate patterns found by FindDuplicates.
+type Duplicate struct {
+	// A and B are indexes of the patterns, A being the lower one
+	A int `json:"a"`
+	B int `json:"b"`
+	// Exact is true if the patterns have the same ContentHash
+	Exact      bool    `json:"exact"`
+	Similarity float64 `json:"similarity"`
+}
+
+// ContentHash returns the hex encoded Hash of the pattern.
+func (p *Pattern) ContentHash() string {
+	sum := p.Hash()
+	return hex.EncodeToString(sum[:])
+}
+
+// Similarity returns the similarity of patterns from 0 to 1: the Jaccard
+// similarity of their hits, matching tracks by name case-insensitively,
+// scaled by the ratio of the slower tempo to the faster one. Offsets of
+// tracks are applied. Patterns without hits are equal, as far as hits go.
+func Similarity(a, b *Pattern) float64 {
+	a.ensureTracks()
+	b.ensureTracks()
+
+	return hitSimilarity(similarityHits(a), similarityHits(b)) * tempoRatio(a.Tempo, b.Tempo)
+}
+
+// hitSimilarity returns the Jaccard similarity of sets of hits.
+func hitSimilarity(a, b map[similarityHit]bool) float64 {
+	shared := 0
+	for hit := range a {
+		if b[hit] {
+			shared++
+		}
+	}
+
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 1
+	}
+
+	return float64(shared) / float64(union)
+}
+
+// similarityHit is a hit of a track with a name.
+type similarityHit struct {
+	name string
+	step int
+}
+
+// similarityHits returns hits of the pattern keyed by lowercased names
+// of tracks.
+func similarityHits(p *Pattern) map[similarityHit]bool {
+	hits := map[similarityHit]bool{}
+	for _, track := range p.Tracks {
+		name := strings.ToLower(strings.TrimSpace(track.Name))
+		for i, step := range track.ShiftedSteps() {
+			if isHit(step) {
+				hits[similarityHit{name, i}] = true
+			}
+		}
+	}
+
+	return hits
+}
+
+// tempoRatio returns the ratio of the slower tempo to the faster one,
+// or 1 if both are equal.
+func tempoRatio(a, b float32) float64 {
+	if a == b {
+		return 1
+	}
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+
+	return math.Min(float64(a), float64(b)) / math.Max(float64(a), float64(b))
+}
+
+// FindDuplicates returns pairs of patterns with the same ContentHash, or
+// a Similarity of at least the threshold, ordered by indexes of patterns.
+// A threshold above 1 finds only exact duplicates.
+func FindDuplicates(patterns []*Pattern, threshold float64) []Duplicate {
+	for _, p := range patterns {
+		p.ensureTracks()
+	}
+
+	hashes := make([]string, len(patterns))
+	hits := make([]map[similarityHit]bool, len(patterns))
+	for i, p := range patterns {
+		hashes[i] = p.ContentHash()
+		hits[i] = similarityHits(p)
+	}
+
+	var duplicates []Duplicate
+	for a := range patterns {
+		for b := a + 1; b < len(patterns); b++ {
+			if hashes[a] == hashes[b] {
+				duplicates = append(duplicates, Duplicate{A: a, B: b, Exact: true, Similarity: 1})
+				continue
+			}
+
+			// Jaccard similarity can't exceed the ratio of numbers of hits,
+			// which rules out most pairs without comparing them
+			lo, hi := min(len(hits[a]), len(hits[b])), max(len(hits[a]), len(hits[b]))
+			if hi > 0 && float64(lo)/float64(hi) < threshold {
+				continue
+			}
+
+			similarity := hitSimilarity(hits[a], hits[b]) * tempoRatio(patterns[a].Tempo, patterns[b].Tempo)
+			if similarity >= threshold {
+				duplicates = append(duplicates, Duplicate{A: a, B: b, Similarity: similarity})
+			}
+		}
+	}
+
+	return duplicates
+}
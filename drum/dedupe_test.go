@@ -0,0 +1,48 @@
+package drum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	base := &Pattern{Version: "0.808", Tempo: 120, Tracks: []Track{
+		{ID: 0, Name: "kick", Steps: stepsFromString("x---x---x---x---")},
+		{ID: 1, Name: "snare", Steps: stepsFromString("----x-------x---")},
+	}}
+
+	// Same content, with tracks in another order and a padded name
+	reordered := base.Clone()
+	reordered.Tracks[0], reordered.Tracks[1] = reordered.Tracks[1], reordered.Tracks[0]
+	reordered.Tracks[0].Name = " snare "
+
+	near := base.Clone()
+	near.Tracks[0].Steps[14] = StepOn
+
+	other := &Pattern{Version: "0.808", Tempo: 120, Tracks: []Track{
+		{ID: 0, Name: "hihat", Steps: stepsFromString("x-x-x-x-x-x-x-x-")},
+	}}
+
+	if base.ContentHash() != reordered.ContentHash() {
+		t.Errorf("expected hashes of reordered patterns to be equal")
+	}
+	if base.ContentHash() == near.ContentHash() {
+		t.Errorf("expected hashes of different patterns to differ")
+	}
+
+	duplicates := FindDuplicates([]*Pattern{base, other, reordered, near}, 0.8)
+	expected := []Duplicate{
+		{A: 0, B: 2, Exact: true, Similarity: 1},
+		{A: 0, B: 3, Similarity: 6.0 / 7},
+		{A: 2, B: 3, Similarity: 6.0 / 7},
+	}
+	if !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("expected duplicates %v, got %v", expected, duplicates)
+	}
+
+	slower := base.Clone()
+	slower.Tempo = 60
+	if similarity := Similarity(base, slower); similarity != 0.5 {
+		t.Errorf("expected similarity 0.5 of patterns at half tempo, got %v", similarity)
+	}
+}
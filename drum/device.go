@@ -0,0 +1,70 @@
+package drum
+
+import "fmt"
+
+// DeviceProfile describes constraints of a target drum machine.
+// Zero values mean no limit.
+type DeviceProfile struct {
+	Name string
+	// MaxTracks is the number of tracks the device can play
+	MaxTracks int
+	// MaxSteps is the maximum number of steps of a track
+	MaxSteps int
+	// Tempo range, inclusive
+	MinTempo, MaxTempo float32
+	// MaxNameLength is the maximum length of a track name in bytes
+	MaxNameLength int
+}
+
+// ConstraintViolation is a single way a pattern doesn't fit a device.
+type ConstraintViolation struct {
+	// Constraint is the name of the violated DeviceProfile field
+	Constraint string
+	// Track is the index of the violating track, or -1 for the whole pattern
+	Track   int
+	Message string
+}
+
+// String returns the violation's message.
+func (v ConstraintViolation) String() string {
+	return v.Message
+}
+
+// CheckDeviceProfile returns all ways the pattern violates constraints
+// of the device profile, or nil if it fits the device.
+func CheckDeviceProfile(p *Pattern, profile DeviceProfile) []ConstraintViolation {
+	p.ensureTracks()
+
+	var violations []ConstraintViolation
+
+	violate := func(constraint string, track int, format string, args ...interface{}) {
+		violations = append(violations, ConstraintViolation{
+			Constraint: constraint,
+			Track:      track,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	if profile.MaxTracks > 0 && len(p.Tracks) > profile.MaxTracks {
+		violate("MaxTracks", -1, "%d tracks, %s supports %d", len(p.Tracks), profile.Name, profile.MaxTracks)
+	}
+	if profile.MinTempo > 0 && p.Tempo < profile.MinTempo {
+		violate("MinTempo", -1, "tempo %v below %s minimum of %v", p.Tempo, profile.Name, profile.MinTempo)
+	}
+	if profile.MaxTempo > 0 && p.Tempo > profile.MaxTempo {
+		violate("MaxTempo", -1, "tempo %v above %s maximum of %v", p.Tempo, profile.Name, profile.MaxTempo)
+	}
+
+	for i, track := range p.Tracks {
+		if profile.MaxSteps > 0 && track.Len() > profile.MaxSteps {
+			violate("MaxSteps", i, "track %q has %d steps, %s supports %d",
+				track.Name, track.Len(), profile.Name, profile.MaxSteps)
+		}
+		if profile.MaxNameLength > 0 && len(track.Name) > profile.MaxNameLength {
+			violate("MaxNameLength", i, "track name %q is %d bytes long, %s supports %d",
+				track.Name, len(track.Name), profile.Name, profile.MaxNameLength)
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,49 @@
+package drum
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestCheckDeviceProfile(t *testing.T) {
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := CheckDeviceProfile(decoded, DeviceProfile{Name: "roomy", MaxTracks: 16}); v != nil {
+		t.Fatalf("expected no violations, got %v", v)
+	}
+
+	violations := CheckDeviceProfile(decoded, DeviceProfile{
+		Name:          "tiny",
+		MaxTracks:     4,
+		MaxSteps:      8,
+		MinTempo:      60,
+		MaxTempo:      110,
+		MaxNameLength: 6,
+	})
+
+	var lines []string
+	for _, v := range violations {
+		lines = append(lines, fmt.Sprintf("%s %d: %s", v.Constraint, v.Track, v))
+	}
+
+	expected := []string{
+		"MaxTracks -1: 6 tracks, tiny supports 4",
+		"MaxTempo -1: tempo 120 above tiny maximum of 110",
+	}
+	for i, track := range decoded.Tracks {
+		expected = append(expected, fmt.Sprintf("MaxSteps %d: track %q has 16 steps, tiny supports 8", i, track.Name))
+		if len(track.Name) > 6 {
+			expected = append(expected, fmt.Sprintf("MaxNameLength %d: track name %q is %d bytes long, tiny supports 6",
+				i, track.Name, len(track.Name)))
+		}
+	}
+
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected violations.\nGot:\n%s\nExpected:\n%s", strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
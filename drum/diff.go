@@ -0,0 +1,200 @@
+package drum
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// DiffKind is the kind of a difference between two patterns.
+type DiffKind int
+
+const (
+	DiffVersion DiffKind = iota
+	DiffTempo
+	DiffTrackAdded
+	DiffTrackRemoved
+	DiffTrackName
+	DiffTrackOffset
+	DiffStep
+	DiffTempoChange
+)
+
+var diffKindNames = []string{"version", "tempo", "track-added", "track-removed", "track-name", "track-offset", "step", "tempo-change"}
+
+// String returns the name of the kind, e.g. "track-added".
+func (k DiffKind) String() string {
+	if int(k) < len(diffKindNames) {
+		return diffKindNames[k]
+	}
+
+	return fmt.Sprintf("DiffKind(%d)", int(k))
+}
+
+// MarshalText returns the name of the kind, so it's readable in JSON.
+func (k DiffKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Difference is a single difference between two patterns.
+// Old and New hold the differing values in their text form.
+type Difference struct {
+	Kind DiffKind `json:"kind"`
+	// TrackID is the ID of the differing track, for track differences
+	TrackID byte `json:"track"`
+	// Step is the index of the differing step, for DiffStep and
+	// DiffTempoChange
+	Step int `json:"step"`
+	// Old and New are empty for tempo changes missing in a pattern
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// String returns the difference in a human readable form.
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffVersion:
+		return fmt.Sprintf("version: %s -> %s", d.Old, d.New)
+	case DiffTempo:
+		return fmt.Sprintf("tempo: %s -> %s", d.Old, d.New)
+	case DiffTrackAdded:
+		return fmt.Sprintf("+ track (%d) %s", d.TrackID, d.New)
+	case DiffTrackRemoved:
+		return fmt.Sprintf("- track (%d) %s", d.TrackID, d.Old)
+	case DiffTrackName:
+		return fmt.Sprintf("track (%d) name: %s -> %s", d.TrackID, d.Old, d.New)
+	case DiffTrackOffset:
+		return fmt.Sprintf("track (%d) offset: %s -> %s", d.TrackID, d.Old, d.New)
+	case DiffTempoChange:
+		return fmt.Sprintf("tempo at step %d: %s -> %s", d.Step+1, orNone(d.Old), orNone(d.New))
+	default:
+		return fmt.Sprintf("track (%d) step %d: %s -> %s", d.TrackID, d.Step+1, d.Old, d.New)
+	}
+}
+
+// Diff returns differences between patterns a and b. Tempo changes are
+// matched by step and tracks by ID. Differences of tracks follow the order
+// of a, with tracks added in b listed last.
+func Diff(a, b *Pattern) []Difference {
+	a.ensureTracks()
+	b.ensureTracks()
+
+	var diffs []Difference
+
+	if a.Version != b.Version {
+		diffs = append(diffs, Difference{Kind: DiffVersion, Old: a.Version, New: b.Version})
+	}
+	if a.Tempo != b.Tempo {
+		diffs = append(diffs, Difference{Kind: DiffTempo, Old: formatTempo(a.Tempo), New: formatTempo(b.Tempo)})
+	}
+	diffs = append(diffs, diffTempoChanges(a.TempoChanges, b.TempoChanges)...)
+
+	for _, ta := range a.Tracks {
+		tb, ok := trackByID(b, ta.ID)
+		if !ok {
+			diffs = append(diffs, Difference{Kind: DiffTrackRemoved, TrackID: ta.ID, Old: ta.Name})
+			continue
+		}
+
+		diffs = append(diffs, diffTracks(ta, tb)...)
+	}
+
+	for _, tb := range b.Tracks {
+		if _, ok := trackByID(a, tb.ID); !ok {
+			diffs = append(diffs, Difference{Kind: DiffTrackAdded, TrackID: tb.ID, New: tb.Name})
+		}
+	}
+
+	return diffs
+}
+
+// diffTracks returns differences between two tracks with the same ID.
+func diffTracks(a, b Track) []Difference {
+	var diffs []Difference
+	a.Unpack()
+	b.Unpack()
+
+	if a.Name != b.Name {
+		diffs = append(diffs, Difference{Kind: DiffTrackName, TrackID: a.ID, Old: a.Name, New: b.Name})
+	}
+	if a.Offset != b.Offset {
+		diffs = append(diffs, Difference{
+			Kind:    DiffTrackOffset,
+			TrackID: a.ID,
+			Old:     strconv.Itoa(a.Offset),
+			New:     strconv.Itoa(b.Offset),
+		})
+	}
+
+	steps := len(a.Steps)
+	if len(b.Steps) > steps {
+		steps = len(b.Steps)
+	}
+
+	for i := 0; i < steps; i++ {
+		sa, sb := stepAt(a.Steps, i), stepAt(b.Steps, i)
+		if sa != sb {
+			diffs = append(diffs, Difference{
+				Kind:    DiffStep,
+				TrackID: a.ID,
+				Step:    i,
+				Old:     plainStepSymbols([]byte{sa})[0],
+				New:     plainStepSymbols([]byte{sb})[0],
+			})
+		}
+	}
+
+	return diffs
+}
+
+// diffTempoChanges returns differences between tempo changes, ordered
+// by step.
+func diffTempoChanges(a, b []TempoChange) []Difference {
+	tempos := [2]map[int]string{{}, {}}
+	seen := map[int]bool{}
+	var steps []int
+	for i, changes := range [2][]TempoChange{a, b} {
+		for _, c := range changes {
+			tempos[i][c.Step] = formatTempo(c.Tempo)
+			if !seen[c.Step] {
+				seen[c.Step] = true
+				steps = append(steps, c.Step)
+			}
+		}
+	}
+	sort.Ints(steps)
+
+	var diffs []Difference
+	for _, step := range steps {
+		if tempos[0][step] != tempos[1][step] {
+			diffs = append(diffs, Difference{Kind: DiffTempoChange, Step: step, Old: tempos[0][step], New: tempos[1][step]})
+		}
+	}
+
+	return diffs
+}
+
+// orNone returns s, or "none" if it's empty.
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+
+	return s
+}
+
+// trackByID returns the track of the pattern with the given ID.
+func trackByID(p *Pattern, id byte) (Track, bool) {
+	for _, track := range p.Tracks {
+		if track.ID == id {
+			return track, true
+		}
+	}
+
+	return Track{}, false
+}
+
+// formatTempo returns the tempo in its shortest text form.
+func formatTempo(tempo float32) string {
+	return strconv.FormatFloat(float64(tempo), 'g', -1, 32)
+}
@@ -0,0 +1,53 @@
+package drum
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diffs := Diff(a, a.Clone()); len(diffs) != 0 {
+		t.Fatalf("expected no differences, got %v", diffs)
+	}
+
+	a.SetTempoChange(4, 130)
+	a.SetTempoChange(8, 140)
+
+	b := a.Clone()
+	b.Tempo = 98.4
+	b.TempoChanges = nil
+	b.SetTempoChange(0, 110)
+	b.SetTempoChange(8, 150)
+	b.Tracks[0].Steps[1] = StepOn
+	b.Tracks[1].Name = "snare2"
+	b.Tracks[2].Steps[4] = StepFlam
+	b.Tracks = append(b.Tracks[:5], Track{ID: 9, Name: "rim", Steps: make([]byte, 16)})
+
+	var lines []string
+	for _, d := range Diff(a, b) {
+		lines = append(lines, fmt.Sprint(d))
+	}
+
+	expected := []string{
+		"tempo: 120 -> 98.4",
+		"tempo at step 1: none -> 110",
+		"tempo at step 5: 130 -> none",
+		"tempo at step 9: 140 -> 150",
+		"track (0) step 2: - -> x",
+		"track (1) name: snare -> snare2",
+		"track (2) step 5: x -> f",
+		"- track (5) cowbell",
+		"+ track (9) rim",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected differences.\nGot:\n%s\nExpected:\n%s",
+			strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
@@ -0,0 +1,176 @@
+package drum
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+//go:embed diff.html
+var diffHTML string
+
+var diffHTMLTemplate = template.Must(template.New("diff.html").Parse(diffHTML))
+
+// diffStats summarizes differences between two patterns.
+type diffStats struct {
+	Differences int
+	Steps       int
+	Added       int
+	Removed     int
+	Renamed     int
+	Shifted     int
+	// Version and Tempo are changes in "old -> new" form, if any
+	Version string
+	Tempo   string
+	// TempoChanges are differing tempo changes in "step N: old -> new" form
+	TempoChanges []string
+}
+
+// diffSide is one of the patterns shown side by side.
+type diffSide struct {
+	Label   string
+	Version string
+	Tempo   string
+	Rows    []diffRow
+}
+
+// diffRow is a track's row of a side. Rows of both sides are aligned,
+// so a track missing on one side has an empty row there.
+type diffRow struct {
+	ID          byte
+	Name        string
+	Class       string
+	Missing     bool
+	NameChanged bool
+	// CSS classes of step cells
+	Cells []string
+}
+
+// WithDiffLabels sets labels of the compared patterns in diff reports,
+// e.g. their file names. Defaults to "a" and "b".
+func WithDiffLabels(a, b string) ExportOption {
+	return func(c *exportConfig) {
+		c.diffLabels = [2]string{a, b}
+	}
+}
+
+// ExportDiffHTML writes a standalone HTML page showing grids of patterns
+// a and b side by side, with changed cells highlighted and a summary of
+// differences, see Diff.
+func ExportDiffHTML(w io.Writer, a, b *Pattern, opts ...ExportOption) error {
+	if err := a.ensureTracks(); err != nil {
+		return err
+	}
+	if err := b.ensureTracks(); err != nil {
+		return err
+	}
+
+	config := newExportConfig(opts)
+	labels := config.diffLabels
+	if labels == [2]string{} {
+		labels = [2]string{"a", "b"}
+	}
+
+	diffs := Diff(a, b)
+
+	stats := diffStats{Differences: len(diffs)}
+	changed := map[byte]map[int]bool{}
+	renamed := map[byte]bool{}
+	for _, d := range diffs {
+		switch d.Kind {
+		case DiffVersion:
+			stats.Version = fmt.Sprintf("%s -> %s", d.Old, d.New)
+		case DiffTempo:
+			stats.Tempo = fmt.Sprintf("%s -> %s", d.Old, d.New)
+		case DiffTempoChange:
+			stats.TempoChanges = append(stats.TempoChanges, fmt.Sprintf("step %d: %s -> %s", d.Step+1, orNone(d.Old), orNone(d.New)))
+		case DiffTrackAdded:
+			stats.Added++
+		case DiffTrackRemoved:
+			stats.Removed++
+		case DiffTrackName:
+			stats.Renamed++
+			renamed[d.TrackID] = true
+		case DiffTrackOffset:
+			stats.Shifted++
+		case DiffStep:
+			stats.Steps++
+			if changed[d.TrackID] == nil {
+				changed[d.TrackID] = map[int]bool{}
+			}
+			changed[d.TrackID][d.Step] = true
+		}
+	}
+
+	// Rows follow the order of Diff: tracks of a, then tracks added in b
+	var ids []byte
+	for _, track := range a.Tracks {
+		ids = append(ids, track.ID)
+	}
+	for _, track := range b.Tracks {
+		if _, ok := trackByID(a, track.ID); !ok {
+			ids = append(ids, track.ID)
+		}
+	}
+
+	sides := [2]diffSide{
+		{Label: labels[0], Version: a.Version, Tempo: formatTempo(a.Tempo)},
+		{Label: labels[1], Version: b.Version, Tempo: formatTempo(b.Tempo)},
+	}
+	for _, id := range ids {
+		ta, okA := trackByID(a, id)
+		tb, okB := trackByID(b, id)
+		steps := max(ta.Len(), tb.Len())
+
+		rowA := diffTrackRow(ta, okA, steps, changed[id], renamed[id])
+		rowB := diffTrackRow(tb, okB, steps, changed[id], renamed[id])
+		if !okB {
+			rowA.Class = "removed"
+		}
+		if !okA {
+			rowB.Class = "added"
+		}
+
+		sides[0].Rows = append(sides[0].Rows, rowA)
+		sides[1].Rows = append(sides[1].Rows, rowB)
+	}
+
+	return diffHTMLTemplate.Execute(w, struct {
+		Labels [2]string
+		Theme  Theme
+		Stats  diffStats
+		Sides  [2]diffSide
+	}{labels, config.theme, stats, sides})
+}
+
+// diffTrackRow returns the row of a track on one side of a diff report,
+// or an empty row if the track doesn't exist on this side.
+func diffTrackRow(t Track, ok bool, steps int, changed map[int]bool, renamed bool) diffRow {
+	row := diffRow{ID: t.ID, Name: t.Name, NameChanged: renamed, Cells: make([]string, steps)}
+	t.Unpack()
+	if !ok {
+		row.Missing = true
+		row.Class = "missing"
+	}
+
+	for i := range row.Cells {
+		var classes []string
+		switch stepAt(t.Steps, i) {
+		case StepOn:
+			classes = append(classes, "on")
+		case StepFlam:
+			classes = append(classes, "flam")
+		}
+		if i%4 == 0 {
+			classes = append(classes, "beat")
+		}
+		if changed[i] {
+			classes = append(classes, "changed")
+		}
+		row.Cells[i] = strings.Join(classes, " ")
+	}
+
+	return row
+}
@@ -0,0 +1,46 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExportDiffHTML(t *testing.T) {
+	a, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := a.Clone()
+	b.Tempo = 98.4
+	b.SetTempoChange(8, 140)
+	b.Tracks[0].Steps[1] = StepOn
+	b.Tracks[1].Name = "snare2"
+	b.Tracks = append(b.Tracks[:5], Track{ID: 9, Name: "rim", Steps: make([]byte, 16)})
+
+	var buf bytes.Buffer
+	if err := ExportDiffHTML(&buf, a, b, WithDiffLabels("old.splice", "new.splice")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`<title>old.splice vs new.splice</title>`,
+		`<tr><td>Differences</td><td>6</td></tr>`,
+		`<tr><td>Steps changed</td><td>1</td></tr>`,
+		`<tr><td>Tracks added</td><td>1</td></tr>`,
+		`<tr><td>Tempo</td><td>120 -&gt; 98.4</td></tr>`,
+		`<tr><td>Tempo change</td><td>step 9: none -&gt; 140</td></tr>`,
+		`<tr class=""><td class="name">(0) kick</td><td class="on beat"></td><td class="changed"></td>`,
+		`<tr class=""><td class="name">(0) kick</td><td class="on beat"></td><td class="on changed"></td>`,
+		`<td class="name changed">(1) snare2</td>`,
+		`<tr class="removed"><td class="name">(5) cowbell</td>`,
+		`<tr class="missing"><td class="name"></td>`,
+		`<tr class="added"><td class="name">(9) rim</td>`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("exported HTML doesn't contain %q", expected)
+		}
+	}
+}
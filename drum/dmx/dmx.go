@@ -0,0 +1,210 @@
+// Package dmx flashes stage lights on hits of played patterns, sending
+// DMX512 frames over Art-Net, so lighting follows the same pattern as
+// audio.
+package dmx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+const (
+	// ArtNetPort is the UDP port of Art-Net nodes.
+	ArtNetPort = 6454
+	// Channels is the number of channels of a DMX universe.
+	Channels = 512
+	// DefaultRefresh is the interval frames are sent at, the fastest
+	// refresh of DMX512 with all channels.
+	DefaultRefresh = 23 * time.Millisecond
+	// DefaultDecay is the decay of flashes of lights without one.
+	DefaultDecay = 200 * time.Millisecond
+)
+
+// artNetOpDMX is the operation code of ArtDMX packets.
+const artNetOpDMX = 0x5000
+
+// Light is a fixture flashed by hits of a track.
+type Light struct {
+	// Channels are DMX channels set to the flash's level, from 1, e.g.
+	// dimmers of several fixtures or red, green and blue of a single one
+	Channels []int
+	// Level is the peak level of flashes of hits at AccentVelocity.
+	// Levels of other hits are scaled by their velocity. 255 if zero.
+	Level byte
+	// Decay is the time a flash fades out over, DefaultDecay if zero
+	Decay time.Duration
+}
+
+// DialArtNet returns a connection to the Art-Net node at the address,
+// on ArtNetPort unless the address has a port.
+func DialArtNet(addr string) (net.Conn, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, fmt.Sprint(ArtNetPort))
+	}
+
+	return net.Dial("udp", addr)
+}
+
+// ArtDMX returns an Art-Net packet carrying the DMX frame to the universe,
+// given as the 15-bit port-address of Art-Net 3 and 4. Sequence orders
+// packets, from 1 wrapping around to 1, or is 0 to disable ordering.
+func ArtDMX(universe uint16, sequence byte, frame []byte) []byte {
+	// Frames must have an even length of at least 2 channels
+	length := max(len(frame)+len(frame)%2, 2)
+
+	packet := make([]byte, 18+length)
+	copy(packet, "Art-Net\x00")
+	binary.LittleEndian.PutUint16(packet[8:], artNetOpDMX)
+	binary.BigEndian.PutUint16(packet[10:], 14)
+	packet[12] = sequence
+	binary.LittleEndian.PutUint16(packet[14:], universe&0x7fff)
+	binary.BigEndian.PutUint16(packet[16:], uint16(length))
+	copy(packet[18:], frame)
+
+	return packet
+}
+
+// flash is the last hit of a track.
+type flash struct {
+	at       time.Time
+	velocity byte
+}
+
+// Sink is a sequencer sink flashing lights of tracks on hits, sending
+// frames to a universe as flashes fade out.
+type Sink struct {
+	w        io.Writer
+	universe uint16
+	lights   map[int]Light
+	refresh  time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	flashes  map[int]flash
+	sequence byte
+	err      error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSink returns a sink flashing lights keyed by indexes of tracks,
+// writing ArtDMX packets of the universe to w, e.g. a connection from
+// DialArtNet, every refresh interval. It must be closed once playback ends.
+func NewSink(w io.Writer, universe uint16, lights map[int]Light, refresh time.Duration) *Sink {
+	if refresh <= 0 {
+		refresh = DefaultRefresh
+	}
+
+	s := &Sink{
+		w:        w,
+		universe: universe,
+		lights:   lights,
+		refresh:  refresh,
+		now:      time.Now,
+		flashes:  map[int]flash{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s
+}
+
+// Trigger flashes the light of the event's track.
+func (s *Sink) Trigger(e drum.Event) {
+	if _, ok := s.lights[e.TrackIndex]; !ok {
+		return
+	}
+
+	s.mu.Lock()
+	s.flashes[e.TrackIndex] = flash{at: s.now(), velocity: e.Velocity}
+	s.mu.Unlock()
+}
+
+// run sends frames until the sink is closed.
+func (s *Sink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.send(s.Frame(s.now()))
+		}
+	}
+}
+
+// send writes the frame in an ArtDMX packet.
+func (s *Sink) send(frame []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequence = s.sequence%255 + 1
+	_, err := s.w.Write(ArtDMX(s.universe, s.sequence, frame))
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Frame returns levels of all channels at the time. Flashes decay
+// linearly, and channels shared by lights take the highest level.
+func (s *Sink) Frame(now time.Time) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	frame := make([]byte, Channels)
+	for track, f := range s.flashes {
+		light := s.lights[track]
+
+		peak := float64(light.Level)
+		if light.Level == 0 {
+			peak = 255
+		}
+		peak *= float64(f.velocity) / float64(drum.AccentVelocity)
+
+		decay := light.Decay
+		if decay <= 0 {
+			decay = DefaultDecay
+		}
+
+		elapsed := now.Sub(f.at)
+		if elapsed >= decay {
+			continue
+		}
+		level := byte(min(peak*float64(decay-max(elapsed, 0))/float64(decay), 255))
+
+		for _, channel := range light.Channels {
+			if channel >= 1 && channel <= Channels {
+				frame[channel-1] = max(frame[channel-1], level)
+			}
+		}
+	}
+
+	return frame
+}
+
+// Close stops sending frames, turning lights off with a blackout frame,
+// and returns the first error of sending, if any.
+func (s *Sink) Close() error {
+	close(s.stop)
+	<-s.done
+
+	s.send(make([]byte, Channels))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
@@ -0,0 +1,64 @@
+package dmx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestArtDMX(t *testing.T) {
+	packet := ArtDMX(0x123, 7, []byte{255, 128, 1})
+
+	expected := []byte{
+		'A', 'r', 't', '-', 'N', 'e', 't', 0,
+		0x00, 0x50, // OpDmx, little endian
+		0, 14, // protocol version
+		7, 0, // sequence, physical
+		0x23, 0x01, // port-address, little endian
+		0, 4, // length, even
+		255, 128, 1, 0,
+	}
+	if !bytes.Equal(packet, expected) {
+		t.Errorf("expected packet %v, got %v", expected, packet)
+	}
+}
+
+func TestSinkFrame(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewSink(&buf, 1, map[int]Light{
+		0: {Channels: []int{1, 3}, Decay: 100 * time.Millisecond},
+		1: {Channels: []int{3, 512}, Level: 100},
+	}, time.Hour)
+
+	start := time.Unix(0, 0)
+	sink.now = func() time.Time { return start }
+
+	sink.Trigger(drum.Event{TrackIndex: 0, Velocity: drum.AccentVelocity})
+	sink.Trigger(drum.Event{TrackIndex: 1, Velocity: drum.AccentVelocity})
+	sink.Trigger(drum.Event{TrackIndex: 2, Velocity: drum.AccentVelocity})
+
+	frame := sink.Frame(start.Add(50 * time.Millisecond))
+	if len(frame) != Channels {
+		t.Fatalf("expected %d channels, got %d", Channels, len(frame))
+	}
+	// Track 0 is halfway through its decay, track 1 a quarter
+	for channel, expected := range map[int]byte{1: 127, 2: 0, 3: 127, 512: 75} {
+		if frame[channel-1] != expected {
+			t.Errorf("expected channel %d at %d, got %d", channel, expected, frame[channel-1])
+		}
+	}
+
+	frame = sink.Frame(start.Add(time.Second))
+	if !bytes.Equal(frame, make([]byte, Channels)) {
+		t.Errorf("expected flashes to fade out")
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), ArtDMX(1, 1, make([]byte, Channels))) {
+		t.Errorf("expected a blackout frame once closed")
+	}
+}
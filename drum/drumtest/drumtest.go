@@ -0,0 +1,103 @@
+// Package drumtest provides helpers for testing code built on the drum
+// package against .splice fixtures.
+package drumtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// AssertRoundTrip fails the test if the file at path isn't encoded back
+// to the same bytes after decoding. Data trailing after the file's declared
+// content length is ignored.
+func AssertRoundTrip(tb testing.TB, path string) {
+	tb.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	content, err := declaredContent(data)
+	if err != nil {
+		tb.Fatalf("%s %v", path, err)
+	}
+
+	p, err := drum.DecodeFile(path)
+	if err != nil {
+		tb.Fatalf("something went wrong decoding %s - %v", path, err)
+	}
+
+	encoded, err := p.MarshalBinary()
+	if err != nil {
+		tb.Fatalf("something went wrong encoding %s - %v", path, err)
+	}
+
+	if !bytes.Equal(encoded, content) {
+		tb.Fatalf("%s wasn't encoded back to the same bytes.\nGot:\n%x\nExpected:\n%x", path, encoded, content)
+	}
+}
+
+// spliceHeader starts every .splice file, followed by the content length.
+const spliceHeader = "SPLICE"
+
+// declaredContent returns the file's header, content length and content
+// as long as the declared length, without trailing data.
+func declaredContent(data []byte) ([]byte, error) {
+	offset := len(spliceHeader) + 8
+	if len(data) < offset || !strings.HasPrefix(string(data), spliceHeader) {
+		return nil, fmt.Errorf("is too short or has no %s header", spliceHeader)
+	}
+
+	length := binary.BigEndian.Uint64(data[len(spliceHeader):offset])
+	if length > uint64(len(data)-offset) {
+		return nil, fmt.Errorf("declares %d bytes of content, but has only %d", length, len(data)-offset)
+	}
+
+	return data[:offset+int(length)], nil
+}
+
+// AssertPatternRoundTrip fails the test if the pattern doesn't survive
+// encoding and decoding unchanged, e.g. after being transformed.
+func AssertPatternRoundTrip(tb testing.TB, p *drum.Pattern) {
+	tb.Helper()
+
+	encoded, err := p.MarshalBinary()
+	if err != nil {
+		tb.Fatalf("something went wrong encoding pattern - %v", err)
+	}
+
+	decoded, err := drum.Decode(bytes.NewReader(encoded))
+	if err != nil {
+		tb.Fatalf("something went wrong decoding pattern - %v", err)
+	}
+
+	if decoded.String() != p.String() {
+		tb.Fatalf("pattern changed after round trip.\nGot:\n%s\nExpected:\n%s", decoded, p)
+	}
+}
+
+// LoadCorpus decodes all .splice files found under dir, recursively,
+// failing the test if any of them can't be decoded. Patterns are keyed
+// by paths relative to dir.
+func LoadCorpus(tb testing.TB, dir string) map[string]*drum.Pattern {
+	tb.Helper()
+
+	patterns, err := drum.DecodeDirFS(os.DirFS(dir), ".")
+	if err != nil {
+		tb.Fatalf("something went wrong loading corpus %s - %v", dir, err)
+	}
+
+	if len(patterns) == 0 {
+		tb.Fatalf("no .splice files found in %s", dir)
+	}
+
+	return patterns
+}
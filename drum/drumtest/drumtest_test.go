@@ -0,0 +1,31 @@
+package drumtest
+
+import (
+	"path"
+	"testing"
+)
+
+func TestCorpus(t *testing.T) {
+	patterns := LoadCorpus(t, "../fixtures")
+
+	for name, p := range patterns {
+		AssertRoundTrip(t, path.Join("../fixtures", name))
+		AssertPatternRoundTrip(t, p)
+	}
+}
+
+func TestDeclaredContent(t *testing.T) {
+	for _, data := range []string{"", "SPLICE\x00", "SPLOCE\x00\x00\x00\x00\x00\x00\x00\x00", "SPLICE\x00\x00\x00\x00\x00\x00\x00\x05abc"} {
+		if _, err := declaredContent([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+
+	content, err := declaredContent([]byte("SPLICE\x00\x00\x00\x00\x00\x00\x00\x02abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "SPLICE\x00\x00\x00\x00\x00\x00\x00\x02ab" {
+		t.Errorf("expected content without trailing data, got %q", content)
+	}
+}
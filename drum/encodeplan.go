@@ -0,0 +1,139 @@
+package drum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EncodeReport describes data MarshalBinary would write for a pattern,
+// so tools can warn before a save loses information.
+type EncodeReport struct {
+	// Size is the number of bytes written
+	Size int
+	// Fields are written fields in order, named like in AnnotateHex
+	Fields []EncodeField
+	// Conversions are lossy changes made to fit the .splice format,
+	// e.g. flams encoded as regular hits
+	Conversions []string
+	// Truncations are track names shortened following the name policy
+	Truncations []NameTruncation
+	// Err is the reason encoding would fail, if it would
+	Err error
+}
+
+// EncodeField is a single field of encoded data.
+type EncodeField struct {
+	Name   string
+	Offset int
+	Size   int
+}
+
+// Lossy returns true if encoding would lose information of the pattern.
+func (r EncodeReport) Lossy() bool {
+	return len(r.Conversions) > 0
+}
+
+func (r EncodeReport) String() string {
+	if r.Err != nil {
+		return "encoding fails: " + r.Err.Error()
+	}
+
+	s := fmt.Sprintf("%d bytes in %d fields", r.Size, len(r.Fields))
+	if r.Lossy() {
+		s += "; " + strings.Join(r.Conversions, "; ")
+	}
+
+	return s
+}
+
+// EncodePlan returns what EncodeBytes would write for the pattern with
+// provided options, without encoding it: offsets and sizes of fields and
+// lossy conversions. Metadata written to the sidecar by EncodeFile isn't
+// reported.
+func (p *Pattern) EncodePlan(opts ...EncodeOption) EncodeReport {
+	var r EncodeReport
+
+	var config encodeConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	r.Err = p.ensureTracks()
+	if r.Err == nil {
+		r.Err = p.checkIDs()
+	}
+	if r.Err == nil {
+		r.Err = p.checkStepWidth(config.stepWidthOf(p))
+	}
+	if r.Err == nil && len(p.Version) >= versionMaxLength {
+		r.Err = fmt.Errorf("version %q too long (max %d bytes)", p.Version, versionMaxLength-1)
+	}
+
+	r.field("header", headerLength)
+	r.field("length", lengthSize)
+	r.field("version", versionMaxLength)
+	r.field("tempo", tempoSize)
+
+	if p.velocityCurve != nil {
+		r.Conversions = append(r.Conversions, "velocity curve dropped")
+	}
+
+	for i, track := range p.Tracks {
+		prefix := fmt.Sprintf("track %d ", i)
+		n := track.Len()
+
+		r.field(prefix+"id", 1)
+		r.field(prefix+"name length", 4)
+		label := fmt.Sprintf("track %d (%s)", i, track.Name)
+
+		full := config.trackName(track)
+		if full != track.Name {
+			r.Conversions = append(r.Conversions, fmt.Sprintf("%s: name transliterated to %q", label, full))
+		}
+
+		name, err := config.encodeName(full)
+		if err != nil {
+			name = full
+			if r.Err == nil {
+				r.Err = err
+			}
+		}
+		if name != full {
+			r.Truncations = append(r.Truncations, NameTruncation{Track: i, Name: full, Encoded: name})
+			r.Conversions = append(r.Conversions, fmt.Sprintf("%s: name encoded as %q", label, name))
+		}
+
+		r.field(prefix+"name", len(name))
+		r.field(prefix+"steps", n)
+
+		if flams := countFlams(track); flams > 0 {
+			r.Conversions = append(r.Conversions, fmt.Sprintf("%s: %d flams encoded as hits, kept only in the sidecar", label, flams))
+		}
+		if track.Offset%max(n, 1) != 0 {
+			r.Conversions = append(r.Conversions, fmt.Sprintf("%s: offset %d applied to steps", label, track.Offset))
+		}
+		if track.velocityScale != 0 {
+			r.Conversions = append(r.Conversions, fmt.Sprintf("%s: velocity scale dropped", label))
+		}
+	}
+
+	return r
+}
+
+// field appends a field of size bytes following the previous ones.
+func (r *EncodeReport) field(name string, size int) {
+	r.Fields = append(r.Fields, EncodeField{Name: name, Offset: r.Size, Size: size})
+	r.Size += size
+}
+
+// countFlams returns the number of flams of the track.
+func countFlams(track Track) int {
+	flams := 0
+	for _, step := range track.Steps {
+		if step == StepFlam {
+			flams++
+		}
+	}
+
+	return flams
+}
@@ -0,0 +1,72 @@
+package drum
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestEncodePlan(t *testing.T) {
+	for _, exp := range tData {
+		p, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report := p.EncodePlan()
+		if report.Err != nil || report.Lossy() {
+			t.Fatalf("%s: unexpected report %v", exp.path, report)
+		}
+		if report.Size != len(data) {
+			t.Fatalf("%s: planned %d bytes, encoded %d", exp.path, report.Size, len(data))
+		}
+
+		last := report.Fields[len(report.Fields)-1]
+		if last.Offset+last.Size != len(data) || last.Name != fmt.Sprintf("track %d steps", len(p.Tracks)-1) {
+			t.Fatalf("%s: unexpected last field %+v", exp.path, last)
+		}
+	}
+}
+
+func TestEncodePlanLossy(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: stepsFromString("x---x---x---x---")},
+			{ID: 1, Name: "snare", Steps: append([]byte{StepOff, StepFlam, StepOn, StepFlam}, make([]byte, 12)...), Offset: 1},
+		},
+	}
+
+	report := p.EncodePlan()
+	if report.Err != nil {
+		t.Fatal(report.Err)
+	}
+	expected := []string{
+		"track 1 (snare): 2 flams encoded as hits, kept only in the sidecar",
+		"track 1 (snare): offset 1 applied to steps",
+	}
+	if strings.Join(report.Conversions, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected conversions:\n%s", strings.Join(report.Conversions, "\n"))
+	}
+
+	p.Tracks[1].Steps = p.Tracks[1].Steps[:4]
+	if report := p.EncodePlan(); report.Err == nil || !strings.Contains(report.String(), "has 4 steps, expected 16") {
+		t.Fatalf("expected encoding of mixed widths to fail, got %v", report)
+	}
+	if report := p.EncodePlan(WithEncodedStepWidth(4)); report.Err == nil {
+		t.Fatalf("expected encoding of mixed widths to fail, got %v", report)
+	}
+
+	p.Tracks[1].ID = 0
+	p.Version = strings.Repeat("v", versionMaxLength)
+	if report := p.EncodePlan(); report.Err == nil || !strings.Contains(report.String(), "encoding fails: duplicate track ID") {
+		t.Fatalf("expected encoding to fail, got %v", report)
+	}
+}
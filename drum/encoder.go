@@ -0,0 +1,156 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+)
+
+// EncodeFile encodes the pattern and writes it to the file at the provided
+// path. Metadata is written to the file's sidecar.
+func EncodeFile(p *Pattern, path string, opts ...EncodeOption) error {
+	data, _, err := EncodeBytes(p, opts...)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return p.writeSidecar(path)
+}
+
+// MarshalBinary encodes pattern attributes in the .splice format.
+// The content's length is computed from the encoded data.
+func (p *Pattern) MarshalBinary() ([]byte, error) {
+	data, _, err := EncodeBytes(p)
+	return data, err
+}
+
+// WithEncodedStepWidth sets the number of steps of every encoded track.
+// Defaults to the width the pattern was decoded with, see WithStepWidth,
+// or 16. Files of other widths are decoded with WithStepWidth.
+func WithEncodedStepWidth(n int) EncodeOption {
+	return func(c *encodeConfig) {
+		c.stepWidth = n
+	}
+}
+
+// stepWidthOf returns the number of steps per track the pattern is encoded
+// with.
+func (c encodeConfig) stepWidthOf(p *Pattern) int {
+	if c.stepWidth > 0 {
+		return c.stepWidth
+	}
+
+	return p.config.stepWidthOrDefault()
+}
+
+// EncodeBytes encodes the pattern in the .splice format with provided
+// options, returning track names shortened to fit the options' limits.
+// The format doesn't store the number of steps, so it fails unless all
+// tracks have the step width of the options.
+func EncodeBytes(p *Pattern, opts ...EncodeOption) ([]byte, []NameTruncation, error) {
+	var config encodeConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	err := p.ensureTracks()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = p.checkIDs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = p.checkStepWidth(config.stepWidthOf(p))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(p.Version) >= versionMaxLength {
+		return nil, nil, fmt.Errorf("version %q too long (max %d bytes)", p.Version, versionMaxLength-1)
+	}
+
+	var content bytes.Buffer
+
+	version := make([]byte, versionMaxLength)
+	copy(version, p.Version)
+	content.Write(version)
+
+	binary.Write(&content, binary.LittleEndian, p.Tempo)
+
+	var truncations []NameTruncation
+	for i, track := range p.Tracks {
+		full := config.trackName(track)
+		name, err := config.encodeName(full)
+		if err != nil {
+			return nil, nil, err
+		}
+		if name != full {
+			p.debug("track name truncated", "id", track.ID, "name", full, "encoded", name)
+			truncations = append(truncations, NameTruncation{Track: i, Name: full, Encoded: name})
+		}
+
+		p.debug("track encoded", "offset", headerLength+8+content.Len(), "id", track.ID, "name", name)
+		content.WriteByte(track.ID)
+		binary.Write(&content, binary.BigEndian, uint32(len(name)))
+		content.WriteString(name)
+		content.Write(track.encodedSteps())
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(spliceHeader)
+	binary.Write(&buffer, binary.BigEndian, uint64(content.Len()))
+	buffer.Write(content.Bytes())
+	p.debug("pattern encoded", "length", content.Len(), "size", buffer.Len())
+
+	return buffer.Bytes(), truncations, nil
+}
+
+// checkIDs returns an error if any two tracks share the same ID.
+func (p *Pattern) checkIDs() error {
+	seen := map[byte]string{}
+
+	for _, track := range p.Tracks {
+		if name, ok := seen[track.ID]; ok {
+			return fmt.Errorf("duplicate track ID %d (%q and %q)", track.ID, name, track.Name)
+		}
+		seen[track.ID] = track.Name
+	}
+
+	return nil
+}
+
+// checkStepWidth returns an error if any track doesn't have width steps.
+func (p *Pattern) checkStepWidth(width int) error {
+	for i, track := range p.Tracks {
+		if track.Len() != width {
+			return fmt.Errorf("track %d (%s) has %d steps, expected %d, see WithEncodedStepWidth", i, track.Name, track.Len(), width)
+		}
+	}
+
+	return nil
+}
+
+// NormalizeIDs assigns sequential IDs to all tracks, starting from 0.
+// It fails without changing IDs if there are more tracks than IDs.
+func (p *Pattern) NormalizeIDs() error {
+	p.ensureTracks()
+
+	if len(p.Tracks) > 256 {
+		return fmt.Errorf("can't number %d tracks, IDs are up to 255", len(p.Tracks))
+	}
+
+	for i := range p.Tracks {
+		p.Tracks[i].ID = byte(i)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	for _, exp := range tData {
+		data, err := ioutil.ReadFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := decoded.MarshalBinary()
+		if err != nil {
+			t.Fatalf("something went wrong encoding %s - %v", exp.path, err)
+		}
+
+		// Ignore any data trailing after the declared length
+		length := binary.BigEndian.Uint64(data[headerLength:])
+		data = data[:headerLength+8+length]
+
+		if !bytes.Equal(encoded, data) {
+			t.Fatalf("%s wasn't encoded as expected.\nGot:\n%x\nExpected:\n%x", exp.path, encoded, data)
+		}
+	}
+}
+
+func TestMarshalBinaryDuplicateIDs(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 1, Name: "kick", Steps: make([]byte, trackSteps)},
+			{ID: 1, Name: "snare", Steps: make([]byte, trackSteps)},
+		},
+	}
+
+	if _, err := p.MarshalBinary(); err == nil {
+		t.Fatal("expected error encoding duplicate IDs")
+	}
+
+	if err := p.NormalizeIDs(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Tracks[0].ID != 0 || p.Tracks[1].ID != 1 {
+		t.Fatalf("IDs weren't normalized: %d, %d", p.Tracks[0].ID, p.Tracks[1].ID)
+	}
+	if _, err := p.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNormalizeIDsTooManyTracks(t *testing.T) {
+	p := &Pattern{Tracks: make([]Track, 257)}
+	if err := p.NormalizeIDs(); err == nil {
+		t.Fatal("expected an error numbering 257 tracks")
+	}
+
+	p.Tracks = p.Tracks[:256]
+	if err := p.NormalizeIDs(); err != nil || p.Tracks[255].ID != 255 {
+		t.Fatalf("expected 256 tracks numbered, got %v", err)
+	}
+}
+
+func TestEncodeStepWidth(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sliced, err := p.Slice(0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sliced.MarshalBinary(); err == nil {
+		t.Fatal("expected an error encoding 4 steps as 16")
+	}
+
+	encoded, _, err := EncodeBytes(sliced, WithEncodedStepWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeBytes(encoded, WithStepWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.String() != sliced.String() {
+		t.Fatalf("sliced pattern didn't round-trip.\nGot:\n%s\nExpected:\n%s", decoded, sliced)
+	}
+
+	// The decoded width is kept for encoding
+	if reencoded, err := decoded.MarshalBinary(); err != nil || !bytes.Equal(reencoded, encoded) {
+		t.Fatalf("expected re-encoding at 4 steps, got %v", err)
+	}
+
+	sliced.Tracks[1].Steps = append(sliced.Tracks[1].Steps, StepOn)
+	if _, _, err := EncodeBytes(sliced, WithEncodedStepWidth(4)); err == nil {
+		t.Fatal("expected an error encoding tracks of different widths")
+	}
+}
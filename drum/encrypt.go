@@ -0,0 +1,170 @@
+package drum
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// encryptedHeader starts files of encrypted patterns, telling them apart
+// from plain .splice files starting with spliceHeader.
+const encryptedHeader = "SPLENC"
+
+const (
+	// encryptedVersion is the version of the encrypted container layout.
+	encryptedVersion = 1
+	// encryptedSaltLength is the length of the key derivation salt.
+	encryptedSaltLength = 16
+	// encryptedIterations is the number of PBKDF2 iterations used to
+	// derive keys from passphrases.
+	encryptedIterations = 600000
+	// encryptedMaxIterations limits iterations read from files, so a
+	// crafted file can't keep decoding busy for long.
+	encryptedMaxIterations = 1000000
+	// encryptedPrefixLength is the number of bytes preceding the nonce.
+	encryptedPrefixLength = len(encryptedHeader) + 1 + encryptedSaltLength + 4
+)
+
+// ErrPassphraseRequired is returned when decoding an encrypted pattern
+// without a passphrase set by WithPassphrase.
+var ErrPassphraseRequired = errors.New("pattern is encrypted, passphrase required")
+
+// errDecryption is returned when an encrypted pattern can't be decrypted.
+var errDecryption = errors.New("can't decrypt pattern - wrong passphrase or corrupted data")
+
+// EncodeFileEncrypted encodes the pattern, encrypts it with a key derived
+// from the passphrase and writes it to the file at the provided path.
+// Files are decrypted by decoding them with WithPassphrase. Metadata isn't
+// written, as the sidecar would keep names and notes in plain text.
+//
+// The file holds the header, the layout version, a random salt and the
+// number of iterations of PBKDF2-HMAC-SHA256 deriving an AES-256 key,
+// then a random nonce and the pattern sealed with AES-GCM, which makes
+// decoding fail if the file was tampered with.
+func EncodeFileEncrypted(p *Pattern, path string, passphrase string) error {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	data, err = encrypt(data, passphrase)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// WithPassphrase sets the passphrase decrypting encrypted patterns.
+// Plain patterns are decoded as usual.
+func WithPassphrase(passphrase string) Option {
+	return func(c *decodeConfig) {
+		c.passphrase = passphrase
+	}
+}
+
+// IsEncrypted reports whether data holds an encrypted pattern.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedHeader))
+}
+
+// encrypt returns data sealed with a key derived from the passphrase.
+func encrypt(data []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("can't encrypt with an empty passphrase")
+	}
+
+	prefix := make([]byte, 0, encryptedPrefixLength)
+	prefix = append(prefix, encryptedHeader...)
+	prefix = append(prefix, encryptedVersion)
+	salt := make([]byte, encryptedSaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, fmt.Errorf("can't generate salt - %v", err)
+	}
+	prefix = append(prefix, salt...)
+	prefix = binary.BigEndian.AppendUint32(prefix, encryptedIterations)
+
+	aead, err := encryptionCipher(passphrase, salt, encryptedIterations)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("can't generate nonce - %v", err)
+	}
+
+	sealed := append(prefix, nonce...)
+	return aead.Seal(sealed, nonce, data, prefix), nil
+}
+
+// decrypt returns data of an encrypted pattern opened with a key derived
+// from the passphrase.
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+	if len(data) < encryptedPrefixLength {
+		return nil, errors.New("encrypted pattern truncated")
+	}
+
+	version := data[len(encryptedHeader)]
+	if version != encryptedVersion {
+		return nil, fmt.Errorf("unsupported encrypted pattern version %d", version)
+	}
+
+	salt := data[len(encryptedHeader)+1 : encryptedPrefixLength-4]
+	iterations := binary.BigEndian.Uint32(data[encryptedPrefixLength-4:])
+	if iterations == 0 || iterations > encryptedMaxIterations {
+		return nil, fmt.Errorf("invalid number of key derivation iterations %d", iterations)
+	}
+
+	aead, err := encryptionCipher(passphrase, salt, int(iterations))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := data[:encryptedPrefixLength]
+	rest := data[encryptedPrefixLength:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("encrypted pattern truncated")
+	}
+
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], prefix)
+	if err != nil {
+		return nil, errDecryption
+	}
+
+	return plain, nil
+}
+
+// encryptionCipher returns AES-GCM keyed with a key derived from the
+// passphrase.
+func encryptionCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := deriveKey(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// deriveKey returns a 32-byte key derived from the passphrase with
+// PBKDF2-HMAC-SHA256 (RFC 8018).
+func deriveKey(passphrase string, salt []byte, iterations int) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+}
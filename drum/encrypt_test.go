@@ -0,0 +1,86 @@
+package drum
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestEncodeFileEncrypted(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := path.Join(t.TempDir(), "secret.splice")
+	err = EncodeFileEncrypted(p, target, "correct horse")
+	if err != nil {
+		t.Fatalf("something went wrong encrypting %s - %v", tData[0].path, err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(data) || strings.Contains(string(data), p.Tracks[0].Name) {
+		t.Fatalf("pattern not encrypted: %q", data)
+	}
+
+	decrypted, err := DecodeFile(target, WithPassphrase("correct horse"))
+	if err != nil {
+		t.Fatalf("something went wrong decrypting %s - %v", tData[0].path, err)
+	}
+	if fmt.Sprint(decrypted) != fmt.Sprint(p) {
+		t.Fatalf("decrypted pattern differs:\n%v\nexpected:\n%v", decrypted, p)
+	}
+
+	_, err = DecodeFile(target)
+	if err != ErrPassphraseRequired {
+		t.Fatalf("expected %v without passphrase, got %v", ErrPassphraseRequired, err)
+	}
+
+	_, err = DecodeFile(target, WithPassphrase("wrong horse"))
+	if err != errDecryption {
+		t.Fatalf("expected %v with wrong passphrase, got %v", errDecryption, err)
+	}
+
+	data[len(data)-1] ^= 1
+	_, err = DecodeBytes(data, WithPassphrase("correct horse"))
+	if err != errDecryption {
+		t.Fatalf("expected %v with tampered data, got %v", errDecryption, err)
+	}
+}
+
+func TestDecodePlainWithPassphrase(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path), WithPassphrase("unused"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(p) != tData[0].output {
+		t.Fatalf("decoded pattern differs:\n%v\nexpected:\n%v", p, tData[0].output)
+	}
+}
+
+func TestDecryptTruncated(t *testing.T) {
+	for _, data := range []string{encryptedHeader, encryptedHeader + "\x01" + strings.Repeat("\x00", 20)} {
+		_, err := DecodeBytes([]byte(data), WithPassphrase("x"))
+		if err == nil || !strings.Contains(err.Error(), "truncated") && !strings.Contains(err.Error(), "iterations") {
+			t.Fatalf("expected error decoding %q, got %v", data, err)
+		}
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	// Test vector of PBKDF2-HMAC-SHA256 from RFC 7914
+	key, err := deriveKey("passwd", []byte("salt"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"
+	if fmt.Sprintf("%x", key) != expected {
+		t.Fatalf("expected key %s, got %x", expected, key)
+	}
+}
@@ -0,0 +1,86 @@
+package drum
+
+import (
+	"sort"
+	"time"
+)
+
+// Event is a single hit of a track at a point in time.
+type Event struct {
+	TrackIndex int
+	Step       int
+	// Time since the beginning of the pattern
+	Time     time.Duration
+	Velocity byte
+	// Flam is true if the hit should be preceded by a grace note
+	Flam bool
+}
+
+// StepDuration returns duration of a single step at the pattern's tempo,
+// or zero if the tempo isn't positive. Tempo changes are ignored, see
+// StepDurationAt.
+func (p *Pattern) StepDuration() time.Duration {
+	return stepDuration(p.Tempo)
+}
+
+// Events returns all hits of the pattern ordered by time, then by track.
+// Track offsets and accents are applied. It's the source of timing for
+// anything playing or exporting patterns in time.
+func (p *Pattern) Events() []Event {
+	var events []Event
+
+	p.ensureTracks()
+
+	length := 0
+	for _, track := range p.Tracks {
+		length = max(length, track.Len())
+	}
+	times := p.stepTimes(length)
+
+	for i, track := range p.Tracks {
+		steps := track.ShiftedSteps()
+		velocities := p.trackVelocities(i)
+
+		for step, velocity := range velocities {
+			if velocity == 0 {
+				continue
+			}
+
+			events = append(events, Event{
+				TrackIndex: i,
+				Step:       step,
+				Time:       times[step],
+				Velocity:   velocity,
+				Flam:       steps[step] == StepFlam,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Step < events[j].Step
+	})
+
+	return events
+}
+
+// stepTime returns time at which the step starts, following tempo changes.
+func (p *Pattern) stepTime(step int) time.Duration {
+	return p.stepTimes(step)[step]
+}
+
+// stepTimes returns times at which steps up to and including n start,
+// following tempo changes in a single pass over them.
+func (p *Pattern) stepTimes(n int) []time.Duration {
+	times := make([]time.Duration, n+1)
+
+	tempo, next := p.Tempo, 0
+	for i := 0; i < n; i++ {
+		for next < len(p.TempoChanges) && p.TempoChanges[next].Step <= i {
+			tempo = p.TempoChanges[next].Tempo
+			next++
+		}
+		times[i+1] = times[i] + stepDuration(tempo)
+	}
+
+	return times
+}
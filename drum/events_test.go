@@ -0,0 +1,33 @@
+package drum
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0, 0, 0, 1, 0, 0, 0}},
+			{ID: 1, Name: "snare", Steps: []byte{0, 0, 0, 2, 0, 0, 0, 0}, Offset: 1},
+			{ID: 2, Name: "accent", Steps: []byte{1, 0, 0, 0, 0, 0, 0, 0}},
+		},
+	}
+
+	if d := p.StepDuration(); d != 125*time.Millisecond {
+		t.Fatalf("expected step duration of 125ms, got %v", d)
+	}
+
+	expected := []Event{
+		{TrackIndex: 0, Step: 0, Time: 0, Velocity: AccentVelocity},
+		{TrackIndex: 0, Step: 4, Time: 500 * time.Millisecond, Velocity: DefaultVelocity},
+		{TrackIndex: 1, Step: 4, Time: 500 * time.Millisecond, Velocity: DefaultVelocity, Flam: true},
+	}
+
+	if events := p.Events(); !reflect.DeepEqual(events, expected) {
+		t.Fatalf("wrong events.\nGot:\n%+v\nExpected:\n%+v", events, expected)
+	}
+}
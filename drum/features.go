@@ -0,0 +1,148 @@
+package drum
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// FeatureNames are names of the features returned by Featurize, in order.
+// Densities are ratios of hits to steps of tracks of an instrument class,
+// guessed from their names. Inter-onset intervals are numbers of steps
+// between consecutive steps with hits of any track, wrapping around.
+var FeatureNames = []string{
+	"density-kick",
+	"density-snare",
+	"density-tom",
+	"density-hihat",
+	"density-cymbal",
+	"density-percussion",
+	"density-other",
+	"syncopation",
+	"complexity",
+	"tempo",
+	"ioi-mean",
+	"ioi-stddev",
+	"ioi-min",
+	"ioi-max",
+}
+
+// featureClasses are instrument classes of density features, in order.
+var featureClasses = []instrument{
+	kickInstrument,
+	snareInstrument,
+	tomInstrument,
+	hihatInstrument,
+	cymbalInstrument,
+	percussionInstrument,
+	otherInstrument,
+}
+
+// Featurize returns a vector of numeric features of the pattern, e.g. for
+// clustering or classification, with as many values as FeatureNames.
+// Values aren't normalized: tempo is in BPM and intervals are in steps.
+func Featurize(p *Pattern) []float64 {
+	p.ensureTracks()
+
+	features := make([]float64, 0, len(FeatureNames))
+
+	hits := map[instrument]int{}
+	steps := map[instrument]int{}
+	length := 0
+	for _, track := range p.Tracks {
+		class := classify(track.Name)
+		hits[class] += countHits(track.Unpacked())
+		steps[class] += track.Len()
+		length = max(length, track.Len())
+	}
+
+	for _, class := range featureClasses {
+		density := 0.0
+		if steps[class] > 0 {
+			density = float64(hits[class]) / float64(steps[class])
+		}
+		features = append(features, density)
+	}
+
+	features = append(features, p.Syncopation(), p.Complexity(), float64(p.Tempo))
+
+	return append(features, interOnsetStats(p, length)...)
+}
+
+// interOnsetStats returns the mean, standard deviation, minimum and maximum
+// of intervals between onsets of the pattern, or zeros if it has no hits.
+func interOnsetStats(p *Pattern, length int) []float64 {
+	onsets := make([]bool, length)
+	for _, track := range p.Tracks {
+		for i, step := range track.ShiftedSteps() {
+			onsets[i] = onsets[i] || isHit(step)
+		}
+	}
+
+	var intervals []float64
+	first, last := -1, -1
+	for i, onset := range onsets {
+		if !onset {
+			continue
+		}
+		if last >= 0 {
+			intervals = append(intervals, float64(i-last))
+		} else {
+			first = i
+		}
+		last = i
+	}
+	if first < 0 {
+		return []float64{0, 0, 0, 0}
+	}
+	intervals = append(intervals, float64(length-last+first))
+
+	mean, lo, hi := 0.0, math.Inf(1), 0.0
+	for _, interval := range intervals {
+		mean += interval / float64(len(intervals))
+		lo = math.Min(lo, interval)
+		hi = math.Max(hi, interval)
+	}
+
+	variance := 0.0
+	for _, interval := range intervals {
+		variance += (interval - mean) * (interval - mean) / float64(len(intervals))
+	}
+
+	return []float64{mean, math.Sqrt(variance), lo, hi}
+}
+
+// FeaturizeAll returns features of all patterns, see Featurize, extracted
+// by the number of workers in parallel. Workers default to GOMAXPROCS
+// if not positive.
+func FeaturizeAll(patterns []*Pattern, workers int) [][]float64 {
+	for _, p := range patterns {
+		p.ensureTracks()
+	}
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	features := make([][]float64, len(patterns))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(patterns)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				features[i] = Featurize(patterns[i])
+			}
+		}()
+	}
+
+	for i := range patterns {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return features
+}
@@ -0,0 +1,67 @@
+package drum
+
+import (
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestFeaturize(t *testing.T) {
+	p := &Pattern{
+		Tempo: 120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: stepsFromString("x---x---x---x---")},
+			{ID: 1, Name: "snare", Steps: stepsFromString("----x-------x---")},
+			{ID: 2, Name: "hh-open", Steps: stepsFromString("--x---x---x---x-")},
+		},
+	}
+
+	features := Featurize(p)
+	if len(features) != len(FeatureNames) {
+		t.Fatalf("expected %d features, got %d", len(FeatureNames), len(features))
+	}
+
+	expected := map[string]float64{
+		"density-kick":  0.25,
+		"density-snare": 0.125,
+		"density-hihat": 0.25,
+		"density-tom":   0,
+		"tempo":         120,
+		"ioi-mean":      2,
+		"ioi-stddev":    0,
+		"ioi-min":       2,
+		"ioi-max":       2,
+	}
+	for i, name := range FeatureNames {
+		if value, ok := expected[name]; ok && features[i] != value {
+			t.Errorf("expected %s to be %v, got %v", name, value, features[i])
+		}
+	}
+
+	empty := Featurize(&Pattern{})
+	if len(empty) != len(FeatureNames) {
+		t.Errorf("expected %d features of an empty pattern, got %d", len(FeatureNames), len(empty))
+	}
+}
+
+func TestFeaturizeAll(t *testing.T) {
+	var patterns []*Pattern
+	for _, exp := range tData {
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		patterns = append(patterns, decoded)
+	}
+
+	features := FeaturizeAll(patterns, 2)
+	for i, p := range patterns {
+		if !reflect.DeepEqual(features[i], Featurize(p)) {
+			t.Errorf("features of pattern %d differ from Featurize", i)
+		}
+	}
+
+	if features := FeaturizeAll(nil, 0); len(features) != 0 {
+		t.Errorf("expected no features, got %v", features)
+	}
+}
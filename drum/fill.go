@@ -0,0 +1,145 @@
+package drum
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// beatSteps is the number of steps in a single beat.
+const beatSteps = 4
+
+// GenerateFill returns a copy of the pattern with its last beat (or last two
+// beats for intensity of 0.5 and above) turned into a fill. Snare-like tracks
+// get rolls, tom tracks get a run from the highest to the lowest tom and
+// hi-hats and cymbals are thinned out to make room. Intensity ranges from 0
+// (no changes) to 1 (the densest fill). The same seed always produces the
+// same fill.
+func GenerateFill(p *Pattern, intensity float64, seed int64) *Pattern {
+	p.ensureTracks()
+
+	if intensity < 0 {
+		intensity = 0
+	} else if intensity > 1 {
+		intensity = 1
+	}
+
+	fill := p.Clone()
+	fill.Unpack()
+	if intensity == 0 {
+		return fill
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+
+	fillSteps := beatSteps
+	if intensity >= 0.5 {
+		fillSteps *= 2
+	}
+
+	var snares, toms []int
+
+	for i, track := range fill.Tracks {
+		switch classify(track.Name) {
+		case snareInstrument:
+			snares = append(snares, i)
+		case tomInstrument:
+			toms = append(toms, i)
+		case hihatInstrument, cymbalInstrument:
+			for _, s := range fillWindow(track, fillSteps) {
+				if rnd.Float64() < intensity {
+					track.Steps[s] = 0
+				}
+			}
+		}
+	}
+
+	// With nothing to roll on, fall back to the busiest non-kick track
+	if len(snares) == 0 && len(toms) == 0 {
+		if i := busiestTrack(fill, kickInstrument); i >= 0 {
+			snares = append(snares, i)
+		}
+	}
+
+	for _, i := range snares {
+		track := fill.Tracks[i]
+		for _, s := range fillWindow(track, fillSteps) {
+			if rnd.Float64() < intensity {
+				track.Steps[s] = StepOn
+			}
+		}
+	}
+
+	if len(toms) > 0 {
+		sort.SliceStable(toms, func(a, b int) bool {
+			return tomPitch(fill.Tracks[toms[a]].Name) > tomPitch(fill.Tracks[toms[b]].Name)
+		})
+
+		for n, i := range toms {
+			track := fill.Tracks[i]
+			window := fillWindow(track, fillSteps)
+
+			for k, s := range window {
+				track.Steps[s] = 0
+
+				// Each tom takes its share of the window, highest first
+				if k*len(toms)/len(window) == n && rnd.Float64() < 0.5+intensity/2 {
+					track.Steps[s] = StepOn
+				}
+			}
+		}
+	}
+
+	return fill
+}
+
+// fillWindow returns indexes of the last n steps of the track.
+func fillWindow(track Track, n int) []int {
+	from := len(track.Steps) - n
+	if from < 0 {
+		from = 0
+	}
+
+	window := make([]int, 0, n)
+	for s := from; s < len(track.Steps); s++ {
+		window = append(window, s)
+	}
+
+	return window
+}
+
+// busiestTrack returns index of the track with the most hits, ignoring
+// the accent track and tracks of the excluded instrument class, or -1
+// if no track has any hits.
+func busiestTrack(p *Pattern, exclude instrument) int {
+	busiest, most := -1, 0
+
+	for i, track := range p.Tracks {
+		if track.IsAccent() || classify(track.Name) == exclude {
+			continue
+		}
+
+		hits := countHits(track.Unpacked())
+		if hits > most {
+			busiest, most = i, hits
+		}
+	}
+
+	return busiest
+}
+
+// tomPitch guesses relative pitch of a tom from its name.
+func tomPitch(name string) int {
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.Contains(name, "hi"):
+		return 3
+	case strings.Contains(name, "mid"):
+		return 2
+	case strings.Contains(name, "low"), strings.Contains(name, "floor"):
+		return 1
+	}
+
+	return 0
+}
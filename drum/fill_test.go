@@ -0,0 +1,48 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestGenerateFill(t *testing.T) {
+	decoded, err := DecodeFile(path.Join("fixtures", tData[2].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := decoded.String()
+
+	fill := GenerateFill(decoded, 1, 42)
+
+	if decoded.String() != original {
+		t.Fatal("GenerateFill modified the source pattern")
+	}
+	if fill.String() != GenerateFill(decoded, 1, 42).String() {
+		t.Fatal("GenerateFill is not deterministic for the same seed")
+	}
+
+	// The first half of the pattern is never touched
+	head, _ := fill.Slice(0, 8)
+	expected, _ := decoded.Slice(0, 8)
+	if head.String() != expected.String() {
+		t.Fatalf("fill changed steps outside of the fill window:\n%s", fill)
+	}
+
+	// Toms run from the highest to the lowest
+	first := map[string]int{}
+	for _, track := range fill.Tracks {
+		for s := 8; s < len(track.Steps); s++ {
+			if track.Steps[s] == 1 {
+				first[track.Name] = s
+				break
+			}
+		}
+	}
+	if !(first["hi-tom"] < first["mid-tom"] && first["mid-tom"] < first["low-tom"]) {
+		t.Fatalf("toms don't run from high to low:\n%s", fill)
+	}
+
+	if GenerateFill(decoded, 0, 42).String() != original {
+		t.Fatal("fill with zero intensity changed the pattern")
+	}
+}
@@ -0,0 +1,255 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Exporter writes patterns in a specific format.
+type Exporter interface {
+	Export(w io.Writer, p *Pattern) error
+}
+
+// Importer reads patterns in a specific format.
+type Importer interface {
+	Import(r io.Reader) (*Pattern, error)
+}
+
+// Detector is implemented by importers able to recognize their format.
+type Detector interface {
+	// Detect returns true if data looks like the importer's format.
+	// It's given at most the first detectLength bytes of the input.
+	Detect(data []byte) bool
+}
+
+// detectLength is the length of input's prefix passed to detectors.
+const detectLength = 512
+
+// ExporterFunc is a function used as an Exporter.
+type ExporterFunc func(w io.Writer, p *Pattern) error
+
+// Export calls f(w, p).
+func (f ExporterFunc) Export(w io.Writer, p *Pattern) error {
+	return f(w, p)
+}
+
+// ImporterFunc is a function used as an Importer.
+type ImporterFunc func(r io.Reader) (*Pattern, error)
+
+// Import calls f(r).
+func (f ImporterFunc) Import(r io.Reader) (*Pattern, error) {
+	return f(r)
+}
+
+var (
+	formatsMu sync.RWMutex
+	exporters = map[string]Exporter{}
+	importers = map[string]Importer{}
+)
+
+func init() {
+	RegisterExporter("splice", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		data, err := p.MarshalBinary()
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(data)
+		return err
+	}))
+	RegisterExporter("csv", ExporterFunc(ExportCSV))
+	RegisterExporter("tsv", ExporterFunc(ExportTSV))
+	RegisterExporter("html", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		return ExportHTML(w, p)
+	}))
+	RegisterExporter("svg", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		return ExportSVG(w, p)
+	}))
+	RegisterExporter("markdown", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		_, err := io.WriteString(w, ExportMarkdown(p))
+		return err
+	}))
+	RegisterExporter("tracker", ExporterFunc(ExportTracker))
+	RegisterExporter("musicxml", ExporterFunc(ExportMusicXML))
+	RegisterExporter("midi", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		return ExportMIDI(w, p)
+	}))
+	RegisterExporter("text", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		_, err := io.WriteString(w, p.String())
+		return err
+	}))
+
+	RegisterExporter("mini", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		_, err := fmt.Fprintln(w, ExportMiniNotation(p))
+		return err
+	}))
+	RegisterExporter("events", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		return ExportEventsJSON(w, p)
+	}))
+	RegisterExporter("json", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}))
+
+	RegisterExporter("canonical", ExporterFunc(func(w io.Writer, p *Pattern) error {
+		_, err := io.WriteString(w, FormatCanonical(p))
+		return err
+	}))
+
+	RegisterImporter("canonical", detectingImporter{
+		ImporterFunc(func(r io.Reader) (*Pattern, error) {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+
+			return ParseCanonical(string(data))
+		}),
+		func(data []byte) bool {
+			return bytes.HasPrefix(data, []byte(canonicalHeader+" "))
+		},
+	})
+	RegisterImporter("splice", detectingImporter{
+		ImporterFunc(func(r io.Reader) (*Pattern, error) {
+			return Decode(r)
+		}),
+		func(data []byte) bool {
+			return bytes.HasPrefix(data, []byte(spliceHeader))
+		},
+	})
+	RegisterImporter("csv", detectingImporter{ImporterFunc(ImportCSV), func(data []byte) bool {
+		return bytes.HasPrefix(data, []byte("version,"))
+	}})
+	RegisterImporter("tsv", detectingImporter{ImporterFunc(ImportTSV), func(data []byte) bool {
+		return bytes.HasPrefix(data, []byte("version\t"))
+	}})
+	RegisterImporter("hydrogen", detectingImporter{ImporterFunc(ImportHydrogen), func(data []byte) bool {
+		return bytes.Contains(data, []byte("<song")) &&
+			(bytes.Contains(data, []byte("hydrogen")) || bytes.Contains(data, []byte("<bpm>")))
+	}})
+	RegisterImporter("lmms", detectingImporter{ImporterFunc(ImportLMMS), detectLMMS})
+	RegisterImporter("mini", ImporterFunc(importMiniNotation))
+	// Roland TR-8S and TR-09 backups aren't imported: they're proprietary,
+	// without a public specification or sample files to test against
+	RegisterImporter("json", detectingImporter{
+		ImporterFunc(func(r io.Reader) (*Pattern, error) {
+			p := &Pattern{}
+			err := json.NewDecoder(r).Decode(p)
+			if err != nil {
+				return nil, err
+			}
+
+			return p, nil
+		}),
+		func(data []byte) bool {
+			return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+		},
+	})
+}
+
+// detectingImporter is an Importer with a detect function.
+type detectingImporter struct {
+	Importer
+	detect func(data []byte) bool
+}
+
+func (i detectingImporter) Detect(data []byte) bool {
+	return i.detect(data)
+}
+
+// DetectFormat returns name of the importer recognizing the data's format,
+// asking registered importers implementing Detector in order of their names.
+func DetectFormat(data []byte) (string, bool) {
+	if len(data) > detectLength {
+		data = data[:detectLength]
+	}
+
+	for _, name := range ImporterNames() {
+		i, _ := LookupImporter(name)
+
+		if d, ok := i.(Detector); ok && d.Detect(data) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// RegisterExporter makes an exporter available under the provided name.
+// It panics if the name is already registered.
+func RegisterExporter(name string, e Exporter) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if _, ok := exporters[name]; ok {
+		panic(fmt.Sprintf("drum: exporter %q registered twice", name))
+	}
+	exporters[name] = e
+}
+
+// RegisterImporter makes an importer available under the provided name.
+// It panics if the name is already registered.
+func RegisterImporter(name string, i Importer) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if _, ok := importers[name]; ok {
+		panic(fmt.Sprintf("drum: importer %q registered twice", name))
+	}
+	importers[name] = i
+}
+
+// LookupExporter returns the exporter registered under the provided name.
+func LookupExporter(name string) (Exporter, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	e, ok := exporters[name]
+	return e, ok
+}
+
+// LookupImporter returns the importer registered under the provided name.
+func LookupImporter(name string) (Importer, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	i, ok := importers[name]
+	return i, ok
+}
+
+// ExporterNames returns sorted names of registered exporters.
+func ExporterNames() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ImporterNames returns sorted names of registered importers.
+func ImporterNames() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(importers))
+	for name := range importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
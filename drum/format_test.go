@@ -0,0 +1,81 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"reflect"
+	"testing"
+)
+
+// roundTripFormats are formats with both an importer and an exporter.
+var roundTripFormats = []string{"canonical", "csv", "json", "splice", "tsv"}
+
+func TestFormatRegistry(t *testing.T) {
+	expected := []string{"canonical", "csv", "hydrogen", "json", "lmms", "mini", "splice", "tsv"}
+	if names := ImporterNames(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("unexpected importers %v, expected %v", names, expected)
+	}
+
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range roundTripFormats {
+		exporter, ok := LookupExporter(name)
+		if !ok {
+			t.Fatalf("no exporter for importer %q", name)
+		}
+		importer, _ := LookupImporter(name)
+
+		var buf bytes.Buffer
+		if err := exporter.Export(&buf, decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		imported, err := importer.Import(&buf)
+		if err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+
+		if fmt.Sprint(imported) != tData[0].output {
+			t.Fatalf("%s round trip failed.\nGot:\n%s\nExpected:\n%s", name, imported, tData[0].output)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range roundTripFormats {
+		exporter, _ := LookupExporter(name)
+
+		var buf bytes.Buffer
+		if err := exporter.Export(&buf, decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		detected, ok := DetectFormat(buf.Bytes())
+		if !ok || detected != name {
+			t.Errorf("%s detected as %q", name, detected)
+		}
+	}
+
+	if _, ok := DetectFormat([]byte("MThd")); ok {
+		t.Fatal("unexpected format detected")
+	}
+}
+
+func TestRegisterExporterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+
+	RegisterExporter("csv", ExporterFunc(ExportCSV))
+}
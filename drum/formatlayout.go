@@ -0,0 +1,62 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// FormatLayout sets how Format writes a pattern.
+type FormatLayout struct {
+	// Symbols of steps, those of DefaultTheme if empty
+	On, Flam, Off string
+	// NoGroupers omits separators between beats
+	NoGroupers bool
+	// Compact writes the whole pattern on a single line, e.g. for logs
+	Compact bool
+}
+
+// Common layouts of Format.
+var (
+	DotsLayout    = FormatLayout{On: "●", Flam: "◉", Off: "○"}
+	BinaryLayout  = FormatLayout{On: "1", Flam: "2", Off: "0"}
+	CompactLayout = FormatLayout{Compact: true}
+)
+
+// Format returns text representation of the pattern in the layout. Unless
+// it's compact, the pattern is rendered like String does, with a row per
+// track. Compact layout joins tracks on a single line, e.g.:
+//
+//	0.808-alpha @ 120 BPM: (0) kick |x---|x---|; (1) snare |----|x---|
+func (p *Pattern) Format(layout FormatLayout) string {
+	p.ensureTracks()
+
+	theme := DefaultTheme
+	for _, symbol := range []struct{ layout, theme *string }{
+		{&layout.On, &theme.On},
+		{&layout.Flam, &theme.Flam},
+		{&layout.Off, &theme.Off},
+	} {
+		if *symbol.layout != "" {
+			*symbol.theme = *symbol.layout
+		}
+	}
+	if layout.NoGroupers {
+		theme.Separator = ""
+	}
+
+	if !layout.Compact {
+		return p.Render(RenderOptions{Theme: &theme})
+	}
+
+	rows := make([]string, len(p.Tracks))
+	for i, track := range p.Tracks {
+		var row bytes.Buffer
+		cells, _ := stepSymbols(track.Unpacked(), theme)
+		writeRow(&row, cells, nil, 1, theme.Separator)
+
+		rows[i] = fmt.Sprintf("(%d) %s %s", track.ID, track.Name, strings.TrimSuffix(row.String(), "\n"))
+	}
+
+	return fmt.Sprintf("%s @ %v BPM: %s", p.Version, p.Tempo, strings.Join(rows, "; "))
+}
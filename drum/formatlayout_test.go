@@ -0,0 +1,44 @@
+package drum
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0, 0, 0, 1, 0, 0, 0}},
+			{ID: 1, Name: "snare", Steps: []byte{0, 0, 0, 0, 2, 0, 0, 0}},
+		},
+	}
+
+	for _, test := range []struct {
+		layout   FormatLayout
+		expected string
+	}{
+		{
+			FormatLayout{},
+			p.String(),
+		},
+		{
+			DotsLayout,
+			"Saved with HW Version: 0.808-alpha\nTempo: 120\n(0) kick\t|●○○○|●○○○|\n(1) snare\t|○○○○|◉○○○|\n",
+		},
+		{
+			FormatLayout{On: "1", Off: "0", NoGroupers: true},
+			"Saved with HW Version: 0.808-alpha\nTempo: 120\n(0) kick\t10001000\n(1) snare\t0000f000\n",
+		},
+		{
+			CompactLayout,
+			"0.808-alpha @ 120 BPM: (0) kick |x---|x---|; (1) snare |----|f---|",
+		},
+		{
+			FormatLayout{On: "1", Flam: "2", Off: "0", NoGroupers: true, Compact: true},
+			"0.808-alpha @ 120 BPM: (0) kick 10001000; (1) snare 00002000",
+		},
+	} {
+		if out := p.Format(test.layout); out != test.expected {
+			t.Errorf("wrong output of %+v.\nGot:\n%s\nExpected:\n%s", test.layout, out, test.expected)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package drum
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// spliceExtension is the file extension of drum machine files.
+const spliceExtension = ".splice"
+
+// DecodeFS decodes the drum machine file found at the provided path
+// in the file system. Metadata is read from the file's sidecar, if present.
+func DecodeFS(fsys fs.FS, path string, opts ...Option) (*Pattern, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := decode(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.readSidecar(func(path string) ([]byte, error) {
+		return fs.ReadFile(fsys, path)
+	}, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// DecodeDirFS decodes all .splice files found in the file system
+// under root, recursively. Returned patterns are keyed by their paths.
+func DecodeDirFS(fsys fs.FS, root string, opts ...Option) (map[string]*Pattern, error) {
+	patterns := map[string]*Pattern{}
+
+	err := WalkDirFS(fsys, root, func(p string, pattern *Pattern, err error) error {
+		if err != nil {
+			return fmt.Errorf("%s: %v", p, err)
+		}
+
+		patterns[p] = pattern
+
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+// WalkDirFS calls fn for every .splice file found in the file system under
+// root, recursively in lexical order, with the decoded pattern or the error
+// decoding it, so callers can skip files that can't be decoded. Walking
+// stops at the first error returned by fn or reading directories.
+func WalkDirFS(fsys fs.FS, root string, fn func(path string, p *Pattern, err error) error, opts ...Option) error {
+	return fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || path.Ext(p) != spliceExtension {
+			return nil
+		}
+
+		pattern, err := DecodeFS(fsys, p, opts...)
+		return fn(p, pattern, err)
+	})
+}
@@ -0,0 +1,67 @@
+package drum
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDecodeDirFS(t *testing.T) {
+	patterns, err := DecodeDirFS(os.DirFS("fixtures"), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(patterns) != len(tData) {
+		t.Fatalf("expected %d patterns, got %d", len(tData), len(patterns))
+	}
+
+	for _, exp := range tData {
+		decoded, ok := patterns[exp.path]
+		if !ok {
+			t.Fatalf("%s wasn't decoded", exp.path)
+		}
+		if fmt.Sprint(decoded) != exp.output {
+			t.Fatalf("%s wasn't decoded as expect.\nGot:\n%s\nExpected:\n%s",
+				exp.path, decoded, exp.output)
+		}
+	}
+}
+
+func TestDecodeFSInvalid(t *testing.T) {
+	data, err := os.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{
+		"good.splice":    {Data: data},
+		"notes.txt":      {Data: []byte("not a pattern")},
+		"bad/bad.splice": {Data: []byte("SPLOCE")},
+	}
+
+	if _, err := DecodeFS(fsys, "good.splice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeDirFS(fsys, "."); err == nil {
+		t.Fatal("expected error decoding invalid file")
+	}
+
+	// Walking continues past files that can't be decoded
+	var walked []string
+	err = WalkDirFS(fsys, ".", func(path string, p *Pattern, err error) error {
+		if (err != nil) != (path == "bad/bad.splice") {
+			t.Errorf("unexpected error decoding %s: %v", path, err)
+		}
+		walked = append(walked, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(walked) != "[bad/bad.splice good.splice]" {
+		t.Fatalf("unexpected files walked %v", walked)
+	}
+}
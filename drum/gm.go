@@ -0,0 +1,50 @@
+package drum
+
+import "strings"
+
+// gmNotes maps name fragments to General MIDI percussion notes. Order
+// matters, as the first matching fragment wins.
+var gmNotes = []struct {
+	fragment string
+	note     byte
+}{
+	{"kick", 36},
+	{"rim", 37},
+	{"snare", 38},
+	{"clap", 39},
+	{"hh-open", 46},
+	{"open", 46},
+	{"hh", 42},
+	{"hat", 42},
+	{"low-tom", 45},
+	{"low tom", 45},
+	{"floor", 43},
+	{"mid", 47},
+	{"hi-tom", 50},
+	{"hi tom", 50},
+	{"tom", 47},
+	{"crash", 49},
+	{"ride", 51},
+	{"tamb", 54},
+	{"cowbell", 56},
+	{"low conga", 64},
+	{"conga", 63},
+	{"bongo", 60},
+	{"maracas", 70},
+	{"shaker", 70},
+	{"clave", 75},
+}
+
+// GMNote returns the General MIDI percussion note of a track, guessed
+// from its name, e.g. 36 for "kick". Returns false if no note matches.
+func GMNote(name string) (byte, bool) {
+	name = strings.ToLower(name)
+
+	for _, n := range gmNotes {
+		if strings.Contains(name, n.fragment) {
+			return n.note, true
+		}
+	}
+
+	return 0, false
+}
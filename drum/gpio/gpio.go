@@ -0,0 +1,180 @@
+// Package gpio triggers GPIO pins on hits of played patterns, e.g. to fire
+// solenoids of a percussion robot driven by a Raspberry Pi.
+package gpio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// DefaultPulseWidth is the width of pulses of outputs without one.
+const DefaultPulseWidth = 10 * time.Millisecond
+
+// Pin is an output pin. Implement it to use other GPIO libraries,
+// e.g. periph.io or gobot, than the sysfs interface of SysfsPin.
+type Pin interface {
+	Set(high bool) error
+}
+
+// sysfsRoot is the directory of the sysfs GPIO interface.
+var sysfsRoot = "/sys/class/gpio"
+
+// SysfsPin is a pin driven through the Linux sysfs GPIO interface.
+type SysfsPin struct {
+	value *os.File
+}
+
+// OpenSysfs exports the pin with the number, e.g. a BCM pin number on
+// a Raspberry Pi, and makes it a low output.
+func OpenSysfs(number int) (*SysfsPin, error) {
+	dir := filepath.Join(sysfsRoot, "gpio"+strconv.Itoa(number))
+
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		err = writeSysfs(filepath.Join(sysfsRoot, "export"), strconv.Itoa(number))
+		if err != nil {
+			return nil, fmt.Errorf("can't export pin %d - %v", number, err)
+		}
+	}
+
+	err := writeSysfs(filepath.Join(dir, "direction"), "low")
+	if err != nil {
+		return nil, fmt.Errorf("can't set direction of pin %d - %v", number, err)
+	}
+
+	value, err := os.OpenFile(filepath.Join(dir, "value"), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SysfsPin{value: value}, nil
+}
+
+// writeSysfs writes the value to an existing sysfs file.
+func writeSysfs(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(value)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Set sets the pin's level.
+func (p *SysfsPin) Set(high bool) error {
+	level := []byte("0")
+	if high {
+		level = []byte("1")
+	}
+
+	_, err := p.value.WriteAt(level, 0)
+	return err
+}
+
+// Close closes the pin, leaving it exported.
+func (p *SysfsPin) Close() error {
+	return p.value.Close()
+}
+
+// Output is a pin triggered by hits of a track.
+type Output struct {
+	Pin Pin
+	// Width of pulses of hits at AccentVelocity, DefaultPulseWidth if zero
+	Width time.Duration
+	// ScaleWidth scales widths of pulses by velocities of hits, so
+	// solenoids strike softer hits with less force
+	ScaleWidth bool
+}
+
+// Sink is a sequencer sink raising pins of tracks for a pulse on every
+// hit. A hit during a pulse extends it.
+type Sink struct {
+	outputs map[int]Output
+
+	mu     sync.Mutex
+	timers map[int]*time.Timer
+	// pulses counts pulses of tracks, so ends of extended pulses are ignored
+	pulses map[int]int
+	err    error
+}
+
+// NewSink returns a sink triggering outputs keyed by indexes of tracks.
+// It must be closed once playback ends.
+func NewSink(outputs map[int]Output) *Sink {
+	return &Sink{
+		outputs: outputs,
+		timers:  map[int]*time.Timer{},
+		pulses:  map[int]int{},
+	}
+}
+
+// Trigger raises the pin of the event's track for a pulse.
+func (s *Sink) Trigger(e drum.Event) {
+	output, ok := s.outputs[e.TrackIndex]
+	if !ok {
+		return
+	}
+
+	width := output.Width
+	if width <= 0 {
+		width = DefaultPulseWidth
+	}
+	if output.ScaleWidth {
+		width = width * time.Duration(e.Velocity) / time.Duration(drum.AccentVelocity)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, ok := s.timers[e.TrackIndex]; ok {
+		timer.Stop()
+	}
+
+	s.pulses[e.TrackIndex]++
+	pulse := s.pulses[e.TrackIndex]
+
+	s.setErr(output.Pin.Set(true))
+	s.timers[e.TrackIndex] = time.AfterFunc(width, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.pulses[e.TrackIndex] == pulse {
+			s.setErr(output.Pin.Set(false))
+		}
+	})
+}
+
+// setErr records the first error of setting pins.
+func (s *Sink) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Close ends pulses, lowering all pins, and returns the first error
+// of setting pins, if any.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for track, timer := range s.timers {
+		timer.Stop()
+		s.pulses[track]++
+	}
+	for _, output := range s.outputs {
+		s.setErr(output.Pin.Set(false))
+	}
+
+	return s.err
+}
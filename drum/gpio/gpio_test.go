@@ -0,0 +1,100 @@
+package gpio
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// fakePin records levels it was set to.
+type fakePin struct {
+	mu     sync.Mutex
+	levels []bool
+}
+
+func (p *fakePin) Set(high bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.levels = append(p.levels, high)
+	return nil
+}
+
+func (p *fakePin) Levels() []bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]bool(nil), p.levels...)
+}
+
+func TestSink(t *testing.T) {
+	kick, snare := &fakePin{}, &fakePin{}
+	sink := NewSink(map[int]Output{
+		0: {Pin: kick, Width: time.Millisecond},
+		1: {Pin: snare, Width: time.Hour},
+	})
+
+	sink.Trigger(drum.Event{TrackIndex: 0, Velocity: drum.DefaultVelocity})
+	sink.Trigger(drum.Event{TrackIndex: 1, Velocity: drum.DefaultVelocity})
+	sink.Trigger(drum.Event{TrackIndex: 2, Velocity: drum.DefaultVelocity})
+
+	deadline := time.Now().Add(time.Second)
+	for len(kick.Levels()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if levels := kick.Levels(); len(levels) != 2 || !levels[0] || levels[1] {
+		t.Errorf("expected a pulse of kick, got levels %v", levels)
+	}
+	if levels := snare.Levels(); len(levels) != 1 || !levels[0] {
+		t.Errorf("expected snare to be high, got levels %v", levels)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if levels := snare.Levels(); len(levels) != 2 || levels[1] {
+		t.Errorf("expected snare to be low once closed, got levels %v", levels)
+	}
+}
+
+func TestOpenSysfs(t *testing.T) {
+	root := t.TempDir()
+	defer func(old string) { sysfsRoot = old }(sysfsRoot)
+	sysfsRoot = root
+
+	dir := filepath.Join(root, "gpio17")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"direction", "value"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pin, err := OpenSysfs(17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pin.Close()
+
+	if err := pin.Set(true); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{"direction": "low", "value": "1"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("expected %s to be %q, got %q", name, expected, data)
+		}
+	}
+
+	if _, err := OpenSysfs(18); err == nil {
+		t.Errorf("expected an error opening a pin that can't be exported")
+	}
+}
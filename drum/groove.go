@@ -0,0 +1,181 @@
+package drum
+
+import (
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// grooveTicksPerQuarter is the resolution of written groove templates,
+	// the one used by MPC samplers.
+	grooveTicksPerQuarter = 96
+	// grooveNote is the note of written groove templates, as samplers
+	// ignore notes of templates.
+	grooveNote = 37
+)
+
+// Groove is a timing feel applied to hits of a pattern, e.g. swing,
+// exchanged with samplers as groove templates. Its steps repeat over
+// the pattern's steps.
+type Groove struct {
+	Steps []GrooveStep
+}
+
+// GrooveStep is the feel of a single step.
+type GrooveStep struct {
+	// Offset moves hits on the step by a fraction of the step's duration,
+	// earlier if negative
+	Offset float64
+	// Velocity scales velocities of hits on the step, 1 for no change
+	Velocity float64
+}
+
+// GrooveOnset is a hit of a reference performance a groove is extracted
+// from, e.g. a note of a MIDI file or an onset detected in audio.
+type GrooveOnset struct {
+	// Position in steps from the start, e.g. 4.1 for a hit late
+	// by a tenth of a step on the second beat
+	Position float64
+	// Velocity relative to the usual one, 1 for no change
+	Velocity float64
+}
+
+// SwingGroove returns a groove delaying every second step by amount,
+// a fraction of the step's duration, e.g. 1/3 for triplet swing.
+func SwingGroove(amount float64) Groove {
+	return Groove{Steps: []GrooveStep{{0, 1}, {amount, 1}}}
+}
+
+// ApplyGroove returns events of the pattern with timing and velocities
+// changed by the groove. Events are moved by offsets of their steps,
+// but never before the start of the pattern.
+func ApplyGroove(p *Pattern, g Groove) []Event {
+	p.ensureTracks()
+
+	events := p.Events()
+	if len(g.Steps) == 0 {
+		return events
+	}
+
+	for i, e := range events {
+		step := g.feel(e.Step)
+
+		shift := time.Duration(step.Offset * float64(p.StepDurationAt(e.Step)))
+		events[i].Time = max(e.Time+shift, 0)
+		events[i].Velocity = step.scaleVelocity(e.Velocity)
+	}
+
+	return events
+}
+
+// feel returns the feel of the step, which is unchanged timing and
+// velocity for empty grooves.
+func (g Groove) feel(step int) GrooveStep {
+	if len(g.Steps) == 0 {
+		return GrooveStep{0, 1}
+	}
+
+	return g.Steps[step%len(g.Steps)]
+}
+
+// scaleVelocity returns velocity scaled by the step's feel.
+func (s GrooveStep) scaleVelocity(velocity byte) byte {
+	scaled := math.Round(float64(velocity) * s.Velocity)
+	return byte(max(min(scaled, maxVelocity), 1))
+}
+
+// ReadGrooveMIDI reads a groove template from a standard MIDI file,
+// like the ones of MPC samplers and Logic. Notes are quantized to the
+// nearest step, then their offsets from it and velocities relative to
+// DefaultVelocity become the feel of the step, averaged if there are more
+// notes per step. Steps without notes are left as they are. The groove
+// lasts whole bars.
+func ReadGrooveMIDI(r io.Reader) (Groove, error) {
+	notes, ticksPerQuarter, err := readSMF(r)
+	if err != nil {
+		return Groove{}, err
+	}
+	if len(notes) == 0 {
+		return Groove{}, errors.New("no notes in groove template")
+	}
+
+	stepTicks := float64(ticksPerQuarter) / beatSteps
+
+	onsets := make([]GrooveOnset, len(notes))
+	for i, note := range notes {
+		onsets[i] = GrooveOnset{
+			Position: float64(note.Tick) / stepTicks,
+			Velocity: float64(note.Velocity) / float64(DefaultVelocity),
+		}
+	}
+
+	return GrooveFromOnsets(onsets), nil
+}
+
+// GrooveFromOnsets returns the groove of a reference performance. Onsets
+// are quantized to the nearest step, then their offsets from it and
+// velocities become the feel of the step, averaged if there are more
+// onsets per step. Steps without onsets are left as they are. The groove
+// lasts whole bars.
+func GrooveFromOnsets(onsets []GrooveOnset) Groove {
+	type feel struct {
+		offset, velocity float64
+		notes            int
+	}
+	feels := map[int]*feel{}
+	last := 0
+
+	for _, onset := range onsets {
+		step := max(int(math.Round(onset.Position)), 0)
+
+		f, ok := feels[step]
+		if !ok {
+			f = &feel{}
+			feels[step] = f
+		}
+		f.offset += onset.Position - float64(step)
+		f.velocity += onset.Velocity
+		f.notes++
+
+		last = max(last, step)
+	}
+
+	bars := last/barSteps + 1
+	g := Groove{Steps: make([]GrooveStep, bars*barSteps)}
+	for i := range g.Steps {
+		g.Steps[i] = GrooveStep{0, 1}
+
+		if f, ok := feels[i]; ok {
+			g.Steps[i] = GrooveStep{f.offset / float64(f.notes), f.velocity / float64(f.notes)}
+		}
+	}
+
+	return g
+}
+
+// WriteGrooveMIDI writes the groove as a template for samplers: a standard
+// MIDI file with a note on every step, moved by its offset and played
+// with DefaultVelocity scaled by the step's velocity.
+func WriteGrooveMIDI(w io.Writer, g Groove) error {
+	if len(g.Steps) == 0 {
+		return errors.New("empty groove")
+	}
+
+	stepTicks := grooveTicksPerQuarter / beatSteps
+
+	notes := make([]smfNote, len(g.Steps))
+	for i, step := range g.Steps {
+		tick := i*stepTicks + int(math.Round(step.Offset*float64(stepTicks)))
+		velocity := math.Round(step.Velocity * float64(DefaultVelocity))
+
+		notes[i] = smfNote{
+			Tick:     max(tick, 0),
+			Note:     grooveNote,
+			Velocity: byte(max(min(velocity, maxVelocity), 1)),
+		}
+	}
+
+	return writeSMF(w, smfNoteMessages(notes, stepTicks/2), grooveTicksPerQuarter)
+}
@@ -0,0 +1,83 @@
+package drum
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGrooveMIDIRoundTrip(t *testing.T) {
+	g := Groove{Steps: make([]GrooveStep, barSteps)}
+	for i := range g.Steps {
+		g.Steps[i] = GrooveStep{0, 1}
+	}
+	g.Steps[1] = GrooveStep{0.25, 0.5}
+	g.Steps[3] = GrooveStep{-0.25, 1.2}
+
+	var buf bytes.Buffer
+	if err := WriteGrooveMIDI(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadGrooveMIDI(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(read.Steps) != len(g.Steps) {
+		t.Fatalf("read groove of %d steps, expected %d", len(read.Steps), len(g.Steps))
+	}
+	for i, step := range read.Steps {
+		expected := g.Steps[i]
+		if math.Abs(step.Offset-expected.Offset) > 0.01 || math.Abs(step.Velocity-expected.Velocity) > 0.01 {
+			t.Errorf("step %d read as %+v, expected %+v", i, step, expected)
+		}
+	}
+}
+
+func TestReadGrooveMIDIInvalid(t *testing.T) {
+	if _, err := ReadGrooveMIDI(bytes.NewReader([]byte("RIFF...."))); err == nil {
+		t.Fatal("expected an error reading a file that isn't MIDI")
+	}
+}
+
+func TestApplyGroove(t *testing.T) {
+	p := &Pattern{
+		Tempo:  120,
+		Tracks: []Track{{Name: "hh", Steps: stepsFromString("xxxx")}},
+	}
+
+	events := ApplyGroove(p, SwingGroove(0.5))
+
+	// Steps of 125ms, every second one delayed by half of it
+	for i, expected := range []time.Duration{0, 187500, 250000, 437500} {
+		if events[i].Time != expected*time.Microsecond {
+			t.Errorf("event %d at %v, expected %v", i, events[i].Time, expected*time.Microsecond)
+		}
+	}
+}
+
+func TestExportMIDIGroove(t *testing.T) {
+	p := &Pattern{Tempo: 120, Tracks: []Track{{Name: "kick", Steps: []byte{1, 1, 1, 1}}}}
+
+	var straight, swung bytes.Buffer
+	if err := ExportMIDI(&straight, p); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportMIDI(&swung, p, WithGroove(SwingGroove(1.0/3))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Groove templates are read back quantized to steps
+	g, err := ReadGrooveMIDI(&swung)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(g.Steps[1].Offset-1.0/3) > 0.05 || g.Steps[0].Offset != 0 {
+		t.Fatalf("MIDI export not locked to groove: %+v", g.Steps[:4])
+	}
+	if bytes.Equal(straight.Bytes(), swung.Bytes()) {
+		t.Fatal("groove didn't change the export")
+	}
+}
@@ -0,0 +1,123 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// headerInfoLength is the number of bytes preceding the first track.
+const headerInfoLength = headerLength + 8 + versionMaxLength + 4
+
+// headerInfoBufferSize is the size of the buffer DecodeHeaderInfo reads
+// through, small as most of the data is skipped.
+const headerInfoBufferSize = 256
+
+// DecodeHeaderInfo reads the version, tempo and number of tracks of the
+// drum machine file read from r, without parsing tracks. Names and steps
+// of tracks are skipped, so it's much faster than Decode for catalog
+// scans. Only WithStepWidth of the options is used.
+func DecodeHeaderInfo(r io.Reader, opts ...Option) (version string, tempo BPM, trackCount int, err error) {
+	var config decodeConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	br := bufio.NewReaderSize(r, headerInfoBufferSize)
+
+	var prefix [headerInfoLength]byte
+	_, err = io.ReadFull(br, prefix[:])
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	if string(prefix[:headerLength]) != spliceHeader {
+		return "", 0, 0, errInvalidHeader
+	}
+
+	length := binary.BigEndian.Uint64(prefix[headerLength:])
+
+	raw := prefix[headerLength+8 : headerLength+8+versionMaxLength]
+	if n := bytes.IndexByte(raw, 0); n >= 0 {
+		raw = raw[:n]
+	}
+	version = string(raw)
+
+	tempo = BPM(math.Float32frombits(binary.LittleEndian.Uint32(prefix[headerInfoLength-4:])))
+
+	if length < versionMaxLength+4 {
+		return version, tempo, 0, nil
+	}
+
+	trackCount, err = countTracks(br, length-versionMaxLength-4, config.stepWidthOrDefault())
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	return version, tempo, trackCount, nil
+}
+
+// countTracks counts tracks in length bytes of track data read from r,
+// skipping their names and steps.
+func countTracks(r *bufio.Reader, length uint64, stepWidth int) (int, error) {
+	var header [5]byte
+	count := 0
+
+	for read := uint64(0); read < length; count++ {
+		_, err := io.ReadFull(r, header[:])
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		skip := int(binary.BigEndian.Uint32(header[1:])) + stepWidth
+		_, err = r.Discard(skip)
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		read += uint64(len(header)) + uint64(skip)
+	}
+
+	return count, nil
+}
+
+// scanTrackCount counts tracks in data from offset up to end by skipping
+// from a track header to the next one. A truncated last track is counted.
+func scanTrackCount(data []byte, offset, end uint64, stepWidth int) int {
+	end = min(end, uint64(len(data)))
+	count := 0
+
+	for offset < end {
+		count++
+
+		if offset+5 > end {
+			break
+		}
+
+		offset += 5 + uint64(binary.BigEndian.Uint32(data[offset+1:])) + uint64(stepWidth)
+	}
+
+	return count
+}
+
+// preallocateTracks makes room for count tracks, limited by the maximum
+// number of tracks, unless there already is.
+func (p *Pattern) preallocateTracks(count int) {
+	if p.config.maxTracks > 0 {
+		count = min(count, p.config.maxTracks)
+	}
+
+	if cap(p.Tracks)-len(p.Tracks) < count {
+		tracks := make([]Track, len(p.Tracks), len(p.Tracks)+count)
+		copy(tracks, p.Tracks)
+		p.Tracks = tracks
+	}
+}
@@ -0,0 +1,56 @@
+package drum
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDecodeHeaderInfo(t *testing.T) {
+	for _, exp := range tData {
+		f, err := os.Open(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		version, tempo, tracks, err := DecodeHeaderInfo(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("decoding header info of %s failed - %v", exp.path, err)
+		}
+
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if version != decoded.Version || tempo != decoded.BPM() || tracks != len(decoded.Tracks) {
+			t.Errorf("%s: got %q @ %v with %d tracks, expected %q @ %v with %d tracks", exp.path,
+				version, tempo, tracks, decoded.Version, decoded.Tempo, len(decoded.Tracks))
+		}
+	}
+}
+
+func TestDecodeHeaderInfoTruncated(t *testing.T) {
+	data, err := os.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := DecodeHeaderInfo(bytes.NewReader(data[:len(data)-10])); err == nil {
+		t.Fatal("expected an error for truncated tracks")
+	}
+	if _, _, _, err := DecodeHeaderInfo(bytes.NewReader([]byte("SPLICE"))); err == nil {
+		t.Fatal("expected an error for a truncated header")
+	}
+}
+
+func BenchmarkDecodeHeaderInfo(b *testing.B) {
+	data := benchmarkData(b)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		DecodeHeaderInfo(bytes.NewReader(data))
+	}
+}
@@ -0,0 +1,128 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// hexLineBytes is the number of bytes printed in a single hex dump line.
+const hexLineBytes = 16
+
+// AnnotateHex writes a hex dump of a .splice file with every field
+// annotated: header, length, version, tempo and each track's ID, name and
+// steps, followed by any trailing data. Truncated fields are marked,
+// so the dump is useful for debugging corrupted files as well.
+func AnnotateHex(w io.Writer, data []byte) error {
+	a := &hexAnnotator{w: w, data: data}
+
+	a.field(headerLength, "header", func(f []byte) string {
+		return fmt.Sprintf("header %q", f)
+	})
+
+	end := len(data)
+	a.field(lengthSize, "length", func(f []byte) string {
+		length := binary.BigEndian.Uint64(f)
+		if length < uint64(len(data)-contentOffset) {
+			end = contentOffset + int(length)
+		}
+		return fmt.Sprintf("length %d", length)
+	})
+
+	a.field(versionMaxLength, "version", func(f []byte) string {
+		if n := bytes.IndexByte(f, 0); n >= 0 {
+			f = f[:n]
+		}
+		return fmt.Sprintf("version %q", f)
+	})
+
+	a.field(tempoSize, "tempo", func(f []byte) string {
+		return fmt.Sprintf("tempo %v", math.Float32frombits(binary.LittleEndian.Uint32(f)))
+	})
+
+	for track := 0; a.offset < end; track++ {
+		prefix := fmt.Sprintf("track %d ", track)
+
+		a.field(1, prefix+"id", func(f []byte) string {
+			return fmt.Sprintf("%sid %d", prefix, f[0])
+		})
+
+		nameLength := 0
+		a.field(4, prefix+"name length", func(f []byte) string {
+			nameLength = int(binary.BigEndian.Uint32(f))
+			return fmt.Sprintf("%sname length %d", prefix, nameLength)
+		})
+
+		a.field(nameLength, prefix+"name", func(f []byte) string {
+			return fmt.Sprintf("%sname %q", prefix, f)
+		})
+
+		a.field(trackSteps, prefix+"steps", func(f []byte) string {
+			return prefix + "steps " + stepsNotation(f)
+		})
+	}
+
+	if a.offset < len(data) {
+		a.field(len(data)-a.offset, fmt.Sprintf("trailing data (%d bytes)", len(data)-a.offset), nil)
+	}
+
+	return a.err
+}
+
+// hexAnnotator writes annotated hex dump lines field by field.
+type hexAnnotator struct {
+	w      io.Writer
+	data   []byte
+	offset int
+	err    error
+}
+
+// field consumes next size bytes of data and writes them, annotating
+// the first line. Complete fields are annotated by annotate, if provided.
+// Fields cut short by the end of data are marked as truncated.
+func (a *hexAnnotator) field(size int, name string, annotate func(field []byte) string) {
+	if a.offset >= len(a.data) {
+		return
+	}
+
+	end := a.offset + size
+	annotation := name
+
+	if end > len(a.data) || end < a.offset {
+		end = len(a.data)
+		annotation += " (truncated)"
+	} else if annotate != nil {
+		annotation = annotate(a.data[a.offset:end])
+	}
+
+	for i := a.offset; i < end || i == a.offset; i += hexLineBytes {
+		line := a.data[i:min(i+hexLineBytes, end)]
+
+		hex := make([]string, len(line))
+		for j, b := range line {
+			hex[j] = fmt.Sprintf("%02x", b)
+		}
+
+		a.writeLine(fmt.Sprintf("%08x  %-47s  %s", i, strings.Join(hex, " "), annotation))
+		annotation = ""
+	}
+
+	a.offset = end
+}
+
+func (a *hexAnnotator) writeLine(line string) {
+	if a.err == nil {
+		_, a.err = fmt.Fprintln(a.w, strings.TrimRight(line, " "))
+	}
+}
+
+// stepsNotation returns steps in x/- notation grouped by beats.
+func stepsNotation(steps []byte) string {
+	var buffer bytes.Buffer
+	writeRow(&buffer, plainStepSymbols(steps), nil, 1, "|")
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
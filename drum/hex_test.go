@@ -0,0 +1,50 @@
+package drum
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func TestAnnotateHex(t *testing.T) {
+	data, err := ioutil.ReadFile(path.Join("fixtures", tData[4].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := AnnotateHex(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `00000000  53 50 4c 49 43 45                                header "SPLICE"
+00000006  00 00 00 00 00 00 00 57                          length 87
+0000000e  30 2e 37 30 38 2d 61 6c 70 68 61 00 00 00 00 00  version "0.708-alpha"
+0000001e  00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00
+0000002e  00 c0 79 44                                      tempo 999
+00000032  01                                               track 0 id 1
+00000033  00 00 00 04                                      track 0 name length 4
+00000037  4b 69 63 6b                                      track 0 name "Kick"
+0000003b  01 00 00 00 00 00 00 00 01 00 00 00 00 00 00 00  track 0 steps |x---|----|x---|----|
+0000004b  02                                               track 1 id 2
+0000004c  00 00 00 05                                      track 1 name length 5
+00000050  48 69 48 61 74                                   track 1 name "HiHat"
+00000055  01 00 01 00 01 00 01 00 01 00 01 00 01 00 01 00  track 1 steps |x-x-|x-x-|x-x-|x-x-|
+00000065  53 50 4c 49 43 45 00 00 00 05 48 69 48 61 74 01  trailing data (31 bytes)
+00000075  00 01 00 01 00 01 00 01 00 01 00 01 00 01 00
+`
+	if buf.String() != expected {
+		t.Fatalf("wrong hex dump.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := AnnotateHex(&buf, data[:0x3d]); err != nil {
+		t.Fatal(err)
+	}
+
+	last := "0000003b  01 00                                            track 0 steps (truncated)\n"
+	if !bytes.HasSuffix(buf.Bytes(), []byte(last)) {
+		t.Fatalf("truncated field wasn't marked:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,93 @@
+package drum
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+)
+
+//go:embed export.html
+var exportHTML string
+
+var htmlTemplate = template.Must(template.New("export.html").Parse(exportHTML))
+
+// htmlPattern is the JSON representation of a pattern used by the HTML player.
+type htmlPattern struct {
+	Version string      `json:"version"`
+	Tempo   float32     `json:"tempo"`
+	Tracks  []htmlTrack `json:"tracks"`
+	// Time between a flam's grace note and main hit, in seconds
+	FlamSpacing float64 `json:"flamSpacing"`
+}
+
+type htmlTrack struct {
+	ID      byte    `json:"id"`
+	Name    string  `json:"name"`
+	Display Display `json:"display"`
+	Accent  bool    `json:"accent"`
+	Steps   []bool  `json:"steps"`
+	Flams   []bool  `json:"flams"`
+	// Velocities of steps, from 0 to 1
+	Velocities []float64  `json:"velocities"`
+	Notes      []htmlNote `json:"notes,omitempty"`
+}
+
+// htmlNote is a note marked in the grid and listed below it.
+type htmlNote struct {
+	Number int `json:"number"`
+	// Step as displayed, with offset applied
+	Step int    `json:"step"`
+	Text string `json:"text"`
+}
+
+// ExportHTML writes a standalone HTML page showing the pattern's grid,
+// with a step sequencer playing it in the browser. Notes attached to steps
+// are marked in the grid and listed as footnotes.
+func ExportHTML(w io.Writer, p *Pattern, opts ...ExportOption) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	config := newExportConfig(opts)
+
+	data := htmlPattern{
+		Version:     p.Version,
+		Tempo:       p.Tempo,
+		Tracks:      make([]htmlTrack, len(p.Tracks)),
+		FlamSpacing: config.flamSpacing.Seconds(),
+	}
+
+	for i, track := range p.Tracks {
+		steps := make([]bool, track.Len())
+		flams := make([]bool, track.Len())
+		velocities := make([]float64, track.Len())
+		trackVelocities := p.trackVelocities(i)
+		for s, step := range track.ShiftedSteps() {
+			steps[s] = isHit(step)
+			flams[s] = step == StepFlam
+			velocities[s] = float64(trackVelocities[s]) / float64(AccentVelocity)
+		}
+
+		data.Tracks[i] = htmlTrack{
+			ID:         track.ID,
+			Name:       track.Name,
+			Display:    track.Display,
+			Accent:     track.IsAccent(),
+			Steps:      steps,
+			Flams:      flams,
+			Velocities: velocities,
+		}
+	}
+
+	footnotes := p.footnotes()
+	for _, f := range footnotes {
+		track := &data.Tracks[f.TrackIndex]
+		track.Notes = append(track.Notes, htmlNote{Number: f.Number, Step: f.Position, Text: f.Text})
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		Pattern   htmlPattern
+		Theme     Theme
+		Footnotes []footnote
+	}{data, config.theme, footnotes})
+}
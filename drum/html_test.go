@@ -0,0 +1,55 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportHTML(t *testing.T) {
+	decoded, err := DecodeFile(path.Join("fixtures", tData[3].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportHTML(&buf, decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`<title>0.909 @ 240 BPM</title>`,
+		`const pattern = {"version":"0.909","tempo":240,"tracks":[{"id":0,"name":"SubKick"`,
+		`{"id":255,"name":"Low Conga","display":{},"accent":false,"steps":[false,false,false,false,true,`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("exported HTML doesn't contain %q", expected)
+		}
+	}
+}
+
+func TestExportHTMLFlams(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 1, Name: "snare", Steps: []byte{StepOff, StepFlam, StepOn, StepOff}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportHTML(&buf, p, WithFlamSpacing(30*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`"steps":[false,true,true,false],"flams":[false,true,false,false],`,
+		`"flamSpacing":0.03}`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("exported HTML doesn't contain %q", expected)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package drum
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// importTicksPerStep is the number of ticks in a sixteenth step in
+// Hydrogen and LMMS projects, both using 48 ticks per quarter note.
+const importTicksPerStep = 12
+
+// hydrogenSong is the part of a Hydrogen .h2song file needed to import it.
+type hydrogenSong struct {
+	Version     string  `xml:"version"`
+	BPM         float32 `xml:"bpm"`
+	Instruments []struct {
+		ID   int    `xml:"id"`
+		Name string `xml:"name"`
+	} `xml:"instrumentList>instrument"`
+	Patterns []struct {
+		Size  int `xml:"size"`
+		Notes []struct {
+			Position   int `xml:"position"`
+			Instrument int `xml:"instrument"`
+		} `xml:"noteList>note"`
+	} `xml:"patternList>pattern"`
+}
+
+// ImportHydrogen reads the first pattern of a Hydrogen .h2song file.
+// Every instrument of the song becomes a track. Notes are quantized to
+// sixteenth steps and their velocities are ignored.
+func ImportHydrogen(r io.Reader) (*Pattern, error) {
+	var song hydrogenSong
+	err := xml.NewDecoder(r).Decode(&song)
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong decoding Hydrogen song - %v", err)
+	}
+
+	if len(song.Patterns) == 0 {
+		return nil, errors.New("no patterns in Hydrogen song")
+	}
+	pattern := song.Patterns[0]
+
+	p := &Pattern{
+		Version: importVersion("hydrogen-" + song.Version),
+		Tempo:   song.BPM,
+	}
+
+	steps := pattern.Size / importTicksPerStep
+	tracks := map[int]int{}
+	for _, instrument := range song.Instruments {
+		if instrument.ID < 0 || instrument.ID > math.MaxUint8 {
+			return nil, fmt.Errorf("invalid instrument id %d", instrument.ID)
+		}
+
+		tracks[instrument.ID] = len(p.Tracks)
+		p.Tracks = append(p.Tracks, Track{
+			ID:    byte(instrument.ID),
+			Name:  instrument.Name,
+			Steps: make([]byte, steps),
+		})
+	}
+
+	for _, note := range pattern.Notes {
+		i, ok := tracks[note.Instrument]
+		if !ok {
+			return nil, fmt.Errorf("note of unknown instrument %d", note.Instrument)
+		}
+
+		if step := quantizeTicks(note.Position); step >= 0 && step < steps {
+			p.Tracks[i].Steps[step] = StepOn
+		}
+	}
+
+	return p, nil
+}
+
+// quantizeTicks returns the step nearest to the position in ticks.
+func quantizeTicks(position int) int {
+	return int(math.Floor(float64(position)/importTicksPerStep + 0.5))
+}
+
+// importVersion returns the version cut to fit in a .splice file.
+func importVersion(version string) string {
+	if len(version) >= versionMaxLength {
+		version = version[:versionMaxLength-1]
+	}
+
+	return version
+}
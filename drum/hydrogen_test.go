@@ -0,0 +1,50 @@
+package drum
+
+import (
+	"strings"
+	"testing"
+)
+
+const hydrogenSongData = `<?xml version="1.0" encoding="UTF-8"?>
+<song xmlns="http://www.hydrogen-music.org/song">
+ <version>0.9.7</version>
+ <bpm>96.5</bpm>
+ <instrumentList>
+  <instrument><id>0</id><name>Kick</name></instrument>
+  <instrument><id>1</id><name>Snare</name></instrument>
+  <instrument><id>2</id><name>Hat</name></instrument>
+ </instrumentList>
+ <patternList>
+  <pattern>
+   <name>Beat</name>
+   <size>96</size>
+   <noteList>
+    <note><position>0</position><velocity>0.8</velocity><instrument>0</instrument></note>
+    <note><position>48</position><velocity>1</velocity><instrument>1</instrument></note>
+    <note><position>25</position><velocity>0.5</velocity><instrument>2</instrument></note>
+    <note><position>96</position><velocity>0.5</velocity><instrument>2</instrument></note>
+   </noteList>
+  </pattern>
+ </patternList>
+</song>`
+
+func TestImportHydrogen(t *testing.T) {
+	if format, ok := DetectFormat([]byte(hydrogenSongData)); !ok || format != "hydrogen" {
+		t.Fatalf("Hydrogen song detected as %q", format)
+	}
+
+	p, err := ImportHydrogen(strings.NewReader(hydrogenSongData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: hydrogen-0.9.7
+Tempo: 96.5
+(0) Kick	|x---|----|
+(1) Snare	|----|x---|
+(2) Hat	|--x-|----|
+`
+	if out := p.String(); out != expected {
+		t.Fatalf("wrong import.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+}
@@ -0,0 +1,54 @@
+package drum
+
+import "strings"
+
+// instrument is a rough class of a track's sound, guessed from its name.
+type instrument int
+
+const (
+	otherInstrument instrument = iota
+	kickInstrument
+	snareInstrument
+	tomInstrument
+	hihatInstrument
+	cymbalInstrument
+	percussionInstrument
+)
+
+// instrumentNames maps name fragments to instrument classes. Order matters,
+// as the first matching fragment wins.
+var instrumentNames = []struct {
+	fragment   string
+	instrument instrument
+}{
+	{"kick", kickInstrument},
+	{"snare", snareInstrument},
+	{"clap", snareInstrument},
+	{"rim", snareInstrument},
+	{"tom", tomInstrument},
+	{"hh", hihatInstrument},
+	{"hat", hihatInstrument},
+	{"cymbal", cymbalInstrument},
+	{"crash", cymbalInstrument},
+	{"ride", cymbalInstrument},
+	{"cowbell", percussionInstrument},
+	{"conga", percussionInstrument},
+	{"bongo", percussionInstrument},
+	{"maracas", percussionInstrument},
+	{"shaker", percussionInstrument},
+	{"clave", percussionInstrument},
+	{"tamb", percussionInstrument},
+}
+
+// classify guesses the instrument class of a track by its name.
+func classify(name string) instrument {
+	name = strings.ToLower(name)
+
+	for _, n := range instrumentNames {
+		if strings.Contains(name, n.fragment) {
+			return n.instrument
+		}
+	}
+
+	return otherInstrument
+}
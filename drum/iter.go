@@ -0,0 +1,29 @@
+package drum
+
+import "iter"
+
+// TracksSeq returns an iterator over indexes of the pattern's tracks and
+// pointers to them, so tracks can be modified in place.
+func (p *Pattern) TracksSeq() iter.Seq2[int, *Track] {
+	p.ensureTracks()
+
+	return func(yield func(int, *Track) bool) {
+		for i := range p.Tracks {
+			if !yield(i, &p.Tracks[i]) {
+				return
+			}
+		}
+	}
+}
+
+// StepsSeq returns an iterator over indexes of the track's steps and
+// whether they're played. The track's offset isn't applied.
+func (t Track) StepsSeq() iter.Seq2[int, bool] {
+	return func(yield func(int, bool) bool) {
+		for i, step := range t.Unpacked() {
+			if !yield(i, isHit(step)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package drum
+
+import "testing"
+
+func TestTracksSeq(t *testing.T) {
+	p := &Pattern{Tracks: []Track{
+		{Name: "kick", Steps: []byte{1, 0, 2, 0}},
+		{Name: "snare", Steps: []byte{0, 1, 0, 1}},
+	}}
+
+	for i, track := range p.TracksSeq() {
+		if i == 1 {
+			track.Name = "clap"
+			break
+		}
+	}
+	if p.Tracks[1].Name != "clap" {
+		t.Fatalf("track wasn't modified in place: %+v", p.Tracks)
+	}
+
+	var hits []int
+	for i, hit := range p.Tracks[0].StepsSeq() {
+		if hit {
+			hits = append(hits, i)
+		}
+	}
+	if len(hits) != 2 || hits[0] != 0 || hits[1] != 2 {
+		t.Fatalf("unexpected hits %v", hits)
+	}
+}
@@ -0,0 +1,142 @@
+package drum
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonPattern is the JSON representation of a pattern.
+type jsonPattern struct {
+	Version      string        `json:"version"`
+	Tempo        float32       `json:"tempo"`
+	TempoChanges []TempoChange `json:"tempoChanges,omitempty"`
+	Scene        string        `json:"scene,omitempty"`
+	Tracks       []jsonTrack   `json:"tracks"`
+}
+
+type jsonTrack struct {
+	ID         byte              `json:"id"`
+	Name       string            `json:"name"`
+	Steps      []int             `json:"steps"`
+	Offset     int               `json:"offset,omitempty"`
+	Display    *Display          `json:"display,omitempty"`
+	Notes      []Note            `json:"notes,omitempty"`
+	Automation []Lane            `json:"automation,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Muted      bool              `json:"muted,omitempty"`
+	Scenes     map[string]string `json:"scenes,omitempty"`
+}
+
+// MarshalJSON returns the pattern as JSON, e.g.:
+//
+//	{"version": "0.808-alpha", "tempo": 120, "tracks": [
+//		{"id": 0, "name": "kick", "steps": [1, 0, 0, 0, ...]}
+//	]}
+//
+// Steps hold values of StepOff, StepOn and StepFlam. Tracks also hold
+// their offset, display metadata, notes, automation, tags, muting and
+// steps of inactive scenes, if set.
+func (p *Pattern) MarshalJSON() ([]byte, error) {
+	if err := p.ensureTracks(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(toJSONPattern(p))
+}
+
+// UnmarshalJSON loads pattern attributes from JSON written by MarshalJSON.
+func (p *Pattern) UnmarshalJSON(data []byte) error {
+	var jp jsonPattern
+	err := json.Unmarshal(data, &jp)
+	if err != nil {
+		return err
+	}
+
+	return p.fromJSONPattern(jp)
+}
+
+func toJSONPattern(p *Pattern) jsonPattern {
+	jp := jsonPattern{
+		Version:      p.Version,
+		Tempo:        p.Tempo,
+		TempoChanges: p.TempoChanges,
+		Scene:        p.scene,
+		Tracks:       make([]jsonTrack, len(p.Tracks)),
+	}
+
+	for i, track := range p.Tracks {
+		steps := make([]int, track.Len())
+		for j, step := range track.Unpacked() {
+			steps[j] = int(step)
+		}
+
+		jp.Tracks[i] = jsonTrack{
+			ID:         track.ID,
+			Name:       track.Name,
+			Steps:      steps,
+			Offset:     track.Offset,
+			Notes:      track.Notes,
+			Automation: track.Automation,
+			Tags:       track.Tags,
+			Muted:      track.Muted,
+			Scenes:     sceneMetadata(track),
+		}
+
+		if track.Display != (Display{}) {
+			display := track.Display
+			jp.Tracks[i].Display = &display
+		}
+	}
+
+	return jp
+}
+
+func (p *Pattern) fromJSONPattern(jp jsonPattern) error {
+	*p = Pattern{
+		Version: jp.Version,
+		Tempo:   jp.Tempo,
+		Tracks:  make([]Track, len(jp.Tracks)),
+		scene:   jp.Scene,
+	}
+
+	for _, change := range jp.TempoChanges {
+		p.SetTempoChange(change.Step, change.Tempo)
+	}
+
+	for i, track := range jp.Tracks {
+		steps := make([]byte, len(track.Steps))
+		for j, step := range track.Steps {
+			if step < int(StepOff) || step > int(StepFlam) {
+				return fmt.Errorf("invalid value %d of step %d in track %q", step, j, track.Name)
+			}
+			steps[j] = byte(step)
+		}
+
+		p.Tracks[i] = Track{
+			ID:     track.ID,
+			Name:   track.Name,
+			Steps:  steps,
+			Offset: track.Offset,
+			Muted:  track.Muted,
+		}
+
+		if track.Display != nil {
+			p.Tracks[i].Display = *track.Display
+		}
+		for _, note := range track.Notes {
+			p.Tracks[i].Annotate(note.Step, note.Text)
+		}
+		applyAutomation(&p.Tracks[i], track.Automation)
+		p.Tracks[i].Tag(track.Tags...)
+
+		for name, notation := range track.Scenes {
+			steps, err := parsePlainSteps(notation)
+			if err != nil {
+				return fmt.Errorf("scene %s of track %q - %v", name, track.Name, err)
+			}
+			p.SetSceneSteps(i, name, steps)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package drum
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, exp := range tData {
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := json.Marshal(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		imported := &Pattern{}
+		if err := json.Unmarshal(data, imported); err != nil {
+			t.Fatalf("something went wrong unmarshaling %s - %v", exp.path, err)
+		}
+
+		if fmt.Sprint(imported) != exp.output {
+			t.Fatalf("%s wasn't unmarshaled as expected.\nGot:\n%s\nExpected:\n%s",
+				exp.path, imported, exp.output)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 1, Name: "kick", Steps: []byte{1, 0, 2, 0}, Display: Display{Color: "red"}},
+		},
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"version":"0.808-alpha","tempo":120,"tracks":[{"id":1,"name":"kick","steps":[1,0,2,0],"display":{"color":"red"}}]}`
+	if string(data) != expected {
+		t.Fatalf("unexpected JSON.\nGot:\n%s\nExpected:\n%s", data, expected)
+	}
+
+	err = json.Unmarshal([]byte(`{"tracks":[{"id":1,"name":"kick","steps":[3]}]}`), &Pattern{})
+	if err == nil {
+		t.Fatal("expected an error for invalid step value")
+	}
+}
@@ -0,0 +1,83 @@
+package drum
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ellipsis marks truncated text.
+const ellipsis = "…"
+
+// wideRanges are ranges of East Asian wide and fullwidth characters,
+// and emoji, taking two terminal columns.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF},
+	{0x20000, 0x3FFFD},
+}
+
+// runeWidth returns the number of terminal columns the rune takes.
+func runeWidth(r rune) int {
+	if r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r) {
+		return 0
+	}
+
+	for _, w := range wideRanges {
+		if r >= w.lo && r <= w.hi {
+			return 2
+		}
+	}
+
+	return 1
+}
+
+// StringWidth returns the number of terminal columns s takes, counting
+// wide characters like CJK as two and combining marks as none.
+func StringWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+
+	return width
+}
+
+// truncate returns s cut to at most width columns, ending with an
+// ellipsis if it was cut.
+func truncate(s string, width int) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+
+	return b.String() + ellipsis
+}
+
+// padRight returns s padded with spaces to width columns.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-StringWidth(s)))
+}
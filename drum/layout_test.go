@@ -0,0 +1,33 @@
+package drum
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	for s, width := range map[string]int{
+		"kick":   4,
+		"太鼓":     4,
+		"é":     1,
+		"🥁 drum": 7,
+		"":       0,
+	} {
+		if w := StringWidth(s); w != width {
+			t.Errorf("width of %q is %d, expected %d", s, w, width)
+		}
+	}
+
+	for _, tt := range []struct {
+		s        string
+		width    int
+		expected string
+	}{
+		{"kick", 4, "kick"},
+		{"kick drum", 5, "kick…"},
+		{"大太鼓", 4, "大…"},
+		{"大太鼓", 5, "大太…"},
+		{"kick", 0, ""},
+	} {
+		if out := truncate(tt.s, tt.width); out != tt.expected {
+			t.Errorf("%q truncated to %d is %q, expected %q", tt.s, tt.width, out, tt.expected)
+		}
+	}
+}
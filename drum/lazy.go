@@ -0,0 +1,90 @@
+package drum
+
+import "io"
+
+// pendingTracks holds what's needed to parse tracks of a pattern decoded
+// with WithLazyTracks.
+type pendingTracks struct {
+	data []byte
+	// Offsets of the first track and of the end of content in data
+	offset, end uint64
+	// Number of tracks found by a pre-scan
+	count  int
+	config decodeConfig
+	// Metadata applied before tracks were loaded
+	metadata []Metadata
+}
+
+// DecodeBytes decodes the drum machine file contained in data. With
+// WithLazyTracks, the pattern references data until its tracks are
+// loaded, so data mustn't be modified until then.
+func DecodeBytes(data []byte, opts ...Option) (*Pattern, error) {
+	return decode(data, opts)
+}
+
+// LoadTracks returns tracks of the pattern. Tracks of patterns decoded
+// with WithLazyTracks are parsed on the first call, which fails if they're
+// malformed. Until then, Tracks is empty, but functions of this package
+// and its subpackages using tracks, including Clone, load them first.
+// Code reading Tracks directly must call LoadTracks.
+func (p *Pattern) LoadTracks() ([]Track, error) {
+	pending := p.pending
+	if pending == nil {
+		return p.Tracks, nil
+	}
+
+	p.config = pending.config
+	p.lastErr = nil
+	p.reader.Reset(pending.data)
+	p.buffer = &p.reader
+
+	_, err := p.buffer.Seek(int64(pending.offset), io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	p.problems = nil
+	p.readTracks(pending.end, uint64(len(pending.data)))
+	p.joinProblems()
+	p.reader.Reset(nil)
+	if p.lastErr != nil {
+		p.debug("decoding tracks failed", "error", p.lastErr)
+		p.Tracks = nil
+		return nil, p.lastErr
+	}
+
+	p.pending = nil
+	for _, m := range pending.metadata {
+		p.ApplyMetadata(m)
+	}
+
+	return p.Tracks, nil
+}
+
+// ensureTracks loads tracks of a pattern decoded with WithLazyTracks,
+// if they weren't loaded yet. Nil patterns are ignored.
+func (p *Pattern) ensureTracks() error {
+	if p == nil || p.pending == nil {
+		return nil
+	}
+
+	_, err := p.LoadTracks()
+	return err
+}
+
+// TrackCount returns the number of tracks of the pattern. For patterns
+// decoded with WithLazyTracks, it's known before tracks are loaded from
+// a scan of their headers.
+func (p *Pattern) TrackCount() int {
+	if p.pending != nil {
+		return p.pending.count
+	}
+
+	return len(p.Tracks)
+}
+
+// TracksLoaded returns false if tracks of the pattern, decoded with
+// WithLazyTracks, weren't loaded by LoadTracks yet.
+func (p *Pattern) TracksLoaded() bool {
+	return p.pending == nil
+}
@@ -0,0 +1,178 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDecodeBytesLazyTracks(t *testing.T) {
+	data, err := os.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eager, err := DecodeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lazy, err := DecodeBytes(data, WithLazyTracks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lazy.Version != eager.Version || lazy.Tempo != eager.Tempo {
+		t.Fatalf("header not parsed eagerly: %q @ %v", lazy.Version, lazy.Tempo)
+	}
+	if len(lazy.Tracks) != 0 || lazy.TracksLoaded() {
+		t.Fatalf("tracks parsed eagerly: %v", lazy.Tracks)
+	}
+
+	tracks, err := lazy.LoadTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != len(eager.Tracks) || !lazy.TracksLoaded() {
+		t.Fatalf("loaded %d tracks, expected %d", len(tracks), len(eager.Tracks))
+	}
+	if fmt.Sprint(lazy) != fmt.Sprint(eager) {
+		t.Fatalf("lazily decoded pattern differs.\nGot:\n%s\nExpected:\n%s", lazy, eager)
+	}
+}
+
+func TestLazyTracksLoadedOnUse(t *testing.T) {
+	data, err := os.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lazy, err := DecodeBytes(data, WithLazyTracks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := lazy.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lazy.TracksLoaded() {
+		t.Fatal("tracks weren't loaded by encoding")
+	}
+
+	eager, err := DecodeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := eager.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, expected) {
+		t.Fatal("lazily decoded pattern encoded differently")
+	}
+
+	truncated, err := DecodeBytes(data[:len(data)-10], WithLazyTracks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := truncated.MarshalBinary(); err == nil {
+		t.Fatal("expected an error encoding malformed lazy tracks")
+	}
+}
+
+func TestLazyTracksAPIs(t *testing.T) {
+	data, err := os.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eager, err := DecodeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, f := range map[string]func(p *Pattern) string{
+		"Clone":          func(p *Pattern) string { return p.Clone().String() },
+		"Slice":          func(p *Pattern) string { s, _ := p.Slice(0, 4); return FormatCanonical(s) },
+		"GenerateFill":   func(p *Pattern) string { return GenerateFill(p, 0.5, 1).String() },
+		"Canonicalize":   func(p *Pattern) string { p.Canonicalize(); return FormatCanonical(p) },
+		"ApplyOffsets":   func(p *Pattern) string { return p.ApplyOffsets().String() },
+		"ExportMarkdown": ExportMarkdown,
+		"MarshalJSON":    func(p *Pattern) string { data, _ := p.MarshalJSON(); return string(data) },
+		"Diff":           func(p *Pattern) string { return fmt.Sprint(Diff(p, eager)) },
+		"Match":          func(p *Pattern) string { ok, _ := Query{Track: "kick"}.Match(p); return fmt.Sprint(ok) },
+		"Density":        func(p *Pattern) string { return fmt.Sprint(p.Density()) },
+		"Featurize":      func(p *Pattern) string { return fmt.Sprint(Featurize(p)) },
+	} {
+		lazy, err := DecodeBytes(data, WithLazyTracks(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := f(eager.Clone())
+		if got := f(lazy); got != expected {
+			t.Errorf("%s of a lazy pattern returned:\n%s\nexpected:\n%s", name, got, expected)
+		}
+	}
+}
+
+func TestLoadTracksMalformed(t *testing.T) {
+	data, err := os.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := data[:len(data)-10]
+
+	p, err := DecodeBytes(truncated, WithLazyTracks(true))
+	if err != nil {
+		t.Fatalf("malformed tracks failed lazy decoding - %v", err)
+	}
+
+	if _, err := p.LoadTracks(); err == nil {
+		t.Fatal("expected an error loading malformed tracks")
+	}
+}
+
+func TestLazyTracksMetadata(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path), WithLazyTracks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.ApplyMetadata(Metadata{Tracks: []TrackMetadata{{ID: 0, Display: &Display{Color: "#e90"}}}})
+
+	tracks, err := p.LoadTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracks[0].Display.Color != "#e90" {
+		t.Fatalf("metadata wasn't applied to lazily loaded tracks: %+v", tracks[0].Display)
+	}
+}
+
+func TestTrackCount(t *testing.T) {
+	for _, exp := range tData {
+		data, err := os.ReadFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		eager, err := DecodeBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cap(eager.Tracks) != len(eager.Tracks) {
+			t.Errorf("%s: tracks weren't preallocated exactly, capacity %d for %d tracks", exp.path, cap(eager.Tracks), len(eager.Tracks))
+		}
+
+		lazy, err := DecodeBytes(data, WithLazyTracks(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := lazy.TrackCount(); n != len(eager.Tracks) {
+			t.Errorf("%s: counted %d tracks before loading, expected %d", exp.path, n, len(eager.Tracks))
+		}
+	}
+}
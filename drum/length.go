@@ -0,0 +1,68 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	lengthSize = 8
+	tempoSize  = 4
+	// trackHeaderSize is the size of track's ID and name length
+	trackHeaderSize = 5
+
+	contentOffset = headerLength + lengthSize
+	tracksOffset  = contentOffset + versionMaxLength + tempoSize
+)
+
+// VerifyLength checks whether the content length declared in data
+// matches its actual content, i.e. it covers the version, tempo and whole
+// tracks only. Data trailing after the declared length is allowed.
+func VerifyLength(data []byte) error {
+	if len(data) < contentOffset {
+		return fmt.Errorf("data too short (%d bytes)", len(data))
+	}
+
+	if !bytes.Equal(data[:headerLength], []byte(spliceHeader)) {
+		return errInvalidHeader
+	}
+
+	declared := binary.BigEndian.Uint64(data[headerLength:])
+	available := uint64(len(data) - contentOffset)
+
+	if declared > available {
+		return fmt.Errorf("declared length %d exceeds available %d bytes", declared, available)
+	}
+
+	boundaries := trackBoundaries(data, trackSteps)
+	for _, boundary := range boundaries {
+		if uint64(boundary-contentOffset) == declared {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("declared length %d doesn't end on a track boundary (content length is %d)",
+		declared, boundaries[len(boundaries)-1]-contentOffset)
+}
+
+// trackBoundaries returns offsets in data at which tracks with stepWidth
+// steps end, starting with the offset of the first track. Scanning stops
+// at the first track that doesn't fit in data.
+func trackBoundaries(data []byte, stepWidth int) []int {
+	boundaries := []int{tracksOffset}
+
+	for offset := tracksOffset; offset+trackHeaderSize <= len(data); {
+		nameLength := binary.BigEndian.Uint32(data[offset+1:])
+
+		end := uint64(offset) + trackHeaderSize + uint64(nameLength) + uint64(stepWidth)
+		if end > uint64(len(data)) {
+			break
+		}
+
+		offset = int(end)
+		boundaries = append(boundaries, offset)
+	}
+
+	return boundaries
+}
@@ -0,0 +1,37 @@
+package drum
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func TestVerifyLength(t *testing.T) {
+	for _, exp := range tData {
+		data, err := ioutil.ReadFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := VerifyLength(data); err != nil {
+			t.Fatalf("%s - %v", exp.path, err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, length := range []uint64{0xc4, 0xc6, 0x1000} {
+		binary.BigEndian.PutUint64(data[headerLength:], length)
+		if err := VerifyLength(data); err == nil {
+			t.Errorf("expected error for declared length %d", length)
+		}
+	}
+
+	if err := VerifyLength([]byte("SPLICE")); err == nil {
+		t.Error("expected error for truncated data")
+	}
+}
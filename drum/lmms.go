@@ -0,0 +1,142 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// lmmsTrack is a track of an LMMS project. Beat/bassline tracks contain
+// instrument tracks with a pattern per beat/bassline.
+type lmmsTrack struct {
+	Type     int    `xml:"type,attr"`
+	Name     string `xml:"name,attr"`
+	Patterns []struct {
+		Pos   int `xml:"pos,attr"`
+		Steps int `xml:"steps,attr"`
+		Notes []struct {
+			Pos int  `xml:"pos,attr"`
+			Vol *int `xml:"vol,attr"`
+		} `xml:"note"`
+	} `xml:"pattern"`
+	Tracks []lmmsTrack `xml:"bbtrack>trackcontainer>track"`
+}
+
+// lmmsProject is the part of an LMMS project file needed to import it.
+type lmmsProject struct {
+	XMLName xml.Name `xml:"lmms-project"`
+	Version string   `xml:"creatorversion,attr"`
+	Head    struct {
+		BPM float32 `xml:"bpm,attr"`
+	} `xml:"head"`
+	Tracks []lmmsTrack `xml:"song>trackcontainer>track"`
+}
+
+const (
+	lmmsInstrumentTrack   = 0
+	lmmsBeatBasslineTrack = 1
+)
+
+// detectLMMS returns true if the data starts an LMMS project, either a plain
+// one or a compressed one, whose prefix is decompressed as far as it goes.
+func detectLMMS(data []byte) bool {
+	if bytes.Contains(data, []byte("<lmms-project")) {
+		return true
+	}
+
+	// Compressed projects start with the uncompressed length
+	if len(data) < 4 {
+		return false
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data[4:]))
+	if err != nil {
+		return false
+	}
+
+	// The prefix is cut mid-stream, so reading it ends with an error
+	prefix, _ := io.ReadAll(io.LimitReader(zr, detectLength))
+
+	return bytes.Contains(prefix, []byte("<lmms-project"))
+}
+
+// ImportLMMS reads the first beat/bassline of an LMMS project, either
+// a plain .mmp or a compressed .mmpz file. Every instrument track of the
+// beat/bassline becomes a track. Notes are quantized to sixteenth steps
+// and muted ones are skipped.
+func ImportLMMS(r io.Reader) (*Pattern, error) {
+	br := bufio.NewReader(r)
+
+	// Compressed projects start with the uncompressed length
+	prefix, _ := br.Peek(detectLength)
+	if !bytes.HasPrefix(bytes.TrimSpace(prefix), []byte("<")) {
+		if _, err := br.Discard(4); err != nil {
+			return nil, err
+		}
+
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("something went wrong decompressing LMMS project - %v", err)
+		}
+		defer zr.Close()
+
+		r = zr
+	} else {
+		r = br
+	}
+
+	var project lmmsProject
+	err := xml.NewDecoder(r).Decode(&project)
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong decoding LMMS project - %v", err)
+	}
+
+	var bb *lmmsTrack
+	for i, track := range project.Tracks {
+		if track.Type == lmmsBeatBasslineTrack {
+			bb = &project.Tracks[i]
+			break
+		}
+	}
+	if bb == nil {
+		return nil, errors.New("no beat/bassline tracks in LMMS project")
+	}
+
+	p := &Pattern{
+		Version: importVersion("lmms-" + project.Version),
+		Tempo:   project.Head.BPM,
+	}
+
+	for _, track := range bb.Tracks {
+		if track.Type != lmmsInstrumentTrack {
+			continue
+		}
+
+		t := Track{ID: byte(len(p.Tracks)), Name: track.Name}
+
+		// Patterns of the first beat/bassline are at position 0
+		for _, pattern := range track.Patterns {
+			if pattern.Pos != 0 {
+				continue
+			}
+
+			t.Steps = make([]byte, pattern.Steps)
+			for _, note := range pattern.Notes {
+				if note.Vol != nil && *note.Vol <= 0 {
+					continue
+				}
+
+				if step := quantizeTicks(note.Pos); step >= 0 && step < len(t.Steps) {
+					t.Steps[step] = StepOn
+				}
+			}
+		}
+
+		p.Tracks = append(p.Tracks, t)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,80 @@
+package drum
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+const lmmsProjectData = `<?xml version="1.0"?>
+<!DOCTYPE lmms-project>
+<lmms-project version="1.0" creatorversion="1.2.2" creator="LMMS" type="song">
+ <head timesig_numerator="4" timesig_denominator="4" bpm="140" mastervol="100"/>
+ <song>
+  <trackcontainer type="song">
+   <track type="0" name="Bass"><pattern pos="0" steps="16"/></track>
+   <track type="1" name="Beat/Bassline 0">
+    <bbtrack>
+     <trackcontainer type="bbtrackcontainer">
+      <track type="0" name="Kicker">
+       <pattern pos="0" steps="8">
+        <note pos="0" len="-192" key="57" vol="100"/>
+        <note pos="48" len="-192" key="57"/>
+       </pattern>
+       <pattern pos="192" steps="8">
+        <note pos="12" len="-192" key="57" vol="100"/>
+       </pattern>
+      </track>
+      <track type="0" name="Snare">
+       <pattern pos="0" steps="8">
+        <note pos="24" len="-192" key="57" vol="100"/>
+        <note pos="72" len="-192" key="57" vol="0"/>
+       </pattern>
+      </track>
+     </trackcontainer>
+    </bbtrack>
+   </track>
+  </trackcontainer>
+ </song>
+</lmms-project>`
+
+func TestImportLMMS(t *testing.T) {
+	if format, ok := DetectFormat([]byte(lmmsProjectData)); !ok || format != "lmms" {
+		t.Fatalf("LMMS project detected as %q", format)
+	}
+
+	expected := `Saved with HW Version: lmms-1.2.2
+Tempo: 140
+(0) Kicker	|x---|x---|
+(1) Snare	|--x-|----|
+`
+
+	p, err := ImportLMMS(strings.NewReader(lmmsProjectData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := p.String(); out != expected {
+		t.Fatalf("wrong import.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+
+	// Compressed .mmpz projects
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(len(lmmsProjectData)))
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte(lmmsProjectData))
+	zw.Close()
+
+	if format, ok := DetectFormat(buf.Bytes()); !ok || format != "lmms" {
+		t.Fatalf("expected compressed project detected as lmms, got %q", format)
+	}
+
+	p, err = ImportLMMS(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := p.String(); out != expected {
+		t.Fatalf("wrong compressed import.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+}
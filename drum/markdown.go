@@ -0,0 +1,72 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	markdownStepOn  = "✅"
+	markdownStepOff = "▫️"
+)
+
+// ExportMarkdown returns the pattern as a GitHub-flavored Markdown table,
+// with tracks as rows and steps as columns. Notes attached to steps are
+// written as footnotes referenced from their cells.
+func ExportMarkdown(p *Pattern) string {
+	p.ensureTracks()
+
+	var buffer bytes.Buffer
+
+	buffer.WriteString(fmt.Sprintf("**Saved with HW Version:** %s  \n", p.Version))
+	buffer.WriteString(fmt.Sprintf("**Tempo:** %v\n\n", p.Tempo))
+
+	steps := 0
+	for _, track := range p.Tracks {
+		if track.Len() > steps {
+			steps = track.Len()
+		}
+	}
+
+	buffer.WriteString("| Track |")
+	for i := 1; i <= steps; i++ {
+		buffer.WriteString(fmt.Sprintf(" %d |", i))
+	}
+
+	buffer.WriteString("\n|---|")
+	buffer.WriteString(strings.Repeat(":-:|", steps))
+	buffer.WriteString("\n")
+
+	footnotes := p.footnotes()
+	markers := make(map[[2]int]string, len(footnotes))
+	for _, f := range footnotes {
+		markers[[2]int{f.TrackIndex, f.Position}] = fmt.Sprintf("[^%d]", f.Number)
+	}
+
+	for t, track := range p.Tracks {
+		name := strings.Replace(track.Name, "|", `\|`, -1)
+		buffer.WriteString(fmt.Sprintf("| (%d) %s |", track.ID, name))
+
+		shifted := track.ShiftedSteps()
+		for i := 0; i < steps; i++ {
+			symbol := markdownStepOff
+			if i < len(shifted) && isHit(shifted[i]) {
+				symbol = markdownStepOn
+			}
+
+			buffer.WriteString(" " + symbol + markers[[2]int{t, i}] + " |")
+		}
+
+		buffer.WriteString("\n")
+	}
+
+	if len(footnotes) > 0 {
+		buffer.WriteString("\n")
+	}
+	for _, f := range footnotes {
+		buffer.WriteString(fmt.Sprintf("[^%d]: %s: %s\n", f.Number, f.Label(), f.Text))
+	}
+
+	return buffer.String()
+}
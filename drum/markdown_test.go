@@ -0,0 +1,25 @@
+package drum
+
+import "testing"
+
+func TestExportMarkdown(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0, 0, 0}},
+			{ID: 1, Name: "hh|open", Steps: []byte{0, 0, 1, 0}},
+		},
+	}
+
+	expected := "**Saved with HW Version:** 0.808-alpha  \n" +
+		"**Tempo:** 120\n\n" +
+		"| Track | 1 | 2 | 3 | 4 |\n" +
+		"|---|:-:|:-:|:-:|:-:|\n" +
+		"| (0) kick | ✅ | ▫️ | ▫️ | ▫️ |\n" +
+		"| (1) hh\\|open | ▫️ | ▫️ | ✅ | ▫️ |\n"
+
+	if out := ExportMarkdown(p); out != expected {
+		t.Fatalf("wrong Markdown.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+}
@@ -0,0 +1,72 @@
+package drum
+
+// MergePolicy decides how steps of two merged tracks are combined.
+type MergePolicy int
+
+const (
+	// MergeOr plays steps hit in either track. Where both tracks hit,
+	// flams win over regular hits.
+	MergeOr MergePolicy = iota
+	// MergeXor plays steps hit in exactly one of the tracks.
+	MergeXor
+	// MergeAPriority plays steps hit in either track. Where both tracks hit,
+	// the step of the first track is kept.
+	MergeAPriority
+	// MergeAlternateBars takes odd bars from the first track
+	// and even bars from the second one.
+	MergeAlternateBars
+)
+
+// barSteps is the number of steps in a single bar.
+const barSteps = 4 * beatSteps
+
+// MergeTracks combines steps of two tracks into one, e.g. to consolidate
+// similar instruments for a device with a limited number of tracks.
+// The result has the ID, name and display of a, and steps as long as the
+// longer track's. Offsets of both tracks are applied to their steps first.
+func MergeTracks(a, b Track, policy MergePolicy) Track {
+	a.Unpack()
+	b.Unpack()
+	sa, sb := a.ShiftedSteps(), b.ShiftedSteps()
+
+	length := len(sa)
+	if len(sb) > length {
+		length = len(sb)
+	}
+
+	merged := a
+	merged.Offset = 0
+	merged.Steps = make([]byte, length)
+
+	for i := range merged.Steps {
+		va, vb := stepAt(sa, i), stepAt(sb, i)
+
+		switch policy {
+		case MergeXor:
+			if isHit(va) && !isHit(vb) {
+				merged.Steps[i] = va
+			} else if isHit(vb) && !isHit(va) {
+				merged.Steps[i] = vb
+			}
+		case MergeAPriority:
+			if isHit(va) {
+				merged.Steps[i] = va
+			} else {
+				merged.Steps[i] = vb
+			}
+		case MergeAlternateBars:
+			if (i/barSteps)%2 == 0 {
+				merged.Steps[i] = va
+			} else {
+				merged.Steps[i] = vb
+			}
+		default:
+			merged.Steps[i] = va
+			if vb > va {
+				merged.Steps[i] = vb
+			}
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,288 @@
+package drum
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MergeConflict is a change made differently in both merged patterns.
+type MergeConflict struct {
+	// Field is the conflicting attribute: "version" or "tempo" of the
+	// pattern, or "track" (changed in one pattern, removed in the other),
+	// "name", "offset", "length" or "step" of a track
+	Field   string `json:"field"`
+	TrackID byte   `json:"track"`
+	// Step is the index of the conflicting step, for "step" conflicts
+	Step int `json:"step"`
+}
+
+// String returns the conflict in a human readable form.
+func (c MergeConflict) String() string {
+	switch c.Field {
+	case "version", "tempo":
+		return c.Field
+	case "step":
+		return fmt.Sprintf("track (%d) step %d", c.TrackID, c.Step+1)
+	case "track":
+		return fmt.Sprintf("track (%d) changed and removed", c.TrackID)
+	default:
+		return fmt.Sprintf("track (%d) %s", c.TrackID, c.Field)
+	}
+}
+
+// MergeResult is the result of a three-way merge, see Merge3.
+type MergeResult struct {
+	// Pattern is the merged pattern, with conflicts resolved to ours
+	Pattern   *Pattern
+	Conflicts []MergeConflict
+
+	// theirs is the merged pattern with conflicts resolved to theirs
+	theirs *Pattern
+}
+
+// Merge3 merges changes made in patterns ours and theirs since their
+// common ancestor base, like git merges text. Tracks are matched by ID.
+// Attributes of the pattern and its tracks, and single steps, are merged
+// independently, so changes of different steps of a track don't conflict.
+// Tracks keep the order of ours, with tracks added in theirs listed last.
+// Other attributes of tracks, e.g. tags, are taken from ours.
+func Merge3(base, ours, theirs *Pattern) MergeResult {
+	base.ensureTracks()
+	ours.ensureTracks()
+	theirs.ensureTracks()
+
+	result := MergeResult{Pattern: ours.Clone(), theirs: ours.Clone()}
+	resolved := [2]*Pattern{result.Pattern, result.theirs}
+
+	// merge returns whether each resolution takes the value of theirs,
+	// recording a conflict if both patterns changed the value differently
+	merge := func(field string, id byte, step int, b, o, t string) [2]bool {
+		switch {
+		case o == t || t == b:
+			return [2]bool{false, false}
+		case o == b:
+			return [2]bool{true, true}
+		default:
+			result.Conflicts = append(result.Conflicts, MergeConflict{Field: field, TrackID: id, Step: step})
+			return [2]bool{false, true}
+		}
+	}
+
+	version := merge("version", 0, 0, base.Version, ours.Version, theirs.Version)
+	tempo := merge("tempo", 0, 0, formatTempo(base.Tempo), formatTempo(ours.Tempo), formatTempo(theirs.Tempo))
+	for i, r := range resolved {
+		if version[i] {
+			r.Version = theirs.Version
+		}
+		if tempo[i] {
+			r.Tempo = theirs.Tempo
+		}
+		r.Tracks = nil
+	}
+
+	for _, id := range mergeTrackIDs(ours, theirs) {
+		tb, inBase := trackByID(base, id)
+		to, inOurs := trackByID(ours, id)
+		tt, inTheirs := trackByID(theirs, id)
+		tb.Unpack()
+		to.Unpack()
+		tt.Unpack()
+
+		switch {
+		case inBase && !inOurs && !inTheirs:
+			continue
+		case inBase && !inOurs:
+			if formatTrack(tt) != formatTrack(tb) {
+				// Changed in theirs, but removed in ours
+				result.Conflicts = append(result.Conflicts, MergeConflict{Field: "track", TrackID: id})
+				resolved[1].Tracks = append(resolved[1].Tracks, tt)
+			}
+			continue
+		case inBase && !inTheirs:
+			if formatTrack(to) != formatTrack(tb) {
+				result.Conflicts = append(result.Conflicts, MergeConflict{Field: "track", TrackID: id})
+				resolved[0].Tracks = append(resolved[0].Tracks, to)
+			}
+			continue
+		case !inOurs:
+			to = tt
+		case !inTheirs:
+			tt = to
+		}
+		if !inBase {
+			// Tracks added in both patterns are merged as if added
+			// to an empty track
+			tb = Track{ID: id}
+		}
+
+		name := merge("name", id, 0, tb.Name, to.Name, tt.Name)
+		offset := merge("offset", id, 0, strconv.Itoa(tb.Offset), strconv.Itoa(to.Offset), strconv.Itoa(tt.Offset))
+		length := merge("length", id, 0, strconv.Itoa(len(tb.Steps)), strconv.Itoa(len(to.Steps)), strconv.Itoa(len(tt.Steps)))
+
+		steps := max(len(tb.Steps), len(to.Steps), len(tt.Steps))
+		tracks := [2]Track{to, to}
+		for i := range tracks {
+			tracks[i].Steps = make([]byte, steps)
+			tracks[i].packed = nil
+			if name[i] {
+				tracks[i].Name = tt.Name
+			}
+			if offset[i] {
+				tracks[i].Offset = tt.Offset
+			}
+		}
+
+		for s := 0; s < steps; s++ {
+			b, o, t := stepAt(tb.Steps, s), stepAt(to.Steps, s), stepAt(tt.Steps, s)
+			from := merge("step", id, s, string(rune(b)), string(rune(o)), string(rune(t)))
+			for i := range tracks {
+				tracks[i].Steps[s] = o
+				if from[i] {
+					tracks[i].Steps[s] = t
+				}
+			}
+		}
+
+		n := [2]int{len(to.Steps), len(to.Steps)}
+		for i := range n {
+			if length[i] {
+				n[i] = len(tt.Steps)
+			}
+		}
+		if length[0] == length[1] && (hasHits(tracks[0].Steps[n[0]:]) || hasHits(tracks[1].Steps[n[1]:])) {
+			// Shortened in one pattern, with cut steps changed in the other
+			result.Conflicts = append(result.Conflicts, MergeConflict{Field: "length", TrackID: id})
+			n = [2]int{len(to.Steps), len(tt.Steps)}
+		}
+
+		// Steps cut in one pattern are kept as the other one has them
+		for s := len(tt.Steps); s < n[0]; s++ {
+			tracks[0].Steps[s] = to.Steps[s]
+		}
+		for s := len(to.Steps); s < n[1]; s++ {
+			tracks[1].Steps[s] = tt.Steps[s]
+		}
+
+		for i := range tracks {
+			tracks[i].Steps = tracks[i].Steps[:n[i]]
+			resolved[i].Tracks = append(resolved[i].Tracks, tracks[i])
+		}
+	}
+
+	return result
+}
+
+// Text returns the merged pattern in the canonical text format, with
+// conflicting lines between git-style conflict markers, showing the
+// versions of ours and theirs.
+func (r MergeResult) Text() string {
+	if len(r.Conflicts) == 0 {
+		return FormatCanonical(r.Pattern)
+	}
+
+	ours := canonicalLines(r.Pattern)
+	theirs := canonicalLines(r.theirs)
+
+	var b strings.Builder
+	for _, key := range mergeLineKeys(ours, theirs) {
+		o, t := ours.lines[key], theirs.lines[key]
+		if o == t {
+			b.WriteString(o + "\n")
+			continue
+		}
+
+		b.WriteString("<<<<<<< ours\n")
+		if o != "" {
+			b.WriteString(o + "\n")
+		}
+		b.WriteString("=======\n")
+		if t != "" {
+			b.WriteString(t + "\n")
+		}
+		b.WriteString(">>>>>>> theirs\n")
+	}
+
+	return b.String()
+}
+
+// keyedLines are lines of the canonical text format keyed by their
+// first two fields, e.g. "tempo" or "track 1".
+type keyedLines struct {
+	keys  []string
+	lines map[string]string
+}
+
+// canonicalLines returns keyed lines of the pattern in the canonical
+// text format.
+func canonicalLines(p *Pattern) keyedLines {
+	l := keyedLines{lines: map[string]string{}}
+	for _, line := range strings.Split(strings.TrimSuffix(FormatCanonical(p), "\n"), "\n") {
+		key, _, _ := strings.Cut(line, " ")
+		if key == "track" {
+			fields := strings.SplitN(line, " ", 3)
+			key += " " + fields[1]
+		}
+
+		l.keys = append(l.keys, key)
+		l.lines[key] = line
+	}
+
+	return l
+}
+
+// mergeLineKeys returns keys of lines of both texts, in the order of a
+// with keys only in b inserted after the preceding key of b.
+func mergeLineKeys(a, b keyedLines) []string {
+	keys := append([]string(nil), a.keys...)
+
+	for i, key := range b.keys {
+		if _, ok := a.lines[key]; ok {
+			continue
+		}
+
+		at := 0
+		if i > 0 {
+			for j, k := range keys {
+				if k == b.keys[i-1] {
+					at = j + 1
+				}
+			}
+		}
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+
+	return keys
+}
+
+// mergeTrackIDs returns IDs of tracks of ours, followed by IDs of tracks
+// only in theirs.
+func mergeTrackIDs(ours, theirs *Pattern) []byte {
+	var ids []byte
+	for _, track := range ours.Tracks {
+		ids = append(ids, track.ID)
+	}
+	for _, track := range theirs.Tracks {
+		if _, ok := trackByID(ours, track.ID); !ok {
+			ids = append(ids, track.ID)
+		}
+	}
+
+	return ids
+}
+
+// formatTrack returns the track in the canonical text format.
+func formatTrack(t Track) string {
+	return FormatCanonical(&Pattern{Tracks: []Track{t}})
+}
+
+// hasHits returns true if any of the steps is a hit.
+func hasHits(steps []byte) bool {
+	for _, step := range steps {
+		if isHit(step) {
+			return true
+		}
+	}
+
+	return false
+}
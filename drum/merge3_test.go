@@ -0,0 +1,110 @@
+package drum
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ours := base.Clone()
+	ours.Tempo = 98.4
+	ours.Tracks[0].Steps[1] = StepOn
+	ours.Tracks = ours.Tracks[:5]
+
+	theirs := base.Clone()
+	theirs.Tracks[0].Steps[2] = StepFlam
+	theirs.Tracks[1].Name = "snare2"
+	theirs.Tracks = append(theirs.Tracks, Track{ID: 9, Name: "rim", Steps: stepsFromString("x-x-")})
+
+	result := Merge3(base, ours, theirs)
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts %v", result.Conflicts)
+	}
+
+	expected := `splice-canonical 1
+version "0.808-alpha"
+tempo 98.4
+steps 16
+track 0 "kick" xxf-x---x---x---
+track 1 "snare2" ----x-------x---
+track 2 "clap" ----x-x---------
+track 3 "hh-open" --x---x-x-x---x-
+track 4 "hh-close" x---x-------x--x
+track 9 "rim" x-x-
+`
+	if text := result.Text(); text != expected {
+		t.Fatalf("unexpected merge.\nGot:\n%s\nExpected:\n%s", text, expected)
+	}
+}
+
+func TestMerge3Conflicts(t *testing.T) {
+	base := &Pattern{Version: "0.808", Tempo: 120, Tracks: []Track{
+		{ID: 0, Name: "kick", Steps: stepsFromString("x---x---")},
+		{ID: 1, Name: "snare", Steps: stepsFromString("----x---")},
+		{ID: 2, Name: "clap", Steps: stepsFromString("--------")},
+	}}
+
+	ours := base.Clone()
+	ours.Tempo = 100
+	ours.Tracks[0].Steps[2] = StepOn
+	ours.Tracks[1].Steps = ours.Tracks[1].Steps[:4]
+	ours.Tracks = ours.Tracks[:2]
+
+	theirs := base.Clone()
+	theirs.Tempo = 130
+	theirs.Tracks[0].Steps[2] = StepFlam
+	theirs.Tracks[0].Steps[6] = StepOn
+	theirs.Tracks[1].Steps[6] = StepOn
+	theirs.Tracks[2].Steps[0] = StepOn
+
+	result := Merge3(base, ours, theirs)
+
+	expected := []MergeConflict{
+		{Field: "tempo"},
+		{Field: "step", TrackID: 0, Step: 2},
+		{Field: "length", TrackID: 1},
+		{Field: "track", TrackID: 2},
+	}
+	if !reflect.DeepEqual(result.Conflicts, expected) {
+		t.Fatalf("expected conflicts %v, got %v", expected, result.Conflicts)
+	}
+
+	text := `splice-canonical 1
+version "0.808"
+<<<<<<< ours
+tempo 100
+=======
+tempo 130
+>>>>>>> theirs
+steps 8
+<<<<<<< ours
+track 0 "kick" x-x-x-x-
+=======
+track 0 "kick" x-f-x-x-
+>>>>>>> theirs
+<<<<<<< ours
+track 1 "snare" ----
+=======
+track 1 "snare" ----x-x-
+>>>>>>> theirs
+<<<<<<< ours
+=======
+track 2 "clap" x-------
+>>>>>>> theirs
+`
+	if got := result.Text(); got != text {
+		t.Fatalf("unexpected merge.\nGot:\n%s\nExpected:\n%s", got, text)
+	}
+
+	// The merged pattern resolves conflicts to ours
+	if got := FormatCanonical(result.Pattern); !strings.Contains(got, "tempo 100\n") || strings.Contains(got, "clap") {
+		t.Errorf("unexpected merged pattern:\n%s", got)
+	}
+}
@@ -0,0 +1,49 @@
+package drum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMergeTracks(t *testing.T) {
+	a := Track{ID: 3, Name: "hh-closed", Steps: []byte{1, 0, 1, 0, 2, 0, 0, 0}}
+	b := Track{ID: 4, Name: "hh-pedal", Steps: []byte{0, 0, 2, 1, 1, 0, 0, 1}}
+
+	for _, tc := range []struct {
+		policy   MergePolicy
+		expected []byte
+	}{
+		{MergeOr, []byte{1, 0, 2, 1, 2, 0, 0, 1}},
+		{MergeXor, []byte{1, 0, 0, 1, 0, 0, 0, 1}},
+		{MergeAPriority, []byte{1, 0, 1, 1, 2, 0, 0, 1}},
+	} {
+		merged := MergeTracks(a, b, tc.policy)
+
+		if merged.ID != a.ID || merged.Name != a.Name {
+			t.Errorf("policy %d: merged track is (%d) %s", tc.policy, merged.ID, merged.Name)
+		}
+		if !bytes.Equal(merged.Steps, tc.expected) {
+			t.Errorf("policy %d: got steps %v, expected %v", tc.policy, merged.Steps, tc.expected)
+		}
+	}
+}
+
+func TestMergeTracksAlternateBars(t *testing.T) {
+	a := Track{Name: "a", Steps: bytes.Repeat([]byte{1, 0}, 24)}
+	b := Track{Name: "b", Steps: bytes.Repeat([]byte{0, 1}, 16), Offset: 1}
+
+	merged := MergeTracks(a, b, MergeAlternateBars)
+
+	// b is delayed by its offset, so its hits wrap to the even steps
+	expected := append(append(bytes.Repeat([]byte{1, 0}, 8), bytes.Repeat([]byte{1, 0}, 8)...), bytes.Repeat([]byte{1, 0}, 8)...)
+	if !bytes.Equal(merged.Steps, expected) {
+		t.Fatalf("got steps %v, expected %v", merged.Steps, expected)
+	}
+
+	b.Offset = 0
+	merged = MergeTracks(a, b, MergeAlternateBars)
+	expected = append(append(bytes.Repeat([]byte{1, 0}, 8), bytes.Repeat([]byte{0, 1}, 8)...), bytes.Repeat([]byte{1, 0}, 8)...)
+	if !bytes.Equal(merged.Steps, expected) {
+		t.Fatalf("got steps %v, expected %v", merged.Steps, expected)
+	}
+}
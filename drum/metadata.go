@@ -0,0 +1,202 @@
+package drum
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sidecarExtension replaces the pattern file's extension in sidecar's path.
+const sidecarExtension = ".meta.json"
+
+// Display holds optional hints for presenting a track in visual outputs.
+type Display struct {
+	// Color in CSS notation, e.g. "#e90"
+	Color string `json:"color,omitempty"`
+	// Icon, e.g. an emoji
+	Icon string `json:"icon,omitempty"`
+	// Label shown instead of the track's name
+	Label string `json:"label,omitempty"`
+}
+
+// Metadata is extended information about a pattern that the .splice format
+// can't store. It's kept in a JSON sidecar file next to the pattern file.
+type Metadata struct {
+	TempoChanges []TempoChange   `json:"tempoChanges,omitempty"`
+	Tracks       []TrackMetadata `json:"tracks,omitempty"`
+	Signature    *Signature      `json:"signature,omitempty"`
+	// Scene is the name of the active scene, if not DefaultScene
+	Scene string `json:"scene,omitempty"`
+}
+
+// TrackMetadata is extended information about a single track,
+// matched to the track by its ID.
+type TrackMetadata struct {
+	ID         byte     `json:"id"`
+	Display    *Display `json:"display,omitempty"`
+	Notes      []Note   `json:"notes,omitempty"`
+	Automation []Lane   `json:"automation,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Muted      bool     `json:"muted,omitempty"`
+	// Scenes hold steps of inactive scenes by their names, written with
+	// x for hits, f for flams and - for rests
+	Scenes map[string]string `json:"scenes,omitempty"`
+	// Flams are indexes of steps encoded as hits that are flams
+	Flams []int `json:"flams,omitempty"`
+}
+
+// SidecarPath returns path of the metadata sidecar file
+// of the pattern file at path, e.g. "beat.meta.json" for "beat.splice".
+func SidecarPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + sidecarExtension
+}
+
+// Metadata returns extended information about the pattern. Tracks with
+// no extended information are omitted.
+func (p *Pattern) Metadata() Metadata {
+	p.ensureTracks()
+
+	m := Metadata{
+		TempoChanges: append([]TempoChange(nil), p.TempoChanges...),
+		Scene:        p.scene,
+	}
+
+	if p.Signature != nil {
+		signature := *p.Signature
+		m.Signature = &signature
+	}
+
+	for _, track := range p.Tracks {
+		tm := TrackMetadata{ID: track.ID}
+
+		if track.Display != (Display{}) {
+			display := track.Display
+			tm.Display = &display
+		}
+		tm.Notes = append([]Note(nil), track.Notes...)
+		tm.Automation = cloneAutomation(track.Automation)
+		tm.Tags = append([]string(nil), track.Tags...)
+		tm.Muted = track.Muted
+		tm.Scenes = sceneMetadata(track)
+		tm.Flams = track.flamSteps()
+
+		if tm.Display != nil || len(tm.Notes) > 0 || len(tm.Automation) > 0 || len(tm.Tags) > 0 || tm.Muted ||
+			len(tm.Scenes) > 0 || len(tm.Flams) > 0 {
+			m.Tracks = append(m.Tracks, tm)
+		}
+	}
+
+	return m
+}
+
+// ApplyMetadata sets extended information of the pattern and its tracks,
+// matching them by ID. Metadata of missing tracks is ignored. Metadata of
+// tracks that aren't loaded yet is applied once they are.
+func (p *Pattern) ApplyMetadata(m Metadata) {
+	if p.pending != nil {
+		p.pending.metadata = append(p.pending.metadata, m)
+	}
+
+	if m.Signature != nil {
+		signature := *m.Signature
+		p.Signature = &signature
+	}
+
+	if m.Scene != "" {
+		p.scene = m.Scene
+	}
+
+	for _, change := range m.TempoChanges {
+		p.SetTempoChange(change.Step, change.Tempo)
+	}
+
+	for _, tm := range m.Tracks {
+		for i := range p.Tracks {
+			if p.Tracks[i].ID != tm.ID {
+				continue
+			}
+
+			if tm.Display != nil {
+				p.Tracks[i].Display = *tm.Display
+			}
+			for _, note := range tm.Notes {
+				p.Tracks[i].Annotate(note.Step, note.Text)
+			}
+			applyAutomation(&p.Tracks[i], tm.Automation)
+			p.Tracks[i].Tag(tm.Tags...)
+			p.Tracks[i].Muted = tm.Muted
+			applySceneMetadata(&p.Tracks[i], tm.Scenes)
+			applyFlams(&p.Tracks[i], tm.Flams)
+		}
+	}
+}
+
+// applyFlams turns hits of the track at the indexes into flams.
+func applyFlams(t *Track, flams []int) {
+	for _, step := range flams {
+		if step >= 0 && step < len(t.Steps) && t.Steps[step] == StepOn {
+			t.Steps[step] = StepFlam
+		}
+	}
+}
+
+// empty returns true if there's no extended information.
+func (m Metadata) empty() bool {
+	return len(m.TempoChanges) == 0 && len(m.Tracks) == 0 && m.Signature == nil && m.Scene == ""
+}
+
+// readSidecar applies metadata read from the sidecar of the pattern file
+// at path, using read to read files. A missing sidecar isn't an error.
+func (p *Pattern) readSidecar(read func(path string) ([]byte, error), path string) error {
+	data, err := read(SidecarPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var m Metadata
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return err
+	}
+
+	p.ApplyMetadata(m)
+
+	return nil
+}
+
+// WriteSidecar writes only the pattern's metadata to the sidecar of the
+// pattern file at path, leaving the file unchanged, e.g. after Sign.
+func WriteSidecar(p *Pattern, path string) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	return p.writeSidecar(path)
+}
+
+// writeSidecar writes the pattern's metadata to the sidecar of the pattern
+// file at path. The sidecar is removed if there's no metadata to write.
+func (p *Pattern) writeSidecar(path string) error {
+	m := p.Metadata()
+
+	if m.empty() {
+		err := os.Remove(SidecarPath(path))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(SidecarPath(path), append(data, '\n'), 0644)
+}
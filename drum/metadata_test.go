@@ -0,0 +1,47 @@
+package drum
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestSidecar(t *testing.T) {
+	if p := SidecarPath("dir/beat.splice"); p != "dir/beat.meta.json" {
+		t.Fatalf("unexpected sidecar path %s", p)
+	}
+
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded.Tracks[1].Display = Display{Color: "#f00", Icon: "🥁", Label: "SD"}
+
+	file := path.Join(t.TempDir(), "beat.splice")
+	if err := EncodeFile(decoded, file); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := DecodeFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reloaded.Metadata(), decoded.Metadata()) {
+		t.Fatalf("metadata wasn't preserved.\nGot:\n%+v\nExpected:\n%+v", reloaded.Metadata(), decoded.Metadata())
+	}
+	if reloaded.Tracks[1].Display.Label != "SD" {
+		t.Fatalf("display wasn't applied: %+v", reloaded.Tracks[1].Display)
+	}
+
+	// Saving without metadata removes the stale sidecar
+	reloaded.Tracks[1].Display = Display{}
+	if err := EncodeFile(reloaded, file); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(SidecarPath(file)); !os.IsNotExist(err) {
+		t.Fatalf("stale sidecar wasn't removed: %v", err)
+	}
+}
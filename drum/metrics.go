@@ -0,0 +1,61 @@
+package drum
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum/metrics"
+)
+
+// decodeLatencyBuckets are upper bounds of decode latency buckets in seconds.
+var decodeLatencyBuckets = []float64{0.00001, 0.0001, 0.001, 0.01, 0.1, 1}
+
+// decodeMetrics holds the *decodeStats set by SetMetrics.
+var decodeMetrics atomic.Pointer[decodeStats]
+
+func init() {
+	SetMetrics(metrics.Nop())
+}
+
+// decodeStats holds metrics reported by the decoder.
+type decodeStats struct {
+	decodes         metrics.Counter
+	headerErrors    metrics.Counter
+	truncatedErrors metrics.Counter
+	otherErrors     metrics.Counter
+	latency         metrics.Histogram
+}
+
+func newDecodeStats(r metrics.Registerer) *decodeStats {
+	return &decodeStats{
+		decodes:         r.Counter("decodes_total"),
+		headerErrors:    r.Counter("decode_errors_header_total"),
+		truncatedErrors: r.Counter("decode_errors_truncated_total"),
+		otherErrors:     r.Counter("decode_errors_other_total"),
+		latency:         r.Histogram("decode_duration_seconds", decodeLatencyBuckets),
+	}
+}
+
+// SetMetrics sets the Registerer used to report decoding metrics.
+// It's safe to call while patterns are being decoded.
+func SetMetrics(r metrics.Registerer) {
+	decodeMetrics.Store(newDecodeStats(r))
+}
+
+// observe records a single decode that started at start and ended with err.
+func (s *decodeStats) observe(start time.Time, err error) {
+	s.decodes.Add(1)
+	s.latency.Observe(time.Since(start).Seconds())
+
+	switch {
+	case err == nil:
+	case errors.Is(err, errInvalidHeader):
+		s.headerErrors.Add(1)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		s.truncatedErrors.Add(1)
+	default:
+		s.otherErrors.Add(1)
+	}
+}
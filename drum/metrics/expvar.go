@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"expvar"
+	"strconv"
+)
+
+// Expvar returns a Registerer publishing metrics as expvar variables
+// with names prefixed by prefix.
+func Expvar(prefix string) Registerer {
+	return expvarRegisterer{prefix: prefix}
+}
+
+type expvarRegisterer struct {
+	prefix string
+}
+
+// Counter returns a counter published as expvar.Int.
+func (r expvarRegisterer) Counter(name string) Counter {
+	name = r.prefix + name
+
+	if v, ok := expvar.Get(name).(*expvar.Int); ok {
+		return v
+	}
+
+	return expvar.NewInt(name)
+}
+
+// Histogram returns a histogram published as expvar.Map with cumulative
+// bucket counts keyed by their upper bounds, a total count and a sum.
+func (r expvarRegisterer) Histogram(name string, buckets []float64) Histogram {
+	name = r.prefix + name
+
+	m, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		m = expvar.NewMap(name)
+	}
+
+	return &expvarHistogram{values: m, buckets: buckets}
+}
+
+type expvarHistogram struct {
+	values  *expvar.Map
+	buckets []float64
+}
+
+// Observe adds value to all buckets it fits in.
+func (h *expvarHistogram) Observe(value float64) {
+	for _, bucket := range h.buckets {
+		if value <= bucket {
+			h.values.Add("le_"+strconv.FormatFloat(bucket, 'g', -1, 64), 1)
+		}
+	}
+
+	h.values.Add("count", 1)
+	h.values.AddFloat("sum", value)
+}
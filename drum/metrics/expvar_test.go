@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"expvar"
+	"testing"
+)
+
+func TestExpvar(t *testing.T) {
+	r := Expvar("test_")
+
+	r.Counter("decodes").Add(2)
+	r.Counter("decodes").Add(1)
+
+	if v := expvar.Get("test_decodes").String(); v != "3" {
+		t.Fatalf("expected counter 3, got %s", v)
+	}
+
+	h := r.Histogram("latency", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+
+	m := expvar.Get("test_latency").(*expvar.Map)
+	for key, expected := range map[string]string{"le_0.1": "1", "le_1": "2", "count": "3", "sum": "5.55"} {
+		if v := m.Get(key).String(); v != expected {
+			t.Errorf("expected %s to be %s, got %s", key, expected, v)
+		}
+	}
+}
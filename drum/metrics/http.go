@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// httpLatencyBuckets are upper bounds of request latency buckets in seconds.
+var httpLatencyBuckets = []float64{0.001, 0.01, 0.1, 0.5, 1, 5}
+
+// InstrumentHandler returns a handler calling h, which reports its
+// requests, server errors and latency to r, as metrics named after the
+// endpoint, e.g. http_patterns_requests_total for "patterns".
+func InstrumentHandler(r Registerer, endpoint string, h http.Handler) http.Handler {
+	requests := r.Counter("http_" + endpoint + "_requests_total")
+	serverErrors := r.Counter("http_" + endpoint + "_errors_total")
+	latency := r.Histogram("http_"+endpoint+"_duration_seconds", httpLatencyBuckets)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, req)
+
+		requests.Add(1)
+		if sw.status >= 500 {
+			serverErrors.Add(1)
+		}
+		latency.Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusWriter records the status code written to a ResponseWriter.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
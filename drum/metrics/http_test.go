@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInstrumentHandler(t *testing.T) {
+	r := Prometheus("")
+	h := InstrumentHandler(r, "patterns", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/broken" {
+			http.Error(w, "broken", http.StatusInternalServerError)
+		}
+	}))
+
+	for _, path := range []string{"/", "/", "/broken"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	var b strings.Builder
+	r.WriteTo(&b)
+	for _, expected := range []string{
+		"http_patterns_requests_total 3\n",
+		"http_patterns_errors_total 1\n",
+		"http_patterns_duration_seconds_count 3\n",
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, b.String())
+		}
+	}
+}
@@ -0,0 +1,32 @@
+// Package metrics defines a minimal interface the drum packages use to
+// report their metrics, so they can be exposed by any monitoring system.
+package metrics
+
+// Counter is a monotonically increasing metric.
+type Counter interface {
+	Add(delta int64)
+}
+
+// Histogram counts observed values in buckets.
+type Histogram interface {
+	Observe(value float64)
+}
+
+// Registerer creates named metrics. Asking for the same name twice
+// should return the same metric.
+type Registerer interface {
+	Counter(name string) Counter
+	Histogram(name string, buckets []float64) Histogram
+}
+
+// Nop returns a Registerer which discards all metrics.
+func Nop() Registerer {
+	return nop{}
+}
+
+type nop struct{}
+
+func (nop) Counter(string) Counter                { return nop{} }
+func (nop) Histogram(string, []float64) Histogram { return nop{} }
+func (nop) Add(int64)                             {}
+func (nop) Observe(float64)                       {}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// PrometheusRegisterer is a Registerer serving metrics in the Prometheus
+// text exposition format, e.g. on a /metrics endpoint.
+type PrometheusRegisterer struct {
+	prefix string
+
+	mu         sync.Mutex
+	counters   map[string]*promCounter
+	histograms map[string]*promHistogram
+}
+
+// Prometheus returns a Registerer exposing metrics with names prefixed
+// by prefix in the Prometheus text format.
+func Prometheus(prefix string) *PrometheusRegisterer {
+	return &PrometheusRegisterer{
+		prefix:     prefix,
+		counters:   map[string]*promCounter{},
+		histograms: map[string]*promHistogram{},
+	}
+}
+
+// Counter returns the counter of the name, creating it if needed.
+func (r *PrometheusRegisterer) Counter(name string) Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name = r.prefix + name
+	if r.counters[name] == nil {
+		r.counters[name] = &promCounter{}
+	}
+
+	return r.counters[name]
+}
+
+// Histogram returns the histogram of the name, creating it with the
+// buckets if needed.
+func (r *PrometheusRegisterer) Histogram(name string, buckets []float64) Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name = r.prefix + name
+	if r.histograms[name] == nil {
+		sorted := append([]float64(nil), buckets...)
+		sort.Float64s(sorted)
+		r.histograms[name] = &promHistogram{buckets: sorted, counts: make([]int64, len(sorted))}
+	}
+
+	return r.histograms[name]
+}
+
+// WriteTo writes all metrics sorted by name in the text format.
+func (r *PrometheusRegisterer) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.counters)+len(r.histograms))
+	for name := range r.counters {
+		names = append(names, name)
+	}
+	for name := range r.histograms {
+		names = append(names, name)
+	}
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, name := range names {
+		r.mu.Lock()
+		counter, histogram := r.counters[name], r.histograms[name]
+		r.mu.Unlock()
+
+		if counter != nil {
+			fmt.Fprintf(cw, "# TYPE %s counter\n%s %d\n", name, name, atomic.LoadInt64(&counter.value))
+			continue
+		}
+		histogram.write(cw, name)
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.(*bufio.Writer).Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+// ServeHTTP serves all metrics in the text format.
+func (r *PrometheusRegisterer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.WriteTo(w)
+}
+
+type promCounter struct {
+	value int64
+}
+
+func (c *promCounter) Add(delta int64) {
+	atomic.AddInt64(&c.value, delta)
+}
+
+type promHistogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	// Counts of values in each bucket, not cumulative
+	counts []int64
+	count  int64
+	sum    float64
+}
+
+// Observe adds value to the first bucket it fits in.
+func (h *promHistogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := sort.SearchFloat64s(h.buckets, value)
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += value
+}
+
+// write writes cumulative buckets, the sum and count of the histogram.
+func (h *promHistogram) write(w io.Writer, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+
+	cumulative := int64(0)
+	for i, bucket := range h.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(bucket), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", name, formatFloat(h.sum), name, h.count)
+}
+
+// formatFloat formats the value the way Prometheus parses it.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter counts bytes written and keeps the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+
+	return n, err
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheus(t *testing.T) {
+	r := Prometheus("splice_")
+
+	r.Counter("decodes_total").Add(2)
+	r.Counter("decodes_total").Add(1)
+
+	h := r.Histogram("decode_duration_seconds", []float64{1, 0.1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+
+	expected := `# TYPE splice_decode_duration_seconds histogram
+splice_decode_duration_seconds_bucket{le="0.1"} 1
+splice_decode_duration_seconds_bucket{le="1"} 2
+splice_decode_duration_seconds_bucket{le="+Inf"} 3
+splice_decode_duration_seconds_sum 5.55
+splice_decode_duration_seconds_count 3
+# TYPE splice_decodes_total counter
+splice_decodes_total 3
+`
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Body.String() != expected {
+		t.Fatalf("expected served metrics:\n%s\ngot:\n%s", expected, w.Body.String())
+	}
+}
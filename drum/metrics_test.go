@@ -0,0 +1,64 @@
+package drum
+
+import (
+	"path"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum/metrics"
+)
+
+type testCounter int64
+
+func (c *testCounter) Add(delta int64) { *c += testCounter(delta) }
+
+type testHistogram []float64
+
+func (h *testHistogram) Observe(v float64) { *h = append(*h, v) }
+
+type testRegisterer struct {
+	counters   map[string]*testCounter
+	histograms map[string]*testHistogram
+}
+
+func (r *testRegisterer) Counter(name string) metrics.Counter {
+	if r.counters[name] == nil {
+		r.counters[name] = new(testCounter)
+	}
+	return r.counters[name]
+}
+
+func (r *testRegisterer) Histogram(name string, buckets []float64) metrics.Histogram {
+	if r.histograms[name] == nil {
+		r.histograms[name] = new(testHistogram)
+	}
+	return r.histograms[name]
+}
+
+func TestMetrics(t *testing.T) {
+	r := &testRegisterer{map[string]*testCounter{}, map[string]*testHistogram{}}
+	SetMetrics(r)
+	defer SetMetrics(metrics.Nop())
+
+	if _, err := DecodeFile(path.Join("fixtures", tData[0].path)); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Pattern{}
+	p.UnmarshalBinary([]byte("SPLOCE"))
+	p = &Pattern{}
+	p.UnmarshalBinary([]byte("SPLICE\x00\x00"))
+
+	for name, expected := range map[string]testCounter{
+		"decodes_total":                 3,
+		"decode_errors_header_total":    1,
+		"decode_errors_truncated_total": 1,
+	} {
+		if c := r.counters[name]; c == nil || *c != expected {
+			t.Errorf("expected %s to be %d, got %v", name, expected, c)
+		}
+	}
+
+	if h := r.histograms["decode_duration_seconds"]; h == nil || len(*h) != 3 {
+		t.Errorf("expected 3 latency observations, got %v", h)
+	}
+}
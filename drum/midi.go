@@ -0,0 +1,92 @@
+package drum
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// midiTicksPerQuarter is the resolution of exported MIDI files.
+const midiTicksPerQuarter = 96
+
+// ExportMIDI writes the pattern as a standard MIDI file with hits of
+// tracks played on the General MIDI percussion channel. Tracks without
+// a note guessed by GMNote are left out. Automation lanes are written
+// as control changes or NRPN sequences preceding hits of their steps.
+// Flams are preceded by grace notes at half velocity, earlier by the flam
+// spacing, unless they'd start before the pattern. Of the options, WithGroove moves hits off the grid and
+// WithFlamSpacing sets the spacing of flams.
+func ExportMIDI(w io.Writer, p *Pattern, opts ...ExportOption) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	config := newExportConfig(opts)
+
+	var g Groove
+	if config.groove != nil {
+		g = *config.groove
+	}
+
+	if p.Tempo <= 0 {
+		return errors.New("can't export a pattern without tempo to MIDI")
+	}
+
+	stepTicks := midiTicksPerQuarter / beatSteps
+
+	messages := []smfMessage{{0, tempoMessage(p.Tempo)}}
+	for _, change := range p.TempoChanges {
+		messages = append(messages, smfMessage{change.Step * stepTicks, tempoMessage(change.Tempo)})
+	}
+
+	// Control messages go first, so they precede notes of the same tick
+	for _, track := range p.Tracks {
+		n := track.Len()
+		if n == 0 {
+			continue
+		}
+
+		for _, lane := range track.Automation {
+			for _, point := range lane.Points {
+				if point.Step < 0 || point.Step >= n {
+					continue
+				}
+
+				tick := ((point.Step+track.Offset)%n + n) % n * stepTicks
+				for _, data := range lane.controlMessages(smfDrumChannel, point.Value) {
+					messages = append(messages, smfMessage{tick, data})
+				}
+			}
+		}
+	}
+
+	var notes []smfNote
+	for _, e := range p.Events() {
+		note, ok := GMNote(p.Tracks[e.TrackIndex].Name)
+		if !ok {
+			continue
+		}
+
+		feel := g.feel(e.Step)
+		tick := max(e.Step*stepTicks+int(math.Round(feel.Offset*float64(stepTicks))), 0)
+		velocity := feel.scaleVelocity(e.Velocity)
+
+		// Grace notes end as their flams start, so they don't cut them
+		if e.Flam {
+			spacing := float64(config.flamSpacing) / float64(p.StepDurationAt(e.Step)) * float64(stepTicks)
+			if grace := tick - max(int(math.Round(spacing)), 1); grace >= 0 {
+				messages = append(messages, smfNoteMessages([]smfNote{{grace, note, max(velocity/2, 1)}}, tick-grace)...)
+			}
+		}
+		notes = append(notes, smfNote{tick, note, velocity})
+	}
+	messages = append(messages, smfNoteMessages(notes, stepTicks/2)...)
+
+	return writeSMF(w, messages, midiTicksPerQuarter)
+}
+
+// tempoMessage returns a meta message setting the tempo.
+func tempoMessage(tempo float32) []byte {
+	micros := int(60e6 / float64(tempo))
+	return []byte{smfMeta, smfTempo, 3, byte(micros >> 16), byte(micros >> 8), byte(micros)}
+}
@@ -0,0 +1,35 @@
+package drum
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportMIDIFlams(t *testing.T) {
+	p := &Pattern{Tempo: 120, Tracks: []Track{{Name: "snare", Steps: []byte{StepOff, StepFlam, StepOn, StepOff}}}}
+	note, _ := GMNote("snare")
+
+	for _, tc := range []struct {
+		spacing time.Duration
+		notes   []smfNote
+	}{
+		// A step at 120 BPM lasts 125ms, or 24 ticks
+		{25 * time.Millisecond, []smfNote{{19, note, DefaultVelocity / 2}, {24, note, DefaultVelocity}, {48, note, DefaultVelocity}}},
+		{50 * time.Millisecond, []smfNote{{14, note, DefaultVelocity / 2}, {24, note, DefaultVelocity}, {48, note, DefaultVelocity}}},
+	} {
+		var buf bytes.Buffer
+		if err := ExportMIDI(&buf, p, WithFlamSpacing(tc.spacing)); err != nil {
+			t.Fatal(err)
+		}
+
+		notes, _, err := readSMF(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(notes, tc.notes) {
+			t.Errorf("expected notes %v with flam spacing %v, got %v", tc.notes, tc.spacing, notes)
+		}
+	}
+}
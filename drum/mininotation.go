@@ -0,0 +1,399 @@
+package drum
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// miniNotationSteps is the number of steps in a cycle of mini-notation.
+const miniNotationSteps = barSteps
+
+// miniNotationTempo is the tempo of patterns parsed from mini-notation,
+// which has no tempo of its own.
+const miniNotationTempo = 120
+
+// miniNotationSounds maps sample names of Tidal and Strudel to track names.
+// Order matters, as the first sound of a track name is used to write it.
+var miniNotationSounds = []struct {
+	sound, name string
+}{
+	{"bd", "kick"},
+	{"sd", "snare"},
+	{"sn", "snare"},
+	{"rim", "rim"},
+	{"cp", "clap"},
+	{"hh", "hh-close"},
+	{"oh", "hh-open"},
+	{"lt", "low-tom"},
+	{"mt", "mid-tom"},
+	{"ht", "hi-tom"},
+	{"cr", "crash"},
+	{"rd", "ride"},
+	{"cb", "cowbell"},
+	{"tb", "tambourine"},
+	{"sh", "shaker"},
+}
+
+// miniNode is a step of a mini-notation sequence: a sound, a rest if
+// sound and children are empty, or a subsequence.
+type miniNode struct {
+	sound    string
+	children []miniNode
+	// Number of times the step is played within its slot
+	fast int
+}
+
+// ParseMiniNotation parses a pattern from Tidal or Strudel mini-notation,
+// e.g. "bd ~ sn ~, hh*8". A cycle is a single bar of 16 steps. Supported
+// are rests (~), subsequences ([a b]), speeding up (a*2), replicating
+// (a!2) and layers separated by commas. Common sample names like bd or sn
+// are mapped to track names like kick or snare, others are kept. Hits not
+// landing on steps, e.g. triplets, are an error.
+func ParseMiniNotation(s string) (*Pattern, error) {
+	p := &Pattern{Tempo: miniNotationTempo}
+
+	for _, layer := range strings.Split(s, ",") {
+		parser := miniParser{s: layer}
+
+		nodes, err := parser.sequence(0)
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.addMiniNodes(nodes, 0, 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// addMiniNodes adds hits of nodes spread over the span of the cycle
+// from start lasting length.
+func (p *Pattern) addMiniNodes(nodes []miniNode, start, length float64) error {
+	slot := length / float64(len(nodes))
+
+	for i, node := range nodes {
+		for n := 0; n < node.fast; n++ {
+			length := slot / float64(node.fast)
+			start := start + float64(i)*slot + float64(n)*length
+
+			if node.children != nil {
+				err := p.addMiniNodes(node.children, start, length)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if node.sound == "" {
+				continue
+			}
+
+			step := start * miniNotationSteps
+			if math.Abs(step-math.Round(step)) > 1e-9 {
+				return fmt.Errorf("%s doesn't land on a step", node.sound)
+			}
+
+			name := miniNotationName(node.sound)
+			i := p.trackIndex(name)
+			if i < 0 {
+				p.Tracks = append(p.Tracks, Track{
+					ID:    byte(len(p.Tracks)),
+					Name:  name,
+					Steps: make([]byte, miniNotationSteps),
+				})
+				i = len(p.Tracks) - 1
+			}
+
+			p.Tracks[i].Steps[int(math.Round(step))] = StepOn
+		}
+	}
+
+	return nil
+}
+
+// miniNotationName returns the track name of a sound.
+func miniNotationName(sound string) string {
+	for _, s := range miniNotationSounds {
+		if s.sound == sound {
+			return s.name
+		}
+	}
+
+	return sound
+}
+
+// miniParser parses a single layer of mini-notation.
+type miniParser struct {
+	s   string
+	pos int
+}
+
+// sequence parses steps up to the closing character, or the end of input
+// if it's 0.
+func (p *miniParser) sequence(closing byte) ([]miniNode, error) {
+	var nodes []miniNode
+
+	for {
+		p.skipSpaces()
+
+		if p.pos == len(p.s) {
+			if closing != 0 {
+				return nil, fmt.Errorf("missing %q", closing)
+			}
+			break
+		}
+		if p.s[p.pos] == closing {
+			p.pos++
+			break
+		}
+
+		node, err := p.step()
+		if err != nil {
+			return nil, err
+		}
+
+		replicas, err := p.modifiers(&node)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < replicas; i++ {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("empty sequence at %d", p.pos)
+	}
+
+	return nodes, nil
+}
+
+// step parses a sound, a rest or a subsequence.
+func (p *miniParser) step() (miniNode, error) {
+	node := miniNode{fast: 1}
+
+	switch c := p.s[p.pos]; {
+	case c == '[':
+		p.pos++
+		children, err := p.sequence(']')
+		if err != nil {
+			return node, err
+		}
+		node.children = children
+	case c == '~':
+		p.pos++
+	case isMiniWordChar(c):
+		start := p.pos
+		for p.pos < len(p.s) && isMiniWordChar(p.s[p.pos]) {
+			p.pos++
+		}
+		node.sound = p.s[start:p.pos]
+	default:
+		return node, fmt.Errorf("unexpected %q at %d", c, p.pos)
+	}
+
+	return node, nil
+}
+
+// modifiers parses modifiers following a step, updating the node.
+// It returns the number of times the step is replicated.
+func (p *miniParser) modifiers(node *miniNode) (int, error) {
+	replicas := 1
+
+	for p.pos < len(p.s) && (p.s[p.pos] == '*' || p.s[p.pos] == '!') {
+		modifier := p.s[p.pos]
+		p.pos++
+
+		start := p.pos
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+
+		n, err := strconv.Atoi(p.s[start:p.pos])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid count after %q at %d", modifier, start)
+		}
+
+		if modifier == '*' {
+			node.fast *= n
+		} else {
+			replicas *= n
+		}
+	}
+
+	return replicas, nil
+}
+
+func (p *miniParser) skipSpaces() {
+	for p.pos < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// isMiniWordChar returns true if c can be a part of a sound name.
+func isMiniWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == ':' || c == '.'
+}
+
+// importMiniNotation reads a pattern written in mini-notation.
+func importMiniNotation(r io.Reader) (*Pattern, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMiniNotation(strings.TrimSpace(string(data)))
+}
+
+// ExportMiniNotation returns the pattern in Tidal or Strudel mini-notation,
+// with a comma separated layer per track, e.g. "bd*4, ~ sd ~ sd". Each
+// track is a cycle, written on the coarsest grid fitting its hits, with
+// repeats and runs of rests factored out. Track names are mapped to common
+// sample names where possible. Offsets are applied and tracks without
+// hits are skipped.
+func ExportMiniNotation(p *Pattern) string {
+	p.ensureTracks()
+
+	var layers []string
+
+	for _, track := range p.Tracks {
+		steps := track.ShiftedSteps()
+		if countHits(steps) == 0 {
+			continue
+		}
+
+		sound := miniNotationSound(track.Name)
+
+		// Coarsest grid all hits land on
+		grid := len(steps)
+		for grid > 1 && !onGrid(steps, grid) {
+			grid--
+		}
+
+		var tokens []string
+		for i := 0; i < len(steps); i += grid {
+			if isHit(steps[i]) {
+				tokens = append(tokens, sound)
+			} else {
+				tokens = append(tokens, "~")
+			}
+		}
+
+		layers = append(layers, miniSequence(tokens))
+	}
+
+	if len(layers) == 0 {
+		return "~"
+	}
+
+	return strings.Join(layers, ", ")
+}
+
+// onGrid returns true if all hits of steps are on multiples of grid,
+// which divides their number.
+func onGrid(steps []byte, grid int) bool {
+	if len(steps)%grid != 0 {
+		return false
+	}
+
+	for i, step := range steps {
+		if isHit(step) && i%grid != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// miniSequence returns the shortest of the tokens written out with runs
+// replicated, and of their shortest repeated period sped up.
+func miniSequence(tokens []string) string {
+	best := miniRuns(tokens)
+
+	for period := 1; period < len(tokens); period++ {
+		if len(tokens)%period != 0 || !isPeriod(tokens, period) {
+			continue
+		}
+
+		repeats := len(tokens) / period
+		var sequence string
+		if period == 1 {
+			sequence = tokens[0] + "*" + strconv.Itoa(repeats)
+		} else {
+			sequence = "[" + miniRuns(tokens[:period]) + "]*" + strconv.Itoa(repeats)
+		}
+
+		if len(sequence) <= len(best) {
+			best = sequence
+		}
+		break
+	}
+
+	return best
+}
+
+// isPeriod returns true if tokens repeat every period tokens.
+func isPeriod(tokens []string, period int) bool {
+	for i := period; i < len(tokens); i++ {
+		if tokens[i] != tokens[i-period] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// miniRuns returns the tokens separated by spaces, with runs of three or
+// more equal tokens replicated, e.g. "~!3".
+func miniRuns(tokens []string) string {
+	var out []string
+
+	for i := 0; i < len(tokens); {
+		run := 1
+		for i+run < len(tokens) && tokens[i+run] == tokens[i] {
+			run++
+		}
+
+		if run >= 3 {
+			out = append(out, tokens[i]+"!"+strconv.Itoa(run))
+		} else {
+			for j := 0; j < run; j++ {
+				out = append(out, tokens[i])
+			}
+		}
+
+		i += run
+	}
+
+	return strings.Join(out, " ")
+}
+
+// miniNotationSound returns the sample name of a track name, or the track
+// name with characters not allowed in sample names replaced.
+func miniNotationSound(name string) string {
+	for _, s := range miniNotationSounds {
+		if s.name == name {
+			return s.sound
+		}
+	}
+
+	sound := []byte(name)
+	for i, c := range sound {
+		if !isMiniWordChar(c) {
+			sound[i] = '_'
+		}
+	}
+	if len(sound) == 0 {
+		return "_"
+	}
+
+	return string(sound)
+}
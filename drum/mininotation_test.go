@@ -0,0 +1,65 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestParseMiniNotation(t *testing.T) {
+	p, err := ParseMiniNotation("bd ~ sn ~, hh*8, [~ cp]!2 ~ ~, bd:3 [~ bd]*2 ~ ~")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: 
+Tempo: 120
+(0) kick	|x---|-x-x|----|----|
+(1) snare	|----|----|x---|----|
+(2) hh-close	|x-x-|x-x-|x-x-|x-x-|
+(3) clap	|--x-|--x-|----|----|
+(4) bd:3	|x---|----|----|----|
+`
+	if out := p.String(); out != expected {
+		t.Fatalf("wrong pattern.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+
+	for _, invalid := range []string{
+		"bd*3",
+		"[bd sn",
+		"bd ]",
+		"bd*0",
+		"<bd sn>",
+		"",
+		"bd, ",
+	} {
+		if _, err := ParseMiniNotation(invalid); err == nil {
+			t.Errorf("expected error parsing %q", invalid)
+		}
+	}
+}
+
+func TestExportMiniNotation(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "bd*4, [~ sd]*2, ~ ~ cp cp ~!4, ~ oh ~ oh!3 ~ oh, hh ~!3 hh ~!7 hh ~ ~ hh, ~!5 cb ~ ~"
+	if out := ExportMiniNotation(p); out != expected {
+		t.Fatalf("wrong mini-notation.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+
+	parsed, err := ParseMiniNotation(ExportMiniNotation(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, track := range parsed.Tracks {
+		if string(track.Steps) != string(p.Tracks[i].Steps) || track.Name != p.Tracks[i].Name {
+			t.Errorf("track %s parsed as %s %v", p.Tracks[i].Name, track.Name, track.Steps)
+		}
+	}
+
+	if out := ExportMiniNotation(&Pattern{}); out != "~" {
+		t.Errorf("unexpected mini-notation of an empty pattern %q", out)
+	}
+}
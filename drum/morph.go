@@ -0,0 +1,96 @@
+package drum
+
+import "math"
+
+// Morph blends two patterns, returning an intermediate groove. At t of 0
+// the result equals a, at 1 it equals b. Tracks are matched by name and
+// for each, fraction t of steps differing between a and b is taken from b,
+// spread evenly across the track. Tracks present in only one pattern are
+// morphed from or to silence and kept on the closer side of the blend.
+// The tempo is interpolated linearly.
+func Morph(a, b *Pattern, t float64) *Pattern {
+	a.ensureTracks()
+	b.ensureTracks()
+
+	t = math.Max(0, math.Min(1, t))
+
+	version := a.Version
+	if t >= 0.5 {
+		version = b.Version
+	}
+
+	morph := &Pattern{
+		Version: version,
+		Tempo:   a.Tempo + (b.Tempo-a.Tempo)*float32(t),
+	}
+
+	for _, track := range a.Tracks {
+		other, ok := findTrack(b, track.Name)
+		if !ok && t >= 0.5 {
+			continue
+		}
+
+		morph.Tracks = append(morph.Tracks, Track{
+			ID:    track.ID,
+			Name:  track.Name,
+			Steps: morphSteps(track.Unpacked(), other.Steps, t),
+		})
+	}
+
+	for _, track := range b.Tracks {
+		if _, ok := findTrack(a, track.Name); ok || t < 0.5 {
+			continue
+		}
+
+		morph.Tracks = append(morph.Tracks, Track{
+			ID:    track.ID,
+			Name:  track.Name,
+			Steps: morphSteps(nil, track.Unpacked(), t),
+		})
+	}
+
+	return morph
+}
+
+// findTrack returns the first track of the pattern with the given name,
+// with its steps unpacked.
+func findTrack(p *Pattern, name string) (Track, bool) {
+	for _, track := range p.Tracks {
+		if track.Name == name {
+			track.Unpack()
+			return track, true
+		}
+	}
+
+	return Track{}, false
+}
+
+// morphSteps returns steps of a with fraction t of steps differing
+// from b replaced by steps of b. Missing steps are treated as off.
+func morphSteps(a, b []byte, t float64) []byte {
+	steps := make([]byte, max(len(a), len(b)))
+	copy(steps, a)
+
+	diffs := 0
+	for i := range steps {
+		if stepAt(a, i) != stepAt(b, i) {
+			// Take every difference at which the rounded count of taken
+			// ones increases, so they're spread evenly
+			if math.Floor(float64(diffs+1)*t+0.5) > math.Floor(float64(diffs)*t+0.5) {
+				steps[i] = stepAt(b, i)
+			}
+			diffs++
+		}
+	}
+
+	return steps
+}
+
+// stepAt returns value of step i, or 0 if there's no such step.
+func stepAt(steps []byte, i int) byte {
+	if i < len(steps) {
+		return steps[i]
+	}
+
+	return 0
+}
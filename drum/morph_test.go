@@ -0,0 +1,35 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestMorph(t *testing.T) {
+	a, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DecodeFile(path.Join("fixtures", tData[1].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := Morph(a, b, 0).String(); out != a.String() {
+		t.Fatalf("morph at 0 isn't equal to a:\n%s", out)
+	}
+	if out := Morph(a, b, 1).String(); out != b.String() {
+		t.Fatalf("morph at 1 isn't equal to b:\n%s", out)
+	}
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 109.2
+(0) kick	|x---|----|x---|x---|
+(1) snare	|----|x---|----|x---|
+(3) hh-open	|--x-|--x-|x-x-|--x-|
+(5) cowbell	|----|----|x-x-|----|
+`
+	if out := Morph(a, b, 0.5).String(); out != expected {
+		t.Fatalf("wrong morph.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+}
@@ -0,0 +1,316 @@
+package drum
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// musicXMLDivisions is the number of MusicXML duration units
+// in a quarter note, so a single step lasts a unit.
+const musicXMLDivisions = beatSteps
+
+// musicXMLDoctype is the document type declaration of partwise scores.
+const musicXMLDoctype = `<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 3.1 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">`
+
+// musicXMLPosition is where an instrument is written on the percussion
+// staff and with which notehead.
+type musicXMLPosition struct {
+	step     string
+	octave   int
+	notehead string
+}
+
+// musicXMLPositions maps General MIDI percussion notes to positions of
+// the common drum set notation: drums below the top line with normal
+// noteheads, cymbals above it with crosses.
+var musicXMLPositions = map[byte]musicXMLPosition{
+	35: {"F", 4, ""},
+	36: {"F", 4, ""},
+	37: {"C", 5, "x"},
+	38: {"C", 5, ""},
+	39: {"B", 4, "x"},
+	40: {"C", 5, ""},
+	41: {"A", 4, ""},
+	42: {"G", 5, "x"},
+	43: {"A", 4, ""},
+	44: {"D", 4, "x"},
+	45: {"B", 4, ""},
+	46: {"G", 5, "circle-x"},
+	47: {"D", 5, ""},
+	48: {"E", 5, ""},
+	49: {"A", 5, "x"},
+	50: {"E", 5, ""},
+	51: {"F", 5, "x"},
+	54: {"B", 5, "triangle"},
+	56: {"E", 5, "triangle"},
+}
+
+// musicXMLDefaultPosition is the position of instruments without
+// a standard notation.
+var musicXMLDefaultPosition = musicXMLPosition{"E", 4, "diamond"}
+
+// musicXMLTypes are note types and whether they're dotted,
+// by duration in steps.
+var musicXMLTypes = [beatSteps + 1]struct {
+	name   string
+	dotted bool
+}{
+	1: {"16th", false},
+	2: {"eighth", false},
+	3: {"eighth", true},
+	4: {"quarter", false},
+}
+
+type musicXMLScore struct {
+	XMLName  xml.Name `xml:"score-partwise"`
+	Version  string   `xml:"version,attr"`
+	Work     string   `xml:"work>work-title"`
+	PartList struct {
+		ScorePart struct {
+			ID              string                   `xml:"id,attr"`
+			Name            string                   `xml:"part-name"`
+			Instruments     []musicXMLInstrument     `xml:"score-instrument"`
+			MIDIInstruments []musicXMLMIDIInstrument `xml:"midi-instrument"`
+		} `xml:"score-part"`
+	} `xml:"part-list"`
+	Part struct {
+		ID       string            `xml:"id,attr"`
+		Measures []musicXMLMeasure `xml:"measure"`
+	} `xml:"part"`
+}
+
+type musicXMLInstrument struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"instrument-name"`
+}
+
+// musicXMLInstrumentRef refers to an instrument of the part from a note.
+type musicXMLInstrumentRef struct {
+	ID string `xml:"id,attr"`
+}
+
+type musicXMLMIDIInstrument struct {
+	ID        string `xml:"id,attr"`
+	Channel   int    `xml:"midi-channel"`
+	Unpitched int    `xml:"midi-unpitched"`
+}
+
+type musicXMLMeasure struct {
+	Number     int                 `xml:"number,attr"`
+	Attributes *musicXMLAttributes `xml:"attributes,omitempty"`
+	Direction  *musicXMLDirection  `xml:"direction,omitempty"`
+	Notes      []musicXMLNote      `xml:"note"`
+}
+
+type musicXMLAttributes struct {
+	Divisions int `xml:"divisions"`
+	Fifths    int `xml:"key>fifths"`
+	Time      struct {
+		Beats    int `xml:"beats"`
+		BeatType int `xml:"beat-type"`
+	} `xml:"time"`
+	Clef string `xml:"clef>sign"`
+}
+
+type musicXMLDirection struct {
+	Placement string `xml:"placement,attr"`
+	Metronome struct {
+		BeatUnit  string  `xml:"beat-unit"`
+		PerMinute float32 `xml:"per-minute"`
+	} `xml:"direction-type>metronome"`
+	Sound struct {
+		Tempo float32 `xml:"tempo,attr"`
+	} `xml:"sound"`
+}
+
+type musicXMLNote struct {
+	Grace     *musicXMLGrace     `xml:"grace,omitempty"`
+	Chord     *struct{}          `xml:"chord,omitempty"`
+	Unpitched *musicXMLUnpitched `xml:"unpitched,omitempty"`
+	Rest      *struct{}          `xml:"rest,omitempty"`
+	// Grace notes have no duration
+	Duration   int                    `xml:"duration,omitempty"`
+	Instrument *musicXMLInstrumentRef `xml:"instrument,omitempty"`
+	Voice      int                    `xml:"voice"`
+	Type       string                 `xml:"type"`
+	Dot        *struct{}              `xml:"dot,omitempty"`
+	Stem       string                 `xml:"stem,omitempty"`
+	Notehead   string                 `xml:"notehead,omitempty"`
+	Accent     *struct{}              `xml:"notations>articulations>accent,omitempty"`
+}
+
+type musicXMLGrace struct {
+	Slash string `xml:"slash,attr"`
+}
+
+type musicXMLUnpitched struct {
+	Step   string `xml:"display-step"`
+	Octave int    `xml:"display-octave"`
+}
+
+// ExportMusicXML writes the pattern as a MusicXML score with a single
+// percussion part, so it can be opened in notation software for printing.
+// Instruments are placed on the staff following common drum set notation,
+// guessed from track names like GMNote does. Each bar holds 16 steps of
+// sixteenth notes, flams are written as grace notes and hits coinciding
+// with the accent track get accent marks.
+func ExportMusicXML(w io.Writer, p *Pattern) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	score := musicXMLScore{Version: "3.1", Work: p.Version}
+	score.Part.ID = "P1"
+
+	part := &score.PartList.ScorePart
+	part.ID = "P1"
+	part.Name = "Drums"
+
+	instruments := make([]*musicXMLInstrumentRef, len(p.Tracks))
+	positions := make([]musicXMLPosition, len(p.Tracks))
+	steps := make([][]byte, len(p.Tracks))
+	length := 0
+
+	for i, track := range p.Tracks {
+		steps[i] = track.ShiftedSteps()
+		length = max(length, len(steps[i]))
+
+		if track.IsAccent() {
+			continue
+		}
+
+		instrument := musicXMLInstrument{
+			ID:   fmt.Sprintf("P1-I%d", i+1),
+			Name: fmt.Sprintf("(%d) %s", track.ID, track.Name),
+		}
+		part.Instruments = append(part.Instruments, instrument)
+		instruments[i] = &musicXMLInstrumentRef{ID: instrument.ID}
+
+		positions[i] = musicXMLDefaultPosition
+		if note, ok := GMNote(track.Name); ok {
+			part.MIDIInstruments = append(part.MIDIInstruments, musicXMLMIDIInstrument{
+				ID:        instrument.ID,
+				Channel:   10,
+				Unpitched: int(note) + 1,
+			})
+
+			if position, ok := musicXMLPositions[note]; ok {
+				positions[i] = position
+			}
+		}
+	}
+
+	// hits returns indexes of tracks hit on the step.
+	hits := func(step int) []int {
+		var tracks []int
+		for i := range p.Tracks {
+			if instruments[i] != nil && step < len(steps[i]) && isHit(steps[i][step]) {
+				tracks = append(tracks, i)
+			}
+		}
+		return tracks
+	}
+
+	bars := max((length+barSteps-1)/barSteps, 1)
+	for bar := 0; bar < bars; bar++ {
+		measure := musicXMLMeasure{Number: bar + 1}
+
+		if bar == 0 {
+			attributes := &musicXMLAttributes{Divisions: musicXMLDivisions, Clef: "percussion"}
+			attributes.Time.Beats = barSteps / beatSteps
+			attributes.Time.BeatType = 4
+			measure.Attributes = attributes
+
+			direction := &musicXMLDirection{Placement: "above"}
+			direction.Metronome.BeatUnit = "quarter"
+			direction.Metronome.PerMinute = p.Tempo
+			direction.Sound.Tempo = p.Tempo
+			measure.Direction = direction
+		}
+
+		for beat := bar * barSteps; beat < (bar+1)*barSteps; beat += beatSteps {
+			for step := beat; step < beat+beatSteps; step++ {
+				tracks := hits(step)
+				if len(tracks) == 0 && step != beat {
+					continue
+				}
+
+				// Notes last until the next hit or the end of the beat
+				duration := 1
+				for step+duration < beat+beatSteps && len(hits(step+duration)) == 0 {
+					duration++
+				}
+				noteType := musicXMLTypes[duration]
+
+				note := musicXMLNote{Duration: duration, Voice: 1, Type: noteType.name}
+				if noteType.dotted {
+					note.Dot = &struct{}{}
+				}
+
+				if len(tracks) == 0 {
+					note.Rest = &struct{}{}
+					measure.Notes = append(measure.Notes, note)
+					continue
+				}
+
+				measure.Notes = append(measure.Notes, musicXMLGraceNotes(steps, tracks, step, positions, instruments)...)
+
+				for n, i := range tracks {
+					chordNote := note
+					if n > 0 {
+						chordNote.Chord = &struct{}{}
+					}
+					chordNote.Unpitched = &musicXMLUnpitched{positions[i].step, positions[i].octave}
+					chordNote.Instrument = instruments[i]
+					chordNote.Stem = "up"
+					chordNote.Notehead = positions[i].notehead
+					if p.baseVelocity(i, step) == AccentVelocity {
+						chordNote.Accent = &struct{}{}
+					}
+
+					measure.Notes = append(measure.Notes, chordNote)
+				}
+			}
+		}
+
+		score.Part.Measures = append(score.Part.Measures, measure)
+	}
+
+	data, err := xml.MarshalIndent(score, "", "  ")
+	if err != nil {
+		return fmt.Errorf("something went wrong encoding MusicXML - %v", err)
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n%s\n", xml.Header, musicXMLDoctype, data)
+	return err
+}
+
+// musicXMLGraceNotes returns a chord of grace notes of tracks
+// with flams on the step.
+func musicXMLGraceNotes(steps [][]byte, tracks []int, step int, positions []musicXMLPosition, instruments []*musicXMLInstrumentRef) []musicXMLNote {
+	var notes []musicXMLNote
+
+	for _, i := range tracks {
+		if steps[i][step] != StepFlam {
+			continue
+		}
+
+		note := musicXMLNote{
+			Grace:      &musicXMLGrace{Slash: "yes"},
+			Unpitched:  &musicXMLUnpitched{positions[i].step, positions[i].octave},
+			Instrument: instruments[i],
+			Voice:      1,
+			Type:       "eighth",
+			Stem:       "up",
+			Notehead:   positions[i].notehead,
+		}
+		if len(notes) > 0 {
+			note.Chord = &struct{}{}
+		}
+
+		notes = append(notes, note)
+	}
+
+	return notes
+}
@@ -0,0 +1,68 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestExportMusicXML(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: stepsFromString("x-----x----x----")},
+			{ID: 1, Name: "snare", Steps: []byte{0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0}},
+			{ID: 2, Name: "accent", Steps: stepsFromString("x---------------")},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportMusicXML(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+
+	var score musicXMLScore
+	if err := xml.Unmarshal(buf.Bytes(), &score); err != nil {
+		t.Fatalf("exported MusicXML is invalid - %v", err)
+	}
+
+	if n := len(score.PartList.ScorePart.Instruments); n != 2 {
+		t.Errorf("expected 2 instruments without the accent track, got %d", n)
+	}
+	if n := len(score.Part.Measures); n != 1 {
+		t.Fatalf("expected a single measure, got %d", n)
+	}
+
+	// Per beat: quarter kick; flammed snare and kick in eighths;
+	// dotted eighth rest and sixteenth kick; quarter snare
+	var notes []string
+	for _, note := range score.Part.Measures[0].Notes {
+		switch {
+		case note.Grace != nil:
+			notes = append(notes, "grace")
+		case note.Rest != nil:
+			notes = append(notes, "rest/"+note.Type)
+		default:
+			notes = append(notes, note.Unpitched.Step+"/"+note.Type)
+		}
+	}
+
+	expected := "F/quarter grace C/eighth F/eighth rest/eighth F/16th C/quarter"
+	if got := strings.Join(notes, " "); got != expected {
+		t.Fatalf("unexpected notes %q, expected %q", got, expected)
+	}
+
+	for _, expected := range []string{
+		`<!DOCTYPE score-partwise`,
+		`<sign>percussion</sign>`,
+		`<sound tempo="120"></sound>`,
+		`<midi-unpitched>37</midi-unpitched>`,
+		`<accent></accent>`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("exported MusicXML doesn't contain %q", expected)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package drum
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"unicode/utf8"
+)
+
+// maxNameLength is the length of the longest track name the .splice
+// format can store, limited by int on 32-bit platforms.
+const maxNameLength = min(math.MaxUint32, math.MaxInt)
+
+// NamePolicy selects how track names longer than allowed are encoded.
+type NamePolicy int
+
+const (
+	// NameError makes encoding fail
+	NameError NamePolicy = iota
+	// NameTruncate cuts names, ending them with an ellipsis
+	NameTruncate
+	// NameHashSuffix cuts names, ending them with "~" and a hash of the
+	// whole name, so names sharing a long prefix stay distinct
+	NameHashSuffix
+)
+
+// NameTruncation is a track name shortened while encoding.
+type NameTruncation struct {
+	// Track is the index of the track
+	Track   int
+	Name    string
+	Encoded string
+}
+
+// WithMaxNameLength limits the length of encoded track names in bytes,
+// e.g. to the limit of a device. Names are limited to the maximum the
+// .splice format can store by default.
+func WithMaxNameLength(n int) EncodeOption {
+	return func(c *encodeConfig) {
+		c.maxNameLength = n
+	}
+}
+
+// WithNamePolicy sets how track names longer than allowed are encoded.
+// Defaults to NameError.
+func WithNamePolicy(policy NamePolicy) EncodeOption {
+	return func(c *encodeConfig) {
+		c.namePolicy = policy
+	}
+}
+
+// encodeName returns the name to encode following the name policy.
+func (c encodeConfig) encodeName(name string) (string, error) {
+	limit := c.maxNameLength
+	if limit <= 0 {
+		limit = maxNameLength
+	}
+
+	if len(name) <= limit {
+		return name, nil
+	}
+
+	switch c.namePolicy {
+	case NameTruncate:
+		if limit < len(ellipsis) {
+			return cutName(name, limit), nil
+		}
+		return cutName(name, limit-len(ellipsis)) + ellipsis, nil
+	case NameHashSuffix:
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		suffix := fmt.Sprintf("~%06x", h.Sum32()&0xffffff)
+		if limit < len(suffix) {
+			return cutName(name, limit), nil
+		}
+		return cutName(name, limit-len(suffix)) + suffix, nil
+	}
+
+	return "", fmt.Errorf("track name %q is %d bytes long (max %d)", name, len(name), limit)
+}
+
+// cutName returns at most n bytes of name, not splitting characters.
+func cutName(name string, n int) string {
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+
+	return name[:n]
+}
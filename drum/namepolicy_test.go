@@ -0,0 +1,65 @@
+package drum
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamePolicy(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: stepsFromString("x---x---x---x---")},
+			{ID: 1, Name: "snare drum", Steps: stepsFromString("----x-------x---")},
+			{ID: 2, Name: "ハイハット", Steps: stepsFromString("x-x-x-x-x-x-x-x-")},
+		},
+	}
+
+	_, _, err := EncodeBytes(p, WithMaxNameLength(8))
+	if err == nil || !strings.Contains(err.Error(), `track name "snare drum" is 10 bytes long (max 8)`) {
+		t.Fatalf("expected name error, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		policy   NamePolicy
+		expected []string
+	}{
+		{NameTruncate, []string{"snare", "ハ…"}},
+		{NameHashSuffix, []string{"s~", "~"}},
+	} {
+		data, truncations, err := EncodeBytes(p, WithMaxNameLength(8), WithNamePolicy(tc.policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(truncations) != 2 || truncations[0].Track != 1 || truncations[1].Track != 2 {
+			t.Fatalf("unexpected truncations %+v", truncations)
+		}
+		for i, truncation := range truncations {
+			if !strings.HasPrefix(truncation.Encoded, tc.expected[i]) || len(truncation.Encoded) > 8 {
+				t.Fatalf("policy %d: unexpected name %q", tc.policy, truncation.Encoded)
+			}
+		}
+
+		decoded, err := DecodeBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Tracks[1].Name != truncations[0].Encoded || decoded.Tracks[0].Name != "kick" {
+			t.Fatalf("unexpected decoded names %q, %q", decoded.Tracks[0].Name, decoded.Tracks[1].Name)
+		}
+
+		report := p.EncodePlan(WithMaxNameLength(8), WithNamePolicy(tc.policy))
+		if report.Err != nil || report.Size != len(data) || len(report.Truncations) != 2 {
+			t.Fatalf("plan doesn't match encoded data: %v", report)
+		}
+	}
+
+	// Names differing past the limit stay distinct with hash suffixes
+	a, _ := encodeConfig{maxNameLength: 8, namePolicy: NameHashSuffix}.encodeName("cymbal crash")
+	b, _ := encodeConfig{maxNameLength: 8, namePolicy: NameHashSuffix}.encodeName("cymbal china")
+	if a == b {
+		t.Fatalf("hash suffixed names collide: %q", a)
+	}
+}
@@ -0,0 +1,36 @@
+package drum
+
+// ShiftedSteps returns the track's steps rotated by its Offset, as they
+// should be played or exported. Steps shifted past the end wrap around.
+func (t Track) ShiftedSteps() []byte {
+	steps := t.Unpacked()
+	n := len(steps)
+	shifted := make([]byte, n)
+
+	for i, step := range steps {
+		shifted[((i+t.Offset)%n+n)%n] = step
+	}
+
+	return shifted
+}
+
+// shiftedStep returns the step at the index of ShiftedSteps, without
+// shifting all of them.
+func (t Track) shiftedStep(i int) byte {
+	n := t.Len()
+	return byte(t.Step(StepIndex(((i-t.Offset)%n + n) % n)))
+}
+
+// ApplyOffsets returns a copy of the pattern with offsets of all tracks
+// applied to their steps and reset to zero.
+func (p *Pattern) ApplyOffsets() *Pattern {
+	c := p.Clone()
+	c.Unpack()
+
+	for i := range c.Tracks {
+		c.Tracks[i].Steps = c.Tracks[i].ShiftedSteps()
+		c.Tracks[i].Offset = 0
+	}
+
+	return c
+}
@@ -0,0 +1,61 @@
+package drum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestShiftedSteps(t *testing.T) {
+	track := Track{Steps: []byte{1, 0, 0, 1}}
+
+	for _, c := range []struct {
+		offset   int
+		expected []byte
+	}{
+		{0, []byte{1, 0, 0, 1}},
+		{1, []byte{1, 1, 0, 0}},
+		{-1, []byte{0, 0, 1, 1}},
+		{6, []byte{0, 1, 1, 0}},
+	} {
+		track.Offset = c.offset
+		if shifted := track.ShiftedSteps(); !bytes.Equal(shifted, c.expected) {
+			t.Errorf("offset %d: expected %v, got %v", c.offset, c.expected, shifted)
+		}
+	}
+
+	if !bytes.Equal(track.Steps, []byte{1, 0, 0, 1}) {
+		t.Error("ShiftedSteps modified the track")
+	}
+}
+
+func TestApplyOffsets(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 1, Name: "snare", Steps: []byte{0, 0, 0, 0, 1, 0, 0, 0}, Offset: 1},
+		},
+	}
+
+	applied := p.ApplyOffsets()
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(1) snare	|----|-x--|
+`
+	if applied.String() != expected || applied.Tracks[0].Offset != 0 {
+		t.Fatalf("offsets weren't applied.\nGot:\n%s\nExpected:\n%s", applied, expected)
+	}
+
+	encoded, _, err := EncodeBytes(p, WithEncodedStepWidth(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(bytes.NewReader(encoded), WithStepWidth(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.String() != expected {
+		t.Fatalf("offsets weren't applied on encode.\nGot:\n%s\nExpected:\n%s", decoded, expected)
+	}
+}
@@ -0,0 +1,246 @@
+package drum
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// defaultFlamSpacing is the default time between a flam's grace note
+// and its main hit.
+const defaultFlamSpacing = 25 * time.Millisecond
+
+// Option configures decoding.
+type Option func(*decodeConfig)
+
+// decodeConfig holds decoding settings set by options.
+type decodeConfig struct {
+	logger    *slog.Logger
+	maxTracks int
+	strict    bool
+	stepWidth int
+	progress  ProgressFunc
+	packed    bool
+	lazy      bool
+	allErrors bool
+	// Whether track names are transliterated
+	transliterate bool
+	// Passphrase decrypting encrypted patterns
+	passphrase string
+}
+
+// ProgressFunc receives decoding progress: bytes read so far, total bytes
+// or 0 if unknown yet, and number of tracks parsed so far.
+type ProgressFunc func(bytesRead, total uint64, tracksParsed int)
+
+// WithMaxTracks limits the number of tracks a pattern may contain.
+// Decoding a file with more tracks fails. Zero means no limit.
+func WithMaxTracks(n int) Option {
+	return func(c *decodeConfig) {
+		c.maxTracks = n
+	}
+}
+
+// WithStrict makes decoding fail on irregularities that are tolerated
+// by default: unterminated version string, track names that aren't valid
+// UTF-8 or contain control characters, step values other than 0 and 1
+// and data trailing after the declared content length.
+func WithStrict(strict bool) Option {
+	return func(c *decodeConfig) {
+		c.strict = strict
+	}
+}
+
+// WithAllErrors makes decoding continue past problems that don't prevent
+// reading the rest of the data, like invalid step values in strict mode,
+// and fail with all problems found, joined by errors.Join. Problems like
+// a truncated track still stop decoding and are reported last.
+func WithAllErrors(all bool) Option {
+	return func(c *decodeConfig) {
+		c.allErrors = all
+	}
+}
+
+// WithStepWidth sets the number of steps stored in each track.
+// Defaults to 16.
+func WithStepWidth(n int) Option {
+	return func(c *decodeConfig) {
+		c.stepWidth = n
+	}
+}
+
+// WithPackedSteps makes decoded tracks store a bit per step rather than
+// a byte, saving memory in constrained environments. Steps of packed
+// tracks are nil, they're accessed with Track.Step, Track.Unpacked or
+// after Pattern.Unpack.
+func WithPackedSteps(packed bool) Option {
+	return func(c *decodeConfig) {
+		c.packed = packed
+	}
+}
+
+// WithLazyTracks makes decoding parse only the header, version and tempo.
+// Tracks are parsed on the first call to Pattern.LoadTracks, or of a method
+// using them, so scans needing only tempos and versions of many files skip
+// track data.
+func WithLazyTracks(lazy bool) Option {
+	return func(c *decodeConfig) {
+		c.lazy = lazy
+	}
+}
+
+// WithProgress sets a function called as decoding progresses: while Decode
+// reads its input, with total unknown, and after parsing each track.
+func WithProgress(f ProgressFunc) Option {
+	return func(c *decodeConfig) {
+		c.progress = f
+	}
+}
+
+// WithLogger sets a logger receiving debug events emitted while decoding,
+// useful for tracing why a malformed file fails to decode. The pattern
+// keeps the logger, so its encoding and playback are logged too.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *decodeConfig) {
+		c.logger = logger
+	}
+}
+
+// Logger returns the logger the pattern was decoded with, or nil.
+func (p *Pattern) Logger() *slog.Logger {
+	return p.config.logger
+}
+
+// debug logs a debug event if a logger is set.
+func (p *Pattern) debug(msg string, args ...any) {
+	if p.config.logger != nil {
+		p.config.logger.Debug(msg, args...)
+	}
+}
+
+// reportProgress reports parsing progress if a progress function is set.
+func (p *Pattern) reportProgress(total uint64) {
+	if p.config.progress == nil || p.lastErr != nil {
+		return
+	}
+
+	p.config.progress(p.currentOffset(), total, len(p.Tracks))
+}
+
+// progressReader reports bytes read from the underlying reader.
+type progressReader struct {
+	r        io.Reader
+	read     uint64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(data []byte) (int, error) {
+	n, err := r.r.Read(data)
+	if n > 0 {
+		r.read += uint64(n)
+		r.progress(r.read, 0, 0)
+	}
+
+	return n, err
+}
+
+// stepWidthOrDefault returns the configured number of steps per track.
+func (c decodeConfig) stepWidthOrDefault() int {
+	if c.stepWidth > 0 {
+		return c.stepWidth
+	}
+
+	return trackSteps
+}
+
+// problem records a problem that doesn't prevent reading further. It
+// fails decoding at once, unless all errors are collected.
+func (p *Pattern) problem(err error) {
+	if !p.config.allErrors {
+		p.lastErr = err
+		return
+	}
+
+	p.debug("problem found", "error", err)
+	p.problems = append(p.problems, err)
+}
+
+// joinProblems fails decoding with all recorded problems, followed by
+// the error that stopped decoding, if any.
+func (p *Pattern) joinProblems() {
+	if len(p.problems) > 0 {
+		p.lastErr = errors.Join(append(p.problems, p.lastErr)...)
+	}
+}
+
+// checkTrackCount sets an error if another track would exceed the limit.
+func (p *Pattern) checkTrackCount() {
+	if p.lastErr != nil || p.config.maxTracks <= 0 {
+		return
+	}
+
+	if len(p.Tracks) >= p.config.maxTracks {
+		p.lastErr = fmt.Errorf("too many tracks (max %d)", p.config.maxTracks)
+	}
+}
+
+// EncodeOption configures encoding.
+type EncodeOption func(*encodeConfig)
+
+// encodeConfig holds encoding settings set by options.
+type encodeConfig struct {
+	maxNameLength int
+	namePolicy    NamePolicy
+	transliterate bool
+	stepWidth     int
+}
+
+// ExportOption configures exporting.
+type ExportOption func(*exportConfig)
+
+// exportConfig holds exporting settings set by options.
+type exportConfig struct {
+	flamSpacing time.Duration
+	theme       Theme
+	groove      *Groove
+	diffLabels  [2]string
+}
+
+// WithFlamSpacing sets the time between a flam's grace note and its
+// main hit. Defaults to 25ms.
+func WithFlamSpacing(spacing time.Duration) ExportOption {
+	return func(c *exportConfig) {
+		c.flamSpacing = spacing
+	}
+}
+
+// WithTheme sets the theme of visual exports. Defaults to DefaultTheme.
+func WithTheme(theme Theme) ExportOption {
+	return func(c *exportConfig) {
+		c.theme = theme
+	}
+}
+
+// newExportConfig returns export settings with opts applied to defaults.
+func newExportConfig(opts []ExportOption) exportConfig {
+	c := exportConfig{
+		flamSpacing: defaultFlamSpacing,
+		theme:       DefaultTheme,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// WithGroove locks timing and velocities of exported hits to the groove,
+// e.g. one extracted from a reference song, instead of the straight grid.
+func WithGroove(g Groove) ExportOption {
+	return func(c *exportConfig) {
+		c.groove = &g
+	}
+}
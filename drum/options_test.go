@@ -0,0 +1,172 @@
+package drum
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log/slog"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`msg="header parsed" length=197`,
+		"version=0.808-alpha tempo=120",
+		`msg="track parsed" offset=50 id=0 name=kick`,
+		`msg="track parsed" offset=183 id=5 name=cowbell`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("log doesn't contain %q:\n%s", expected, buf.String())
+		}
+	}
+
+	// The pattern keeps the logger for encoding
+	buf.Reset()
+	if _, err := p.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`msg="track encoded" offset=50 id=0 name=kick`,
+		`msg="track encoded" offset=183 id=5 name=cowbell`,
+		`msg="pattern encoded" length=197 size=211`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("log doesn't contain %q:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestDecodeOptions(t *testing.T) {
+	fixture := func(i int) string {
+		return path.Join("fixtures", tData[i].path)
+	}
+
+	if _, err := DecodeFile(fixture(0), WithMaxTracks(5)); err == nil {
+		t.Error("expected error decoding 6 tracks with limit of 5")
+	}
+	if _, err := DecodeFile(fixture(0), WithMaxTracks(6)); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := DecodeFile(fixture(4), WithStrict(true)); err == nil {
+		t.Error("expected error decoding trailing data in strict mode")
+	}
+	if _, err := DecodeFile(fixture(0), WithStrict(true)); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := DecodeFile(fixture(4), WithStepWidth(17)); err == nil {
+		t.Error("expected error decoding with mismatched step width")
+	}
+}
+
+func TestDecodeUnterminatedVersion(t *testing.T) {
+	data := []byte("SPLICE\x00\x00\x00\x00\x00\x00\x00\x24" +
+		"0123456789abcdef0123456789abcdef" + "\x00\x00\xf0\x42")
+
+	decoded, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Version != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("unexpected version %q", decoded.Version)
+	}
+
+	if _, err := Decode(bytes.NewReader(data), WithStrict(true)); err == nil {
+		t.Error("expected error decoding unterminated version in strict mode")
+	}
+}
+
+func TestWithAllErrors(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}},
+			{ID: 1, Name: "snare", Steps: make([]byte, 16)},
+			{ID: 2, Name: "hh", Steps: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4}},
+		},
+	}
+
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, "trailing"...)
+
+	_, err = Decode(bytes.NewReader(data), WithStrict(true))
+	if err == nil || strings.Contains(err.Error(), "hh") {
+		t.Fatalf("expected decoding to stop at the first problem, got %v", err)
+	}
+
+	_, err = Decode(bytes.NewReader(data), WithStrict(true), WithAllErrors(true))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	expected := `invalid value 3 of step 2 in track "kick"
+invalid value 4 of step 15 in track "hh"
+8 bytes of trailing data`
+	if err.Error() != expected {
+		t.Fatalf("unexpected errors:\n%v\nexpected:\n%s", err, expected)
+	}
+}
+
+func TestDecodeNameOverflow(t *testing.T) {
+	data := []byte("SPLICE\x00\x00\x00\x00\x00\x00\x00\x2d" +
+		"0.808-alpha\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00" +
+		"\x00\x00\xf0\x42" + "\x00\x00\x00\x10\x00kick")
+
+	_, err := Decode(bytes.NewReader(data), WithAllErrors(true))
+	if err == nil || !strings.Contains(err.Error(), "name of track 0 is 4096 bytes long") {
+		t.Fatalf("expected name overflow error, got %v", err)
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	data, err := ioutil.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type progress struct {
+		bytesRead, total uint64
+		tracks           int
+	}
+	var reports []progress
+
+	_, err = Decode(bytes.NewReader(data), WithProgress(func(bytesRead, total uint64, tracks int) {
+		reports = append(reports, progress{bytesRead, total, tracks})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading the input, then the header and 6 tracks
+	if len(reports) < 8 {
+		t.Fatalf("expected at least 8 progress reports, got %v", reports)
+	}
+	if r := reports[0]; r.total != 0 || r.tracks != 0 {
+		t.Errorf("unexpected report while reading %+v", r)
+	}
+
+	parsed := reports[len(reports)-7:]
+	for i, r := range parsed {
+		if r.total != uint64(len(data)) || r.tracks != i {
+			t.Errorf("unexpected report %+v after %d tracks", r, i)
+		}
+		if i > 0 && r.bytesRead <= parsed[i-1].bytesRead {
+			t.Errorf("bytes read didn't grow: %v", parsed)
+		}
+	}
+}
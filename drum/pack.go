@@ -0,0 +1,258 @@
+package drum
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// PackManifestName is the path of the manifest in pattern packs.
+const PackManifestName = "manifest.json"
+
+// PackManifest is machine-readable attribution of a pattern pack,
+// stored in the pack along with its patterns.
+type PackManifest struct {
+	Name    string     `json:"name,omitempty"`
+	Author  string     `json:"author"`
+	License string     `json:"license"`
+	URLs    []string   `json:"urls,omitempty"`
+	Files   []PackFile `json:"files"`
+}
+
+// PackFile is attribution of a single pattern of a pack.
+type PackFile struct {
+	Path string `json:"path"`
+	// SHA256 is the hex encoded hash of the file's data
+	SHA256 string `json:"sha256"`
+	// SidecarSHA256 is the hash of the file's metadata sidecar, if any
+	SidecarSHA256 string `json:"sidecarSha256,omitempty"`
+	// Signer is the hex encoded public key of the pattern's signature,
+	// if it's signed and the signature is valid, see Sign
+	Signer string `json:"signer,omitempty"`
+	// Author and License override those of the pack if set
+	Author  string   `json:"author,omitempty"`
+	License string   `json:"license,omitempty"`
+	Credits []string `json:"credits,omitempty"`
+}
+
+// BuildPackManifest returns the manifest listing all .splice files found
+// in the file system, with hashes of them and their sidecars, and signers
+// of valid signatures. Credits and other attribution of files already
+// listed in m are kept, missing files are dropped.
+func BuildPackManifest(fsys fs.FS, m PackManifest) (PackManifest, error) {
+	listed := map[string]PackFile{}
+	for _, file := range m.Files {
+		listed[file.Path] = file
+	}
+
+	m.Files = nil
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != spliceExtension {
+			return nil
+		}
+
+		sum, err := fileSHA256(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		file := listed[p]
+		file.Path = p
+		file.SHA256 = sum
+
+		file.SidecarSHA256, err = fileSHA256(fsys, SidecarPath(p))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		pattern, err := DecodeFS(fsys, p)
+		if err != nil {
+			return fmt.Errorf("%s: %v", p, err)
+		}
+		file.Signer = ""
+		if pattern.Signature != nil && Verify(pattern, pattern.Signature.PublicKey) == nil {
+			file.Signer = hex.EncodeToString(pattern.Signature.PublicKey)
+		}
+
+		m.Files = append(m.Files, file)
+
+		return nil
+	})
+	if err != nil {
+		return PackManifest{}, err
+	}
+
+	return m, nil
+}
+
+// Validate returns an error if the manifest lacks attribution or doesn't
+// match patterns in the file system: a listed file or sidecar is missing,
+// changed or malformed, a listed signer didn't sign the pattern, or
+// a pattern or sidecar isn't listed.
+func (m PackManifest) Validate(fsys fs.FS) error {
+	if m.Author == "" {
+		return errors.New("manifest has no author")
+	}
+	if m.License == "" {
+		return errors.New("manifest has no license")
+	}
+
+	listed := map[string]bool{}
+	for _, file := range m.Files {
+		if listed[file.Path] {
+			return fmt.Errorf("%s listed twice", file.Path)
+		}
+		listed[file.Path] = true
+
+		sum, err := fileSHA256(fsys, file.Path)
+		if err != nil {
+			return err
+		}
+		if sum != file.SHA256 {
+			return fmt.Errorf("%s doesn't match its hash", file.Path)
+		}
+
+		sidecar := SidecarPath(file.Path)
+		sum, err = fileSHA256(fsys, sidecar)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && file.SidecarSHA256 == "":
+		case err != nil:
+			return err
+		case file.SidecarSHA256 == "":
+			return fmt.Errorf("%s isn't listed in the manifest", sidecar)
+		case sum != file.SidecarSHA256:
+			return fmt.Errorf("%s doesn't match its hash", sidecar)
+		}
+
+		p, err := DecodeFS(fsys, file.Path)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.Path, err)
+		}
+
+		if file.Signer != "" {
+			key, err := hex.DecodeString(file.Signer)
+			if err != nil {
+				return fmt.Errorf("%s: invalid signer - %v", file.Path, err)
+			}
+			err = Verify(p, key)
+			if err != nil {
+				return fmt.Errorf("%s: %v", file.Path, err)
+			}
+		}
+	}
+
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && path.Ext(p) == spliceExtension && !listed[p] {
+			return fmt.Errorf("%s isn't listed in the manifest", p)
+		}
+
+		return nil
+	})
+}
+
+// WritePack writes a zip archive holding the manifest, patterns it lists
+// and their metadata sidecars listed with hashes, read from the file system.
+func WritePack(w io.Writer, fsys fs.FS, m PackManifest) error {
+	zw := zip.NewWriter(w)
+
+	manifest, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	f, err := zw.Create(PackManifestName)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(manifest, '\n'))
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, file := range m.Files {
+		paths = append(paths, file.Path)
+		if file.SidecarSHA256 != "" {
+			paths = append(paths, SidecarPath(file.Path))
+		}
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		f, err := zw.Create(p)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// DecodePack validates the manifest of the zip archive read from r and
+// decodes patterns it lists, keyed by their paths. Packs without attribution
+// or with files not matching the manifest aren't decoded.
+func DecodePack(r io.ReaderAt, size int64, opts ...Option) (PackManifest, map[string]*Pattern, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return PackManifest{}, nil, fmt.Errorf("something went wrong reading pack - %v", err)
+	}
+
+	data, err := fs.ReadFile(zr, PackManifestName)
+	if err != nil {
+		return PackManifest{}, nil, err
+	}
+
+	var m PackManifest
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return PackManifest{}, nil, fmt.Errorf("something went wrong decoding manifest - %v", err)
+	}
+
+	err = m.Validate(zr)
+	if err != nil {
+		return PackManifest{}, nil, err
+	}
+
+	patterns := map[string]*Pattern{}
+	for _, file := range m.Files {
+		patterns[file.Path], err = DecodeFS(zr, file.Path, opts...)
+		if err != nil {
+			return PackManifest{}, nil, fmt.Errorf("%s: %v", file.Path, err)
+		}
+	}
+
+	return m, patterns, nil
+}
+
+// fileSHA256 returns the hex encoded hash of the file at path.
+func fileSHA256(fsys fs.FS, path string) (string, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
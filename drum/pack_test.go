@@ -0,0 +1,156 @@
+package drum
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPack(t *testing.T) {
+	fsys := os.DirFS("fixtures")
+
+	m, err := BuildPackManifest(fsys, PackManifest{
+		Author:  "m110",
+		License: "CC-BY-4.0",
+		Files:   []PackFile{{Path: tData[1].path, Credits: []string{"hi-hats by a friend"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Files) != len(tData) {
+		t.Fatalf("expected %d files, got %+v", len(tData), m.Files)
+	}
+	if m.Files[1].Path != tData[1].path || len(m.Files[1].Credits) != 1 || m.Files[1].SHA256 == "" {
+		t.Fatalf("attribution of %s not kept: %+v", tData[1].path, m.Files[1])
+	}
+
+	var pack bytes.Buffer
+	err = WritePack(&pack, fsys, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decodedManifest, patterns, err := DecodePack(bytes.NewReader(pack.Bytes()), int64(pack.Len()))
+	if err != nil {
+		t.Fatalf("something went wrong decoding pack - %v", err)
+	}
+	if decodedManifest.License != m.License || len(decodedManifest.Files) != len(m.Files) {
+		t.Fatalf("manifest not preserved: %+v", decodedManifest)
+	}
+
+	for _, exp := range tData {
+		if fmt.Sprint(patterns[exp.path]) != exp.output {
+			t.Fatalf("%s wasn't decoded as expected:\n%v", exp.path, patterns[exp.path])
+		}
+	}
+}
+
+func TestPackManifestValidate(t *testing.T) {
+	data, err := os.ReadFile("fixtures/" + tData[0].path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{"beat.splice": {Data: data}, "beat.meta.json": {Data: []byte("{}\n")}}
+	valid, err := BuildPackManifest(fsys, PackManifest{Author: "m110", License: "MIT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid.Files[0].SidecarSHA256 == "" {
+		t.Fatalf("sidecar not hashed: %+v", valid.Files[0])
+	}
+	if err := valid.Validate(fsys); err != nil {
+		t.Fatal(err)
+	}
+
+	noLicense := valid
+	noLicense.License = ""
+
+	changed := valid
+	changed.Files = []PackFile{{Path: "beat.splice", SHA256: "00"}}
+
+	unlisted := valid
+	unlisted.Files = nil
+
+	changedSidecar := valid
+	changedSidecar.Files = []PackFile{valid.Files[0]}
+	changedSidecar.Files[0].SidecarSHA256 = "00"
+
+	unlistedSidecar := valid
+	unlistedSidecar.Files = []PackFile{valid.Files[0]}
+	unlistedSidecar.Files[0].SidecarSHA256 = ""
+
+	for _, tc := range []struct {
+		manifest PackManifest
+		err      string
+	}{
+		{noLicense, "no license"},
+		{changed, "doesn't match"},
+		{unlisted, "isn't listed"},
+		{changedSidecar, "beat.meta.json doesn't match"},
+		{unlistedSidecar, "beat.meta.json isn't listed"},
+	} {
+		err := tc.manifest.Validate(fsys)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("expected error containing %q, got %v", tc.err, err)
+		}
+	}
+}
+
+func TestPackSigner(t *testing.T) {
+	dir := t.TempDir()
+	for i, name := range []string{"signed.splice", "unsigned.splice"} {
+		data, err := os.ReadFile("fixtures/" + tData[i].path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pub, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := DecodeFile(filepath.Join(dir, "signed.splice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Sign(p, private); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSidecar(p, filepath.Join(dir, "signed.splice")); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := os.DirFS(dir)
+	m, err := BuildPackManifest(fsys, PackManifest{Author: "m110", License: "MIT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Files[0].Signer != hex.EncodeToString(pub) || m.Files[1].Signer != "" {
+		t.Fatalf("expected only signed.splice signed by %x, got %+v", pub, m.Files)
+	}
+	if err := m.Validate(fsys); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Files[0].Signer = hex.EncodeToString(other)
+	if err := m.Validate(fsys); err == nil || !strings.Contains(err.Error(), ErrUnknownSigner.Error()) {
+		t.Errorf("expected %v for another signer, got %v", ErrUnknownSigner, err)
+	}
+}
@@ -0,0 +1,90 @@
+package drum
+
+// Packed tracks store a bit per step instead of a byte, for decoding into
+// memory-constrained environments, see WithPackedSteps. Flams can't be
+// stored, they're packed as hits.
+
+// IsPacked returns true if the track's steps are packed, leaving Steps nil.
+func (t Track) IsPacked() bool {
+	return t.packed != nil
+}
+
+// Len returns the number of steps of the track, packed or not.
+func (t Track) Len() int {
+	if t.IsPacked() {
+		return t.packedLen
+	}
+
+	return len(t.Steps)
+}
+
+// Unpacked returns the track's steps as bytes: Steps of an unpacked
+// track, or a copy unpacked from its bits. It's a view for code written
+// for Steps.
+func (t Track) Unpacked() []byte {
+	if !t.IsPacked() {
+		return t.Steps
+	}
+
+	steps := make([]byte, t.packedLen)
+	for i := range steps {
+		if t.packed[i/8]&(1<<uint(i%8)) != 0 {
+			steps[i] = StepOn
+		}
+	}
+
+	return steps
+}
+
+// Pack packs the track's steps into bits and sets Steps to nil.
+func (t *Track) Pack() {
+	if t.IsPacked() {
+		return
+	}
+
+	t.packed = packSteps(t.Steps)
+	t.packedLen = len(t.Steps)
+	t.Steps = nil
+}
+
+// Unpack sets the track's Steps unpacked from its bits.
+func (t *Track) Unpack() {
+	if !t.IsPacked() {
+		return
+	}
+
+	t.Steps = t.Unpacked()
+	t.packed, t.packedLen = nil, 0
+}
+
+// Pack packs steps of all tracks of the pattern.
+func (p *Pattern) Pack() {
+	p.ensureTracks()
+
+	for i := range p.Tracks {
+		p.Tracks[i].Pack()
+	}
+}
+
+// Unpack unpacks steps of all tracks of the pattern, so it can be used
+// with functions working on Steps.
+func (p *Pattern) Unpack() {
+	p.ensureTracks()
+
+	for i := range p.Tracks {
+		p.Tracks[i].Unpack()
+	}
+}
+
+// packSteps returns hits of steps as bits, step i being bit i%8
+// of byte i/8. The result isn't nil, even for no steps.
+func packSteps(steps []byte) []byte {
+	packed := make([]byte, (len(steps)+7)/8)
+	for i, step := range steps {
+		if isHit(step) {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+
+	return packed
+}
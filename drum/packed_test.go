@@ -0,0 +1,123 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestWithPackedSteps(t *testing.T) {
+	for _, exp := range tData {
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path), WithPackedSteps(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, track := range decoded.Tracks {
+			if !track.IsPacked() || track.Steps != nil || track.Len() != 16 {
+				t.Fatalf("track %s of %s isn't packed", track.Name, exp.path)
+			}
+		}
+
+		if fmt.Sprint(decoded) != exp.output {
+			t.Fatalf("%s wasn't decoded as expected.\nGot:\n%s\nExpected:\n%s", exp.path, decoded, exp.output)
+		}
+
+		unpacked, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, track := range decoded.Tracks {
+			if track.Bitmask() != unpacked.Tracks[i].Bitmask() {
+				t.Errorf("bitmask of packed track %s differs", track.Name)
+			}
+		}
+
+		decoded.Unpack()
+		for i, track := range decoded.Tracks {
+			if !bytes.Equal(track.Steps, unpacked.Tracks[i].Steps) {
+				t.Errorf("track %s unpacked as %v", track.Name, track.Steps)
+			}
+		}
+	}
+}
+
+func TestPackedTrack(t *testing.T) {
+	track := Track{Steps: stepsFromString("x---x---x-x-x---x")}
+	track.Pack()
+
+	if err := track.SetStep(1, Flam); err != nil {
+		t.Fatal(err)
+	}
+	if err := track.SetStep(16, Off); err != nil {
+		t.Fatal(err)
+	}
+	if s := track.Step(1); s != On {
+		t.Errorf("unexpected step %v", s)
+	}
+
+	if steps := track.Unpacked(); !bytes.Equal(steps, stepsFromString("xx--x---x-x-x----")) {
+		t.Errorf("unexpected steps %v", steps)
+	}
+}
+
+func TestPackedRoundTrip(t *testing.T) {
+	for _, exp := range tData {
+		packed, err := DecodeFile(path.Join("fixtures", exp.path), WithPackedSteps(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		unpacked, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := packed.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := unpacked.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Fatalf("packed %s encoded differently", exp.path)
+		}
+		if report := packed.EncodePlan(); report.Size != len(data) || report.Lossy() {
+			t.Errorf("unexpected plan of packed %s: %v", exp.path, report)
+		}
+
+		decoded := &Pattern{}
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(decoded) != exp.output {
+			t.Fatalf("packed %s didn't round-trip.\nGot:\n%s\nExpected:\n%s", exp.path, decoded, exp.output)
+		}
+
+		if !reflect.DeepEqual(packed.Events(), unpacked.Events()) {
+			t.Errorf("events of packed %s differ", exp.path)
+		}
+		if packed.Density() != unpacked.Density() {
+			t.Errorf("density of packed %s is %v, expected %v", exp.path, packed.Density(), unpacked.Density())
+		}
+		if FormatCanonical(packed) != FormatCanonical(unpacked) {
+			t.Errorf("canonical text of packed %s differs", exp.path)
+		}
+	}
+}
+
+func TestPackedClone(t *testing.T) {
+	p := &Pattern{Tracks: []Track{{Name: "kick", Steps: stepsFromString("x---x---")}}}
+	p.Pack()
+
+	c := p.Clone()
+	if err := c.Tracks[0].SetStep(1, On); err != nil {
+		t.Fatal(err)
+	}
+	if p.Tracks[0].Step(1) != Off {
+		t.Error("setting a step of a clone changed the original")
+	}
+}
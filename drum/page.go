@@ -0,0 +1,73 @@
+package drum
+
+import "strings"
+
+// Paginate splits the pattern into pages of stepsPerPage steps, the way
+// hardware splits long sequences into pages, e.g. 64 steps into four pages
+// of 16. The last page is shorter if steps don't divide evenly. Track
+// offsets are applied before splitting. It returns nil if stepsPerPage
+// isn't positive.
+func Paginate(p *Pattern, stepsPerPage int) []*Pattern {
+	p.ensureTracks()
+
+	if stepsPerPage <= 0 {
+		return nil
+	}
+
+	applied := p.ApplyOffsets()
+
+	length := 0
+	for _, track := range applied.Tracks {
+		if len(track.Steps) > length {
+			length = len(track.Steps)
+		}
+	}
+
+	var pages []*Pattern
+	for from := 0; from < length; from += stepsPerPage {
+		to := min(from+stepsPerPage, length)
+
+		page := applied.Clone()
+		for i, track := range applied.Tracks {
+			steps := make([]byte, to-from)
+			for s := range steps {
+				steps[s] = stepAt(track.Steps, from+s)
+			}
+			page.Tracks[i].Steps = steps
+		}
+
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// PageChain is the order in which pages are played, by their labels.
+type PageChain []string
+
+// NewPageChain returns a chain playing the given number of pages in order,
+// labeled A, B, C and so on, then AA, AB, etc.
+func NewPageChain(pages int) PageChain {
+	chain := make(PageChain, pages)
+	for i := range chain {
+		chain[i] = PageLabel(i)
+	}
+
+	return chain
+}
+
+// String returns labels of the chain joined with dashes, e.g. A-B-C-D.
+func (c PageChain) String() string {
+	return strings.Join(c, "-")
+}
+
+// PageLabel returns the label of the page at index i: A to Z, then AA
+// to ZZ and so on.
+func PageLabel(i int) string {
+	label := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		label = string(rune('A'+(i-1)%26)) + label
+	}
+
+	return label
+}
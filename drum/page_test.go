@@ -0,0 +1,48 @@
+package drum
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0, 0, 0, 1, 0, 0, 0, 1, 0}},
+			{ID: 1, Name: "snare", Steps: []byte{0, 0, 1, 0, 0, 0, 1, 0, 0, 0}, Offset: 1},
+		},
+	}
+
+	pages := Paginate(p, 4)
+	if len(pages) != 3 {
+		t.Fatalf("expected 3 pages, got %d", len(pages))
+	}
+
+	var got []string
+	for _, page := range pages {
+		got = append(got, fmt.Sprint(page.Tracks[0].Steps, page.Tracks[1].Steps))
+	}
+
+	expected := []string{"[1 0 0 0] [0 0 0 1]", "[1 0 0 0] [0 0 0 1]", "[1 0] [0 0]"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Fatalf("unexpected pages %v, expected %v", got, expected)
+	}
+
+	if pages := Paginate(p, 0); pages != nil {
+		t.Fatalf("expected no pages, got %d", len(pages))
+	}
+}
+
+func TestPageChain(t *testing.T) {
+	if chain := NewPageChain(4).String(); chain != "A-B-C-D" {
+		t.Fatalf("unexpected chain %s", chain)
+	}
+
+	for i, label := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := PageLabel(i); got != label {
+			t.Errorf("PageLabel(%d) = %s, expected %s", i, got, label)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package plugin loads transforms from Go plugins. Plugins are supported
+// on Linux, macOS and FreeBSD with cgo; elsewhere Load always fails.
+package plugin
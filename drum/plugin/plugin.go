@@ -0,0 +1,33 @@
+//go:build (linux || darwin || freebsd) && cgo
+
+package plugin
+
+import (
+	"fmt"
+	goplugin "plugin"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// Load loads a Transform from a Go plugin built with
+// "go build -buildmode=plugin". The plugin must export a variable
+// named Transform implementing the drum.Transform interface.
+func Load(path string) (drum.Transform, error) {
+	plug, err := goplugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sym, err := plug.Lookup("Transform")
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup returns a pointer to the exported variable
+	t, ok := sym.(*drum.Transform)
+	if !ok {
+		return nil, fmt.Errorf("%s: Transform is %T, not drum.Transform", path, sym)
+	}
+
+	return *t, nil
+}
@@ -0,0 +1,15 @@
+//go:build !((linux || darwin || freebsd) && cgo)
+
+package plugin
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// Load fails, as Go plugins aren't supported by this build.
+func Load(path string) (drum.Transform, error) {
+	return nil, fmt.Errorf("can't load plugin %s - plugins aren't supported on %s/%s without cgo", path, runtime.GOOS, runtime.GOARCH)
+}
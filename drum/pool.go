@@ -0,0 +1,75 @@
+package drum
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// DecoderPool decodes patterns reusing memory of patterns returned to it,
+// cutting allocations for services decoding many files. Track names are
+// interned, so common names aren't allocated again. It's safe for
+// concurrent use.
+type DecoderPool struct {
+	opts     []Option
+	patterns sync.Pool
+	buffers  sync.Pool
+}
+
+// NewDecoderPool returns a pool decoding patterns with the options.
+func NewDecoderPool(opts ...Option) *DecoderPool {
+	return &DecoderPool{
+		opts: opts,
+		patterns: sync.Pool{New: func() interface{} {
+			return &Pattern{names: map[string]string{}}
+		}},
+		buffers: sync.Pool{New: func() interface{} {
+			return &bytes.Buffer{}
+		}},
+	}
+}
+
+// Decode decodes the drum machine file read from r. The pattern should be
+// returned with Put once it's no longer used.
+func (d *DecoderPool) Decode(r io.Reader) (*Pattern, error) {
+	buf := d.buffers.Get().(*bytes.Buffer)
+	defer d.buffers.Put(buf)
+	buf.Reset()
+
+	_, err := buf.ReadFrom(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.DecodeBytes(buf.Bytes())
+}
+
+// DecodeBytes decodes the drum machine file contained in data. The pattern
+// doesn't reference data. It should be returned with Put once it's no
+// longer used.
+func (d *DecoderPool) DecodeBytes(data []byte) (*Pattern, error) {
+	p := d.patterns.Get().(*Pattern)
+	for _, opt := range d.opts {
+		opt(&p.config)
+	}
+
+	err := p.UnmarshalBinary(data)
+	p.reader.Reset(nil)
+	if p.pending != nil {
+		// Data may be reused by the caller, but it's needed by LoadTracks
+		p.pending.data = bytes.Clone(data)
+	}
+	if err != nil {
+		d.Put(p)
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Put returns the pattern to the pool for reuse. Neither the pattern nor
+// its tracks and steps may be used afterwards.
+func (d *DecoderPool) Put(p *Pattern) {
+	p.Reset()
+	d.patterns.Put(p)
+}
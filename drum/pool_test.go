@@ -0,0 +1,69 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func TestDecoderPool(t *testing.T) {
+	pool := NewDecoderPool()
+
+	// Decode every fixture a few times, reusing patterns
+	for i := 0; i < 3; i++ {
+		for _, exp := range tData {
+			data, err := ioutil.ReadFile(path.Join("fixtures", exp.path))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := pool.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("something went wrong decoding %s - %v", exp.path, err)
+			}
+			if fmt.Sprint(decoded) != exp.output {
+				t.Fatalf("%s wasn't decoded as expected.\nGot:\n%s\nExpected:\n%s",
+					exp.path, decoded, exp.output)
+			}
+
+			pool.Put(decoded)
+		}
+	}
+
+	if _, err := pool.DecodeBytes([]byte("SPLICE")); err == nil {
+		t.Fatal("expected error decoding truncated data")
+	}
+}
+
+func benchmarkData(b *testing.B) []byte {
+	data, err := ioutil.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return data
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data := benchmarkData(b)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		Decode(bytes.NewReader(data))
+	}
+}
+
+func BenchmarkDecoderPool(b *testing.B) {
+	data := benchmarkData(b)
+	pool := NewDecoderPool()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		p, err := pool.Decode(bytes.NewReader(data))
+		if err == nil {
+			pool.Put(p)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package presets provides a library of canonical genre drum patterns
+// embedded in the binary, so no external files are needed to use them.
+package presets
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+const extension = ".splice"
+
+//go:embed patterns
+var patterns embed.FS
+
+// Get returns a freshly decoded preset pattern, e.g. "house/basic".
+func Get(name string) (*drum.Pattern, error) {
+	p, err := drum.DecodeFS(patterns, path.Join("patterns", name+extension))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unknown preset %q", name)
+	}
+
+	return p, err
+}
+
+// Names returns sorted names of all available presets.
+func Names() []string {
+	var names []string
+
+	fs.WalkDir(patterns, "patterns", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(p, extension) {
+			names = append(names, strings.TrimSuffix(strings.TrimPrefix(p, "patterns/"), extension))
+		}
+		return err
+	})
+
+	sort.Strings(names)
+
+	return names
+}
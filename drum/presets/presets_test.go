@@ -0,0 +1,42 @@
+package presets
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	names := Names()
+	if len(names) == 0 {
+		t.Fatal("no presets found")
+	}
+
+	for _, name := range names {
+		p, err := Get(name)
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", name, err)
+		}
+		if len(p.Tracks) == 0 {
+			t.Errorf("preset %s has no tracks", name)
+		}
+	}
+
+	if _, err := Get("polka/basic"); err == nil {
+		t.Fatal("expected error for unknown preset")
+	}
+}
+
+func TestGetHouse(t *testing.T) {
+	p, err := Get("house/basic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: 0.909
+Tempo: 124
+(0) kick	|x---|x---|x---|x---|
+(1) clap	|----|x---|----|x---|
+(2) hh-close	|x-x-|x-x-|x-x-|x-x-|
+(3) hh-open	|--x-|--x-|--x-|--x-|
+`
+	if p.String() != expected {
+		t.Fatalf("house/basic wasn't decoded as expected.\nGot:\n%s\nExpected:\n%s", p, expected)
+	}
+}
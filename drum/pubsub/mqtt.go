@@ -0,0 +1,119 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// MQTT control packet types, shifted to the high nibble.
+const (
+	mqttConnect    = 1 << 4
+	mqttConnAck    = 2 << 4
+	mqttPublish    = 3 << 4
+	mqttDisconnect = 14 << 4
+)
+
+// MQTTClient publishes messages to an MQTT 3.1.1 broker with QoS 0,
+// at most once, as triggers are worthless once late.
+type MQTTClient struct {
+	mu   sync.Mutex
+	conn io.ReadWriteCloser
+}
+
+// DialMQTT connects to the MQTT broker at the address.
+func DialMQTT(addr, clientID string) (*MQTTClient, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := NewMQTTClient(conn, clientID)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// NewMQTTClient connects to the MQTT broker over the connection.
+// The session is clean and keep alive is disabled.
+func NewMQTTClient(conn io.ReadWriteCloser, clientID string) (*MQTTClient, error) {
+	var body []byte
+	body = appendMQTTString(body, "MQTT")
+	// Protocol level 4, clean session, keep alive disabled
+	body = append(body, 4, 0x02, 0, 0)
+	body = appendMQTTString(body, clientID)
+
+	_, err := conn.Write(mqttPacket(mqttConnect, body))
+	if err != nil {
+		return nil, err
+	}
+
+	var ack [4]byte
+	_, err = io.ReadFull(conn, ack[:])
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong reading CONNACK - %v", err)
+	}
+	if ack[0] != mqttConnAck || ack[1] != 2 {
+		return nil, errors.New("invalid CONNACK")
+	}
+	if ack[3] != 0 {
+		return nil, fmt.Errorf("connection refused with code %d", ack[3])
+	}
+
+	return &MQTTClient{conn: conn}, nil
+}
+
+// Publish publishes the payload to the topic.
+func (c *MQTTClient) Publish(topic string, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	body := appendMQTTString(nil, topic)
+	_, err := c.conn.Write(mqttPacket(mqttPublish, append(body, payload...)))
+
+	return err
+}
+
+// Close disconnects from the broker.
+func (c *MQTTClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.conn.Write(mqttPacket(mqttDisconnect, nil))
+	if closeErr := c.conn.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// mqttPacket returns a control packet with the body.
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+
+	// Remaining length, 7 bits per byte with the high bit continuing it
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if length == 0 {
+			break
+		}
+	}
+
+	return append(packet, body...)
+}
+
+// appendMQTTString appends the string prefixed by its length.
+func appendMQTTString(b []byte, s string) []byte {
+	return append(append(b, byte(len(s)>>8), byte(len(s))), s...)
+}
@@ -0,0 +1,98 @@
+package pubsub
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// NATSClient publishes messages to a NATS server.
+type NATSClient struct {
+	mu   sync.Mutex
+	conn io.ReadWriteCloser
+	// done is closed once the server's messages are read
+	done chan struct{}
+}
+
+// DialNATS connects to the NATS server at the address.
+func DialNATS(addr string) (*NATSClient, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := NewNATSClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// NewNATSClient connects to the NATS server over the connection.
+// Pings of the server are answered in the background.
+func NewNATSClient(conn io.ReadWriteCloser) (*NATSClient, error) {
+	reader := bufio.NewReader(conn)
+
+	info, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong reading INFO - %v", err)
+	}
+	if !strings.HasPrefix(info, "INFO ") {
+		return nil, errors.New("invalid INFO")
+	}
+
+	_, err = io.WriteString(conn, "CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"splice\"}\r\n")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &NATSClient{conn: conn, done: make(chan struct{})}
+	go c.read(reader)
+
+	return c, nil
+}
+
+// read answers pings of the server until the connection is closed.
+func (c *NATSClient) read(r *bufio.Reader) {
+	defer close(c.done)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		if strings.TrimSpace(line) == "PING" {
+			c.mu.Lock()
+			io.WriteString(c.conn, "PONG\r\n")
+			c.mu.Unlock()
+		}
+	}
+}
+
+// Publish publishes the payload to the subject.
+func (c *NATSClient) Publish(subject string, payload []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("invalid subject %q", subject)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := fmt.Fprintf(c.conn, "PUB %s %d\r\n%s\r\n", subject, len(payload), payload)
+	return err
+}
+
+// Close closes the connection.
+func (c *NATSClient) Close() error {
+	err := c.conn.Close()
+	<-c.done
+
+	return err
+}
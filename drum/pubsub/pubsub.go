@@ -0,0 +1,164 @@
+// Package pubsub publishes events of played patterns to message brokers,
+// e.g. to drive LED walls or drum robots listening on MQTT or NATS.
+package pubsub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// sinkBuffer is the number of events a Sink holds while publishing.
+const sinkBuffer = 256
+
+// Publisher publishes messages to topics of a broker.
+type Publisher interface {
+	Publish(topic string, payload []byte) error
+	Close() error
+}
+
+// Dial connects to the broker at the URL, e.g. "mqtt://localhost:1883"
+// or "nats://localhost:4222", returning a publisher and the topic given
+// as the URL's path, if any.
+func Dial(rawURL string) (Publisher, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	topic := ""
+	if len(u.Path) > 1 {
+		topic = u.Path[1:]
+	}
+
+	switch u.Scheme {
+	case "mqtt":
+		c, err := DialMQTT(hostPort(u, "1883"), fmt.Sprintf("splice-%d", time.Now().UnixNano()))
+		return c, topic, err
+	case "nats":
+		c, err := DialNATS(hostPort(u, "4222"))
+		return c, topic, err
+	default:
+		return nil, "", fmt.Errorf("unsupported broker %q, expected mqtt or nats", u.Scheme)
+	}
+}
+
+// hostPort returns the host and port of the URL, with the default port
+// if it has none.
+func hostPort(u *url.URL, port string) string {
+	if u.Port() == "" {
+		return u.Hostname() + ":" + port
+	}
+
+	return u.Host
+}
+
+// Trigger is the message published for every triggered event.
+type Trigger struct {
+	// Step is the index of the step, from 0
+	Step    int    `json:"step"`
+	Track   string `json:"track"`
+	TrackID byte   `json:"trackId"`
+	// Velocity is from 0 to 127
+	Velocity byte `json:"velocity"`
+	Flam     bool `json:"flam,omitempty"`
+	// Time is when the event was triggered, in Unix milliseconds
+	Time int64 `json:"time"`
+}
+
+// Sink is a sequencer sink publishing triggers of events as JSON to a
+// topic. Events are published in the background, so a slow broker doesn't
+// delay playback; events triggered while the buffer is full are dropped.
+type Sink struct {
+	pattern   *drum.Pattern
+	publisher Publisher
+	topic     string
+	events    chan Trigger
+	done      chan struct{}
+
+	mu      sync.Mutex
+	err     error
+	dropped int
+}
+
+// NewSink returns a sink publishing triggers of the pattern's events
+// to the topic. It must be closed once playback ends.
+func NewSink(p *drum.Pattern, publisher Publisher, topic string) *Sink {
+	s := &Sink{
+		pattern:   p,
+		publisher: publisher,
+		topic:     topic,
+		events:    make(chan Trigger, sinkBuffer),
+		done:      make(chan struct{}),
+	}
+
+	go s.publish()
+
+	return s
+}
+
+// Trigger queues the event for publishing.
+func (s *Sink) Trigger(e drum.Event) {
+	track := s.pattern.Tracks[e.TrackIndex]
+	trigger := Trigger{
+		Step:     e.Step,
+		Track:    track.Name,
+		TrackID:  track.ID,
+		Velocity: e.Velocity,
+		Flam:     e.Flam,
+		Time:     time.Now().UnixMilli(),
+	}
+
+	select {
+	case s.events <- trigger:
+	default:
+		s.mu.Lock()
+		s.dropped++
+		s.mu.Unlock()
+	}
+}
+
+// publish publishes queued triggers until the sink is closed.
+func (s *Sink) publish() {
+	defer close(s.done)
+
+	for trigger := range s.events {
+		payload, err := json.Marshal(trigger)
+		if err == nil {
+			err = s.publisher.Publish(s.topic, payload)
+		}
+		if err != nil {
+			s.mu.Lock()
+			if s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close publishes queued triggers and closes the publisher. It returns
+// the first error of publishing, if any, or an error if triggers were
+// dropped.
+func (s *Sink) Close() error {
+	close(s.events)
+	<-s.done
+
+	err := s.publisher.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	if s.dropped > 0 {
+		return fmt.Errorf("dropped %d triggers, publishing was too slow", s.dropped)
+	}
+
+	return err
+}
@@ -0,0 +1,116 @@
+package pubsub
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestMQTTClient(t *testing.T) {
+	client, server := net.Pipe()
+
+	received := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(acceptMQTT(t, server))
+		received <- data
+	}()
+
+	c, err := NewMQTTClient(client, "splice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Publish("drums/hits", []byte("kick")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{
+		mqttPublish, 16, 0, 10, 'd', 'r', 'u', 'm', 's', '/', 'h', 'i', 't', 's', 'k', 'i', 'c', 'k',
+		mqttDisconnect, 0,
+	}
+	if data := <-received; !bytes.Equal(data, expected) {
+		t.Errorf("expected packets %v, got %v", expected, data)
+	}
+}
+
+// acceptMQTT reads the CONNECT packet from conn and accepts it.
+func acceptMQTT(t *testing.T, conn net.Conn) io.Reader {
+	connect := make([]byte, 20)
+	if _, err := io.ReadFull(conn, connect); err != nil {
+		t.Error(err)
+	}
+
+	expected := []byte{mqttConnect, 18, 0, 4, 'M', 'Q', 'T', 'T', 4, 2, 0, 0, 0, 6, 's', 'p', 'l', 'i', 'c', 'e'}
+	if !bytes.Equal(connect, expected) {
+		t.Errorf("expected CONNECT %v, got %v", expected, connect)
+	}
+
+	conn.Write([]byte{mqttConnAck, 2, 0, 0})
+
+	return conn
+}
+
+func TestMQTTPacketLength(t *testing.T) {
+	packet := mqttPacket(mqttPublish, make([]byte, 321))
+	if !bytes.Equal(packet[:3], []byte{mqttPublish, 0xc1, 0x02}) {
+		t.Errorf("unexpected header %v", packet[:3])
+	}
+}
+
+func TestSinkNATS(t *testing.T) {
+	client, server := net.Pipe()
+
+	lines := make(chan string)
+	go func() {
+		io.WriteString(server, "INFO {\"server_id\":\"test\"}\r\n")
+
+		scanner := bufio.NewScanner(server)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"splice\"}" {
+				io.WriteString(server, "PING\r\n")
+				continue
+			}
+			lines <- line
+		}
+		close(lines)
+	}()
+
+	c, err := NewNATSClient(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &drum.Pattern{Tempo: 120, Tracks: []drum.Track{{ID: 3, Name: "snare"}}}
+	sink := NewSink(p, c, "drums.hits")
+
+	if line := <-lines; line != "PONG" {
+		t.Fatalf("expected PONG, got %q", line)
+	}
+
+	sink.Trigger(drum.Event{TrackIndex: 0, Step: 4, Velocity: 100, Flam: true})
+	if line := <-lines; line[:15] != "PUB drums.hits " {
+		t.Fatalf("unexpected PUB %q", line)
+	}
+
+	var trigger Trigger
+	if err := json.Unmarshal([]byte(<-lines), &trigger); err != nil {
+		t.Fatal(err)
+	}
+	trigger.Time = 0
+	expected := Trigger{Step: 4, Track: "snare", TrackID: 3, Velocity: 100, Flam: true}
+	if trigger != expected {
+		t.Errorf("expected trigger %+v, got %+v", expected, trigger)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package drum
+
+import (
+	"path"
+	"strings"
+)
+
+// Query selects patterns by their content. Zero fields match everything.
+type Query struct {
+	// Track is a path.Match glob, e.g. "hh*", one of the pattern's track
+	// names must match case-insensitively
+	Track string
+	// Tempo range, inclusive
+	MinTempo, MaxTempo float32
+	// MinDensity is the minimum density of the matching track, or of the
+	// whole pattern if Track and Tag are empty
+	MinDensity float64
+	// Tag is a tag the matching track must have, see Track.Tag
+	Tag string
+}
+
+// Match returns true if the pattern satisfies the query.
+// It returns an error only if the Track glob is malformed, or tracks of
+// a lazily decoded pattern fail to load.
+func (q Query) Match(p *Pattern) (bool, error) {
+	if err := p.ensureTracks(); err != nil {
+		return false, err
+	}
+
+	if q.MinTempo > 0 && p.Tempo < q.MinTempo {
+		return false, nil
+	}
+	if q.MaxTempo > 0 && p.Tempo > q.MaxTempo {
+		return false, nil
+	}
+
+	if q.Track == "" && q.Tag == "" {
+		return p.Density() >= q.MinDensity, nil
+	}
+
+	for _, track := range p.Tracks {
+		if q.Tag != "" && !track.HasTag(q.Tag) {
+			continue
+		}
+
+		ok := true
+		if q.Track != "" {
+			var err error
+			ok, err = path.Match(strings.ToLower(q.Track), strings.ToLower(track.Name))
+			if err != nil {
+				return false, err
+			}
+		}
+
+		if ok && track.Density() >= q.MinDensity {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Density returns the ratio of hits to all steps of the track.
+func (t Track) Density() float64 {
+	if t.Len() == 0 {
+		return 0
+	}
+
+	return float64(countHits(t.Unpacked())) / float64(t.Len())
+}
+
+// Density returns the ratio of hits to all steps of the pattern.
+func (p *Pattern) Density() float64 {
+	p.ensureTracks()
+
+	hits, steps := 0, 0
+	for _, track := range p.Tracks {
+		hits += countHits(track.Unpacked())
+		steps += track.Len()
+	}
+
+	if steps == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(steps)
+}
@@ -0,0 +1,41 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestQueryMatch(t *testing.T) {
+	// pattern_1 at 120 BPM, hh-open plays 4 of 16 steps, hh-close 5
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		query Query
+		match bool
+	}{
+		{Query{}, true},
+		{Query{MinTempo: 118, MaxTempo: 128}, true},
+		{Query{MinTempo: 121}, false},
+		{Query{MaxTempo: 100}, false},
+		{Query{Track: "HH*"}, true},
+		{Query{Track: "hh*", MinDensity: 0.3}, true},
+		{Query{Track: "hh*", MinDensity: 0.4}, false},
+		{Query{Track: "ride"}, false},
+		{Query{MinDensity: 0.5}, false},
+	} {
+		match, err := tc.query.Match(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if match != tc.match {
+			t.Errorf("%+v: expected match %v, got %v", tc.query, tc.match, match)
+		}
+	}
+
+	if _, err := (Query{Track: "["}).Match(p); err == nil {
+		t.Fatal("expected an error for malformed glob")
+	}
+}
@@ -0,0 +1,164 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// beatLabels are legend labels of steps within a beat. The first one
+// is replaced by the beat's number.
+var beatLabels = [beatSteps]string{"", "e", "&", "a"}
+
+// RenderOptions configures text rendering of a pattern.
+type RenderOptions struct {
+	// Legend adds a header row with beat numbers (1 e & a 2 e & a ...)
+	Legend bool
+	// CellWidth is the width of a single step, defaults to the theme's
+	CellWidth int
+	// Theme sets characters and colors, defaults to DefaultTheme
+	Theme *Theme
+	// Align pads track labels to the same display width, counting wide
+	// characters like CJK as two columns, and separates them from steps
+	// with a space rather than a tab, so rows line up in any terminal
+	Align bool
+	// MaxLabelWidth truncates longer labels with an ellipsis and implies
+	// Align. Zero means no limit.
+	MaxLabelWidth int
+}
+
+// Render returns text representation of the pattern, with a row per track.
+// Notes attached to steps are listed below as numbered footnotes.
+func (p *Pattern) Render(opts RenderOptions) string {
+	var buffer bytes.Buffer
+
+	// Malformed lazy tracks are rendered as none, LoadTracks reports them
+	p.ensureTracks()
+
+	buffer.WriteString(fmt.Sprintf("Saved with HW Version: %s\n", p.Version))
+	buffer.WriteString(fmt.Sprintf("Tempo: %v\n", p.Tempo))
+
+	theme := DefaultTheme
+	if opts.Theme != nil {
+		theme = *opts.Theme
+	}
+
+	width := opts.CellWidth
+	if width < 1 {
+		width = theme.CellWidth
+	}
+	if width < 1 {
+		width = 1
+	}
+
+	align := opts.Align || opts.MaxLabelWidth > 0
+	separator := "\t"
+	if align {
+		separator = " "
+	}
+
+	labels := make([]string, len(p.Tracks))
+	labelWidth := 0
+	steps := 0
+
+	for i, track := range p.Tracks {
+		labels[i] = fmt.Sprintf("(%d) %s", track.ID, track.Name)
+		if opts.MaxLabelWidth > 0 {
+			labels[i] = truncate(labels[i], opts.MaxLabelWidth)
+		}
+
+		if w := StringWidth(labels[i]); w > labelWidth {
+			labelWidth = w
+		}
+		if track.Len() > steps {
+			steps = track.Len()
+		}
+	}
+
+	if opts.Legend {
+		legend := make([]string, steps)
+		for i := range legend {
+			legend[i] = stepLabel(i)
+
+			if len(legend[i]) > width {
+				width = len(legend[i])
+			}
+		}
+
+		buffer.WriteString(strings.Repeat(" ", labelWidth) + separator)
+		writeRow(&buffer, legend, nil, width, theme.Separator)
+	}
+
+	// Pad labels to the same width, so the legend and rows line up
+	if opts.Legend || align {
+		for i := range labels {
+			labels[i] = padRight(labels[i], labelWidth)
+		}
+	}
+
+	for i, track := range p.Tracks {
+		buffer.WriteString(ansi(labels[i], theme.ANSILabel) + separator)
+
+		cells, colors := stepSymbols(track.Unpacked(), theme)
+		writeRow(&buffer, cells, colors, width, theme.Separator)
+	}
+
+	writeFootnotes(&buffer, p.footnotes())
+
+	return buffer.String()
+}
+
+// writeRow writes cells padded to width, grouped by beats with the
+// separator. Cells are colored with ANSI parameters of colors, if set.
+func writeRow(buffer *bytes.Buffer, cells, colors []string, width int, separator string) {
+	for i, cell := range cells {
+		if i%beatSteps == 0 {
+			buffer.WriteString(separator)
+		}
+
+		padded := padRight(cell, width)
+		if colors != nil {
+			padded = ansi(padded, colors[i])
+		}
+		buffer.WriteString(padded)
+	}
+
+	buffer.WriteString(separator + "\n")
+}
+
+// stepLabel returns the legend label of the step at index i.
+func stepLabel(i int) string {
+	if i%beatSteps == 0 {
+		return strconv.Itoa(i/beatSteps + 1)
+	}
+
+	return beatLabels[i%beatSteps]
+}
+
+// stepSymbols returns characters of the theme for steps that are on,
+// flams and the others, along with their ANSI colors.
+func stepSymbols(steps []byte, theme Theme) ([]string, []string) {
+	symbols := make([]string, len(steps))
+	colors := make([]string, len(steps))
+
+	for i, step := range steps {
+		switch step {
+		case StepOn:
+			symbols[i], colors[i] = theme.On, theme.ANSIOn
+		case StepFlam:
+			symbols[i], colors[i] = theme.Flam, theme.ANSIFlam
+		default:
+			symbols[i], colors[i] = theme.Off, theme.ANSIOff
+		}
+	}
+
+	return symbols, colors
+}
+
+// plainStepSymbols returns x for every step that is on, f for every flam
+// and - for every other, as used by text formats regardless of themes.
+func plainStepSymbols(steps []byte) []string {
+	symbols, _ := stepSymbols(steps, Theme{On: "x", Flam: "f", Off: "-"})
+	return symbols
+}
@@ -0,0 +1,50 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestRenderLegend(t *testing.T) {
+	decoded, err := DecodeFile(path.Join("fixtures", tData[4].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: 0.708-alpha
+Tempo: 999
+         	|1e&a|2e&a|3e&a|4e&a|
+(1) Kick 	|x---|----|x---|----|
+(2) HiHat	|x-x-|x-x-|x-x-|x-x-|
+`
+	if out := decoded.Render(RenderOptions{Legend: true}); out != expected {
+		t.Fatalf("wrong render.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+
+	expected = `Saved with HW Version: 0.708-alpha
+Tempo: 999
+(1) Kick	|x - - - |- - - - |x - - - |- - - - |
+(2) HiHat	|x - x - |x - x - |x - x - |x - x - |
+`
+	if out := decoded.Render(RenderOptions{CellWidth: 2}); out != expected {
+		t.Fatalf("wrong render.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+}
+
+func TestRenderAlign(t *testing.T) {
+	p := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []Track{
+		{ID: 0, Name: "太鼓", Steps: []byte{1, 0, 0, 0}},
+		{ID: 1, Name: "snare", Steps: []byte{0, 0, 1, 0}},
+		{ID: 2, Name: "open hi-hat with a long name", Steps: []byte{0, 1, 0, 1}},
+	}}
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(0) 太鼓     |x---|
+(1) snare    |--x-|
+(2) open hi… |-x-x|
+`
+	if out := p.Render(RenderOptions{MaxLabelWidth: 12}); out != expected {
+		t.Fatalf("wrong render.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+}
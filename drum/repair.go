@@ -0,0 +1,114 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// RepairReport describes changes made by Repair.
+type RepairReport struct {
+	// DeclaredLength is the content length found in the data
+	DeclaredLength uint64
+	// Length is the content length after repair
+	Length uint64
+	// VersionTerminated is true if a null byte was added to the version
+	VersionTerminated bool
+	// ClampedSteps is the number of step values greater than 1 set to 1
+	ClampedSteps int
+}
+
+// Changed returns true if any repair was made.
+func (r RepairReport) Changed() bool {
+	return r.DeclaredLength != r.Length || r.VersionTerminated || r.ClampedSteps > 0
+}
+
+func (r RepairReport) String() string {
+	var changes []string
+
+	if r.DeclaredLength != r.Length {
+		changes = append(changes, fmt.Sprintf("length fixed from %d to %d", r.DeclaredLength, r.Length))
+	}
+	if r.VersionTerminated {
+		changes = append(changes, "version terminated")
+	}
+	if r.ClampedSteps > 0 {
+		changes = append(changes, fmt.Sprintf("%d steps clamped", r.ClampedSteps))
+	}
+
+	if len(changes) == 0 {
+		return "no changes"
+	}
+
+	return strings.Join(changes, ", ")
+}
+
+// Repair fixes recoverable corruptions of a .splice file: wrong content
+// length, unterminated version string and step values greater than 1.
+// The length is set to the track boundary nearest to the declared one.
+// Returns repaired copy of data and a report of what was changed.
+func Repair(data []byte) ([]byte, RepairReport, error) {
+	var report RepairReport
+
+	if len(data) < tracksOffset {
+		return nil, report, fmt.Errorf("data too short (%d bytes)", len(data))
+	}
+
+	if !bytes.Equal(data[:headerLength], []byte(spliceHeader)) {
+		return nil, report, errInvalidHeader
+	}
+
+	repaired := append([]byte(nil), data...)
+
+	report.DeclaredLength = binary.BigEndian.Uint64(repaired[headerLength:])
+	report.Length = report.DeclaredLength
+
+	boundaries := trackBoundaries(repaired, trackSteps)
+	if VerifyLength(repaired) != nil {
+		report.Length = nearestLength(boundaries, report.DeclaredLength)
+		binary.BigEndian.PutUint64(repaired[headerLength:], report.Length)
+	}
+
+	version := repaired[contentOffset : contentOffset+versionMaxLength]
+	if bytes.IndexByte(version, 0) < 0 {
+		version[len(version)-1] = 0
+		report.VersionTerminated = true
+	}
+
+	end := contentOffset + int(report.Length)
+	for i := 0; i < len(boundaries)-1 && boundaries[i+1] <= end; i++ {
+		steps := repaired[boundaries[i+1]-trackSteps : boundaries[i+1]]
+		for s, step := range steps {
+			if step > 1 {
+				steps[s] = 1
+				report.ClampedSteps++
+			}
+		}
+	}
+
+	return repaired, report, nil
+}
+
+// nearestLength returns content length ending on the track boundary
+// closest to length.
+func nearestLength(boundaries []int, length uint64) uint64 {
+	nearest := uint64(boundaries[0] - contentOffset)
+
+	for _, boundary := range boundaries {
+		candidate := uint64(boundary - contentOffset)
+		if distance(candidate, length) < distance(nearest, length) {
+			nearest = candidate
+		}
+	}
+
+	return nearest
+}
+
+func distance(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
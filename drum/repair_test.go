@@ -0,0 +1,56 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	data, err := ioutil.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repaired, report, err := Repair(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Changed() || !bytes.Equal(repaired, data) {
+		t.Fatalf("valid file was changed: %s", report)
+	}
+
+	corrupted := append([]byte(nil), data...)
+	binary.BigEndian.PutUint64(corrupted[headerLength:], 0xc3)
+	copy(corrupted[contentOffset:], bytes.Repeat([]byte("v"), versionMaxLength))
+	corrupted[tracksOffset+trackHeaderSize+len("kick")] = 5
+
+	repaired, report, err = Repair(corrupted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := RepairReport{
+		DeclaredLength:    0xc3,
+		Length:            0xc5,
+		VersionTerminated: true,
+		ClampedSteps:      1,
+	}
+	if report != expected {
+		t.Fatalf("wrong report.\nGot:\n%+v\nExpected:\n%+v", report, expected)
+	}
+
+	decoded, err := Decode(bytes.NewReader(repaired), WithStrict(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Version != "vvvvvvvvvvvvvvvvvvvvvvvvvvvvvvv" || len(decoded.Tracks) != 6 || decoded.Tracks[0].Steps[0] != 1 {
+		t.Fatalf("file wasn't repaired:\n%s", decoded)
+	}
+
+	if _, _, err := Repair([]byte("SPLOCE")); err == nil {
+		t.Fatal("expected error repairing invalid header")
+	}
+}
@@ -0,0 +1,54 @@
+package drum
+
+// RepresentativeBar returns the bar of the pattern that best summarizes
+// its groove, e.g. for thumbnails and similarity indexing. It's the bar
+// with the fewest steps differing from all other bars, the earliest one
+// in case of a tie. Track offsets are applied and a shorter last bar is
+// only considered if there are no whole ones.
+func RepresentativeBar(p *Pattern) *Pattern {
+	p.ensureTracks()
+
+	bars := Paginate(p, barSteps)
+	if len(bars) == 0 {
+		return p.ApplyOffsets()
+	}
+
+	if len(bars) > 1 && barLength(bars[len(bars)-1]) < barSteps {
+		bars = bars[:len(bars)-1]
+	}
+
+	best, bestDistance := 0, -1
+	for i, bar := range bars {
+		distance := 0
+		for _, other := range bars {
+			distance += barDistance(bar, other)
+		}
+
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+
+	return bars[best]
+}
+
+// barLength returns the number of steps of the bar's longest track.
+func barLength(bar *Pattern) int {
+	length := 0
+	for _, track := range bar.Tracks {
+		length = max(length, track.Len())
+	}
+
+	return length
+}
+
+// barDistance returns the number of steps played differently in two bars
+// of the same pattern, which fit in bitmasks.
+func barDistance(a, b *Pattern) int {
+	distance := 0
+	for i, track := range a.Tracks {
+		distance += track.Bitmask().Xor(b.Tracks[i].Bitmask()).Hits()
+	}
+
+	return distance
+}
@@ -0,0 +1,29 @@
+package drum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRepresentativeBar(t *testing.T) {
+	main := stepsFromString("x---x---x---x---")
+	fill := stepsFromString("x---x---x-x-xxxx")
+	variation := stepsFromString("x---x---x---x-x-")
+
+	var steps []byte
+	for _, bar := range [][]byte{variation, main, main, main, fill, {1, 1}} {
+		steps = append(steps, bar...)
+	}
+
+	p := &Pattern{Tempo: 120, Tracks: []Track{{Name: "kick", Steps: steps}}}
+
+	bar := RepresentativeBar(p)
+	if !bytes.Equal(bar.Tracks[0].Steps, main) {
+		t.Fatalf("unexpected representative bar %v", bar.Tracks[0].Steps)
+	}
+
+	short := &Pattern{Tracks: []Track{{Name: "kick", Steps: []byte{1, 0, 1}}}}
+	if bar := RepresentativeBar(short); !bytes.Equal(bar.Tracks[0].Steps, []byte{1, 0, 1}) {
+		t.Fatalf("unexpected representative bar %v of a short pattern", bar.Tracks[0].Steps)
+	}
+}
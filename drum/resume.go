@@ -0,0 +1,114 @@
+package drum
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ResumeToken holds the state of a decode which ran out of data, e.g.
+// an upload cut off mid-track. Decoding continues with Resume once more
+// data arrives, without parsing what was parsed already.
+type ResumeToken struct {
+	pattern *Pattern
+	// Whether header, version and tempo were parsed
+	headerParsed bool
+	// Content bytes left to parse after tail
+	remaining uint64
+	// Received bytes not parsed yet, e.g. a part of a track
+	tail []byte
+	// Number of bytes parsed
+	parsed uint64
+}
+
+// DecodeResumable decodes the drum machine file contained in data, which
+// may be only its beginning. If data ends before the pattern does, it
+// returns a token to resume decoding with the rest of the data instead
+// of the pattern. Sidecar metadata isn't read.
+func DecodeResumable(data []byte, opts ...Option) (*Pattern, *ResumeToken, error) {
+	p := &Pattern{}
+	for _, opt := range opts {
+		opt(&p.config)
+	}
+
+	return (&ResumeToken{pattern: p}).Resume(data)
+}
+
+// Resume continues decoding with data following the data decoded so far.
+// Like DecodeResumable, it returns either the pattern or a token to resume
+// decoding again with more data. The token mustn't be used afterwards.
+func (t *ResumeToken) Resume(data []byte) (*Pattern, *ResumeToken, error) {
+	p := t.pattern
+	tail := append(t.tail, data...)
+
+	p.reader.Reset(tail)
+	p.buffer = &p.reader
+
+	if !t.headerParsed {
+		if len(tail) < headerInfoLength {
+			return nil, t.rest(tail, 0), nil
+		}
+
+		p.checkHeader()
+		length := p.readLength()
+		p.readVersion()
+		p.readTempo()
+		if p.lastErr != nil {
+			return nil, nil, p.lastErr
+		}
+
+		t.headerParsed = true
+		t.remaining = length - min(length, versionMaxLength+4)
+		p.debug("header parsed", "length", length)
+	}
+
+	stepWidth := uint64(p.config.stepWidthOrDefault())
+	for t.remaining > 0 {
+		unread := p.reader.Len()
+		offset := len(tail) - unread
+
+		// Parse only whole tracks, partial ones are parsed once complete
+		if unread < 5 {
+			return nil, t.rest(tail, offset), nil
+		}
+		size := 5 + uint64(binary.BigEndian.Uint32(tail[offset+1:])) + stepWidth
+		if uint64(unread) < size {
+			return nil, t.rest(tail, offset), nil
+		}
+
+		p.checkTrackCount()
+		p.readTrack()
+		if p.lastErr != nil {
+			return nil, nil, p.lastErr
+		}
+
+		t.remaining -= min(t.remaining, size)
+	}
+
+	if trailing := p.reader.Len(); p.config.strict && trailing > 0 {
+		p.problem(fmt.Errorf("%d bytes of trailing data", trailing))
+	}
+	p.joinProblems()
+
+	p.reader.Reset(nil)
+	if p.lastErr != nil {
+		return nil, nil, p.lastErr
+	}
+
+	return p, nil, nil
+}
+
+// Parsed returns the number of bytes parsed so far. Bytes received after
+// them were kept to be parsed once more data arrives.
+func (t *ResumeToken) Parsed() uint64 {
+	return t.parsed
+}
+
+// rest returns the token keeping bytes of tail from offset on,
+// which weren't parsed yet.
+func (t *ResumeToken) rest(tail []byte, offset int) *ResumeToken {
+	t.parsed += uint64(offset)
+	t.tail = append([]byte(nil), tail[offset:]...)
+	t.pattern.reader.Reset(nil)
+
+	return t
+}
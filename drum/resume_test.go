@@ -0,0 +1,76 @@
+package drum
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDecodeResumable(t *testing.T) {
+	data, err := os.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := DecodeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Chunks cut the header, a track's name and a track's steps
+	chunks := [][]byte{data[:20], data[20:57], data[57:70], data[70:]}
+
+	p, token, err := DecodeResumable(chunks[0])
+	for _, chunk := range chunks[1:] {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != nil {
+			t.Fatal("pattern decoded before all data was received")
+		}
+
+		p, token, err = token.Resume(chunk)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != nil || p == nil {
+		t.Fatal("decoding wasn't finished with all data received")
+	}
+	if fmt.Sprint(p) != fmt.Sprint(expected) {
+		t.Fatalf("resumed decoding differs.\nGot:\n%s\nExpected:\n%s", p, expected)
+	}
+}
+
+func TestResumeTokenParsed(t *testing.T) {
+	data, err := os.ReadFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, token, err := DecodeResumable(data[:70])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Header of 50 bytes, the first track of 5+4+16 bytes isn't complete
+	if parsed := token.Parsed(); parsed != 50 {
+		t.Fatalf("parsed %d bytes, expected 50", parsed)
+	}
+
+	_, token, err = token.Resume(data[70:80])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed := token.Parsed(); parsed != 50+25 {
+		t.Fatalf("parsed %d bytes, expected 75", parsed)
+	}
+}
+
+func TestDecodeResumableInvalid(t *testing.T) {
+	if _, _, err := DecodeResumable([]byte("NOT A SPLICE FILE, BUT LONG ENOUGH TO HOLD A HEADER")); err == nil {
+		t.Fatal("expected an error decoding an invalid header")
+	}
+}
@@ -0,0 +1,173 @@
+package drum
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultScene is the scene of patterns that never switched scenes.
+const DefaultScene = "A"
+
+// Scene returns the name of the active scene, whose steps are the Steps
+// of tracks.
+func (p *Pattern) Scene() string {
+	if p.scene == "" {
+		return DefaultScene
+	}
+
+	return p.scene
+}
+
+// SetScene activates the scene, like switching between A/B/C/D step sets
+// of a groovebox: steps of tracks are stored as steps of the active scene
+// and replaced by steps of the new one. Tracks without steps in the new
+// scene keep their steps, so a new scene starts as a copy of the active one.
+// Scenes are stored in the metadata sidecar.
+func (p *Pattern) SetScene(name string) {
+	p.ensureTracks()
+
+	if name == "" {
+		name = DefaultScene
+	}
+
+	active := p.Scene()
+	if name == active {
+		return
+	}
+
+	for i := range p.Tracks {
+		t := &p.Tracks[i]
+		if t.Scenes == nil {
+			t.Scenes = map[string][]byte{}
+		}
+
+		t.Scenes[active] = t.Unpacked()
+		if steps, ok := t.Scenes[name]; ok {
+			t.Steps = steps
+			t.packed = nil
+			delete(t.Scenes, name)
+		} else {
+			t.Steps = append([]byte(nil), t.Steps...)
+		}
+	}
+
+	p.scene = name
+}
+
+// SetSceneSteps sets steps of the track at the index in the scene.
+// Steps of the active scene are set to the track's Steps.
+func (p *Pattern) SetSceneSteps(track int, scene string, steps []byte) {
+	p.ensureTracks()
+
+	t := &p.Tracks[track]
+	if scene == p.Scene() {
+		t.Steps = steps
+		t.packed = nil
+		return
+	}
+
+	if t.Scenes == nil {
+		t.Scenes = map[string][]byte{}
+	}
+	t.Scenes[scene] = steps
+}
+
+// SceneSteps returns steps of the track at the index in the scene,
+// or its Steps if it has no steps in the scene.
+func (p *Pattern) SceneSteps(track int, scene string) []byte {
+	p.ensureTracks()
+
+	t := p.Tracks[track]
+	if steps, ok := t.Scenes[scene]; ok && scene != p.Scene() {
+		return steps
+	}
+
+	return t.Unpacked()
+}
+
+// Scenes returns sorted names of all scenes of the pattern,
+// including the active one.
+func (p *Pattern) Scenes() []string {
+	p.ensureTracks()
+
+	seen := map[string]bool{p.Scene(): true}
+	for _, track := range p.Tracks {
+		for name := range track.Scenes {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// sceneMetadata returns steps of the track's inactive scenes
+// in plain notation.
+func sceneMetadata(t Track) map[string]string {
+	if len(t.Scenes) == 0 {
+		return nil
+	}
+
+	scenes := map[string]string{}
+	for name, steps := range t.Scenes {
+		scenes[name] = strings.Join(plainStepSymbols(steps), "")
+	}
+
+	return scenes
+}
+
+// applySceneMetadata sets steps of the track's scenes written
+// by sceneMetadata. Scenes with invalid steps are skipped.
+func applySceneMetadata(t *Track, scenes map[string]string) {
+	for name, notation := range scenes {
+		steps, err := parsePlainSteps(notation)
+		if err != nil {
+			continue
+		}
+
+		if t.Scenes == nil {
+			t.Scenes = map[string][]byte{}
+		}
+		t.Scenes[name] = steps
+	}
+}
+
+// cloneScenes returns a deep copy of scenes.
+func cloneScenes(scenes map[string][]byte) map[string][]byte {
+	if scenes == nil {
+		return nil
+	}
+
+	c := make(map[string][]byte, len(scenes))
+	for name, steps := range scenes {
+		c[name] = append([]byte(nil), steps...)
+	}
+
+	return c
+}
+
+// parsePlainSteps parses steps written with x for hits, f for flams
+// and - for rests.
+func parsePlainSteps(s string) ([]byte, error) {
+	steps := make([]byte, 0, len(s))
+	for _, symbol := range s {
+		switch symbol {
+		case 'x':
+			steps = append(steps, StepOn)
+		case 'f':
+			steps = append(steps, StepFlam)
+		case '-':
+			steps = append(steps, StepOff)
+		default:
+			return nil, fmt.Errorf("invalid step %q", symbol)
+		}
+	}
+
+	return steps, nil
+}
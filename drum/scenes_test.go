@@ -0,0 +1,71 @@
+package drum
+
+import (
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestScenes(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := p.Clone()
+
+	if p.Scene() != DefaultScene {
+		t.Fatalf("expected default scene, got %s", p.Scene())
+	}
+
+	p.SetScene("B")
+	if !reflect.DeepEqual(p.Tracks[1].Steps, original.Tracks[1].Steps) {
+		t.Fatalf("new scene doesn't start as a copy: %v", p.Tracks[1].Steps)
+	}
+	fill := stepsFromString("----x-------xxxx")
+	p.Tracks[1].Steps = fill
+	p.SetSceneSteps(0, "C", stepsFromString("x-x-x-x-x-x-x-x-"))
+
+	if scenes := p.Scenes(); !reflect.DeepEqual(scenes, []string{"A", "B", "C"}) {
+		t.Fatalf("unexpected scenes %v", scenes)
+	}
+	if steps := p.SceneSteps(1, "A"); !reflect.DeepEqual(steps, original.Tracks[1].Steps) {
+		t.Fatalf("steps of scene A not kept: %v", steps)
+	}
+
+	file := path.Join(t.TempDir(), "scenes.splice")
+	if err := EncodeFile(p, file); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := DecodeFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Scene() != "B" || !reflect.DeepEqual(reloaded.Tracks[1].Steps, fill) {
+		t.Fatalf("active scene not preserved: %s %v", reloaded.Scene(), reloaded.Tracks[1].Steps)
+	}
+
+	reloaded.SetScene("A")
+	if reloaded.String() != original.String() {
+		t.Fatalf("scene A not restored:\n%v\nexpected:\n%v", reloaded, original)
+	}
+
+	reloaded.SetScene("C")
+	if !reflect.DeepEqual(reloaded.Tracks[0].Steps, stepsFromString("x-x-x-x-x-x-x-x-")) {
+		t.Fatalf("unexpected steps of scene C: %v", reloaded.Tracks[0].Steps)
+	}
+	if !reflect.DeepEqual(reloaded.Tracks[1].Steps, original.Tracks[1].Steps) {
+		t.Fatalf("track without steps in scene C changed: %v", reloaded.Tracks[1].Steps)
+	}
+
+	data, err := reloaded.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Pattern
+	if err := decoded.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Metadata(), reloaded.Metadata()) {
+		t.Fatalf("scenes not preserved in JSON: %+v", decoded.Metadata())
+	}
+}
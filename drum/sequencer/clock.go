@@ -0,0 +1,193 @@
+package sequencer
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock signals the sequencer when to advance to the next step.
+type Clock interface {
+	// Start starts ticking once per step of the given duration and returns
+	// the channel ticks are sent to. Clocks synced to an external source
+	// may ignore the duration.
+	Start(step time.Duration) <-chan struct{}
+	// Stop stops ticking.
+	Stop()
+}
+
+// StepSetter is implemented by clocks whose step duration can be changed
+// while they're running, e.g. to follow tempo changes.
+type StepSetter interface {
+	// SetStep sets duration of the step started by the last tick, so the
+	// next tick follows the last one after step, and of steps after it.
+	SetStep(step time.Duration)
+}
+
+// RealClock returns a clock ticking in real time. Ticks are scheduled
+// against absolute time, so they don't drift.
+func RealClock() Clock {
+	return &realClock{}
+}
+
+type realClock struct {
+	mu   sync.Mutex
+	step time.Duration
+	// Time the last tick was due
+	last time.Time
+	// changed is signaled when the step is set, to reschedule the next tick
+	changed chan struct{}
+	stop    chan struct{}
+}
+
+func (c *realClock) Start(step time.Duration) <-chan struct{} {
+	ticks := make(chan struct{})
+	c.stop = make(chan struct{})
+
+	c.mu.Lock()
+	c.step = step
+	c.last = time.Now()
+	c.changed = make(chan struct{}, 1)
+	c.mu.Unlock()
+
+	go func(stop, changed chan struct{}) {
+		for {
+			select {
+			case ticks <- struct{}{}:
+			case <-stop:
+				return
+			}
+
+			// The step may be set while waiting, by the sequencer
+			// advancing on the tick
+			for {
+				c.mu.Lock()
+				next := c.last.Add(c.step)
+				c.mu.Unlock()
+
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-timer.C:
+				case <-changed:
+					timer.Stop()
+					continue
+				case <-stop:
+					timer.Stop()
+					return
+				}
+
+				c.mu.Lock()
+				c.last = next
+				c.mu.Unlock()
+				break
+			}
+		}
+	}(c.stop, c.changed)
+
+	return ticks
+}
+
+func (c *realClock) SetStep(step time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.step = step
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (c *realClock) Stop() {
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+// midiPulsesPerStep is the number of MIDI timing clock messages, sent
+// 24 times per quarter note, in a single sixteenth step.
+const midiPulsesPerStep = 24 / 4
+
+// MIDIClock is a clock slaved to an external MIDI timing clock.
+// Pulse must be called for every timing clock message received.
+type MIDIClock struct {
+	mu      sync.Mutex
+	pulses  int
+	ticks   chan struct{}
+	running bool
+}
+
+// NewMIDIClock returns a clock waiting for MIDI timing clock pulses.
+func NewMIDIClock() *MIDIClock {
+	return &MIDIClock{ticks: make(chan struct{}, 1)}
+}
+
+// Start starts ticking on every sixth pulse, ignoring the step duration,
+// as the tempo is set by the source of the pulses.
+func (c *MIDIClock) Start(time.Duration) <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pulses = 0
+	c.running = true
+
+	return c.ticks
+}
+
+func (c *MIDIClock) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.running = false
+}
+
+// Pulse registers a single MIDI timing clock message. The first pulse
+// after Start ticks immediately.
+func (c *MIDIClock) Pulse() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return
+	}
+
+	if c.pulses%midiPulsesPerStep == 0 {
+		// Drop the tick if the sequencer didn't keep up with the previous one
+		select {
+		case c.ticks <- struct{}{}:
+		default:
+		}
+	}
+
+	c.pulses++
+}
+
+// Reset makes the next pulse tick immediately, e.g. on a start or continue
+// message, which is followed by the pulse of the first step.
+func (c *MIDIClock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pulses = 0
+}
+
+// FakeClock is a clock ticking only when told to, for use in tests.
+type FakeClock struct {
+	ticks chan struct{}
+}
+
+// NewFakeClock returns a clock ticking on calls to Tick.
+func NewFakeClock() *FakeClock {
+	return &FakeClock{ticks: make(chan struct{})}
+}
+
+func (c *FakeClock) Start(time.Duration) <-chan struct{} {
+	return c.ticks
+}
+
+func (c *FakeClock) Stop() {}
+
+// Tick sends a single tick, blocking until the sequencer receives it.
+func (c *FakeClock) Tick() {
+	c.ticks <- struct{}{}
+}
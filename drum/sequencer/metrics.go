@@ -0,0 +1,56 @@
+package sequencer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum/metrics"
+)
+
+// jitterBuckets are upper bounds of step timing jitter buckets in seconds.
+var jitterBuckets = []float64{0.0001, 0.001, 0.005, 0.01, 0.05, 0.1}
+
+// playbackMetrics holds the *playbackStats set by SetMetrics.
+var playbackMetrics atomic.Pointer[playbackStats]
+
+func init() {
+	SetMetrics(metrics.Nop())
+}
+
+// playbackStats holds metrics reported by sequencers.
+type playbackStats struct {
+	steps     metrics.Counter
+	underruns metrics.Counter
+	jitter    metrics.Histogram
+}
+
+func newPlaybackStats(r metrics.Registerer) *playbackStats {
+	return &playbackStats{
+		steps:     r.Counter("playback_steps_total"),
+		underruns: r.Counter("playback_underruns_total"),
+		jitter:    r.Histogram("playback_step_jitter_seconds", jitterBuckets),
+	}
+}
+
+// SetMetrics sets the Registerer used to report playback metrics: steps
+// played, and for clocks paced by the sequencer, like RealClock, how far
+// ticks are off their steps and underruns, when a tick is a step or more
+// late. It's safe to call while patterns are being played.
+func SetMetrics(r metrics.Registerer) {
+	playbackMetrics.Store(newPlaybackStats(r))
+}
+
+// observeTick records a tick late by lateness, negative if early,
+// starting a step of the duration. It returns true for underruns.
+func (s *playbackStats) observeTick(lateness, step time.Duration) bool {
+	underrun := lateness >= step
+	if underrun {
+		s.underruns.Add(1)
+	}
+	if lateness < 0 {
+		lateness = -lateness
+	}
+	s.jitter.Observe(lateness.Seconds())
+
+	return underrun
+}
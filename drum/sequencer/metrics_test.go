@@ -0,0 +1,38 @@
+package sequencer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	r := metrics.Prometheus("")
+	SetMetrics(r)
+	defer SetMetrics(metrics.Nop())
+
+	clock := steppingClock{NewFakeClock(), make(chan time.Duration, 16)}
+	s := New(testPattern, clock, make(recordingSink, 16))
+
+	// Steps are 125ms long, the third one is due at 250ms
+	start := time.Now()
+	for _, at := range []time.Duration{0, 125 * time.Millisecond, 400 * time.Millisecond} {
+		s.now = func() time.Time { return start.Add(at) }
+		s.advance()
+	}
+
+	var b strings.Builder
+	r.WriteTo(&b)
+	for _, expected := range []string{
+		"playback_steps_total 3\n",
+		"playback_underruns_total 1\n",
+		"playback_step_jitter_seconds_count 2\n",
+		`playback_step_jitter_seconds_bucket{le="0.0001"} 1` + "\n",
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, b.String())
+		}
+	}
+}
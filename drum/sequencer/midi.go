@@ -0,0 +1,233 @@
+package sequencer
+
+import (
+	"bufio"
+	"io"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// MIDI message status bytes.
+const (
+	midiNoteOn       = 0x90
+	midiSysExStart   = 0xf0
+	midiSongPosition = 0xf2
+	midiSysExEnd     = 0xf7
+	midiTimingClock  = 0xf8
+	midiStart        = 0xfa
+	midiContinue     = 0xfb
+	midiStop         = 0xfc
+	midiRealtime     = 0xf8
+)
+
+// MIDI Machine Control messages and commands.
+const (
+	mmcRealtime = 0x7f
+	mmcCommand  = 0x06
+	mmcAllCall  = 0x7f
+
+	mmcStop         = 0x01
+	mmcPlay         = 0x02
+	mmcDeferredPlay = 0x03
+	mmcPause        = 0x09
+	mmcLocate       = 0x44
+	mmcTarget       = 0x01
+)
+
+// mmcFrameRates are SMPTE frame rates of MMC time codes, by bits 5-6
+// of their hours.
+var mmcFrameRates = [4]float64{24, 25, 29.97, 30}
+
+// NoteMap maps MIDI note numbers to track indexes.
+type NoteMap map[byte]int
+
+// GMNoteMap returns a map of General MIDI percussion notes to tracks
+// of the pattern, guessed from their names. Tracks without a matching
+// note aren't mapped.
+func GMNoteMap(p *drum.Pattern) NoteMap {
+	notes := NoteMap{}
+	p.LoadTracks()
+
+	for i, track := range p.Tracks {
+		if note, ok := drum.GMNote(track.Name); ok {
+			if _, taken := notes[note]; !taken {
+				notes[note] = i
+			}
+		}
+	}
+
+	return notes
+}
+
+// MIDIListener reads MIDI messages, e.g. from a raw MIDI device,
+// and reports notes of pads mapped to tracks.
+type MIDIListener struct {
+	// Notes maps note numbers to track indexes
+	Notes NoteMap
+	// OnHit is called for every note on of a mapped note
+	OnHit func(track int, velocity byte)
+	// OnClock is called for every timing clock message,
+	// e.g. with MIDIClock.Pulse
+	OnClock func()
+	// OnStart, OnContinue and OnStop are called for start, continue and
+	// stop messages. MMC play commands call OnContinue and MMC stop and
+	// pause commands call OnStop.
+	OnStart    func()
+	OnContinue func()
+	OnStop     func()
+	// OnSongPosition is called for song position pointers with the
+	// number of sixteenth steps since the start of the song
+	OnSongPosition func(steps int)
+	// OnLocate is called for MMC locate commands with the time located to
+	OnLocate func(t time.Duration)
+	// DeviceID is the MMC device ID of the listener. Commands to other
+	// devices are ignored, except for ones to all of them.
+	DeviceID byte
+}
+
+// ControlTransport makes start, stop and locate messages control the
+// transport, so the sequencer follows a DAW or hardware as a slave device.
+// Start plays from the start of the loop and continue from the position.
+// Pulses of the clock, if not nil, are counted from start and continue
+// messages.
+func (l *MIDIListener) ControlTransport(t *Transport, clock *MIDIClock) {
+	play := func() {
+		if clock != nil {
+			clock.Reset()
+		}
+		t.Play()
+	}
+
+	l.OnStart = func() {
+		from, _ := t.Loop()
+		t.Seek(from/barSteps, from%barSteps)
+		play()
+	}
+	l.OnContinue = play
+	l.OnStop = t.Pause
+	l.OnSongPosition = func(steps int) {
+		// Songs longer than the pattern loop it
+		if length := t.length(); length > 0 {
+			steps %= length
+			t.Seek(steps/barSteps, steps%barSteps)
+		}
+	}
+	l.OnLocate = func(d time.Duration) {
+		t.Locate(d)
+	}
+}
+
+// Listen reads MIDI messages from r until it's exhausted.
+func (l *MIDIListener) Listen(r io.Reader) error {
+	reader := bufio.NewReader(r)
+
+	var status byte
+	var data []byte
+
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case b >= midiRealtime:
+			// Real-time messages may appear anywhere, even between data bytes
+			l.realtime(b)
+			continue
+		case b == midiSysExEnd:
+			if status == midiSysExStart {
+				l.sysEx(data)
+			}
+			status = 0
+			continue
+		case b >= 0x80:
+			status = b
+			data = data[:0]
+			continue
+		}
+
+		// Data byte, possibly using running status
+		if status == 0 {
+			continue
+		}
+
+		data = append(data, b)
+		if len(data) < 2 || status == midiSysExStart {
+			continue
+		}
+
+		switch {
+		case status == midiSongPosition:
+			if l.OnSongPosition != nil {
+				// Song positions count MIDI beats, which are sixteenth steps
+				l.OnSongPosition(int(data[0]) | int(data[1])<<7)
+			}
+			status = 0
+		case status&0xf0 == midiNoteOn && data[1] > 0:
+			if track, ok := l.Notes[data[0]]; ok && l.OnHit != nil {
+				l.OnHit(track, data[1])
+			}
+		}
+
+		data = data[:0]
+	}
+}
+
+// realtime handles the real-time message.
+func (l *MIDIListener) realtime(b byte) {
+	var f func()
+	switch b {
+	case midiTimingClock:
+		f = l.OnClock
+	case midiStart:
+		f = l.OnStart
+	case midiContinue:
+		f = l.OnContinue
+	case midiStop:
+		f = l.OnStop
+	}
+
+	if f != nil {
+		f()
+	}
+}
+
+// sysEx handles the system exclusive message without its start and end
+// bytes, if it's an MMC command to the listener's device.
+func (l *MIDIListener) sysEx(data []byte) {
+	if len(data) < 4 || data[0] != mmcRealtime || data[2] != mmcCommand {
+		return
+	}
+	if data[1] != l.DeviceID && data[1] != mmcAllCall {
+		return
+	}
+
+	var f func()
+	switch data[3] {
+	case mmcPlay, mmcDeferredPlay:
+		f = l.OnContinue
+	case mmcStop, mmcPause:
+		f = l.OnStop
+	case mmcLocate:
+		// Locate to a target time of hours, minutes, seconds, frames
+		// and hundredths of frames
+		target := data[4:]
+		if len(target) < 7 || target[1] != mmcTarget || l.OnLocate == nil {
+			return
+		}
+
+		rate := mmcFrameRates[target[2]>>5&0x3]
+		seconds := float64(target[2]&0x1f)*3600 + float64(target[3])*60 + float64(target[4])
+		seconds += (float64(target[5]) + float64(target[6])/100) / rate
+		l.OnLocate(time.Duration(seconds * float64(time.Second)))
+	}
+
+	if f != nil {
+		f()
+	}
+}
@@ -0,0 +1,119 @@
+package sequencer
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGMNoteMap(t *testing.T) {
+	expected := NoteMap{36: 0, 38: 1}
+	if notes := GMNoteMap(testPattern); !reflect.DeepEqual(notes, expected) {
+		t.Fatalf("expected %v, got %v", expected, notes)
+	}
+}
+
+func TestMIDIListener(t *testing.T) {
+	var hits [][2]int
+	clocks := 0
+
+	l := &MIDIListener{
+		Notes:   NoteMap{36: 0, 38: 1},
+		OnHit:   func(track int, velocity byte) { hits = append(hits, [2]int{track, int(velocity)}) },
+		OnClock: func() { clocks++ },
+	}
+
+	stream := []byte{
+		0x99, 36, 100, // Note on, channel 10
+		38, 0x7f, // Running status
+		0xf8,        // Timing clock
+		38, 0xf8, 0, // Note off as note on with velocity 0, with clock between data bytes
+		0xf0, 0x41, 0x10, 0xf7, // SysEx
+		0x89, 36, 64, // Note off
+		0x99, 40, 90, // Unmapped note
+	}
+
+	if err := l.Listen(bytes.NewReader(stream)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][2]int{{0, 100}, {1, 127}}
+	if !reflect.DeepEqual(hits, expected) {
+		t.Fatalf("expected hits %v, got %v", expected, hits)
+	}
+	if clocks != 2 {
+		t.Fatalf("expected 2 clock pulses, got %d", clocks)
+	}
+}
+
+func TestMIDIListenerTransportMessages(t *testing.T) {
+	var messages []string
+	l := &MIDIListener{
+		OnStart:        func() { messages = append(messages, "start") },
+		OnContinue:     func() { messages = append(messages, "continue") },
+		OnStop:         func() { messages = append(messages, "stop") },
+		OnSongPosition: func(steps int) { messages = append(messages, fmt.Sprintf("position %d", steps)) },
+		OnLocate:       func(d time.Duration) { messages = append(messages, fmt.Sprintf("locate %v", d)) },
+		DeviceID:       0x10,
+	}
+
+	stream := []byte{
+		0xf2, 0x05, 0x01, // Song position of 133 steps
+		0xfa, 0xfc, 0xfb, // Start, stop, continue
+		0xf0, 0x7f, 0x10, 0x06, 0x01, 0xf7, // MMC stop
+		0xf0, 0x7f, 0x7f, 0x06, 0x02, 0xf7, // MMC play to all devices
+		0xf0, 0x7f, 0x11, 0x06, 0x01, 0xf7, // MMC stop to another device
+		// MMC locate to 1h 2m 3s and 12.5 frames at 25 fps
+		0xf0, 0x7f, 0x10, 0x06, 0x44, 0x06, 0x01, 0x21, 0x02, 0x03, 0x0c, 0x32, 0xf7,
+	}
+
+	if err := l.Listen(bytes.NewReader(stream)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"position 133", "start", "stop", "continue", "stop", "continue", "locate 1h2m3.5s"}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("expected messages %v, got %v", expected, messages)
+	}
+}
+
+func TestControlTransport(t *testing.T) {
+	s := New(testPattern, NewFakeClock(), make(recordingSink, 16))
+	transport := s.Transport()
+	transport.Pause()
+
+	l := &MIDIListener{}
+	l.ControlTransport(transport, nil)
+
+	l.OnSongPosition(6)
+	if bar, step := transport.Position(); bar != 0 || step != 2 {
+		t.Errorf("expected song position looping the pattern to step 2, got step %d of bar %d", step, bar)
+	}
+
+	l.OnLocate(130 * time.Millisecond)
+	if bar, step := transport.Position(); bar != 0 || step != 1 {
+		t.Errorf("expected located step 1, got step %d of bar %d", step, bar)
+	}
+
+	l.OnStart()
+	if bar, step := transport.Position(); bar != 0 || step != 0 {
+		t.Errorf("expected start at step 0, got step %d of bar %d", step, bar)
+	}
+
+	s.advance()
+	l.OnStop()
+	s.advance()
+	if bar, step := transport.Position(); bar != 0 || step != 1 {
+		t.Errorf("expected position kept at step 1 after stop, got step %d of bar %d", step, bar)
+	}
+
+	if err := transport.SetLoop(2, 4); err != nil {
+		t.Fatal(err)
+	}
+	l.OnStart()
+	if bar, step := transport.Position(); bar != 0 || step != 2 {
+		t.Errorf("expected start at step 2 of the loop, got step %d of bar %d", step, bar)
+	}
+}
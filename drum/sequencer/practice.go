@@ -0,0 +1,122 @@
+package sequencer
+
+import (
+	"fmt"
+	"time"
+)
+
+// countInBeatSteps is the number of steps in a single beat of a count-in.
+const countInBeatSteps = 4
+
+// Practice configures playback for drummers practicing a pattern.
+type Practice struct {
+	// CountIn is the number of bars of clicks played before the pattern
+	CountIn int
+	// Click is called on every beat of the count-in with the beat's
+	// number, counting from 1
+	Click func(beat int)
+	// Speed scales the tempo, e.g. 0.5 for half-speed practice.
+	// Zero means full speed.
+	Speed float64
+	// LoopStart and LoopEnd limit playback to the section of steps
+	// from LoopStart up to, but not including, LoopEnd. Zero LoopEnd
+	// means the end of the pattern.
+	LoopStart int
+	LoopEnd   int
+}
+
+// SetPractice sets practice options, restarting playback from the count-in
+// and the start of the looped section. It fails if the section is out of
+// the pattern's range.
+func (s *Sequencer) SetPractice(practice Practice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if practice.Speed == 0 {
+		practice.Speed = 1
+	}
+	if practice.Speed < 0 {
+		return fmt.Errorf("invalid speed %v", practice.Speed)
+	}
+	if practice.CountIn < 0 {
+		return fmt.Errorf("invalid count-in of %d bars", practice.CountIn)
+	}
+	if practice.LoopEnd == 0 {
+		practice.LoopEnd = len(s.steps)
+	}
+	if practice.LoopStart < 0 || practice.LoopStart >= practice.LoopEnd || practice.LoopEnd > len(s.steps) {
+		return fmt.Errorf("invalid loop of steps %d-%d in a pattern of %d steps",
+			practice.LoopStart+1, practice.LoopEnd, len(s.steps))
+	}
+
+	s.practice = practice
+	s.position = practice.LoopStart
+	s.played = -1
+	s.countIn = practice.CountIn * countInBeatSteps * countInBeatSteps
+	s.pushStepDuration()
+
+	return nil
+}
+
+// pushStepDuration sets the duration of the step being played on the
+// clock, if it supports it, once the speed changed.
+func (s *Sequencer) pushStepDuration() {
+	setter, ok := s.clock.(StepSetter)
+	if !ok {
+		return
+	}
+
+	step := s.played
+	if step < 0 {
+		step = s.position
+	}
+	setter.SetStep(s.stepDuration(step))
+}
+
+// loopStart returns the first step played in a loop. Loops starting past
+// the end of a shorter scene play all of it.
+func (s *Sequencer) loopStart() int {
+	if s.practice.LoopStart >= s.loopEnd() {
+		return 0
+	}
+
+	return s.practice.LoopStart
+}
+
+// loopEnd returns the step following the last one played in a loop,
+// cut to the length of a shorter scene.
+func (s *Sequencer) loopEnd() int {
+	if s.practice.LoopEnd == 0 || s.practice.LoopEnd > len(s.steps) {
+		return len(s.steps)
+	}
+
+	return s.practice.LoopEnd
+}
+
+// stepDuration returns the duration of the step at the position,
+// scaled by the practice speed.
+func (s *Sequencer) stepDuration(position int) time.Duration {
+	duration := s.pattern.StepDurationAt(position)
+	if s.practice.Speed > 0 {
+		duration = time.Duration(float64(duration) / s.practice.Speed)
+	}
+
+	return duration
+}
+
+// countInStep plays a step of the count-in, clicking on its beats.
+// It returns false once the count-in is over.
+func (s *Sequencer) countInStep() bool {
+	if s.countIn == 0 {
+		return false
+	}
+
+	played := s.practice.CountIn*countInBeatSteps*countInBeatSteps - s.countIn
+	if played%countInBeatSteps == 0 && s.practice.Click != nil {
+		s.practice.Click(played/countInBeatSteps%countInBeatSteps + 1)
+	}
+
+	s.countIn--
+
+	return true
+}
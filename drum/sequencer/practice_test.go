@@ -0,0 +1,90 @@
+package sequencer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPracticeCountIn(t *testing.T) {
+	sink := make(recordingSink, 16)
+	s := New(testPattern, NewFakeClock(), sink)
+
+	var beats []int
+	err := s.SetPractice(Practice{CountIn: 1, Click: func(beat int) { beats = append(beats, beat) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A bar of count-in, then the first step of the pattern
+	for i := 0; i < 17; i++ {
+		s.advance()
+	}
+
+	if expected := []int{1, 2, 3, 4}; !reflect.DeepEqual(beats, expected) {
+		t.Fatalf("unexpected count-in beats %v, expected %v", beats, expected)
+	}
+	expectSteps(t, sink, 0)
+	if len(sink) != 0 {
+		t.Fatalf("unexpected events during the count-in")
+	}
+}
+
+func TestPracticeLoopSection(t *testing.T) {
+	sink := make(recordingSink, 16)
+	s := New(testPattern, NewFakeClock(), sink)
+
+	if err := s.SetPractice(Practice{LoopStart: 1, LoopEnd: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	var ends []bool
+	for i := 0; i < 4; i++ {
+		ends = append(ends, s.advance())
+	}
+
+	expectSteps(t, sink, 1, 2, 1, 2)
+	if expected := []bool{false, true, false, true}; !reflect.DeepEqual(ends, expected) {
+		t.Fatalf("unexpected loop ends %v, expected %v", ends, expected)
+	}
+}
+
+func TestPracticeSpeed(t *testing.T) {
+	s := New(testPattern, NewFakeClock(), make(recordingSink, 16))
+
+	if err := s.SetPractice(Practice{Speed: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if d := s.stepDuration(0); d != 250*time.Millisecond {
+		t.Fatalf("unexpected step duration %v at half speed", d)
+	}
+}
+
+func TestPracticeSpeedClock(t *testing.T) {
+	clock := steppingClock{NewFakeClock(), make(chan time.Duration, 1)}
+	s := New(testPattern, clock, make(recordingSink, 16))
+	s.advance()
+
+	// The clock follows the speed from the step being played
+	if err := s.SetPractice(Practice{Speed: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if step := <-clock.steps; step != 250*time.Millisecond {
+		t.Fatalf("clock set to step of %v at half speed", step)
+	}
+}
+
+func TestPracticeInvalid(t *testing.T) {
+	s := New(testPattern, NewFakeClock(), make(recordingSink, 16))
+
+	for _, practice := range []Practice{
+		{Speed: -1},
+		{CountIn: -1},
+		{LoopStart: 2, LoopEnd: 2},
+		{LoopStart: 0, LoopEnd: 5},
+	} {
+		if err := s.SetPractice(practice); err == nil {
+			t.Errorf("expected an error for %+v", practice)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sequencer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// Record starts recording hits into the track at the given index of
+// a copy of the played pattern. Steps already present in the track are kept.
+func (s *Sequencer) Record(track int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if track < 0 || track >= len(s.pattern.Tracks) {
+		return fmt.Errorf("no track at index %d", track)
+	}
+
+	s.recording = s.pattern.Clone()
+	s.recording.Unpack()
+	s.recordTrack = track
+
+	return nil
+}
+
+// Hit records a hit, e.g. from a key press, into the recorded track.
+// See HitTrack.
+func (s *Sequencer) Hit() {
+	s.mu.Lock()
+	track := s.recordTrack
+	s.mu.Unlock()
+
+	s.HitTrack(track)
+}
+
+// HitTrack records a hit into the track at the given index, e.g. from
+// a MIDI pad mapped to the track. Hits are quantized to the nearest step:
+// hits landing in the second half of a step are recorded on the step played
+// next, also when a looped section wraps around. Hits outside of recording,
+// during the count-in and into missing tracks are ignored.
+func (s *Sequencer) HitTrack(track int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.recording == nil || s.played < 0 || len(s.steps) == 0 {
+		return
+	}
+
+	if track < 0 || track >= len(s.recording.Tracks) {
+		return
+	}
+
+	interval := s.tickInterval
+	if interval == 0 {
+		interval = s.stepDuration(s.played)
+	}
+
+	// Late hits belong to the step played next, where position points
+	step := s.played
+	if s.now().Sub(s.lastTick) > interval/2 {
+		step = s.position
+	}
+
+	// Tracks are played shifted by their offsets, so the hit belongs to
+	// the step played at the position
+	recorded := s.recording.Tracks[track]
+	if n := len(recorded.Steps); n > 0 {
+		recorded.Steps[((step-recorded.Offset)%n+n)%n] = drum.StepOn
+	}
+}
+
+// StopRecording stops recording and returns the recorded pattern.
+func (s *Sequencer) StopRecording() (*drum.Pattern, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.recording == nil {
+		return nil, errors.New("not recording")
+	}
+
+	recorded := s.recording
+	s.recording = nil
+
+	return recorded, nil
+}
@@ -0,0 +1,127 @@
+package sequencer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	sink := make(recordingSink, 16)
+	s := New(testPattern, NewFakeClock(), sink)
+
+	now := time.Unix(0, 0)
+	s.now = func() time.Time { return now }
+
+	if err := s.Record(5); err == nil {
+		t.Fatal("expected error recording into missing track")
+	}
+	if err := s.Record(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Step 0 and a hit right after it
+	s.advance()
+	now = now.Add(10 * time.Millisecond)
+	s.Hit()
+
+	// Step 1 and a hit late in it, so on step 2
+	now = now.Add(115 * time.Millisecond)
+	s.advance()
+	now = now.Add(100 * time.Millisecond)
+	s.Hit()
+
+	recorded, err := s.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{1, 1, 1, 0}
+	for i, step := range recorded.Tracks[1].Steps {
+		if step != expected[i] {
+			t.Fatalf("expected recorded steps %v, got %v", expected, recorded.Tracks[1].Steps)
+		}
+	}
+
+	if testPattern.Tracks[1].Steps[0] != 0 {
+		t.Fatal("recording modified the played pattern")
+	}
+
+	if _, err := s.StopRecording(); err == nil {
+		t.Fatal("expected error stopping recording twice")
+	}
+}
+
+func TestRecordLoopSection(t *testing.T) {
+	s := New(testPattern, NewFakeClock(), make(recordingSink, 16))
+
+	now := time.Unix(0, 0)
+	s.now = func() time.Time { return now }
+
+	if err := s.SetPractice(Practice{CountIn: 1, LoopStart: 1, LoopEnd: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Record(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hits during the count-in are ignored
+	for i := 0; i < countInBeatSteps*countInBeatSteps; i++ {
+		s.advance()
+		now = now.Add(100 * time.Millisecond)
+		s.Hit()
+		now = now.Add(25 * time.Millisecond)
+	}
+
+	// Steps 1 and 2, with a hit early in step 2, after which the section
+	// wraps around to step 1
+	s.advance()
+	now = now.Add(125 * time.Millisecond)
+	s.advance()
+	now = now.Add(10 * time.Millisecond)
+	s.Hit()
+
+	recorded, err := s.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{0, 1, 1, 0}
+	if !bytes.Equal(recorded.Tracks[1].Steps, expected) {
+		t.Fatalf("expected recorded steps %v, got %v", expected, recorded.Tracks[1].Steps)
+	}
+}
+
+func TestRecordOffset(t *testing.T) {
+	p := testPattern.Clone()
+	p.Tracks[1].Offset = 1
+
+	s := New(p, NewFakeClock(), make(recordingSink, 16))
+
+	now := time.Unix(0, 0)
+	s.now = func() time.Time { return now }
+
+	if err := s.Record(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// A hit on step 0, played from the last step of the delayed track,
+	// and on step 2, played from its step 1
+	s.advance()
+	s.Hit()
+	now = now.Add(125 * time.Millisecond)
+	s.advance()
+	now = now.Add(125 * time.Millisecond)
+	s.advance()
+	s.Hit()
+
+	recorded, err := s.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{0, 1, 0, 1}
+	if !bytes.Equal(recorded.Tracks[1].Steps, expected) {
+		t.Fatalf("expected recorded steps %v, got %v", expected, recorded.Tracks[1].Steps)
+	}
+}
@@ -0,0 +1,130 @@
+package sequencer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// routeBuffer is the number of delayed events a route holds.
+const routeBuffer = 256
+
+// Route is an output of a Router.
+type Route struct {
+	Sink Sink
+	// Latency is the time between triggering an event on the sink and it
+	// being heard or seen, e.g. the audio buffer or network delay
+	Latency time.Duration
+	// Tracks are indexes of tracks routed to the sink, all if empty
+	Tracks []int
+}
+
+// Router is a sink routing events of tracks to outputs, like a matrix of
+// tracks and outputs. Events are delayed on outputs faster than the
+// slowest one, so all of them land in sync despite different latencies.
+type Router struct {
+	routes []*route
+	wg     sync.WaitGroup
+}
+
+// route is a Route delaying events by the difference of its latency from
+// the slowest one's.
+type route struct {
+	Route
+	tracks map[int]bool
+	delay  time.Duration
+	events chan delayedEvent
+}
+
+// delayedEvent is an event due to be triggered at a time.
+type delayedEvent struct {
+	drum.Event
+	due time.Time
+}
+
+// NewRouter returns a router triggering events on the routes.
+// Close must be called once playback stops.
+func NewRouter(routes ...Route) *Router {
+	r := &Router{}
+
+	for _, rt := range routes {
+		r.routes = append(r.routes, &route{Route: rt})
+	}
+
+	slowest := r.Latency()
+	for _, rt := range r.routes {
+		if len(rt.Tracks) > 0 {
+			rt.tracks = map[int]bool{}
+			for _, track := range rt.Tracks {
+				rt.tracks[track] = true
+			}
+		}
+
+		rt.delay = slowest - rt.Latency
+		if rt.delay > 0 {
+			rt.events = make(chan delayedEvent, routeBuffer)
+			r.wg.Add(1)
+			go r.run(rt)
+		}
+	}
+
+	return r
+}
+
+// Latency returns the latency of the slowest route, which is the time
+// between events being triggered on the router and all outputs playing them.
+func (r *Router) Latency() time.Duration {
+	var latency time.Duration
+	for _, rt := range r.routes {
+		latency = max(latency, rt.Latency)
+	}
+
+	return latency
+}
+
+// Trigger triggers the event on routes of its track, delayed by each
+// route's difference of latency from the slowest one.
+func (r *Router) Trigger(e drum.Event) {
+	now := time.Now()
+
+	for _, rt := range r.routes {
+		if rt.tracks != nil && !rt.tracks[e.TrackIndex] {
+			continue
+		}
+
+		if rt.events == nil {
+			rt.Sink.Trigger(e)
+			continue
+		}
+
+		// Drop the event rather than hold up other routes
+		select {
+		case rt.events <- delayedEvent{Event: e, due: now.Add(rt.delay)}:
+		default:
+		}
+	}
+}
+
+// run triggers delayed events of the route once they're due.
+// As all events of a route are delayed equally, they stay in order.
+func (r *Router) run(rt *route) {
+	defer r.wg.Done()
+
+	for e := range rt.events {
+		time.Sleep(time.Until(e.due))
+		rt.Sink.Trigger(e.Event)
+	}
+}
+
+// Close waits for delayed events to be triggered. The router mustn't be
+// triggered after it's closed.
+func (r *Router) Close() {
+	for _, rt := range r.routes {
+		if rt.events != nil {
+			close(rt.events)
+		}
+	}
+
+	r.wg.Wait()
+}
@@ -0,0 +1,50 @@
+package sequencer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestRouter(t *testing.T) {
+	type trigger struct {
+		drum.Event
+		at time.Time
+	}
+
+	fast := make(chan trigger, 16)
+	slow := make(chan trigger, 16)
+	record := func(c chan trigger) Sink {
+		return SinkFunc(func(e drum.Event) { c <- trigger{e, time.Now()} })
+	}
+
+	r := NewRouter(
+		Route{Sink: record(fast), Latency: 10 * time.Millisecond, Tracks: []int{1}},
+		Route{Sink: record(slow), Latency: 50 * time.Millisecond},
+	)
+	if r.Latency() != 50*time.Millisecond {
+		t.Errorf("expected latency of 50ms, got %v", r.Latency())
+	}
+
+	start := time.Now()
+	r.Trigger(drum.Event{TrackIndex: 0, Step: 0})
+	r.Trigger(drum.Event{TrackIndex: 1, Step: 1})
+	r.Close()
+
+	if len(slow) != 2 {
+		t.Fatalf("expected 2 events on the slow route, got %d", len(slow))
+	}
+	for _, step := range []int{0, 1} {
+		if e := <-slow; e.Step != step || e.at.Sub(start) > 10*time.Millisecond {
+			t.Errorf("expected step %d triggered at once on the slow route, got %+v after %v", step, e.Event, e.at.Sub(start))
+		}
+	}
+
+	if len(fast) != 1 {
+		t.Fatalf("expected only the routed track on the fast route, got %d events", len(fast))
+	}
+	if e := <-fast; e.Step != 1 || e.at.Sub(start) < 40*time.Millisecond {
+		t.Errorf("expected step 1 delayed by 40ms on the fast route, got %+v after %v", e.Event, e.at.Sub(start))
+	}
+}
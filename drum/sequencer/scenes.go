@@ -0,0 +1,142 @@
+package sequencer
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// SceneSwitching configures switching scenes of the pattern between
+// loops, for evolving live playback. Scenes may differ in length, and
+// loops reaching past the end of a shorter scene are cut to it.
+type SceneSwitching struct {
+	// Chain is a fixed order of scenes played loop after loop, repeated
+	// once it ends, see ParseChain
+	Chain []string
+	// Weights are relative chances of scenes to be played in each loop,
+	// used if Chain is empty
+	Weights map[string]float64
+	// Seed of random choices of scenes, making them reproducible
+	Seed int64
+	// OnSwitch is called with the scene's name when a loop of it starts.
+	// It mustn't call methods of the sequencer.
+	OnSwitch func(scene string)
+}
+
+// ParseChain parses a chain of single-letter scene names, e.g.
+// "AABB ABAC". Spaces are ignored.
+func ParseChain(s string) []string {
+	var chain []string
+	for _, r := range strings.ReplaceAll(s, " ", "") {
+		chain = append(chain, string(r))
+	}
+
+	return chain
+}
+
+// sceneSwitcher picks scenes of loops and holds their events.
+type sceneSwitcher struct {
+	SceneSwitching
+	// Events of scenes grouped by step
+	steps map[string][][]drum.Event
+	// Scenes with positive weights, sorted for reproducible choices
+	scenes []string
+	total  float64
+	rand   *rand.Rand
+	loop   int
+	scene  string
+}
+
+// SetSceneSwitching makes the sequencer switch scenes at the start of
+// each loop, including the current one. It fails if the chain or
+// weights refer to scenes the pattern doesn't have.
+func (s *Sequencer) SetSceneSwitching(switching SceneSwitching) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := map[string]bool{}
+	for _, name := range s.pattern.Scenes() {
+		known[name] = true
+	}
+
+	sw := &sceneSwitcher{
+		SceneSwitching: switching,
+		steps:          map[string][][]drum.Event{},
+		rand:           rand.New(rand.NewSource(switching.Seed)),
+	}
+
+	names := switching.Chain
+	if len(names) == 0 {
+		for name, weight := range switching.Weights {
+			if weight < 0 {
+				return fmt.Errorf("invalid weight %v of scene %s", weight, name)
+			}
+			if weight > 0 {
+				sw.scenes = append(sw.scenes, name)
+				sw.total += weight
+			}
+		}
+		sort.Strings(sw.scenes)
+		names = sw.scenes
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no scenes to switch between")
+	}
+
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("pattern has no scene %s", name)
+		}
+		if sw.steps[name] == nil {
+			p := s.pattern.Clone()
+			p.SetScene(name)
+			sw.steps[name] = stepEvents(p)
+		}
+	}
+
+	s.switcher = sw
+	s.nextScene()
+
+	return nil
+}
+
+// Scene returns the name of the scene being played.
+func (s *Sequencer) Scene() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.switcher == nil {
+		return s.pattern.Scene()
+	}
+
+	return s.switcher.scene
+}
+
+// nextScene switches to the scene of the next loop.
+func (s *Sequencer) nextScene() {
+	sw := s.switcher
+
+	if len(sw.Chain) > 0 {
+		sw.scene = sw.Chain[sw.loop%len(sw.Chain)]
+	} else {
+		x := sw.rand.Float64() * sw.total
+		for _, name := range sw.scenes {
+			sw.scene = name
+			x -= sw.Weights[name]
+			if x < 0 {
+				break
+			}
+		}
+	}
+	sw.loop++
+
+	s.steps = sw.steps[sw.scene]
+	s.position = s.clampToLoop(s.position)
+	s.debug("scene switched", "scene", sw.scene, "loop", sw.loop)
+	if sw.OnSwitch != nil {
+		sw.OnSwitch(sw.scene)
+	}
+}
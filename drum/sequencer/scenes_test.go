@@ -0,0 +1,107 @@
+package sequencer
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestSceneSwitchingChain(t *testing.T) {
+	p := testPattern.Clone()
+	p.SetSceneSteps(0, "B", []byte{0, 0, 0, 1})
+
+	sink := make(recordingSink, 16)
+	s := New(p, NewFakeClock(), sink)
+
+	var scenes []string
+	err := s.SetSceneSwitching(SceneSwitching{
+		Chain:    ParseChain("AB B"),
+		OnSwitch: func(scene string) { scenes = append(scenes, scene) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4*4; i++ {
+		s.advance()
+	}
+
+	if expected := []string{"A", "B", "B", "A", "B"}; !reflect.DeepEqual(scenes, expected) {
+		t.Fatalf("unexpected scenes %v, expected %v", scenes, expected)
+	}
+	expectSteps(t, sink, 0, 1, 2, 1, 3, 1, 3, 0, 1, 2)
+	if s.Scene() != "B" {
+		t.Fatalf("unexpected scene %s", s.Scene())
+	}
+
+	if err := s.SetSceneSwitching(SceneSwitching{Chain: ParseChain("AC")}); err == nil {
+		t.Fatalf("expected error switching to a missing scene")
+	}
+}
+
+func TestSceneSwitchingShorterScene(t *testing.T) {
+	p := &drum.Pattern{
+		Tempo:  120,
+		Tracks: []drum.Track{{Name: "kick", Steps: bytes.Repeat([]byte{drum.StepOn}, 16)}},
+	}
+	p.SetSceneSteps(0, "B", bytes.Repeat([]byte{drum.StepOn}, 8))
+
+	sink := make(recordingSink, 64)
+	s := New(p, NewFakeClock(), sink)
+	if err := s.Transport().SetLoop(4, 16); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetSceneSwitching(SceneSwitching{Chain: ParseChain("AB")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The loop is cut to steps 4-8 of scene B
+	for i := 0; i < 12+4+12; i++ {
+		s.advance()
+	}
+	expectSteps(t, sink, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 4, 5, 6, 7, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15)
+	if from, to := s.Transport().Loop(); s.Scene() != "B" || from != 4 || to != 8 {
+		t.Fatalf("expected loop of steps 4-8 in scene B, got %d-%d in scene %s", from, to, s.Scene())
+	}
+}
+
+func TestSceneSwitchingWeights(t *testing.T) {
+	p := testPattern.Clone()
+	p.SetSceneSteps(0, "B", []byte{0, 0, 0, 1})
+
+	play := func(weights map[string]float64, seed int64) []string {
+		var scenes []string
+		s := New(p, NewFakeClock(), make(recordingSink, 64))
+		err := s.SetSceneSwitching(SceneSwitching{
+			Weights:  weights,
+			Seed:     seed,
+			OnSwitch: func(scene string) { scenes = append(scenes, scene) },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 8*4; i++ {
+			s.advance()
+		}
+
+		return scenes
+	}
+
+	weights := map[string]float64{"A": 1, "B": 1}
+	first := play(weights, 42)
+	if !reflect.DeepEqual(first, play(weights, 42)) {
+		t.Fatalf("scenes not reproducible with the same seed")
+	}
+	if len(first) != 9 {
+		t.Fatalf("expected 9 switches, got %v", first)
+	}
+
+	for _, scene := range play(map[string]float64{"A": 0, "B": 1}, 1) {
+		if scene != "B" {
+			t.Fatalf("scene %s of zero weight played", scene)
+		}
+	}
+}
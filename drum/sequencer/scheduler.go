@@ -0,0 +1,95 @@
+package sequencer
+
+import (
+	"math"
+	"sync"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// ScheduledEvent is an event due at a frame of an audio buffer.
+type ScheduledEvent struct {
+	drum.Event
+	// Frame is the offset of the event in the buffer
+	Frame int
+}
+
+// Scheduler advances a sequencer on a timeline of samples instead of
+// a clock. It's meant to be called from an audio callback, which runs
+// once per buffer of a fixed sample rate, so events land on exact samples
+// and timing doesn't depend on when the callback happens to run. Practice
+// options, scenes and the transport of the sequencer apply as with a clock.
+type Scheduler struct {
+	sequencer  *Sequencer
+	sampleRate int
+
+	mu sync.Mutex
+	// Sample of the next step since the start. It's kept fractional,
+	// so rounding of step lengths doesn't accumulate.
+	next float64
+	// Samples processed since the start
+	elapsed int64
+	// Events of the buffer being processed and the frame of the step
+	events []ScheduledEvent
+	frame  int
+}
+
+// NewScheduler returns a scheduler playing the pattern at the sample rate.
+func NewScheduler(p *drum.Pattern, sampleRate int) *Scheduler {
+	s := &Scheduler{sampleRate: sampleRate}
+	s.sequencer = New(p, nil, SinkFunc(s.schedule))
+
+	return s
+}
+
+// Sequencer returns the sequencer advanced by the scheduler, e.g. to set
+// its practice options or control its transport. It mustn't be run.
+func (s *Scheduler) Sequencer() *Sequencer {
+	return s.sequencer
+}
+
+// Process returns events due within the next buffer of the given number
+// of frames, ordered by frame, and moves past the buffer. Nothing is
+// scheduled if the pattern has no steps or its tempo isn't positive.
+// The returned events are valid until the next call, which reuses them,
+// so processing doesn't allocate.
+func (s *Scheduler) Process(frames int) []ScheduledEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = s.events[:0]
+	end := s.elapsed + int64(frames)
+
+	for {
+		at := int64(math.Round(s.next))
+		if at >= end {
+			break
+		}
+
+		step := s.sequencer.nextStepDuration()
+		if step <= 0 {
+			break
+		}
+
+		s.frame = int(at - s.elapsed)
+		s.sequencer.advance()
+		s.next += step.Seconds() * float64(s.sampleRate)
+	}
+
+	s.elapsed = end
+
+	return s.events
+}
+
+// schedule adds an event triggered by the sequencer at the current frame.
+func (s *Scheduler) schedule(e drum.Event) {
+	s.events = append(s.events, ScheduledEvent{Event: e, Frame: s.frame})
+}
+
+// Elapsed returns the number of samples processed since the start.
+func (s *Scheduler) Elapsed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.elapsed
+}
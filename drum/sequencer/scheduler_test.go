@@ -0,0 +1,60 @@
+package sequencer
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	const rate = 44100
+	s := NewScheduler(testPattern, rate)
+
+	// Buffers of odd sizes, not aligned with 5512.5 samples long steps
+	var frames []int64
+	var steps []int
+	for _, size := range []int{300, 512, 4096, 1000, 7, 2048, 9000, 333, 4096, 8192, 10000} {
+		start := s.Elapsed()
+		for _, e := range s.Process(size) {
+			if e.Frame < 0 || e.Frame >= size {
+				t.Fatalf("event %+v outside of buffer of %d frames", e, size)
+			}
+			frames = append(frames, start+int64(e.Frame))
+			steps = append(steps, e.Step)
+		}
+	}
+
+	expectedSteps := []int{0, 1, 2, 0, 1, 2}
+	if len(steps) != len(expectedSteps) {
+		t.Fatalf("expected events at steps %v, got %v", expectedSteps, steps)
+	}
+
+	for i, frame := range frames {
+		// Steps of 125ms, 4 per loop
+		loop, step := i/3, expectedSteps[i]
+		expected := time.Duration(loop*4+step) * 125 * time.Millisecond
+
+		actual := time.Duration(float64(frame) / rate * float64(time.Second))
+		if jitter := actual - expected; math.Abs(float64(jitter)) > float64(time.Second/rate) {
+			t.Errorf("event at step %d of loop %d off by %v", step, loop, jitter)
+		}
+	}
+}
+
+func TestSchedulerTransport(t *testing.T) {
+	s := NewScheduler(testPattern, 8000)
+
+	// Steps of testPattern are 1000 samples long at 8000 Hz
+	if err := s.Sequencer().Transport().Seek(0, 2); err != nil {
+		t.Fatal(err)
+	}
+	events := s.Process(1000)
+	if len(events) != 1 || events[0].Step != 2 || events[0].Frame != 0 {
+		t.Fatalf("expected step 2 at frame 0 after seeking, got %+v", events)
+	}
+
+	s.Sequencer().Transport().Pause()
+	if events := s.Process(4000); len(events) != 0 {
+		t.Fatalf("expected no events while paused, got %+v", events)
+	}
+}
@@ -0,0 +1,262 @@
+// Package sequencer plays drum patterns step by step, triggering
+// their events on a sink in time with a clock.
+package sequencer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// Sink receives events triggered by the sequencer. Events are triggered
+// without holding locks of the sequencer, so sinks may use its methods
+// and transport.
+type Sink interface {
+	Trigger(e drum.Event)
+}
+
+// SinkFunc is an adapter allowing use of a function as a Sink.
+type SinkFunc func(e drum.Event)
+
+// Trigger calls f(e).
+func (f SinkFunc) Trigger(e drum.Event) {
+	f(e)
+}
+
+// MultiSink returns a sink triggering events on all sinks, in order.
+func MultiSink(sinks ...Sink) Sink {
+	return SinkFunc(func(e drum.Event) {
+		for _, sink := range sinks {
+			sink.Trigger(e)
+		}
+	})
+}
+
+// Sequencer loops a pattern, triggering events of each step
+// on every tick of its clock.
+type Sequencer struct {
+	pattern *drum.Pattern
+	clock   Clock
+	sink    Sink
+	// Logger of the pattern, see drum.WithLogger
+	logger *slog.Logger
+
+	mu sync.Mutex
+	// Events grouped by step
+	steps    [][]drum.Event
+	position int
+	// Step last played, or -1 if none was since the start or count-in
+	played int
+
+	now func() time.Time
+	// Time of the last tick and interval between the last two ticks
+	lastTick     time.Time
+	tickInterval time.Duration
+	// Time the next tick is due, for clocks paced by step durations
+	due time.Time
+
+	recording   *drum.Pattern
+	recordTrack int
+
+	practice Practice
+	// Steps of the count-in left to play
+	countIn int
+
+	switcher *sceneSwitcher
+
+	running   bool
+	paused    bool
+	listeners []func(change TransportChange)
+}
+
+// New returns a sequencer playing the pattern to the sink, driven by the clock.
+func New(p *drum.Pattern, clock Clock, sink Sink) *Sequencer {
+	s := &Sequencer{
+		clock:  clock,
+		sink:   sink,
+		now:    time.Now,
+		played: -1,
+	}
+	s.load(p)
+
+	return s
+}
+
+// load prepares events of the pattern for playback.
+func (s *Sequencer) load(p *drum.Pattern) {
+	s.logger = p.Logger()
+	if _, err := p.LoadTracks(); err != nil {
+		s.debug("loading tracks failed", "error", err)
+	}
+	s.pattern = p
+	s.steps = stepEvents(p)
+}
+
+// debug logs a debug event if the pattern has a logger.
+func (s *Sequencer) debug(msg string, args ...any) {
+	if s.logger != nil {
+		s.logger.Debug(msg, args...)
+	}
+}
+
+// stepEvents returns events of the pattern grouped by step.
+func stepEvents(p *drum.Pattern) [][]drum.Event {
+	length := 0
+	for _, track := range p.Tracks {
+		if track.Len() > length {
+			length = track.Len()
+		}
+	}
+
+	steps := make([][]drum.Event, length)
+	for _, e := range p.Events() {
+		steps[e.Step] = append(steps[e.Step], e)
+	}
+
+	return steps
+}
+
+// Run plays the pattern in a loop until ctx is done.
+func (s *Sequencer) Run(ctx context.Context) error {
+	ticks := s.clock.Start(s.nextStepDuration())
+	defer s.clock.Stop()
+
+	s.setRunning(true)
+	defer s.setRunning(false)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticks:
+			s.advance()
+		}
+	}
+}
+
+// RunLoops plays the pattern n times, or in a loop if n is 0. Once ctx is
+// done, playback stops at the end of the current bar or loop rather than
+// at once, so it's never cut off mid-bar, unless the transport is paused.
+// If the clock stops ticking meanwhile, e.g. an external MIDI clock, the
+// bar is given up once a tick is two steps late.
+func (s *Sequencer) RunLoops(ctx context.Context, n int) error {
+	if len(s.steps) == 0 {
+		return nil
+	}
+
+	ticks := s.clock.Start(s.nextStepDuration())
+	defer s.clock.Stop()
+
+	s.setRunning(true)
+	defer s.setRunning(false)
+
+	done := ctx.Done()
+	// late is set once ctx is done, while the bar is finished
+	var late <-chan time.Time
+	for loops := 0; n == 0 || loops < n; {
+		select {
+		case <-ticks:
+		case <-late:
+			return nil
+		case <-done:
+			// A paused sequencer has no bar to finish
+			if s.Transport().State() == Paused || s.atBarStart() {
+				return nil
+			}
+			done = nil
+			late = time.After(2 * s.nextStepDuration())
+			continue
+		}
+
+		if s.advance() {
+			loops++
+		}
+
+		if late != nil {
+			if s.atBarStart() {
+				break
+			}
+			late = time.After(2 * s.nextStepDuration())
+		}
+	}
+
+	return nil
+}
+
+// atBarStart returns true if the step played next starts a bar or the loop.
+func (s *Sequencer) atBarStart() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.position%barSteps == 0 || s.position == s.loopStart()
+}
+
+// nextStepDuration returns the duration of the step played next.
+func (s *Sequencer) nextStepDuration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stepDuration(s.position)
+}
+
+// advance triggers events of the current step and moves to the next one.
+// It returns true if the step was the last one of the pattern, or of the
+// looped section if practice options limit it.
+func (s *Sequencer) advance() bool {
+	s.mu.Lock()
+	events, wrapped := s.step()
+	s.mu.Unlock()
+
+	for _, e := range events {
+		s.sink.Trigger(e)
+	}
+
+	return wrapped
+}
+
+// step moves to the next step, returning events of the current one to
+// trigger, and whether the step wrapped around the loop.
+func (s *Sequencer) step() ([]drum.Event, bool) {
+	now := s.now()
+	if !s.lastTick.IsZero() {
+		s.tickInterval = now.Sub(s.lastTick)
+	}
+	s.lastTick = now
+
+	if _, paced := s.clock.(StepSetter); paced && len(s.steps) > 0 {
+		step := s.stepDuration(s.position)
+		if !s.due.IsZero() {
+			late := now.Sub(s.due)
+			if playbackMetrics.Load().observeTick(late, step) {
+				s.debug("tick late", "position", s.position, "late", late)
+			}
+		}
+		s.due = now.Add(step)
+	}
+
+	if len(s.steps) == 0 || s.paused || s.countInStep() {
+		return nil, false
+	}
+
+	events := s.steps[s.position]
+	s.played = s.position
+	playbackMetrics.Load().steps.Add(1)
+
+	// The step lasts until the next tick
+	if setter, ok := s.clock.(StepSetter); ok && len(s.pattern.TempoChanges) > 0 {
+		setter.SetStep(s.stepDuration(s.position))
+	}
+
+	s.position++
+	if s.position >= s.loopEnd() {
+		s.position = s.loopStart()
+		if s.switcher != nil {
+			s.nextScene()
+		}
+	}
+
+	return events, s.position == s.loopStart()
+}
@@ -0,0 +1,324 @@
+package sequencer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+var testPattern = &drum.Pattern{
+	Version: "0.808-alpha",
+	Tempo:   120,
+	Tracks: []drum.Track{
+		{ID: 0, Name: "kick", Steps: []byte{1, 0, 1, 0}},
+		{ID: 1, Name: "snare", Steps: []byte{0, 1, 0, 0}},
+	},
+}
+
+// recordingSink collects triggered events.
+type recordingSink chan drum.Event
+
+func (r recordingSink) Trigger(e drum.Event) {
+	r <- e
+}
+
+// expectSteps reads events from the sink, expecting hits on given steps.
+func expectSteps(t *testing.T, sink recordingSink, steps ...int) {
+	t.Helper()
+
+	for _, step := range steps {
+		select {
+		case e := <-sink:
+			if e.Step != step {
+				t.Fatalf("expected event at step %d, got %+v", step, e)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event at step %d", step)
+		}
+	}
+}
+
+func TestSequencer(t *testing.T) {
+	clock := NewFakeClock()
+	sink := make(recordingSink, 16)
+	s := New(testPattern, clock, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	// Loop the pattern one and a half times
+	for i := 0; i < 6; i++ {
+		clock.Tick()
+	}
+
+	expectSteps(t, sink, 0, 1, 2, 0, 1)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunLoops(t *testing.T) {
+	clock := NewFakeClock()
+	sink := make(recordingSink, 16)
+	s := New(testPattern, clock, sink)
+
+	done := make(chan error)
+	go func() { done <- s.RunLoops(context.Background(), 2) }()
+
+	for i := 0; i < 8; i++ {
+		clock.Tick()
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	expectSteps(t, sink, 0, 1, 2, 0, 1, 2)
+}
+
+func TestRunLoopsStopsAtLoopEnd(t *testing.T) {
+	clock := NewFakeClock()
+	sink := make(recordingSink, 16)
+	s := New(testPattern, clock, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.RunLoops(ctx, 0) }()
+
+	// Cancel mid-loop, the loop is still played until its end
+	clock.Tick()
+	cancel()
+	for i := 0; i < 3; i++ {
+		clock.Tick()
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	expectSteps(t, sink, 0, 1, 2)
+}
+
+func TestSinkUsesTransport(t *testing.T) {
+	clock := NewFakeClock()
+	sink := make(recordingSink, 16)
+
+	// Jump back to the start on every snare, which mustn't deadlock
+	var s *Sequencer
+	s = New(testPattern, clock, SinkFunc(func(e drum.Event) {
+		sink.Trigger(e)
+		if e.TrackIndex == 1 {
+			if err := s.Transport().Seek(0, 0); err != nil {
+				t.Error(err)
+			}
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	for i := 0; i < 4; i++ {
+		clock.Tick()
+	}
+	expectSteps(t, sink, 0, 1, 0, 1)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSequencerLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	p, err := drum.DecodeFile(path.Join("..", "fixtures", "pattern_1.splice"), drum.WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+
+	clock := NewFakeClock()
+	sink := make(recordingSink, 16)
+	s := New(p, clock, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	clock.Tick()
+	expectSteps(t, sink, 0)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`msg="playback started" position=0`,
+		`msg="playback stopped" position=1`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("log doesn't contain %q:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestMIDIClock(t *testing.T) {
+	clock := NewMIDIClock()
+	sink := make(recordingSink, 16)
+	s := New(testPattern, clock, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	// Wait for the sequencer to start the clock
+	for {
+		clock.mu.Lock()
+		running := clock.running
+		clock.mu.Unlock()
+		if running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// A step every sixth pulse
+	for i := 0; i < 12; i++ {
+		clock.Pulse()
+		if i%midiPulsesPerStep == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	expectSteps(t, sink, 0, 1)
+}
+
+func TestRealClock(t *testing.T) {
+	clock := RealClock()
+	ticks := clock.Start(10 * time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		<-ticks
+	}
+	clock.Stop()
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("5 ticks of 10ms took only %v", elapsed)
+	}
+
+	// Setting the step after a tick reschedules the following one
+	ticks = clock.Start(time.Hour)
+	<-ticks
+	clock.(StepSetter).SetStep(10 * time.Millisecond)
+	select {
+	case <-ticks:
+	case <-time.After(time.Second):
+		t.Fatal("tick wasn't rescheduled")
+	}
+	clock.Stop()
+}
+
+// steppingClock is a fake clock recording step durations it's set to.
+type steppingClock struct {
+	*FakeClock
+	steps chan time.Duration
+}
+
+func (c steppingClock) SetStep(step time.Duration) {
+	c.steps <- step
+}
+
+func TestSequencerTempoChanges(t *testing.T) {
+	p := testPattern.Clone()
+	p.SetTempoChange(2, 60)
+
+	clock := steppingClock{NewFakeClock(), make(chan time.Duration, 16)}
+	s := New(p, clock, make(recordingSink, 16))
+
+	for i := 0; i < 4; i++ {
+		s.advance()
+	}
+
+	// Each step lasts until the next tick
+	for _, expected := range []time.Duration{125, 125, 250, 250} {
+		if step := <-clock.steps; step != expected*time.Millisecond {
+			t.Fatalf("clock set to step of %v, expected %v", step, expected*time.Millisecond)
+		}
+	}
+}
+
+func TestMultiSink(t *testing.T) {
+	a, b := make(recordingSink, 1), make(recordingSink, 1)
+
+	MultiSink(a, b).Trigger(drum.Event{Step: 3})
+
+	expectSteps(t, a, 3)
+	expectSteps(t, b, 3)
+}
+
+func TestRunLoopsStopsAtBarEnd(t *testing.T) {
+	p := &drum.Pattern{
+		Tempo:  120,
+		Tracks: []drum.Track{{Name: "kick", Steps: make([]byte, 32)}},
+	}
+	for i := range p.Tracks[0].Steps {
+		p.Tracks[0].Steps[i] = drum.StepOn
+	}
+
+	clock := NewFakeClock()
+	sink := make(recordingSink, 32)
+	s := New(p, clock, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.RunLoops(ctx, 0) }()
+
+	// Cancel in the first bar, which is played until its end
+	for i := 0; i < 3; i++ {
+		clock.Tick()
+	}
+	cancel()
+	for i := 3; i < 16; i++ {
+		clock.Tick()
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(sink) != 16 {
+		t.Fatalf("expected 16 steps played, got %d", len(sink))
+	}
+}
+
+func TestRunLoopsStoppedClock(t *testing.T) {
+	p := &drum.Pattern{Tempo: 6000, Tracks: testPattern.Tracks}
+	s := New(p, NewFakeClock(), make(recordingSink, 16))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.RunLoops(ctx, 0) }()
+
+	// The clock never ticks, so the bar isn't waited for
+	s.Transport().Seek(0, 1)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for playback to stop")
+	}
+}
@@ -0,0 +1,201 @@
+package sequencer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+// Session is the state of a live set, saved so it can be resumed exactly
+// where it stopped.
+type Session struct {
+	// Pattern is the path of the played pattern
+	Pattern string `json:"pattern"`
+	// Scene of the pattern being played
+	Scene string `json:"scene,omitempty"`
+	// Muted are names of muted tracks
+	Muted []string `json:"muted,omitempty"`
+	// Tempo overrides the pattern's tempo, if positive
+	Tempo drum.BPM `json:"tempo,omitempty"`
+	// Speed scales the tempo, see Practice
+	Speed float64 `json:"speed,omitempty"`
+	// Outputs are settings of outputs of a Router by their names
+	Outputs map[string]SessionOutput `json:"outputs,omitempty"`
+
+	// Bar and Step of the transport's position, see Transport.Position
+	Bar  int `json:"bar"`
+	Step int `json:"step"`
+	// LoopStart and LoopEnd are steps of the loop, see Transport.SetLoop
+	LoopStart int `json:"loopStart"`
+	LoopEnd   int `json:"loopEnd"`
+}
+
+// SessionOutput is the mix of an output, see Route.
+type SessionOutput struct {
+	LatencyMs float64 `json:"latencyMs,omitempty"`
+	// Tracks are names of tracks routed to the output, all if empty
+	Tracks []string `json:"tracks,omitempty"`
+}
+
+// NewSession returns the session of the sequencer playing the pattern
+// at the path. Its tempo is left for the caller to set if it overrides
+// the pattern's one, so the session follows later edits of the pattern.
+func NewSession(path string, s *Sequencer) Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := Session{
+		Pattern:   path,
+		Scene:     s.pattern.Scene(),
+		Speed:     s.practice.Speed,
+		Bar:       s.position / barSteps,
+		Step:      s.position % barSteps,
+		LoopStart: s.loopStart(),
+		LoopEnd:   s.loopEnd(),
+	}
+	if s.switcher != nil {
+		session.Scene = s.switcher.scene
+	}
+
+	for _, track := range s.pattern.Tracks {
+		if track.Muted {
+			session.Muted = append(session.Muted, track.Name)
+		}
+	}
+
+	return session
+}
+
+// LoadSession reads a session from a JSON file.
+func LoadSession(path string) (Session, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Session{}, err
+	}
+
+	var session Session
+	err = json.Unmarshal(data, &session)
+	if err != nil {
+		return Session{}, fmt.Errorf("something went wrong reading session %s - %v", path, err)
+	}
+
+	return session, nil
+}
+
+// Save writes the session to a JSON file.
+func (session Session) Save(path string) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Apply sets the scene, mutes and tempo of the session on the pattern,
+// before it's played. It fails if muted tracks are missing.
+func (session Session) Apply(p *drum.Pattern) error {
+	if _, err := p.LoadTracks(); err != nil {
+		return err
+	}
+
+	muted := map[int]bool{}
+	for _, name := range session.Muted {
+		i, ok := trackByName(p, name)
+		if !ok {
+			return fmt.Errorf("no track %q to mute", name)
+		}
+		muted[i] = true
+	}
+
+	if session.Scene != "" {
+		p.SetScene(session.Scene)
+	}
+	for i := range p.Tracks {
+		p.Tracks[i].Muted = muted[i]
+	}
+	if session.Tempo > 0 {
+		p.SetBPM(session.Tempo)
+	}
+
+	return nil
+}
+
+// Restore sets the speed, loop and position of the session on the
+// sequencer, after its practice options are set. Positions outside of
+// the loop are ignored.
+func (session Session) Restore(s *Sequencer) error {
+	t := s.Transport()
+
+	err := t.SetLoop(session.LoopStart, session.LoopEnd)
+	if err != nil {
+		return err
+	}
+
+	from, to := t.Loop()
+	if position := session.Bar*barSteps + session.Step; position >= from && position < to {
+		err = t.Seek(session.Bar, session.Step)
+		if err != nil {
+			return err
+		}
+	}
+
+	if session.Speed > 0 {
+		s.mu.Lock()
+		s.practice.Speed = session.Speed
+		s.pushStepDuration()
+		s.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Routes returns routes of the sinks with latencies and tracks of the
+// session's outputs of the same names. Outputs without sinks are ignored.
+func (session Session) Routes(p *drum.Pattern, sinks map[string]Sink) ([]Route, error) {
+	if _, err := p.LoadTracks(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(sinks))
+	for name := range sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	routes := make([]Route, len(names))
+	for i, name := range names {
+		output := session.Outputs[name]
+		routes[i] = Route{
+			Sink:    sinks[name],
+			Latency: time.Duration(output.LatencyMs * float64(time.Millisecond)),
+		}
+
+		for _, track := range output.Tracks {
+			index, ok := trackByName(p, track)
+			if !ok {
+				return nil, fmt.Errorf("no track %q to route to %s", track, name)
+			}
+			routes[i].Tracks = append(routes[i].Tracks, index)
+		}
+	}
+
+	return routes, nil
+}
+
+// trackByName returns the index of the pattern's track with the name,
+// matched case-insensitively.
+func trackByName(p *drum.Pattern, name string) (int, bool) {
+	for i, track := range p.Tracks {
+		if strings.EqualFold(track.Name, name) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
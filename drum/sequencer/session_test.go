@@ -0,0 +1,106 @@
+package sequencer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestSession(t *testing.T) {
+	p := &drum.Pattern{
+		Tempo: 120,
+		Tracks: []drum.Track{
+			{Name: "kick", Steps: make([]byte, 32)},
+			{Name: "snare", Steps: make([]byte, 32), Muted: true},
+		},
+	}
+
+	s := New(p, NewFakeClock(), make(recordingSink, 16))
+	if err := s.SetPractice(Practice{Speed: 0.5, LoopStart: 16}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Transport().Seek(1, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	session := NewSession("beat.splice", s)
+	if session.Tempo != 0 {
+		t.Fatalf("expected no tempo override, got %v", session.Tempo)
+	}
+	session.Tempo = 120
+	session.Outputs = map[string]SessionOutput{"dmx": {LatencyMs: 25, Tracks: []string{"snare"}}}
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := session.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, session) {
+		t.Fatalf("expected loaded session %+v, got %+v", session, loaded)
+	}
+
+	expected := Session{
+		Pattern:   "beat.splice",
+		Scene:     drum.DefaultScene,
+		Muted:     []string{"snare"},
+		Tempo:     120,
+		Speed:     0.5,
+		Outputs:   session.Outputs,
+		Bar:       1,
+		Step:      3,
+		LoopStart: 16,
+		LoopEnd:   32,
+	}
+	if !reflect.DeepEqual(loaded, expected) {
+		t.Fatalf("expected session %+v, got %+v", expected, loaded)
+	}
+
+	// Resume the session with a fresh copy of the pattern
+	resumed := p.Clone()
+	resumed.Tempo = 90
+	resumed.Tracks[0].Muted = true
+	resumed.Tracks[1].Muted = false
+	if err := loaded.Apply(resumed); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Tempo != 120 || resumed.Tracks[0].Muted || !resumed.Tracks[1].Muted {
+		t.Fatalf("expected tempo and mutes of the session, got %+v", resumed)
+	}
+
+	r := New(resumed, NewFakeClock(), make(recordingSink, 16))
+	if err := loaded.Restore(r); err != nil {
+		t.Fatal(err)
+	}
+	if bar, step := r.Transport().Position(); bar != 1 || step != 3 {
+		t.Errorf("expected position at step 3 of bar 1, got step %d of bar %d", step, bar)
+	}
+	if from, to := r.Transport().Loop(); from != 16 || to != 32 {
+		t.Errorf("expected loop of steps 16-32, got %d-%d", from, to)
+	}
+	if d := r.stepDuration(0); d != 250*time.Millisecond {
+		t.Errorf("expected steps of 250ms at half speed, got %v", d)
+	}
+
+	sink := make(recordingSink, 1)
+	routes, err := loaded.Routes(resumed, map[string]Sink{"dmx": sink, "print": sink})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 2 || routes[0].Latency != 25*time.Millisecond || !reflect.DeepEqual(routes[0].Tracks, []int{1}) {
+		t.Errorf("expected the dmx route of the session, got %+v", routes)
+	}
+	if routes[1].Latency != 0 || routes[1].Tracks != nil {
+		t.Errorf("expected the print route to get all tracks at once, got %+v", routes[1])
+	}
+
+	loaded.Muted = []string{"clap"}
+	if err := loaded.Apply(resumed); err == nil {
+		t.Error("expected an error muting a missing track")
+	}
+}
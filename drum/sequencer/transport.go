@@ -0,0 +1,244 @@
+package sequencer
+
+import (
+	"fmt"
+	"time"
+)
+
+// barSteps is the number of steps in a bar of positions of the transport.
+const barSteps = 16
+
+// TransportState is whether the sequencer is playing.
+type TransportState int
+
+const (
+	// Stopped means the sequencer isn't running
+	Stopped TransportState = iota
+	// Playing means the sequencer is running and plays steps on ticks
+	Playing
+	// Paused means the sequencer is running, but ignores ticks
+	Paused
+)
+
+func (s TransportState) String() string {
+	switch s {
+	case Playing:
+		return "playing"
+	case Paused:
+		return "paused"
+	default:
+		return "stopped"
+	}
+}
+
+// TransportChange describes the transport after it started, stopped or
+// paused, was sought or its loop was set.
+type TransportChange struct {
+	State TransportState
+	// Bar and Step of the position, see Transport.Position
+	Bar, Step int
+	// LoopStart and LoopEnd are steps of the loop, see Transport.SetLoop
+	LoopStart, LoopEnd int
+}
+
+// Transport controls the playhead of a sequencer, like transport controls
+// of a DAW, for UIs and handlers of external sync.
+type Transport struct {
+	s *Sequencer
+}
+
+// Transport returns the transport of the sequencer.
+func (s *Sequencer) Transport() *Transport {
+	return &Transport{s: s}
+}
+
+// State returns whether the sequencer is playing.
+func (t *Transport) State() TransportState {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	return t.s.state()
+}
+
+// Pause makes the sequencer ignore ticks, keeping its position, until
+// Play is called. A sequencer paused before it runs starts paused.
+func (t *Transport) Pause() {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	if !t.s.paused {
+		t.s.paused = true
+		t.s.notify()
+	}
+}
+
+// Play resumes playback from the position after Pause.
+func (t *Transport) Play() {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	if t.s.paused {
+		t.s.paused = false
+		t.s.notify()
+	}
+}
+
+// Position returns the bar and step within the bar, counting from 0,
+// of the step played next. Bars are 16 steps long.
+func (t *Transport) Position() (bar, step int) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	return t.s.position / barSteps, t.s.position % barSteps
+}
+
+// Seek moves the playhead to the step of the bar, counting from 0, so it's
+// played next. Steps outside of the loop are clamped to it. It fails if
+// the pattern has no such step.
+func (t *Transport) Seek(bar, step int) error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	position := bar*barSteps + step
+	if bar < 0 || step < 0 || step >= barSteps || position >= len(t.s.steps) {
+		return fmt.Errorf("no step %d of bar %d in a pattern of %d steps", step+1, bar+1, len(t.s.steps))
+	}
+
+	t.s.position = t.s.clampToLoop(position)
+	t.s.notify()
+
+	return nil
+}
+
+// Locate moves the playhead to the step being played the duration after
+// the start of the pattern, following tempo changes and the practice speed.
+// Durations longer than the pattern wrap around, like its loops, and
+// steps outside of the loop are clamped to it.
+func (t *Transport) Locate(d time.Duration) error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	if d < 0 {
+		return fmt.Errorf("invalid time %v", d)
+	}
+
+	var length time.Duration
+	for i := range t.s.steps {
+		length += t.s.stepDuration(i)
+	}
+	if length <= 0 {
+		return fmt.Errorf("can't locate %v in a pattern of %d steps", d, len(t.s.steps))
+	}
+
+	d %= length
+	position := 0
+	for ; d >= t.s.stepDuration(position); position++ {
+		d -= t.s.stepDuration(position)
+	}
+
+	t.s.position = t.s.clampToLoop(position)
+	t.s.notify()
+
+	return nil
+}
+
+// clampToLoop returns the position moved into the loop.
+func (s *Sequencer) clampToLoop(position int) int {
+	if s.loopEnd() == 0 {
+		return 0
+	}
+
+	return min(max(position, s.loopStart()), s.loopEnd()-1)
+}
+
+// Loop returns steps of the loop, see SetLoop.
+func (t *Transport) Loop() (from, to int) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	return t.s.loopStart(), t.s.loopEnd()
+}
+
+// SetLoop loops playback over steps from up to, but not including, to,
+// like the section of Practice. Zero to means the end of the pattern.
+// The playhead moves to the start of the loop if it's outside of it.
+func (t *Transport) SetLoop(from, to int) error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	if to == 0 {
+		to = len(t.s.steps)
+	}
+	if from < 0 || from >= to || to > len(t.s.steps) {
+		return fmt.Errorf("invalid loop of steps %d-%d in a pattern of %d steps", from+1, to, len(t.s.steps))
+	}
+
+	t.s.practice.LoopStart = from
+	t.s.practice.LoopEnd = to
+	if t.s.position < from || t.s.position >= to {
+		t.s.position = from
+	}
+	t.s.notify()
+
+	return nil
+}
+
+// Notify makes the transport call f on every change. It's called
+// synchronously and mustn't call methods of the sequencer or transport.
+func (t *Transport) Notify(f func(change TransportChange)) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	t.s.listeners = append(t.s.listeners, f)
+}
+
+// length returns the number of steps of the pattern.
+func (t *Transport) length() int {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	return len(t.s.steps)
+}
+
+// setRunning sets whether the sequencer is running.
+func (s *Sequencer) setRunning(running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running = running
+	// Ticks of a new run aren't due after the last one
+	s.due = time.Time{}
+	if running {
+		s.debug("playback started", "position", s.position)
+	} else {
+		s.debug("playback stopped", "position", s.position)
+	}
+	s.notify()
+}
+
+// state returns the state of the transport.
+func (s *Sequencer) state() TransportState {
+	switch {
+	case !s.running:
+		return Stopped
+	case s.paused:
+		return Paused
+	default:
+		return Playing
+	}
+}
+
+// notify calls listeners of the transport with its current state.
+func (s *Sequencer) notify() {
+	change := TransportChange{
+		State:     s.state(),
+		Bar:       s.position / barSteps,
+		Step:      s.position % barSteps,
+		LoopStart: s.loopStart(),
+		LoopEnd:   s.loopEnd(),
+	}
+
+	for _, f := range s.listeners {
+		f(change)
+	}
+}
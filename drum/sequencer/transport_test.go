@@ -0,0 +1,155 @@
+package sequencer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestTransport(t *testing.T) {
+	p := &drum.Pattern{
+		Tempo:  120,
+		Tracks: []drum.Track{{Name: "kick", Steps: make([]byte, 32)}},
+	}
+	p.Tracks[0].Steps[17] = drum.StepOn
+
+	clock := NewFakeClock()
+	sink := make(recordingSink, 16)
+	s := New(p, clock, sink)
+	transport := s.Transport()
+
+	var changes []TransportChange
+	transport.Notify(func(change TransportChange) {
+		changes = append(changes, change)
+	})
+
+	if err := transport.Seek(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if bar, step := transport.Position(); bar != 1 || step != 1 {
+		t.Errorf("expected position at step 1 of bar 1, got step %d of bar %d", step, bar)
+	}
+	if err := transport.Seek(2, 0); err == nil {
+		t.Error("expected an error seeking past the pattern")
+	}
+
+	// Looping a section without the playhead moves it to the loop's start
+	if err := transport.SetLoop(16, 18); err != nil {
+		t.Fatal(err)
+	}
+	if bar, step := transport.Position(); bar != 1 || step != 1 {
+		t.Errorf("expected position kept at step 1 of bar 1, got step %d of bar %d", step, bar)
+	}
+	if err := transport.SetLoop(4, 8); err != nil {
+		t.Fatal(err)
+	}
+	if bar, step := transport.Position(); bar != 0 || step != 4 {
+		t.Errorf("expected position at step 4 of bar 0, got step %d of bar %d", step, bar)
+	}
+	if err := transport.SetLoop(16, 33); err == nil {
+		t.Error("expected an error looping past the pattern")
+	}
+
+	if err := transport.SetLoop(16, 18); err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.Seek(1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	// Steps 17, 16 and 17 of the loop
+	for i := 0; i < 3; i++ {
+		clock.Tick()
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	expectSteps(t, sink, 17, 17)
+
+	expected := []TransportChange{
+		{Stopped, 1, 1, 0, 32},
+		{Stopped, 1, 1, 16, 18},
+		{Stopped, 0, 4, 4, 8},
+		{Stopped, 1, 0, 16, 18},
+		{Stopped, 1, 1, 16, 18},
+		{Playing, 1, 1, 16, 18},
+		{Stopped, 1, 0, 16, 18},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected changes %v, got %v", expected, changes)
+	}
+	for i, change := range changes {
+		if change != expected[i] {
+			t.Errorf("expected change %d to be %+v, got %+v", i, expected[i], change)
+		}
+	}
+}
+
+func TestTransportSeekLoop(t *testing.T) {
+	p := &drum.Pattern{
+		Tempo:  120,
+		Tracks: []drum.Track{{Name: "kick", Steps: make([]byte, 32)}},
+	}
+	transport := New(p, NewFakeClock(), make(recordingSink, 16)).Transport()
+
+	var changes []TransportChange
+	transport.Notify(func(change TransportChange) {
+		changes = append(changes, change)
+	})
+
+	if err := transport.SetLoop(16, 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.Seek(0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.Seek(1, 8); err != nil {
+		t.Fatal(err)
+	}
+	// Half a second is the step 8 at 120 BPM
+	if err := transport.Locate(500 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TransportChange{
+		{Stopped, 1, 0, 16, 20},
+		{Stopped, 1, 0, 16, 20},
+		{Stopped, 1, 3, 16, 20},
+		{Stopped, 1, 0, 16, 20},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected changes %v, got %v", expected, changes)
+	}
+}
+
+func TestTransportPause(t *testing.T) {
+	clock := NewFakeClock()
+	sink := make(recordingSink, 16)
+	s := New(testPattern, clock, sink)
+	transport := s.Transport()
+
+	transport.Pause()
+	s.advance()
+	transport.Play()
+	s.advance()
+	expectSteps(t, sink, 0)
+
+	// Paused playback stops at once, without finishing the loop
+	transport.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.RunLoops(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink) != 0 {
+		t.Fatal("unexpected events while paused")
+	}
+}
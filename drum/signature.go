@@ -0,0 +1,90 @@
+package drum
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+)
+
+// signatureContext prefixes signed messages, so signatures of patterns
+// can't be mistaken for signatures of other data made with the same key.
+const signatureContext = "splice pattern signature v1\x00"
+
+var (
+	// ErrUnsigned is returned by Verify for patterns without a signature.
+	ErrUnsigned = errors.New("pattern isn't signed")
+	// ErrInvalidSignature is returned by Verify for patterns changed after
+	// they were signed.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrUnknownSigner is returned by Verify for patterns signed with
+	// a key other than the expected one.
+	ErrUnknownSigner = errors.New("pattern signed with another key")
+)
+
+// Signature is a detached signature proving authorship of a pattern,
+// stored in the metadata sidecar.
+type Signature struct {
+	// PublicKey is the key of the signer
+	PublicKey ed25519.PublicKey `json:"publicKey"`
+	Value     []byte            `json:"value"`
+}
+
+// Sign signs the pattern's encoded data and metadata with the key.
+// The signature is stored in the pattern's metadata, so it's written
+// to the sidecar by EncodeFile. Changing the pattern afterwards makes
+// the signature invalid.
+func Sign(p *Pattern, key ed25519.PrivateKey) error {
+	message, err := signedMessage(p)
+	if err != nil {
+		return err
+	}
+
+	p.Signature = &Signature{
+		PublicKey: key.Public().(ed25519.PublicKey),
+		Value:     ed25519.Sign(key, message),
+	}
+
+	return nil
+}
+
+// Verify returns nil if the pattern is signed with the private key
+// of pub and wasn't changed since.
+func Verify(p *Pattern, pub ed25519.PublicKey) error {
+	if p.Signature == nil {
+		return ErrUnsigned
+	}
+	if !bytes.Equal(p.Signature.PublicKey, pub) {
+		return ErrUnknownSigner
+	}
+
+	message, err := signedMessage(p)
+	if err != nil {
+		return err
+	}
+
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, message, p.Signature.Value) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// signedMessage returns data covered by the pattern's signature:
+// the encoded pattern followed by its metadata without the signature.
+func signedMessage(p *Pattern) ([]byte, error) {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	m := p.Metadata()
+	m.Signature = nil
+	metadata, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	message := append([]byte(signatureContext), data...)
+	return append(message, metadata...), nil
+}
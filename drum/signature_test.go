@@ -0,0 +1,57 @@
+package drum
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"path"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	pub := key.Public().(ed25519.PublicKey)
+
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(p, pub); err != ErrUnsigned {
+		t.Fatalf("expected %v, got %v", ErrUnsigned, err)
+	}
+
+	p.Tracks[1].Annotate(4, "ghost")
+	if err := Sign(p, key); err != nil {
+		t.Fatal(err)
+	}
+
+	file := path.Join(t.TempDir(), "signed.splice")
+	if err := EncodeFile(p, file); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := DecodeFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(signed, pub); err != nil {
+		t.Fatalf("signature of saved pattern not valid: %v", err)
+	}
+
+	other := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize))
+	if err := Verify(signed, other.Public().(ed25519.PublicKey)); err != ErrUnknownSigner {
+		t.Fatalf("expected %v, got %v", ErrUnknownSigner, err)
+	}
+
+	changed := signed.Clone()
+	changed.Tracks[0].Steps[1] = StepOn
+	if err := Verify(changed, pub); err != ErrInvalidSignature {
+		t.Fatalf("expected %v after changing steps, got %v", ErrInvalidSignature, err)
+	}
+
+	changed = signed.Clone()
+	changed.Tracks[1].Annotate(4, "forged")
+	if err := Verify(changed, pub); err != ErrInvalidSignature {
+		t.Fatalf("expected %v after changing notes, got %v", ErrInvalidSignature, err)
+	}
+}
@@ -0,0 +1,32 @@
+package drum
+
+import "fmt"
+
+// Slice returns a new pattern containing only steps from fromStep (inclusive)
+// to toStep (exclusive) of every track.
+func (p *Pattern) Slice(fromStep, toStep int) (*Pattern, error) {
+	if err := p.ensureTracks(); err != nil {
+		return nil, err
+	}
+
+	if fromStep < 0 || fromStep >= toStep {
+		return nil, fmt.Errorf("invalid step range %d-%d", fromStep, toStep)
+	}
+
+	slice := p.Clone()
+	slice.Unpack()
+
+	for i, track := range slice.Tracks {
+		if toStep > len(track.Steps) {
+			return nil, fmt.Errorf("step range %d-%d out of bounds for track %q with %d steps",
+				fromStep, toStep, track.Name, len(track.Steps))
+		}
+
+		steps := make([]byte, toStep-fromStep)
+		copy(steps, track.Steps[fromStep:toStep])
+
+		slice.Tracks[i].Steps = steps
+	}
+
+	return slice, nil
+}
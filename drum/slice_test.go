@@ -0,0 +1,37 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slice, err := decoded.Slice(4, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(0) kick	|x---|
+(1) snare	|x---|
+(2) clap	|x-x-|
+(3) hh-open	|--x-|
+(4) hh-close	|x---|
+(5) cowbell	|----|
+`
+	if slice.String() != expected {
+		t.Fatalf("wrong slice.\nGot:\n%s\nExpected:\n%s", slice, expected)
+	}
+
+	for _, r := range [][2]int{{-1, 4}, {4, 4}, {8, 4}, {0, 17}} {
+		if _, err := decoded.Slice(r[0], r[1]); err == nil {
+			t.Errorf("expected error for range %d-%d", r[0], r[1])
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Standard MIDI file message status bytes.
+const (
+	smfNoteOff = 0x80
+	smfNoteOn  = 0x90
+	smfControl = 0xb0
+	smfSysEx   = 0xf0
+	smfEscape  = 0xf7
+	smfMeta    = 0xff
+
+	smfEndOfTrack = 0x2f
+	smfTempo      = 0x51
+
+	// smfDrumChannel is the zero-based General MIDI percussion channel
+	smfDrumChannel = 9
+)
+
+// smfNote is a note on message of a standard MIDI file.
+type smfNote struct {
+	Tick     int
+	Note     byte
+	Velocity byte
+}
+
+// readSMF reads note on messages of all tracks of a standard MIDI file
+// and its resolution in ticks per quarter note.
+func readSMF(r io.Reader) ([]smfNote, int, error) {
+	var header struct {
+		ID       [4]byte
+		Length   uint32
+		Format   uint16
+		Tracks   uint16
+		Division uint16
+	}
+
+	br := bufio.NewReader(r)
+	err := binary.Read(br, binary.BigEndian, &header)
+	if err != nil {
+		return nil, 0, fmt.Errorf("something went wrong reading MIDI file header - %v", err)
+	}
+	if string(header.ID[:]) != "MThd" || header.Length < 6 {
+		return nil, 0, errors.New("not a standard MIDI file")
+	}
+	if header.Division&0x8000 != 0 || header.Division == 0 {
+		return nil, 0, errors.New("MIDI files with SMPTE time division aren't supported")
+	}
+
+	_, err = br.Discard(int(header.Length) - 6)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var notes []smfNote
+	for i := 0; i < int(header.Tracks); i++ {
+		var chunk struct {
+			ID     [4]byte
+			Length uint32
+		}
+		err = binary.Read(br, binary.BigEndian, &chunk)
+		if err != nil {
+			return nil, 0, fmt.Errorf("something went wrong reading MIDI track %d - %v", i, err)
+		}
+
+		data := make([]byte, chunk.Length)
+		_, err = io.ReadFull(br, data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("something went wrong reading MIDI track %d - %v", i, err)
+		}
+
+		if string(chunk.ID[:]) != "MTrk" {
+			continue
+		}
+
+		trackNotes, err := readSMFTrack(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid MIDI track %d - %v", i, err)
+		}
+		notes = append(notes, trackNotes...)
+	}
+
+	return notes, int(header.Division), nil
+}
+
+// readSMFTrack returns note on messages of a track chunk's data.
+func readSMFTrack(data []byte) ([]smfNote, error) {
+	var notes []smfNote
+	r := bytes.NewReader(data)
+	tick := 0
+	var status byte
+
+	for r.Len() > 0 {
+		delta, err := readVarInt(r)
+		if err != nil {
+			return nil, err
+		}
+		tick += delta
+
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case b == smfMeta:
+			kind, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			err = skipSMFData(r)
+			if err != nil || kind == smfEndOfTrack {
+				return notes, err
+			}
+		case b == smfSysEx || b == smfEscape:
+			err = skipSMFData(r)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			// Running status repeats the previous status byte
+			if b&0x80 != 0 {
+				status = b
+			} else {
+				if status == 0 {
+					return nil, errors.New("data byte without status")
+				}
+				r.UnreadByte()
+			}
+
+			data := make([]byte, smfDataLength(status))
+			_, err = io.ReadFull(r, data)
+			if err != nil {
+				return nil, err
+			}
+
+			if status&0xf0 == smfNoteOn && data[1] > 0 {
+				notes = append(notes, smfNote{Tick: tick, Note: data[0], Velocity: data[1]})
+			}
+		}
+	}
+
+	return notes, nil
+}
+
+// smfDataLength returns the number of data bytes of a channel message.
+func smfDataLength(status byte) int {
+	switch status & 0xf0 {
+	case 0xc0, 0xd0:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// skipSMFData skips data of a meta or system exclusive message,
+// prefixed with its length.
+func skipSMFData(r *bytes.Reader) error {
+	length, err := readVarInt(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Seek(int64(length), io.SeekCurrent)
+	return err
+}
+
+// readVarInt reads a variable-length quantity of at most 4 bytes.
+func readVarInt(r io.ByteReader) (int, error) {
+	value := 0
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		value = value<<7 | int(b&0x7f)
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+
+	return 0, errors.New("variable-length quantity too long")
+}
+
+// appendVarInt appends value as a variable-length quantity.
+func appendVarInt(data []byte, value int) []byte {
+	var buf [4]byte
+	i := len(buf) - 1
+	buf[i] = byte(value & 0x7f)
+
+	for value >>= 7; value > 0 && i > 0; value >>= 7 {
+		i--
+		buf[i] = byte(value&0x7f) | 0x80
+	}
+
+	return append(data, buf[i:]...)
+}
+
+// smfMessage is a message of a standard MIDI file at a tick.
+type smfMessage struct {
+	Tick int
+	Data []byte
+}
+
+// smfNoteMessages returns note on and off messages of notes played
+// on channel 10, each lasting length ticks.
+func smfNoteMessages(notes []smfNote, length int) []smfMessage {
+	messages := make([]smfMessage, 0, 2*len(notes))
+	for _, note := range notes {
+		messages = append(messages,
+			smfMessage{note.Tick, []byte{smfNoteOn | smfDrumChannel, note.Note, note.Velocity}},
+			smfMessage{note.Tick + length, []byte{smfNoteOff | smfDrumChannel, note.Note, 0}},
+		)
+	}
+
+	return messages
+}
+
+// writeSMF writes a single track standard MIDI file with the messages.
+// Messages of the same tick are written in the order they're given.
+func writeSMF(w io.Writer, messages []smfMessage, ticksPerQuarter int) error {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Tick < messages[j].Tick
+	})
+
+	var track []byte
+	tick := 0
+	for _, m := range messages {
+		track = appendVarInt(track, m.Tick-tick)
+		track = append(track, m.Data...)
+		tick = m.Tick
+	}
+	track = append(track, 0, smfMeta, smfEndOfTrack, 0)
+
+	var buf bytes.Buffer
+	buf.WriteString("MThd")
+	binary.Write(&buf, binary.BigEndian, []uint32{6})
+	binary.Write(&buf, binary.BigEndian, []uint16{0, 1, uint16(ticksPerQuarter)})
+	buf.WriteString("MTrk")
+	binary.Write(&buf, binary.BigEndian, uint32(len(track)))
+	buf.Write(track)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
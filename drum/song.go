@@ -0,0 +1,177 @@
+package drum
+
+import "math"
+
+// Song is an arrangement of patterns played one after another.
+type Song struct {
+	Patterns []*Pattern
+}
+
+// TransitionStyle decides how a transition swaps one groove for another.
+type TransitionStyle int
+
+const (
+	// TransitionMorph gradually swaps steps of every track, see Morph.
+	TransitionMorph TransitionStyle = iota
+	// TransitionSwap swaps whole tracks one by one, in order of the
+	// first pattern's tracks followed by ones only the second one has.
+	TransitionSwap
+)
+
+// Transition returns a song of the given number of bars gradually
+// moving from one pattern to another, e.g. for a DJ-style blend. The first
+// bar plays from and the last one plays to, with bars in between evenly
+// spread across the transition. A single bar plays to right away.
+// The tempo is interpolated linearly.
+func Transition(from, to *Pattern, bars int, style TransitionStyle) *Song {
+	from.ensureTracks()
+	to.ensureTracks()
+
+	song := &Song{}
+
+	for i := 0; i < bars; i++ {
+		t := 1.0
+		if bars > 1 {
+			t = float64(i) / float64(bars-1)
+		}
+
+		switch style {
+		case TransitionSwap:
+			song.Patterns = append(song.Patterns, swapTracks(from, to, t))
+		default:
+			song.Patterns = append(song.Patterns, Morph(from, to, t))
+		}
+	}
+
+	return song
+}
+
+// swapTracks returns a pattern with fraction t of tracks of a swapped
+// for tracks of b with the same name. Tracks missing in b are dropped
+// when they're swapped and tracks only b has are added.
+func swapTracks(a, b *Pattern, t float64) *Pattern {
+	var names []string
+	for _, track := range a.Tracks {
+		names = append(names, track.Name)
+	}
+	for _, track := range b.Tracks {
+		if _, ok := findTrack(a, track.Name); !ok {
+			names = append(names, track.Name)
+		}
+	}
+
+	swapped := int(math.Floor(float64(len(names))*t + 0.5))
+
+	version := a.Version
+	if t >= 0.5 {
+		version = b.Version
+	}
+
+	p := &Pattern{
+		Version: version,
+		Tempo:   a.Tempo + (b.Tempo-a.Tempo)*float32(t),
+	}
+
+	for i, name := range names {
+		source := a
+		if i < swapped {
+			source = b
+		}
+
+		if track, ok := findTrack(source, name); ok {
+			track.Steps = append([]byte(nil), track.Steps...)
+			p.Tracks = append(p.Tracks, track)
+		}
+	}
+
+	return p
+}
+
+// Flatten returns the song as a single pattern, with patterns' steps
+// concatenated. Tracks are matched by name and silent where a pattern
+// doesn't have them. Track offsets are applied. Patterns' tempos are
+// kept as tempo changes.
+func (s *Song) Flatten() *Pattern {
+	flat := &Pattern{}
+	if len(s.Patterns) == 0 {
+		return flat
+	}
+
+	flat.Version = s.Patterns[0].Version
+	flat.Tempo = s.Patterns[0].Tempo
+
+	var length int
+	for _, p := range s.Patterns {
+		steps := 0
+		for _, track := range p.Tracks {
+			steps = max(steps, track.Len())
+		}
+
+		if length > 0 {
+			flat.SetTempoChange(length, p.Tempo)
+		}
+		for _, change := range p.TempoChanges {
+			flat.SetTempoChange(length+change.Step, change.Tempo)
+		}
+
+		for _, track := range p.Tracks {
+			i := flat.trackIndex(track.Name)
+			if i < 0 {
+				flat.Tracks = append(flat.Tracks, Track{
+					ID:      track.ID,
+					Name:    track.Name,
+					Display: track.Display,
+				})
+				i = len(flat.Tracks) - 1
+			}
+
+			shifted := track.ShiftedSteps()
+			steps := make([]byte, length+len(shifted))
+			copy(steps, flat.Tracks[i].Steps)
+			copy(steps[length:], shifted)
+			flat.Tracks[i].Steps = steps
+		}
+
+		length += steps
+	}
+
+	// Pad tracks missing at the end
+	for i := range flat.Tracks {
+		steps := make([]byte, length)
+		copy(steps, flat.Tracks[i].Steps)
+		flat.Tracks[i].Steps = steps
+	}
+
+	flat.dropRedundantTempoChanges()
+
+	return flat
+}
+
+// trackIndex returns index of the first track with the given name, or -1.
+func (p *Pattern) trackIndex(name string) int {
+	for i, track := range p.Tracks {
+		if track.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// dropRedundantTempoChanges removes tempo changes not changing the tempo.
+func (p *Pattern) dropRedundantTempoChanges() {
+	changes := p.TempoChanges[:0]
+	tempo := p.Tempo
+
+	for _, change := range p.TempoChanges {
+		if change.Tempo != tempo {
+			changes = append(changes, change)
+			tempo = change.Tempo
+		}
+	}
+
+	if len(changes) == 0 {
+		changes = nil
+	}
+	p.TempoChanges = changes
+}
@@ -0,0 +1,74 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestTransition(t *testing.T) {
+	a, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := DecodeFile(path.Join("fixtures", tData[1].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, style := range []TransitionStyle{TransitionMorph, TransitionSwap} {
+		song := Transition(a, b, 4, style)
+		if len(song.Patterns) != 4 {
+			t.Fatalf("expected 4 bars, got %d", len(song.Patterns))
+		}
+		if out := song.Patterns[0].String(); out != a.String() {
+			t.Errorf("first bar isn't equal to from:\n%s", out)
+		}
+		if out := song.Patterns[3].String(); out != b.String() {
+			t.Errorf("last bar isn't equal to to:\n%s", out)
+		}
+	}
+
+	// Kick, snare and clap are swapped, clap isn't in b
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 109.2
+(0) kick	|x---|----|x---|----|
+(1) snare	|----|x---|----|x---|
+(3) hh-open	|--x-|--x-|x-x-|--x-|
+(4) hh-close	|x---|x---|----|x--x|
+(5) cowbell	|----|----|--x-|----|
+`
+	if out := Transition(a, b, 3, TransitionSwap).Patterns[1].String(); out != expected {
+		t.Fatalf("wrong swap.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+}
+
+func TestSongFlatten(t *testing.T) {
+	a := &Pattern{Version: "a", Tempo: 120, Tracks: []Track{
+		{ID: 0, Name: "kick", Steps: []byte{1, 0}},
+		{ID: 1, Name: "snare", Steps: []byte{0, 1}},
+	}}
+	b := &Pattern{Version: "b", Tempo: 100, Tracks: []Track{
+		{ID: 2, Name: "hh", Steps: []byte{1, 1, 1}},
+		{ID: 0, Name: "kick", Steps: []byte{1, 0, 1}},
+	}}
+
+	flat := (&Song{Patterns: []*Pattern{a, b, a}}).Flatten()
+
+	expected := map[string][]byte{
+		"kick":  {1, 0, 1, 0, 1, 1, 0},
+		"snare": {0, 1, 0, 0, 0, 0, 1},
+		"hh":    {0, 0, 1, 1, 1, 0, 0},
+	}
+	if len(flat.Tracks) != len(expected) {
+		t.Fatalf("unexpected tracks %+v", flat.Tracks)
+	}
+	for _, track := range flat.Tracks {
+		if string(track.Steps) != string(expected[track.Name]) {
+			t.Errorf("track %s has steps %v, expected %v", track.Name, track.Steps, expected[track.Name])
+		}
+	}
+
+	if flat.Tempo != 120 || flat.TempoAt(2) != 100 || flat.TempoAt(5) != 120 || len(flat.TempoChanges) != 2 {
+		t.Errorf("unexpected tempo changes %+v", flat.TempoChanges)
+	}
+}
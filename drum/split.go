@@ -0,0 +1,94 @@
+package drum
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SplitTrack distributes steps of the track into new tracks named by keys
+// of partitions, each getting the steps at its listed indexes. Steps not
+// listed in any partition are dropped, indexes out of the track's range are
+// ignored. Tracks are returned in order of their names, with IDs counting
+// up from the split track's ID. Use Pattern.SplitTrack to split a track
+// within a pattern, keeping IDs unique.
+func SplitTrack(t Track, partitions map[string][]int) []Track {
+	t.Unpack()
+
+	names := make([]string, 0, len(partitions))
+	for name := range partitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tracks := make([]Track, len(names))
+
+	for i, name := range names {
+		track := t
+		track.ID = t.ID + byte(i)
+		track.Name = name
+		track.Steps = make([]byte, len(t.Steps))
+
+		for _, step := range partitions[name] {
+			if step >= 0 && step < len(t.Steps) {
+				track.Steps[step] = t.Steps[step]
+			}
+		}
+
+		tracks[i] = track
+	}
+
+	return tracks
+}
+
+// SplitTrack returns a new pattern with the track at the given index
+// replaced by tracks split from it by SplitTrack. The first new track keeps
+// the split track's ID, others get the lowest IDs unused in the pattern.
+func (p *Pattern) SplitTrack(index int, partitions map[string][]int) (*Pattern, error) {
+	if err := p.ensureTracks(); err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(p.Tracks) {
+		return nil, fmt.Errorf("no track at index %d", index)
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("no partitions to split track %q into", p.Tracks[index].Name)
+	}
+
+	for name, steps := range partitions {
+		for _, step := range steps {
+			if step < 0 || step >= p.Tracks[index].Len() {
+				return nil, fmt.Errorf("step %d of partition %q out of bounds for track %q with %d steps",
+					step, name, p.Tracks[index].Name, p.Tracks[index].Len())
+			}
+		}
+	}
+
+	split := p.Clone()
+	tracks := SplitTrack(split.Tracks[index], partitions)
+
+	used := map[byte]bool{}
+	for i, track := range split.Tracks {
+		if i != index {
+			used[track.ID] = true
+		}
+	}
+	used[tracks[0].ID] = true
+
+	next := 0
+	for i := 1; i < len(tracks); i++ {
+		for next < 256 && used[byte(next)] {
+			next++
+		}
+		if next == 256 {
+			return nil, fmt.Errorf("no unused track IDs left for partition %q", tracks[i].Name)
+		}
+
+		tracks[i].ID = byte(next)
+		used[byte(next)] = true
+	}
+
+	split.Tracks = append(split.Tracks[:index], append(tracks, split.Tracks[index+1:]...)...)
+
+	return split, nil
+}
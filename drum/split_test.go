@@ -0,0 +1,65 @@
+package drum
+
+import (
+	"fmt"
+	"path"
+	"testing"
+)
+
+func TestSplitTrack(t *testing.T) {
+	track := Track{ID: 7, Name: "acc", Steps: []byte{1, 0, 1, 0, 2, 0, 1, 1}}
+
+	tracks := SplitTrack(track, map[string][]int{
+		"acc-lo": {0, 1, 2, 3, 42},
+		"acc-hi": {4, 5, 6, 7},
+	})
+
+	expected := "[{acc-hi 7 [0 0 0 0 2 0 1 1]} {acc-lo 8 [1 0 1 0 0 0 0 0]}]"
+	var got []string
+	for _, tr := range tracks {
+		got = append(got, fmt.Sprintf("{%s %d %v}", tr.Name, tr.ID, tr.Steps))
+	}
+	if fmt.Sprint(got) != expected {
+		t.Fatalf("unexpected split tracks %v, expected %s", got, expected)
+	}
+}
+
+func TestPatternSplitTrack(t *testing.T) {
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Split hh-close (ID 4) by halves; IDs 0-5 are taken
+	split, err := decoded.SplitTrack(4, map[string][]int{
+		"hh-a": {0, 1, 2, 3, 4, 5, 6, 7},
+		"hh-b": {8, 9, 10, 11, 12, 13, 14, 15},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(0) kick	|x---|x---|x---|x---|
+(1) snare	|----|x---|----|x---|
+(2) clap	|----|x-x-|----|----|
+(3) hh-open	|--x-|--x-|x-x-|--x-|
+(4) hh-a	|x---|x---|----|----|
+(6) hh-b	|----|----|----|x--x|
+(5) cowbell	|----|----|--x-|----|
+`
+	if fmt.Sprint(split) != expected {
+		t.Fatalf("track wasn't split as expected.\nGot:\n%s\nExpected:\n%s", split, expected)
+	}
+	if fmt.Sprint(decoded) != tData[0].output {
+		t.Fatal("SplitTrack modified the original pattern")
+	}
+
+	if _, err := decoded.SplitTrack(6, map[string][]int{"a": {0}}); err == nil {
+		t.Error("expected an error for missing track")
+	}
+	if _, err := decoded.SplitTrack(0, map[string][]int{"a": {16}}); err == nil {
+		t.Error("expected an error for step out of bounds")
+	}
+}
@@ -0,0 +1,76 @@
+package drum
+
+import "bytes"
+
+// Values of steps stored in Track.Steps.
+const (
+	StepOff byte = 0
+	StepOn  byte = 1
+	// StepFlam is a hit preceded by a quick grace note. The .splice format
+	// can't represent flams, so they're encoded as regular hits and listed
+	// in the metadata sidecar.
+	StepFlam byte = 2
+)
+
+// isHit returns true if the step is played.
+func isHit(step byte) bool {
+	return step == StepOn || step == StepFlam
+}
+
+// decodedSteps returns steps of raw step bytes of a .splice file. Only
+// StepOff and StepOn are valid raw steps, others are decoded as StepOff,
+// so they're never mistaken for flams.
+func decodedSteps(raw []byte) []byte {
+	for i, step := range raw {
+		if step > StepOn {
+			raw[i] = StepOff
+		}
+	}
+
+	return raw
+}
+
+// encodedSteps returns raw step bytes of the track to encode. Raw steps
+// of a decoded track with invalid ones are written back unchanged, as long
+// as the track's steps weren't changed since.
+func (t Track) encodedSteps() []byte {
+	if t.rawSteps != nil && bytes.Equal(t.Steps, decodedSteps(append([]byte(nil), t.rawSteps...))) {
+		raw := t
+		raw.Steps = t.rawSteps
+		return raw.ShiftedSteps()
+	}
+
+	steps := t.ShiftedSteps()
+	for i, step := range steps {
+		if step == StepFlam {
+			steps[i] = StepOn
+		}
+	}
+
+	return steps
+}
+
+// flamSteps returns indexes of flams of the track as it's encoded,
+// with its offset applied.
+func (t Track) flamSteps() []int {
+	var flams []int
+	for i, step := range t.ShiftedSteps() {
+		if step == StepFlam {
+			flams = append(flams, i)
+		}
+	}
+
+	return flams
+}
+
+// countHits returns the number of played steps.
+func countHits(steps []byte) int {
+	hits := 0
+	for _, step := range steps {
+		if isHit(step) {
+			hits++
+		}
+	}
+
+	return hits
+}
@@ -0,0 +1,105 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestFlams(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 1, Name: "snare", Steps: []byte{StepOff, StepFlam, StepOn, StepOff}},
+		},
+	}
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(1) snare	|-fx-|
+`
+	if p.String() != expected {
+		t.Fatalf("wrong flam notation.\nGot:\n%s\nExpected:\n%s", p, expected)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportCSV(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ImportCSV(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.String() != expected {
+		t.Fatalf("flams didn't survive CSV round trip:\n%s", imported)
+	}
+
+	// The binary format has no flams, so they're encoded as hits
+	encoded, _, err := EncodeBytes(p, WithEncodedStepWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(bytes.NewReader(encoded), WithStepWidth(4), WithStrict(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Tracks[0].Steps, []byte{StepOff, StepOn, StepOn, StepOff}) {
+		t.Fatalf("flams weren't encoded as hits: %v", decoded.Tracks[0].Steps)
+	}
+
+	// Flams are kept in the sidecar
+	file := path.Join(t.TempDir(), "flams.splice")
+	if err := EncodeFile(p, file, WithEncodedStepWidth(4)); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := DecodeFile(file, WithStepWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.String() != expected {
+		t.Fatalf("flams didn't survive the sidecar round trip:\n%s", reloaded)
+	}
+}
+
+func TestInvalidRawSteps(t *testing.T) {
+	p := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []Track{{ID: 1, Name: "snare", Steps: []byte{1, 1, 0, 0}}}}
+	encoded, _, err := EncodeBytes(p, WithEncodedStepWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Raw steps other than 0 and 1 aren't flams, but are written back
+	raw := append([]byte(nil), encoded...)
+	copy(raw[len(raw)-4:], []byte{2, 1, 7, 0})
+
+	decoded, err := Decode(bytes.NewReader(raw), WithStepWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Tracks[0].Steps, []byte{StepOff, StepOn, StepOff, StepOff}) {
+		t.Fatalf("expected invalid raw steps decoded as rests, got %v", decoded.Tracks[0].Steps)
+	}
+	if _, err := Decode(bytes.NewReader(raw), WithStepWidth(4), WithStrict(true)); err == nil {
+		t.Fatal("expected invalid raw steps to fail strict decoding")
+	}
+
+	reencoded, err := decoded.Clone().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reencoded, raw) {
+		t.Fatalf("raw steps weren't written back.\nGot:\n%x\nExpected:\n%x", reencoded, raw)
+	}
+
+	// Changed steps are written as they are
+	decoded.Tracks[0].Steps[3] = StepOn
+	reencoded, err = decoded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(reencoded[len(reencoded)-4:], []byte{0, 1, 0, 1}) {
+		t.Fatalf("expected changed steps written as they are, got %v", reencoded[len(reencoded)-4:])
+	}
+}
@@ -0,0 +1,66 @@
+package drum
+
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
+// ExportSVG writes the pattern's grid as an SVG image, with a row per
+// track, styled with the theme set by WithTheme.
+func ExportSVG(w io.Writer, p *Pattern, opts ...ExportOption) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	config := newExportConfig(opts)
+	theme := config.theme
+
+	cell := theme.CellSize
+	if cell < 1 {
+		cell = DefaultTheme.CellSize
+	}
+
+	steps, labelWidth := 0, 0
+	for _, track := range p.Tracks {
+		steps = max(steps, track.Len())
+		labelWidth = max(labelWidth, len(fmt.Sprintf("(%d) %s", track.ID, track.Name)))
+	}
+
+	// Labels are roughly 0.6 of the cell size wide per character
+	left := labelWidth*cell*6/10 + cell/2
+	width, height := left+steps*cell+1, len(p.Tracks)*cell+1
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="%d">`+"\n",
+		width, height, cell*6/10)
+	fmt.Fprintf(w, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", html.EscapeString(theme.Background))
+
+	for i, track := range p.Tracks {
+		y := i * cell
+		fmt.Fprintf(w, `<text x="0" y="%d" fill="%s">%s</text>`+"\n", y+cell*3/4,
+			html.EscapeString(theme.Foreground), html.EscapeString(fmt.Sprintf("(%d) %s", track.ID, track.Name)))
+
+		for s, step := range track.ShiftedSteps() {
+			fill := "none"
+			switch {
+			case step == StepFlam:
+				fill = theme.FlamColor
+			case isHit(step) && track.Display.Color != "":
+				fill = track.Display.Color
+			case isHit(step):
+				fill = theme.OnColor
+			}
+
+			fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s"/>`+"\n",
+				left+s*cell, y, cell, cell, html.EscapeString(fill), html.EscapeString(theme.Grid))
+		}
+	}
+
+	for s := 0; s <= steps; s += beatSteps {
+		fmt.Fprintf(w, `<line x1="%d" y1="0" x2="%d" y2="%d" stroke="%s" stroke-width="2"/>`+"\n",
+			left+s*cell, left+s*cell, height, html.EscapeString(theme.Beat))
+	}
+
+	_, err := io.WriteString(w, "</svg>\n")
+	return err
+}
@@ -0,0 +1,122 @@
+package drum
+
+import (
+	"math"
+	"math/bits"
+)
+
+// metricalWeight returns the Longuet-Higgins and Lee metrical weight of
+// the step within a 4/4 bar of sixteenth notes: 0 for the downbeat, -1 for
+// the half bar, -2 for beats, -3 for eighth notes and -4 for sixteenths.
+func metricalWeight(step int) int {
+	step %= barSteps
+	if step == 0 {
+		return 0
+	}
+
+	return bits.TrailingZeros(uint(step)) - bits.TrailingZeros(uint(barSteps))
+}
+
+// Syncopation returns the Longuet-Higgins and Lee syncopation index of the
+// track, with its offset applied. Each hit followed by silence on a step of
+// a higher metrical weight than its own, before the next hit, adds the
+// difference of weights. The track is looped, so silence at its end is
+// followed by its beginning.
+func (t Track) Syncopation() int {
+	steps := t.ShiftedSteps()
+
+	var onsets []int
+	for i, step := range steps {
+		if isHit(step) {
+			onsets = append(onsets, i)
+		}
+	}
+
+	syncopation := 0
+	for i, onset := range onsets {
+		next := onsets[(i+1)%len(onsets)]
+		if next <= onset {
+			next += len(steps)
+		}
+
+		if next == onset+1 {
+			continue
+		}
+
+		rest := math.MinInt
+		for s := onset + 1; s < next; s++ {
+			rest = max(rest, metricalWeight(s%len(steps)))
+		}
+
+		if weight := metricalWeight(onset); rest > weight {
+			syncopation += rest - weight
+		}
+	}
+
+	return syncopation
+}
+
+// Complexity returns the Shannon entropy in bits of intervals between
+// consecutive hits of the looped track, with its offset applied. Evenly
+// spaced hits have no complexity, irregular ones have more.
+func (t Track) Complexity() float64 {
+	steps := t.ShiftedSteps()
+
+	var onsets []int
+	for i, step := range steps {
+		if isHit(step) {
+			onsets = append(onsets, i)
+		}
+	}
+
+	counts := map[int]int{}
+	for i, onset := range onsets {
+		next := onsets[(i+1)%len(onsets)]
+		if next <= onset {
+			next += len(steps)
+		}
+		counts[next-onset]++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(len(onsets))
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// Syncopation returns the mean syncopation index of tracks with hits.
+func (p *Pattern) Syncopation() float64 {
+	p.ensureTracks()
+
+	return p.meanOfPlayed(func(t Track) float64 {
+		return float64(t.Syncopation())
+	})
+}
+
+// Complexity returns the mean complexity of tracks with hits.
+func (p *Pattern) Complexity() float64 {
+	p.ensureTracks()
+
+	return p.meanOfPlayed(Track.Complexity)
+}
+
+// meanOfPlayed returns the mean of f over tracks with hits,
+// or 0 if there are none.
+func (p *Pattern) meanOfPlayed(f func(Track) float64) float64 {
+	sum, played := 0.0, 0
+	for _, track := range p.Tracks {
+		if countHits(track.Unpacked()) > 0 {
+			sum += f(track)
+			played++
+		}
+	}
+
+	if played == 0 {
+		return 0
+	}
+
+	return sum / float64(played)
+}
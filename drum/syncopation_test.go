@@ -0,0 +1,40 @@
+package drum
+
+import "testing"
+
+func TestSyncopation(t *testing.T) {
+	for _, tt := range []struct {
+		steps       string
+		syncopation int
+		complexity  float64
+	}{
+		{"x---x---x---x---", 0, 0},
+		{"----------------", 0, 0},
+		// Eighth notes followed by silence on the half bar
+		// and the downbeat of the next bar
+		{"--x-------x-----", 2 + 3, 0},
+		// A sixteenth note followed by silence on the half bar
+		{"x------x--------", 0 + 3, 1},
+		{"xxxxxxxxxxxxxxxx", 0, 0},
+	} {
+		track := Track{Steps: stepsFromString(tt.steps)}
+
+		if s := track.Syncopation(); s != tt.syncopation {
+			t.Errorf("syncopation of %s is %d, expected %d", tt.steps, s, tt.syncopation)
+		}
+		if c := track.Complexity(); c != tt.complexity {
+			t.Errorf("complexity of %s is %v, expected %v", tt.steps, c, tt.complexity)
+		}
+	}
+}
+
+func stepsFromString(s string) []byte {
+	steps := make([]byte, len(s))
+	for i, c := range s {
+		if c == 'x' {
+			steps[i] = StepOn
+		}
+	}
+
+	return steps
+}
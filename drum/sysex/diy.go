@@ -0,0 +1,24 @@
+package sysex
+
+import "github.com/m110/go-challenge-1/drum"
+
+// encodeDIY encodes the pattern for DIY drum machines, e.g. built on
+// microcontrollers, in a single message:
+//
+//	F0 7D <pattern in .splice format, packed by Pack7> F7
+//
+// It uses the non-commercial manufacturer ID, so it's not meant for
+// commercial hardware.
+func encodeDIY(p *drum.Pattern) ([][]byte, error) {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := Message([]byte{NonCommercialID}, Pack7(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return [][]byte{msg}, nil
+}
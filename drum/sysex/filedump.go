@@ -0,0 +1,116 @@
+package sysex
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+const (
+	// universalNonRealtime is the ID of universal non-real time messages.
+	universalNonRealtime = 0x7e
+	// allCall addresses all devices.
+	allCall = 0x7f
+
+	// Sub-IDs of MIDI File Dump messages
+	fileDump       = 0x07
+	fileDumpHeader = 0x01
+	fileDumpData   = 0x02
+	endOfFile      = 0x7b
+
+	// fileDumpPacket is the number of bytes of the file in a data packet,
+	// encoded as 112 7-bit bytes.
+	fileDumpPacket = 98
+	// fileDumpName is the name the file is sent with.
+	fileDumpName = "PATTERN.MID"
+)
+
+// encodeFileDump encodes the pattern as a Standard MIDI File, see
+// drum.ExportMIDI, sent with the MIDI File Dump of the MIDI 1.0
+// specification to sequencers and drum machines loading MIDI files:
+//
+//	F0 7E 7F 07 01 00 "MIDI" <length> <name> F7              header
+//	F0 7E 7F 07 02 <packet> <count> <data> <checksum> F7     data packets
+//	F0 7E 7F 7B <packet> F7                                  end of file
+//
+// Length is the file's length in four 7-bit bytes, least significant
+// first. Packets are numbered from 0, wrapping at 128, and carry up to
+// 98 bytes of the file in groups of 7 bytes preceded by a byte of their
+// most significant bits, the first byte's in bit 6. Count is the number
+// of data bytes less 1 and the checksum is the XOR of all bytes from 7E
+// up to it. Messages are sent without waiting for handshakes.
+func encodeFileDump(p *drum.Pattern) ([][]byte, error) {
+	var file bytes.Buffer
+	if err := drum.ExportMIDI(&file, p); err != nil {
+		return nil, err
+	}
+	data := file.Bytes()
+	if len(data) >= 1<<28 {
+		return nil, fmt.Errorf("MIDI file of %d bytes is too long", len(data))
+	}
+
+	header := []byte{universalNonRealtime, allCall, fileDump, fileDumpHeader, 0x00}
+	header = append(header, "MIDI"...)
+	for i := 0; i < 4; i++ {
+		header = append(header, byte(len(data)>>(7*i)&0x7f))
+	}
+	header = append(header, fileDumpName...)
+
+	msg, err := Message(nil, header)
+	if err != nil {
+		return nil, err
+	}
+	messages := [][]byte{msg}
+
+	packet := 0
+	for ; len(data) > 0; packet++ {
+		n := min(fileDumpPacket, len(data))
+		encoded := packFileDump(data[:n])
+		data = data[n:]
+
+		body := []byte{universalNonRealtime, allCall, fileDump, fileDumpData, byte(packet % 128), byte(len(encoded) - 1)}
+		body = append(body, encoded...)
+
+		var checksum byte
+		for _, b := range body {
+			checksum ^= b
+		}
+
+		msg, err := Message(nil, append(body, checksum&0x7f))
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	msg, err = Message(nil, []byte{universalNonRealtime, allCall, endOfFile, byte(packet % 128)})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(messages, msg), nil
+}
+
+// packFileDump encodes 8-bit data as 7-bit bytes the way of the MIDI File
+// Dump. Unlike Pack7, the first byte's most significant bit is in bit 6 of
+// its group's first byte.
+func packFileDump(data []byte) []byte {
+	packed := make([]byte, 0, len(data)+(len(data)+6)/7)
+
+	for i := 0; i < len(data); i += 7 {
+		group := data[i:min(i+7, len(data))]
+
+		var msbs byte
+		for j, b := range group {
+			msbs |= (b >> 7) << (6 - j)
+		}
+
+		packed = append(packed, msbs)
+		for _, b := range group {
+			packed = append(packed, b&0x7f)
+		}
+	}
+
+	return packed
+}
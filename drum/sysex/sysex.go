@@ -0,0 +1,161 @@
+// Package sysex encodes drum patterns as MIDI system exclusive messages
+// for transferring them to hardware drum machines.
+//
+// Each device has its own encoder, registered under the device's name.
+// Encoders for other devices can be added with Register. Encoders of
+// devices with limits implement Profiler, so patterns are checked to fit
+// before they're sent.
+//
+// There's no encoder of Elektron pattern dumps, with or without parameter
+// locks: their formats are undocumented and differ per device and
+// firmware, so a guessed layout would be refused or misread by the
+// hardware. Automation lanes reach such devices as control changes of
+// an exported MIDI file, see drum.ExportMIDI and the "filedump" encoder.
+package sysex
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+const (
+	// Start and end of every system exclusive message
+	start = 0xf0
+	end   = 0xf7
+
+	// NonCommercialID is the manufacturer ID reserved for non-commercial
+	// and educational use, used by the DIY encoder.
+	NonCommercialID = 0x7d
+)
+
+// Encoder turns a pattern into system exclusive messages for a device.
+type Encoder interface {
+	Encode(p *drum.Pattern) ([][]byte, error)
+}
+
+// Profiler is implemented by encoders of devices with limits.
+type Profiler interface {
+	// Profile returns constraints patterns must fit, see
+	// drum.CheckDeviceProfile.
+	Profile() drum.DeviceProfile
+}
+
+// EncoderFunc is a function used as an Encoder.
+type EncoderFunc func(p *drum.Pattern) ([][]byte, error)
+
+// Encode calls f(p).
+func (f EncoderFunc) Encode(p *drum.Pattern) ([][]byte, error) {
+	return f(p)
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{}
+)
+
+func init() {
+	// Track IDs are single bytes of the .splice format
+	Register("diy", profiledEncoder{EncoderFunc(encodeDIY), drum.DeviceProfile{Name: "DIY", MaxTracks: 256}})
+	Register("filedump", EncoderFunc(encodeFileDump))
+}
+
+// profiledEncoder is an Encoder with the profile of its device.
+type profiledEncoder struct {
+	Encoder
+	profile drum.DeviceProfile
+}
+
+func (e profiledEncoder) Profile() drum.DeviceProfile {
+	return e.profile
+}
+
+// Register makes an encoder available for the named device.
+// It panics if the name is already registered.
+func Register(device string, e Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	if _, ok := encoders[device]; ok {
+		panic(fmt.Sprintf("sysex: encoder %q registered twice", device))
+	}
+	encoders[device] = e
+}
+
+// Lookup returns the encoder registered for the named device.
+func Lookup(device string) (Encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	e, ok := encoders[device]
+	return e, ok
+}
+
+// Devices returns sorted names of devices with registered encoders.
+func Devices() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Message returns a system exclusive message with the manufacturer ID
+// and data, which must be 7-bit already.
+func Message(manufacturer []byte, data []byte) ([]byte, error) {
+	for i, b := range append(append([]byte(nil), manufacturer...), data...) {
+		if b > 0x7f {
+			return nil, fmt.Errorf("byte %d of message (0x%02x) isn't 7-bit", i, b)
+		}
+	}
+
+	msg := []byte{start}
+	msg = append(msg, manufacturer...)
+	msg = append(msg, data...)
+
+	return append(msg, end), nil
+}
+
+// Pack7 packs 8-bit data into 7-bit bytes. Every group of up to 7 bytes
+// is preceded by a byte holding their most significant bits, the first
+// byte's in bit 0.
+func Pack7(data []byte) []byte {
+	packed := make([]byte, 0, len(data)+(len(data)+6)/7)
+
+	for i := 0; i < len(data); i += 7 {
+		group := data[i:min(i+7, len(data))]
+
+		var msbs byte
+		for j, b := range group {
+			msbs |= (b >> 7) << j
+		}
+
+		packed = append(packed, msbs)
+		for _, b := range group {
+			packed = append(packed, b&0x7f)
+		}
+	}
+
+	return packed
+}
+
+// Unpack7 reverses Pack7.
+func Unpack7(packed []byte) []byte {
+	data := make([]byte, 0, len(packed))
+
+	for i := 0; i < len(packed); i += 8 {
+		msbs := packed[i]
+		for j, b := range packed[i+1 : min(i+8, len(packed))] {
+			data = append(data, b|((msbs>>j)&1)<<7)
+		}
+	}
+
+	return data
+}
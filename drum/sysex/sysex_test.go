@@ -0,0 +1,147 @@
+package sysex
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/m110/go-challenge-1/drum"
+)
+
+func TestPack7(t *testing.T) {
+	data := []byte{0x01, 0x80, 0xff, 0x7f, 0x00, 0x81, 0x02, 0x90, 0x03}
+
+	packed := Pack7(data)
+
+	expected := []byte{0x26, 0x01, 0x00, 0x7f, 0x7f, 0x00, 0x01, 0x02, 0x01, 0x10, 0x03}
+	if !bytes.Equal(packed, expected) {
+		t.Fatalf("got % x, expected % x", packed, expected)
+	}
+
+	if unpacked := Unpack7(packed); !bytes.Equal(unpacked, data) {
+		t.Fatalf("got % x after unpacking, expected % x", unpacked, data)
+	}
+}
+
+func TestDIYEncoder(t *testing.T) {
+	p, err := drum.DecodeFile(path.Join("..", "fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoder, ok := Lookup("diy")
+	if !ok {
+		t.Fatalf("diy encoder not registered, got %v", Devices())
+	}
+
+	messages, err := encoder.Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("expected a single message, got %d", len(messages))
+	}
+
+	msg := messages[0]
+	if msg[0] != start || msg[1] != NonCommercialID || msg[len(msg)-1] != end {
+		t.Fatalf("invalid message framing % x", msg)
+	}
+	for _, b := range msg[1 : len(msg)-1] {
+		if b > 0x7f {
+			t.Fatalf("message contains 8-bit byte 0x%02x", b)
+		}
+	}
+
+	decoded, err := drum.Decode(bytes.NewReader(Unpack7(msg[2 : len(msg)-1])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(decoded) != fmt.Sprint(p) {
+		t.Fatalf("decoded message differs.\nGot:\n%s\nExpected:\n%s", decoded, p)
+	}
+}
+
+func TestFileDumpEncoder(t *testing.T) {
+	p, err := drum.DecodeFile(path.Join("..", "fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoder, _ := Lookup("filedump")
+	messages, err := encoder.Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var file bytes.Buffer
+	if err := drum.ExportMIDI(&file, p); err != nil {
+		t.Fatal(err)
+	}
+	length := file.Len()
+
+	header := []byte{start, 0x7e, 0x7f, 0x07, 0x01, 0x00, 'M', 'I', 'D', 'I',
+		byte(length & 0x7f), byte(length >> 7 & 0x7f), byte(length >> 14 & 0x7f), byte(length >> 21)}
+	header = append(header, "PATTERN.MID"...)
+	if !bytes.Equal(messages[0], append(header, end)) {
+		t.Fatalf("unexpected header % x", messages[0])
+	}
+
+	var data []byte
+	packets := messages[1 : len(messages)-1]
+	for i, msg := range packets {
+		if !bytes.HasPrefix(msg, []byte{start, 0x7e, 0x7f, 0x07, 0x02, byte(i)}) || msg[len(msg)-1] != end {
+			t.Fatalf("invalid framing of packet %d % x", i, msg)
+		}
+
+		encoded := msg[7 : len(msg)-2]
+		if int(msg[6]) != len(encoded)-1 {
+			t.Fatalf("packet %d has %d data bytes, count is %d", i, len(encoded), msg[6])
+		}
+
+		var checksum byte
+		for _, b := range msg[1 : len(msg)-2] {
+			checksum ^= b
+		}
+		if checksum&0x7f != msg[len(msg)-2] {
+			t.Fatalf("wrong checksum of packet %d", i)
+		}
+
+		for j := 0; j < len(encoded); j += 8 {
+			for k, b := range encoded[j+1 : min(j+8, len(encoded))] {
+				data = append(data, b|(encoded[j]>>(6-k)&1)<<7)
+			}
+		}
+	}
+	if !bytes.Equal(data, file.Bytes()) {
+		t.Fatalf("sent file differs.\nGot:      % x\nExpected: % x", data, file.Bytes())
+	}
+
+	eof := []byte{start, 0x7e, 0x7f, 0x7b, byte(len(packets)), end}
+	if last := messages[len(messages)-1]; !bytes.Equal(last, eof) {
+		t.Fatalf("unexpected end of file % x", last)
+	}
+}
+
+func TestPackFileDump(t *testing.T) {
+	packed := packFileDump([]byte{0x80, 0x01, 0xff})
+
+	expected := []byte{0x50, 0x00, 0x01, 0x7f}
+	if !bytes.Equal(packed, expected) {
+		t.Fatalf("got % x, expected % x", packed, expected)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	encoder, _ := Lookup("diy")
+	profiler, ok := encoder.(Profiler)
+	if !ok {
+		t.Fatal("diy encoder has no profile")
+	}
+
+	p := &drum.Pattern{Tempo: 120, Tracks: make([]drum.Track, 257)}
+	violations := drum.CheckDeviceProfile(p, profiler.Profile())
+	if len(violations) != 1 || violations[0].Constraint != "MaxTracks" {
+		t.Fatalf("expected too many tracks, got %v", violations)
+	}
+}
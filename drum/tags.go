@@ -0,0 +1,76 @@
+package drum
+
+import "strings"
+
+// Tag adds tags to the track, e.g. "percussion" or "top-loop", so tracks
+// can be handled in groups. Tags are matched case-insensitively and
+// stored in the metadata sidecar.
+func (t *Track) Tag(tags ...string) {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !t.HasTag(tag) {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+}
+
+// Untag removes the tag from the track.
+func (t *Track) Untag(tag string) {
+	tags := t.Tags[:0]
+	for _, other := range t.Tags {
+		if !strings.EqualFold(other, tag) {
+			tags = append(tags, other)
+		}
+	}
+
+	t.Tags = tags
+	if len(t.Tags) == 0 {
+		t.Tags = nil
+	}
+}
+
+// HasTag returns true if the track has the tag.
+func (t Track) HasTag(tag string) bool {
+	for _, other := range t.Tags {
+		if strings.EqualFold(other, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tagged returns indexes of tracks having the tag.
+func (p *Pattern) Tagged(tag string) []int {
+	p.ensureTracks()
+
+	var indexes []int
+	for i, track := range p.Tracks {
+		if track.HasTag(tag) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+// MuteTag mutes tracks having the tag and returns their number.
+// Muted tracks keep their steps, but aren't played, see Track.Muted.
+func (p *Pattern) MuteTag(tag string) int {
+	return p.setMutedTag(tag, true)
+}
+
+// UnmuteTag unmutes tracks having the tag and returns their number.
+func (p *Pattern) UnmuteTag(tag string) int {
+	return p.setMutedTag(tag, false)
+}
+
+// setMutedTag sets whether tracks having the tag are muted.
+func (p *Pattern) setMutedTag(tag string, muted bool) int {
+	indexes := p.Tagged(tag)
+	for _, i := range indexes {
+		p.Tracks[i].Muted = muted
+	}
+
+	return len(indexes)
+}
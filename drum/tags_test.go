@@ -0,0 +1,85 @@
+package drum
+
+import (
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Tracks[2].Tag("percussion", "top-loop")
+	p.Tracks[3].Tag("Percussion", "percussion")
+	p.Tracks[3].Untag("top-loop")
+
+	if tagged := p.Tagged("PERCUSSION"); !reflect.DeepEqual(tagged, []int{2, 3}) {
+		t.Fatalf("unexpected tracks tagged percussion: %v", tagged)
+	}
+	if len(p.Tracks[3].Tags) != 1 {
+		t.Fatalf("duplicate tags added: %v", p.Tracks[3].Tags)
+	}
+
+	hits := len(p.Events())
+	if n := p.MuteTag("percussion"); n != 2 {
+		t.Fatalf("expected 2 tracks muted, got %d", n)
+	}
+	for _, e := range p.Events() {
+		if e.TrackIndex == 2 || e.TrackIndex == 3 {
+			t.Fatalf("muted track played: %+v", e)
+		}
+	}
+
+	file := path.Join(t.TempDir(), "tagged.splice")
+	if err := EncodeFile(p, file); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := DecodeFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded.Metadata(), p.Metadata()) || !reloaded.Tracks[2].Muted {
+		t.Fatalf("tags not preserved: %+v", reloaded.Metadata())
+	}
+
+	reloaded.UnmuteTag("percussion")
+	if len(reloaded.Events()) != hits {
+		t.Fatalf("expected %d events after unmuting, got %d", hits, len(reloaded.Events()))
+	}
+
+	// Metadata replaces the muted state instead of adding to it
+	p.ApplyMetadata(reloaded.Metadata())
+	if p.Tracks[2].Muted || p.Tracks[3].Muted {
+		t.Fatal("tracks still muted after applying unmuted metadata")
+	}
+}
+
+func TestQueryTag(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Tracks[1].Tag("backbeat")
+
+	for _, tc := range []struct {
+		query Query
+		match bool
+	}{
+		{Query{Tag: "backbeat"}, true},
+		{Query{Tag: "fills"}, false},
+		{Query{Tag: "backbeat", Track: p.Tracks[1].Name}, true},
+		{Query{Tag: "backbeat", Track: p.Tracks[0].Name}, false},
+		{Query{Tag: "backbeat", MinDensity: 1}, false},
+	} {
+		match, err := tc.query.Match(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if match != tc.match {
+			t.Fatalf("expected %+v to match: %v", tc.query, tc.match)
+		}
+	}
+}
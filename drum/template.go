@@ -0,0 +1,93 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+)
+
+// Template is a pattern written in the CSV format of ExportCSV
+// with text/template placeholders, e.g. "tempo,{{.Tempo}}".
+//
+// Besides the standard functions, templates can generate a track's
+// step fields with:
+//
+//	density d   round(d*16) hits spread evenly across 16 steps
+//	every n     a hit on every n-th step, starting with the first one
+type Template struct {
+	tmpl *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"density": densitySteps,
+	"every":   everySteps,
+}
+
+// ParseTemplate parses a pattern template from text.
+func ParseTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("pattern").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Instantiate resolves the template's placeholders with params
+// and returns the resulting pattern.
+func (t *Template) Instantiate(params interface{}) (*Pattern, error) {
+	var buffer bytes.Buffer
+	err := t.tmpl.Execute(&buffer, params)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := ImportCSV(&buffer)
+	if err != nil {
+		return nil, fmt.Errorf("instantiated template is invalid - %v", err)
+	}
+
+	return p, nil
+}
+
+// densitySteps returns step fields with round(d*16) hits spread evenly.
+func densitySteps(d float64) (string, error) {
+	if d < 0 || d > 1 {
+		return "", fmt.Errorf("density %v out of range [0, 1]", d)
+	}
+
+	hits := int(math.Round(d * trackSteps))
+
+	steps := make([]byte, trackSteps)
+	for i := 0; i < hits; i++ {
+		steps[i*trackSteps/hits] = StepOn
+	}
+
+	return stepFields(steps), nil
+}
+
+// everySteps returns step fields with a hit on every n-th step.
+func everySteps(n int) (string, error) {
+	if n < 1 {
+		return "", fmt.Errorf("invalid interval %d", n)
+	}
+
+	steps := make([]byte, trackSteps)
+	for i := 0; i < trackSteps; i += n {
+		steps[i] = StepOn
+	}
+
+	return stepFields(steps), nil
+}
+
+// stepFields joins steps into comma-separated fields.
+func stepFields(steps []byte) string {
+	fields := make([]string, len(steps))
+	for i, step := range steps {
+		fields[i] = fmt.Sprint(step)
+	}
+
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,54 @@
+package drum
+
+import (
+	"fmt"
+	"testing"
+)
+
+const testTemplate = `version,{{.Version}}
+tempo,{{.Tempo}}
+0,kick,{{every 4}}
+1,hh,{{density .HatDensity}}
+`
+
+func TestTemplateInstantiate(t *testing.T) {
+	tmpl, err := ParseTemplate(testTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := tmpl.Instantiate(map[string]interface{}{
+		"Version":    "0.808-alpha",
+		"Tempo":      128,
+		"HatDensity": 0.375,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 128
+(0) kick	|x---|x---|x---|x---|
+(1) hh	|x-x-|-x--|x-x-|-x--|
+`
+	if fmt.Sprint(p) != expected {
+		t.Fatalf("template wasn't instantiated as expected.\nGot:\n%s\nExpected:\n%s", p, expected)
+	}
+}
+
+func TestTemplateInstantiateErrors(t *testing.T) {
+	tmpl, err := ParseTemplate(testTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, params := range []map[string]interface{}{
+		{"Version": "0.808", "Tempo": 120},
+		{"Version": "0.808", "Tempo": 120, "HatDensity": 1.5},
+		{"Version": "0.808", "Tempo": "fast", "HatDensity": 0.5},
+	} {
+		if _, err := tmpl.Instantiate(params); err == nil {
+			t.Fatalf("expected an error for %v", params)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package drum
+
+import (
+	"sort"
+	"time"
+)
+
+// TempoChange sets the pattern's tempo from a step on.
+type TempoChange struct {
+	Step  int     `json:"step"`
+	Tempo float32 `json:"tempo"`
+}
+
+// SetTempoChange adds a tempo change at the step, replacing an existing
+// change at the same step. Changes are kept ordered by step.
+func (p *Pattern) SetTempoChange(step int, tempo float32) {
+	i := sort.Search(len(p.TempoChanges), func(i int) bool {
+		return p.TempoChanges[i].Step >= step
+	})
+
+	if i < len(p.TempoChanges) && p.TempoChanges[i].Step == step {
+		p.TempoChanges[i].Tempo = tempo
+		return
+	}
+
+	p.TempoChanges = append(p.TempoChanges, TempoChange{})
+	copy(p.TempoChanges[i+1:], p.TempoChanges[i:])
+	p.TempoChanges[i] = TempoChange{Step: step, Tempo: tempo}
+}
+
+// TempoAt returns the tempo in effect at the step: that of the last tempo
+// change at or before it, or the pattern's Tempo if there's none.
+func (p *Pattern) TempoAt(step int) float32 {
+	tempo := p.Tempo
+
+	for _, change := range p.TempoChanges {
+		if change.Step > step {
+			break
+		}
+		tempo = change.Tempo
+	}
+
+	return tempo
+}
+
+// StepDurationAt returns duration of the step at the tempo in effect,
+// or zero if the tempo isn't positive.
+func (p *Pattern) StepDurationAt(step int) time.Duration {
+	return stepDuration(p.TempoAt(step))
+}
+
+// stepDuration returns duration of a single step at the tempo.
+func stepDuration(tempo float32) time.Duration {
+	return BPM(tempo).StepDuration()
+}
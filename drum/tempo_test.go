@@ -0,0 +1,69 @@
+package drum
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTempoChanges(t *testing.T) {
+	p := &Pattern{
+		Tempo: 120,
+		Tracks: []Track{
+			{Name: "kick", Steps: []byte{1, 0, 0, 0, 1, 0, 0, 0}},
+		},
+	}
+
+	p.SetTempoChange(6, 240)
+	p.SetTempoChange(4, 60)
+	p.SetTempoChange(6, 30)
+
+	expected := []TempoChange{{4, 60}, {6, 30}}
+	if !reflect.DeepEqual(p.TempoChanges, expected) {
+		t.Fatalf("unexpected tempo changes %v, expected %v", p.TempoChanges, expected)
+	}
+
+	for step, tempo := range map[int]float32{0: 120, 3: 120, 4: 60, 5: 60, 6: 30, 7: 30} {
+		if got := p.TempoAt(step); got != tempo {
+			t.Errorf("tempo at step %d is %v, expected %v", step, got, tempo)
+		}
+	}
+
+	if d := p.StepDurationAt(4); d != 250*time.Millisecond {
+		t.Errorf("unexpected step duration %v at 60 BPM", d)
+	}
+
+	// Four steps of 125ms at 120 BPM
+	events := p.Events()
+	if events[1].Time != 500*time.Millisecond {
+		t.Errorf("unexpected time of the second hit %v", events[1].Time)
+	}
+	if d := p.stepTime(8); d != 4*125*time.Millisecond+2*250*time.Millisecond+2*500*time.Millisecond {
+		t.Errorf("unexpected pattern duration %v", d)
+	}
+}
+
+func TestTempoChangesPersistence(t *testing.T) {
+	p := &Pattern{Tempo: 120, Tracks: []Track{{Name: "kick", Steps: []byte{1, 0}}}}
+	p.SetTempoChange(1, 90)
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmarshaled := &Pattern{}
+	if err := json.Unmarshal(data, unmarshaled); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarshaled.TempoChanges, p.TempoChanges) {
+		t.Fatalf("tempo changes weren't unmarshaled: %v", unmarshaled.TempoChanges)
+	}
+
+	restored := &Pattern{Tempo: 120, Tracks: p.Clone().Tracks}
+	restored.ApplyMetadata(p.Metadata())
+	if !reflect.DeepEqual(restored.TempoChanges, p.TempoChanges) {
+		t.Fatalf("tempo changes weren't restored from metadata: %v", restored.TempoChanges)
+	}
+}
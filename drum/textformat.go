@@ -0,0 +1,256 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// canonicalHeader starts every pattern in the canonical text format,
+// followed by the format's version.
+const (
+	canonicalHeader  = "splice-canonical"
+	canonicalVersion = 1
+)
+
+// FormatCanonical returns the pattern in the canonical text format.
+// Unlike String, its output is versioned and guaranteed to stay the same
+// across library versions, which makes it fit for golden files and
+// storing patterns in version control:
+//
+//	splice-canonical 1
+//	version "0.808-alpha"
+//	tempo 120 8=90 12=60
+//	steps 16
+//	track 0 "kick" x---x---x---x---
+//	track 1 "snare" ----x---f---x--- offset=1 scale=0.8 color="#f00"
+//
+// Tempo changes follow the tempo as step=tempo, counting steps from 0.
+// Steps are written as x for hits, f for flams and - for rests. Track
+// attributes follow the steps only if they're set, in the order of offset,
+// scale (of velocities), color, icon and label.
+func FormatCanonical(p *Pattern) string {
+	p.ensureTracks()
+
+	var buffer bytes.Buffer
+
+	steps := 0
+	for _, track := range p.Tracks {
+		if track.Len() > steps {
+			steps = track.Len()
+		}
+	}
+
+	fmt.Fprintf(&buffer, "%s %d\n", canonicalHeader, canonicalVersion)
+	fmt.Fprintf(&buffer, "version %s\n", strconv.Quote(p.Version))
+	fmt.Fprintf(&buffer, "tempo %s", formatTempo(p.Tempo))
+	for _, change := range p.TempoChanges {
+		fmt.Fprintf(&buffer, " %d=%s", change.Step, formatTempo(change.Tempo))
+	}
+	buffer.WriteString("\n")
+	fmt.Fprintf(&buffer, "steps %d\n", steps)
+
+	for _, track := range p.Tracks {
+		fmt.Fprintf(&buffer, "track %d %s %s", track.ID, strconv.Quote(track.Name),
+			strings.Join(plainStepSymbols(track.Unpacked()), ""))
+
+		if track.Offset != 0 {
+			fmt.Fprintf(&buffer, " offset=%d", track.Offset)
+		}
+		if track.velocityScale != 0 {
+			fmt.Fprintf(&buffer, " scale=%s", strconv.FormatFloat(track.velocityScale, 'g', -1, 64))
+		}
+		if track.Display.Color != "" {
+			fmt.Fprintf(&buffer, " color=%s", strconv.Quote(track.Display.Color))
+		}
+		if track.Display.Icon != "" {
+			fmt.Fprintf(&buffer, " icon=%s", strconv.Quote(track.Display.Icon))
+		}
+		if track.Display.Label != "" {
+			fmt.Fprintf(&buffer, " label=%s", strconv.Quote(track.Display.Label))
+		}
+
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
+
+// ParseCanonical parses a pattern written by FormatCanonical.
+// Any deviation from the format is an error.
+func ParseCanonical(s string) (*Pattern, error) {
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	p := &Pattern{}
+
+	expect := []string{canonicalHeader, "version", "tempo", "steps"}
+	steps := 0
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		fields, err := canonicalFields(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		if line <= len(expect) {
+			err = p.parseCanonicalHeader(expect[line-1], fields, &steps)
+		} else {
+			err = p.parseCanonicalTrack(fields, steps)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if line < len(expect) {
+		return nil, fmt.Errorf("missing %s line", expect[line])
+	}
+
+	return p, nil
+}
+
+// parseCanonicalHeader parses one of the lines preceding tracks.
+func (p *Pattern) parseCanonicalHeader(key string, fields []string, steps *int) error {
+	if len(fields) < 2 || fields[0] != key || len(fields) > 2 && key != "tempo" {
+		return fmt.Errorf("expected %s line", key)
+	}
+
+	var err error
+
+	switch key {
+	case canonicalHeader:
+		if fields[1] != strconv.Itoa(canonicalVersion) {
+			return fmt.Errorf("unsupported format version %s", fields[1])
+		}
+	case "version":
+		p.Version = fields[1]
+	case "tempo":
+		var tempo float64
+		tempo, err = strconv.ParseFloat(fields[1], 32)
+		p.Tempo = float32(tempo)
+		if err == nil {
+			return p.parseCanonicalTempoChanges(fields[2:])
+		}
+	case "steps":
+		*steps, err = strconv.Atoi(fields[1])
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", key, fields[1])
+	}
+
+	return nil
+}
+
+// parseCanonicalTempoChanges parses step=tempo fields of the tempo line.
+func (p *Pattern) parseCanonicalTempoChanges(fields []string) error {
+	for _, field := range fields {
+		step, tempo, _ := strings.Cut(field, "=")
+
+		s, err := strconv.Atoi(step)
+		if err != nil || s < 0 {
+			return fmt.Errorf("invalid tempo change %q", field)
+		}
+		t, err := strconv.ParseFloat(tempo, 32)
+		if err != nil {
+			return fmt.Errorf("invalid tempo change %q", field)
+		}
+
+		p.SetTempoChange(s, float32(t))
+	}
+
+	return nil
+}
+
+// parseCanonicalTrack parses a track line.
+func (p *Pattern) parseCanonicalTrack(fields []string, steps int) error {
+	if len(fields) < 4 || fields[0] != "track" {
+		return errors.New("expected track line")
+	}
+
+	id, err := strconv.ParseUint(fields[1], 10, 8)
+	if err != nil {
+		return fmt.Errorf("invalid track ID %q", fields[1])
+	}
+
+	track := Track{ID: byte(id), Name: fields[2]}
+
+	if len(fields[3]) > steps {
+		return fmt.Errorf("track has %d steps, more than %d", len(fields[3]), steps)
+	}
+	track.Steps, err = parsePlainSteps(fields[3])
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields[4:] {
+		key, value, _ := strings.Cut(field, "=")
+
+		switch key {
+		case "offset":
+			track.Offset, err = strconv.Atoi(value)
+		case "scale":
+			track.velocityScale, err = strconv.ParseFloat(value, 64)
+		case "color":
+			track.Display.Color = value
+		case "icon":
+			track.Display.Icon = value
+		case "label":
+			track.Display.Label = value
+		default:
+			return fmt.Errorf("unknown track attribute %q", key)
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", key, value)
+		}
+	}
+
+	p.Tracks = append(p.Tracks, track)
+
+	return nil
+}
+
+// canonicalFields splits a line into space-separated fields, unquoting
+// quoted strings, also when used as values of key=value fields.
+func canonicalFields(line string) ([]string, error) {
+	var fields []string
+
+	for line != "" {
+		if line[0] == ' ' {
+			line = line[1:]
+			continue
+		}
+
+		prefix := ""
+		if key, value, ok := strings.Cut(line, "="); ok && !strings.Contains(key, " ") && strings.HasPrefix(value, `"`) {
+			prefix, line = key+"=", value
+		}
+
+		if !strings.HasPrefix(line, `"`) {
+			field, rest, _ := strings.Cut(line, " ")
+			fields = append(fields, prefix+field)
+			line = rest
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted string in %q", line)
+		}
+		field, _ := strconv.Unquote(quoted)
+
+		fields = append(fields, prefix+field)
+		line = line[len(quoted):]
+	}
+
+	return fields, nil
+}
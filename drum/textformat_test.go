@@ -0,0 +1,86 @@
+package drum
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalRoundTrip(t *testing.T) {
+	for _, exp := range tData {
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ParseCanonical(FormatCanonical(decoded))
+		if err != nil {
+			t.Fatalf("something went wrong parsing %s - %v", exp.path, err)
+		}
+
+		if fmt.Sprint(parsed) != exp.output {
+			t.Fatalf("%s wasn't parsed as expected.\nGot:\n%s\nExpected:\n%s", exp.path, parsed, exp.output)
+		}
+	}
+}
+
+func TestFormatCanonical(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   98.4,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0, 0, 0, 1, 0, 0, 0}},
+			{ID: 1, Name: "snare 2", Steps: []byte{0, 0, 1, 0, 0, 0, 2, 0}, Offset: 1,
+				Display: Display{Color: "#f00", Label: `the "snare"`}},
+		},
+	}
+	p.Tracks[1].SetVelocityScale(0.8)
+	p.SetTempoChange(6, 60)
+	p.SetTempoChange(4, 90.5)
+
+	expected := `splice-canonical 1
+version "0.808-alpha"
+tempo 98.4 4=90.5 6=60
+steps 8
+track 0 "kick" x---x---
+track 1 "snare 2" --x---f- offset=1 scale=0.8 color="#f00" label="the \"snare\""
+`
+	formatted := FormatCanonical(p)
+	if formatted != expected {
+		t.Fatalf("unexpected canonical format.\nGot:\n%s\nExpected:\n%s", formatted, expected)
+	}
+
+	parsed, err := ParseCanonical(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := FormatCanonical(parsed); again != expected {
+		t.Fatalf("format isn't stable.\nGot:\n%s\nExpected:\n%s", again, expected)
+	}
+}
+
+func TestParseCanonicalErrors(t *testing.T) {
+	valid := "splice-canonical 1\nversion \"0.808\"\ntempo 120\nsteps 4\ntrack 0 \"kick\" x---\n"
+
+	for _, input := range []string{
+		"",
+		strings.Replace(valid, "splice-canonical 1", "splice-canonical 2", 1),
+		strings.Replace(valid, "tempo 120\n", "", 1),
+		strings.Replace(valid, "tempo 120", "tempo 120 4", 1),
+		strings.Replace(valid, "tempo 120", "tempo 120 -1=60", 1),
+		strings.Replace(valid, "steps 4", "steps 4 8", 1),
+		strings.Replace(valid, "x---", "x----", 1),
+		strings.Replace(valid, "x---", "x-o-", 1),
+		strings.Replace(valid, "x---", "x--- swing=0.5", 1),
+		strings.Replace(valid, `"kick"`, `"kick`, 1),
+	} {
+		if _, err := ParseCanonical(input); err == nil {
+			t.Errorf("expected an error parsing:\n%s", input)
+		}
+	}
+
+	if _, err := ParseCanonical(valid); err != nil {
+		t.Fatal(err)
+	}
+}
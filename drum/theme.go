@@ -0,0 +1,150 @@
+package drum
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+)
+
+// Theme sets the look of visual outputs: the text renderer, and the HTML
+// and SVG exporters. Colors are in CSS notation, e.g. "#e90". ANSI fields
+// are SGR parameters for terminals, e.g. "1;33", empty ones add no escape
+// codes.
+type Theme struct {
+	Name string `json:"name"`
+
+	// Characters of steps and beat separators in text
+	On        string `json:"on"`
+	Flam      string `json:"flam"`
+	Off       string `json:"off"`
+	Separator string `json:"separator"`
+	// CellWidth is the width of a step in text, see RenderOptions.CellWidth
+	CellWidth int `json:"cellWidth"`
+
+	ANSIOn    string `json:"ansiOn,omitempty"`
+	ANSIFlam  string `json:"ansiFlam,omitempty"`
+	ANSIOff   string `json:"ansiOff,omitempty"`
+	ANSILabel string `json:"ansiLabel,omitempty"`
+
+	Background string `json:"background"`
+	Foreground string `json:"foreground"`
+	Grid       string `json:"grid"`
+	Beat       string `json:"beat"`
+	OnColor    string `json:"onColor"`
+	FlamColor  string `json:"flamColor"`
+	Current    string `json:"current"`
+	// CellSize is the size of a step in HTML and SVG, in pixels
+	CellSize int `json:"cellSize"`
+}
+
+// DefaultTheme is used when no theme is set.
+var DefaultTheme = Theme{
+	Name:       "default",
+	On:         "x",
+	Flam:       "f",
+	Off:        "-",
+	Separator:  "|",
+	CellWidth:  1,
+	Background: "#222",
+	Foreground: "#eee",
+	Grid:       "#444",
+	Beat:       "#888",
+	OnColor:    "#e90",
+	FlamColor:  "#f50",
+	Current:    "#fff",
+	CellSize:   24,
+}
+
+var (
+	themesMu sync.RWMutex
+	themes   = map[string]Theme{}
+)
+
+func init() {
+	RegisterTheme(DefaultTheme)
+
+	dark := DefaultTheme
+	dark.Name = "dark"
+	dark.On, dark.Flam, dark.Off = "●", "◉", "·"
+	dark.ANSIOn, dark.ANSIFlam, dark.ANSIOff, dark.ANSILabel = "33", "1;31", "90", "1"
+	RegisterTheme(dark)
+
+	light := dark
+	light.Name = "light"
+	light.ANSIOn, light.ANSIFlam, light.ANSIOff, light.ANSILabel = "34", "1;35", "37", "1"
+	light.Background, light.Foreground, light.Grid, light.Beat = "#fafafa", "#222", "#ccc", "#777"
+	light.OnColor, light.FlamColor, light.Current = "#1565c0", "#ad1457", "#000"
+	RegisterTheme(light)
+
+	contrast := DefaultTheme
+	contrast.Name = "high-contrast"
+	contrast.On, contrast.Flam, contrast.Off = "█", "▓", " "
+	contrast.ANSIOn, contrast.ANSIFlam, contrast.ANSIOff, contrast.ANSILabel = "1;97", "1;93", "", "1;97"
+	contrast.Background, contrast.Foreground, contrast.Grid, contrast.Beat = "#000", "#fff", "#fff", "#ff0"
+	contrast.OnColor, contrast.FlamColor, contrast.Current = "#fff", "#ff0", "#0ff"
+	RegisterTheme(contrast)
+}
+
+// RegisterTheme makes a theme available under its name. It panics if
+// a theme with the name is already registered.
+func RegisterTheme(t Theme) {
+	themesMu.Lock()
+	defer themesMu.Unlock()
+
+	if _, ok := themes[t.Name]; ok {
+		panic("drum: theme " + t.Name + " registered twice")
+	}
+	themes[t.Name] = t
+}
+
+// LookupTheme returns the theme registered under the name.
+func LookupTheme(name string) (Theme, bool) {
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+
+	t, ok := themes[name]
+	return t, ok
+}
+
+// ThemeNames returns sorted names of registered themes.
+func ThemeNames() []string {
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LoadTheme reads a theme from a JSON file with fields of Theme.
+// Missing fields are taken from DefaultTheme.
+func LoadTheme(path string) (Theme, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+
+	t := DefaultTheme
+	t.Name = path
+	err = json.Unmarshal(data, &t)
+	if err != nil {
+		return Theme{}, fmt.Errorf("something went wrong reading theme %s - %v", path, err)
+	}
+
+	return t, nil
+}
+
+// ansi wraps s in escape codes with SGR parameters, if any.
+func ansi(s, params string) string {
+	if params == "" {
+		return s
+	}
+
+	return "\x1b[" + params + "m" + s + "\x1b[0m"
+}
@@ -0,0 +1,70 @@
+package drum
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestRenderTheme(t *testing.T) {
+	p := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []Track{
+		{ID: 1, Name: "snare", Steps: []byte{StepOff, StepOn, StepFlam, StepOff}},
+	}}
+
+	theme, ok := LookupTheme("dark")
+	if !ok {
+		t.Fatal("no dark theme")
+	}
+
+	expected := "Saved with HW Version: 0.808-alpha\nTempo: 120\n" +
+		"\x1b[1m(1) snare\x1b[0m\t|\x1b[90m· \x1b[0m\x1b[33m● \x1b[0m\x1b[1;31m◉ \x1b[0m\x1b[90m· \x1b[0m|\n"
+	if out := p.Render(RenderOptions{Theme: &theme, CellWidth: 2}); out != expected {
+		t.Fatalf("wrong render.\nGot:\n%q\nExpected:\n%q", out, expected)
+	}
+
+	if out := p.Render(RenderOptions{Theme: &DefaultTheme}); out != p.String() {
+		t.Fatalf("default theme changed rendering:\n%s", out)
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	dir, err := ioutil.TempDir("", "theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "theme.json")
+	if err := ioutil.WriteFile(file, []byte(`{"on": "#", "onColor": "red"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	theme, err := LoadTheme(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.On != "#" || theme.OnColor != "red" || theme.Off != DefaultTheme.Off {
+		t.Fatalf("unexpected theme %+v", theme)
+	}
+
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, export := range map[string]func(*bytes.Buffer) error{
+		"HTML": func(buf *bytes.Buffer) error { return ExportHTML(buf, decoded, WithTheme(theme)) },
+		"SVG":  func(buf *bytes.Buffer) error { return ExportSVG(buf, decoded, WithTheme(theme)) },
+	} {
+		var buf bytes.Buffer
+		if err := export(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "red") || !strings.Contains(buf.String(), DefaultTheme.Background) {
+			t.Errorf("%s export doesn't use the theme:\n%s", name, buf.String())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package drum
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// trackerNote is the note triggered by every hit
+	trackerNote = "C-4"
+	// trackerColumnWidth fits a note and its volume, e.g. "C-4 7F"
+	trackerColumnWidth = 6
+)
+
+// ExportTracker writes the pattern as tracker-style pattern text, with
+// a row per step and a column per track. Every hit triggers C-4 with its
+// velocity as the volume in hex. Steps are mapped to rows at 4 lines per
+// beat. The accent track is folded into volumes instead of getting its own
+// column.
+func ExportTracker(w io.Writer, p *Pattern) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+
+	var columns []int
+	velocities := make([][]byte, len(p.Tracks))
+	rows := 0
+
+	for i, track := range p.Tracks {
+		if track.IsAccent() {
+			continue
+		}
+
+		columns = append(columns, i)
+		velocities[i] = p.trackVelocities(i)
+		if track.Len() > rows {
+			rows = track.Len()
+		}
+	}
+
+	fmt.Fprintf(writer, "BPM: %v\n", p.Tempo)
+	fmt.Fprintf(writer, "LPB: %d\n", beatSteps)
+	fmt.Fprintf(writer, "Rows: %d\n\n", rows)
+
+	writer.WriteString("  ")
+	for _, i := range columns {
+		name := p.Tracks[i].Name
+		if runes := []rune(name); len(runes) > trackerColumnWidth {
+			name = string(runes[:trackerColumnWidth])
+		}
+		fmt.Fprintf(writer, " |%-*s", trackerColumnWidth, name)
+	}
+	writer.WriteString(" |\n")
+
+	for row := 0; row < rows; row++ {
+		fmt.Fprintf(writer, "%02X", row)
+
+		for _, i := range columns {
+			cell := strings.Repeat("-", len(trackerNote)) + " .."
+			if row < len(velocities[i]) && velocities[i][row] > 0 {
+				cell = fmt.Sprintf("%s %02X", trackerNote, velocities[i][row])
+			}
+
+			writer.WriteString(" |" + cell)
+		}
+
+		writer.WriteString(" |\n")
+	}
+
+	return writer.Flush()
+}
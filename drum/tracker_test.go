@@ -0,0 +1,39 @@
+package drum
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportTracker(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 0, 0, 0, 1, 0}},
+			{ID: 1, Name: "hé-clôsé", Steps: []byte{0, 0, 1, 0, 0, 0}},
+			{ID: 2, Name: "accent", Steps: []byte{1, 0, 0, 0, 0, 0}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTracker(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `BPM: 120
+LPB: 4
+Rows: 6
+
+   |kick   |hé-clô |
+00 |C-4 7F |--- .. |
+01 |--- .. |--- .. |
+02 |--- .. |C-4 64 |
+03 |--- .. |--- .. |
+04 |C-4 64 |--- .. |
+05 |--- .. |--- .. |
+`
+	if buf.String() != expected {
+		t.Fatalf("wrong tracker export.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,91 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Transform changes a pattern, returning the result.
+// It may modify and return the pattern it was given.
+type Transform interface {
+	Apply(p *Pattern) (*Pattern, error)
+}
+
+// TransformFunc is a function used as a Transform.
+type TransformFunc func(p *Pattern) (*Pattern, error)
+
+// Apply calls f(p).
+func (f TransformFunc) Apply(p *Pattern) (*Pattern, error) {
+	return f(p)
+}
+
+// Pipeline is a Transform applying transforms in order.
+type Pipeline []Transform
+
+// Apply applies all transforms of the pipeline to an unpacked copy of p.
+func (pl Pipeline) Apply(p *Pattern) (*Pattern, error) {
+	p = p.Clone()
+	p.Unpack()
+
+	for i, t := range pl {
+		var err error
+		p, err = t.Apply(p)
+		if err != nil {
+			return nil, fmt.Errorf("transform %d: %v", i+1, err)
+		}
+	}
+
+	return p, nil
+}
+
+// Transforms returns built-in transforms by name.
+func Transforms() map[string]Transform {
+	return map[string]Transform{
+		"canonicalize": TransformFunc(func(p *Pattern) (*Pattern, error) {
+			p.Canonicalize()
+			return p, nil
+		}),
+		"normalize-ids": TransformFunc(func(p *Pattern) (*Pattern, error) {
+			return p, p.NormalizeIDs()
+		}),
+		"apply-offsets": TransformFunc(func(p *Pattern) (*Pattern, error) {
+			return p.ApplyOffsets(), nil
+		}),
+	}
+}
+
+// Command returns a Transform running the named program with args.
+// The pattern is written to the program's standard input as JSON, in the
+// form of MarshalJSON, and the transformed pattern is read from its
+// standard output in the same form. Anything written to standard error
+// is included in the error if the program fails.
+func Command(name string, args ...string) Transform {
+	return TransformFunc(func(p *Pattern) (*Pattern, error) {
+		input, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		err = cmd.Run()
+		if err != nil {
+			return nil, fmt.Errorf("%s failed - %v: %s", name, err, strings.TrimSpace(stderr.String()))
+		}
+
+		output := &Pattern{}
+		err = json.Unmarshal(stdout.Bytes(), output)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output of %s - %v", name, err)
+		}
+
+		return output, nil
+	})
+}
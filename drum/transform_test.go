@@ -0,0 +1,80 @@
+package drum
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+)
+
+// TestTransformHelperProcess isn't a real test. It's run as a subprocess
+// by TestCommand, reversing steps of each track.
+func TestTransformHelperProcess(t *testing.T) {
+	if os.Getenv("DRUM_TRANSFORM_HELPER") != "1" {
+		return
+	}
+
+	var p jsonPattern
+	if err := json.NewDecoder(os.Stdin).Decode(&p); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, track := range p.Tracks {
+		for i, j := 0, len(track.Steps)-1; i < j; i, j = i+1, j-1 {
+			track.Steps[i], track.Steps[j] = track.Steps[j], track.Steps[i]
+		}
+	}
+
+	json.NewEncoder(os.Stdout).Encode(p)
+	os.Exit(0)
+}
+
+func TestCommand(t *testing.T) {
+	t.Setenv("DRUM_TRANSFORM_HELPER", "1")
+
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pipeline := Pipeline{
+		Command(os.Args[0], "-test.run=^TestTransformHelperProcess$"),
+		Transforms()["canonicalize"],
+	}
+
+	transformed, err := pipeline.Apply(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(0) kick	|---x|---x|---x|---x|
+(2) clap	|----|----|-x-x|----|
+(1) snare	|---x|----|---x|----|
+(4) hh-close	|x--x|----|---x|---x|
+(3) hh-open	|-x--|-x-x|-x--|-x--|
+(5) cowbell	|----|-x--|----|----|
+`
+	if fmt.Sprint(transformed) != expected {
+		t.Fatalf("pattern wasn't transformed as expected.\nGot:\n%s\nExpected:\n%s", transformed, expected)
+	}
+
+	if fmt.Sprint(decoded) != tData[0].output {
+		t.Fatal("pipeline modified its input")
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	decoded, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Command("false").Apply(decoded)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,123 @@
+package drum
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// transliterations map accented Latin letters to ASCII.
+var transliterations = map[rune]string{}
+
+func init() {
+	for _, group := range []struct{ from, to string }{
+		{"àáâãäåāăą", "a"}, {"ÀÁÂÃÄÅĀĂĄ", "A"},
+		{"çćĉċč", "c"}, {"ÇĆĈĊČ", "C"},
+		{"ďđð", "d"}, {"ĎĐÐ", "D"},
+		{"èéêëēĕėęě", "e"}, {"ÈÉÊËĒĔĖĘĚ", "E"},
+		{"ĝğġģ", "g"}, {"ĜĞĠĢ", "G"},
+		{"ìíîïĩīĭįı", "i"}, {"ÌÍÎÏĨĪĬĮİ", "I"},
+		{"ķ", "k"}, {"Ķ", "K"},
+		{"ĺļľŀł", "l"}, {"ĹĻĽĿŁ", "L"},
+		{"ñńņňŉ", "n"}, {"ÑŃŅŇ", "N"},
+		{"òóôõöøōŏő", "o"}, {"ÒÓÔÕÖØŌŎŐ", "O"},
+		{"ŕŗř", "r"}, {"ŔŖŘ", "R"},
+		{"śŝşš", "s"}, {"ŚŜŞŠ", "S"},
+		{"ţťŧ", "t"}, {"ŢŤŦ", "T"},
+		{"ùúûüũūŭůűų", "u"}, {"ÙÚÛÜŨŪŬŮŰŲ", "U"},
+		{"ýÿŷ", "y"}, {"ÝŸŶ", "Y"},
+		{"źżž", "z"}, {"ŹŻŽ", "Z"},
+		{"æ", "ae"}, {"Æ", "AE"}, {"œ", "oe"}, {"Œ", "OE"},
+		{"ß", "ss"}, {"þ", "th"}, {"Þ", "TH"},
+	} {
+		for _, r := range group.from {
+			transliterations[r] = group.to
+		}
+	}
+}
+
+// ValidateName returns an error if the track name isn't valid UTF-8
+// or contains control characters.
+func ValidateName(name string) error {
+	if !utf8.ValidString(name) {
+		return errors.New("name isn't valid UTF-8")
+	}
+
+	for i, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("name contains control character %U at byte %d", r, i)
+		}
+	}
+
+	return nil
+}
+
+// Transliterate returns the track name with accented Latin letters
+// replaced by ASCII ones, e.g. "é" by "e", control characters removed
+// and invalid UTF-8 bytes replaced by "?". Other characters are kept.
+func Transliterate(name string) string {
+	var b strings.Builder
+
+	for i, r := range name {
+		switch {
+		case r == utf8.RuneError && !strings.HasPrefix(name[i:], string(utf8.RuneError)):
+			b.WriteByte('?')
+		case unicode.IsControl(r):
+		case transliterations[r] != "":
+			b.WriteString(transliterations[r])
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// WithTransliteration makes decoding transliterate track names, see
+// Transliterate. Names as stored in the file are kept for encoding,
+// as long as the names aren't changed, see Track.RawName.
+func WithTransliteration(transliterate bool) Option {
+	return func(c *decodeConfig) {
+		c.transliterate = transliterate
+	}
+}
+
+// WithNameTransliteration makes encoding transliterate track names,
+// see Transliterate, instead of writing names as they are.
+func WithNameTransliteration(transliterate bool) EncodeOption {
+	return func(c *encodeConfig) {
+		c.transliterate = transliterate
+	}
+}
+
+// RawName returns the track's name as stored in the file it was decoded
+// from, before transliteration. It's Name if the track was renamed
+// since or its name wasn't transliterated.
+func (t Track) RawName() string {
+	if t.rawName != "" && Transliterate(t.rawName) == t.Name {
+		return t.rawName
+	}
+
+	return t.Name
+}
+
+// transliterateName transliterates the track's name, keeping the raw one.
+func (t *Track) transliterateName() {
+	name := Transliterate(t.Name)
+	if name != t.Name {
+		t.rawName = t.Name
+		t.Name = name
+	}
+}
+
+// trackName returns the name of the track to encode, before the name
+// policy is applied: the raw name, unless transliteration is enabled.
+func (c encodeConfig) trackName(t Track) string {
+	if c.transliterate {
+		return Transliterate(t.Name)
+	}
+
+	return t.RawName()
+}
@@ -0,0 +1,91 @@
+package drum
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransliterate(t *testing.T) {
+	for _, tc := range []struct{ name, expected string }{
+		{"kick", "kick"},
+		{"crash café", "crash cafe"},
+		{"Łódź tom", "Lodz tom"},
+		{"hat\x00\topen", "hatopen"},
+		{"snare\xffdrum", "snare?drum"},
+		{"ハイハット", "ハイハット"},
+		{"fuß", "fuss"},
+	} {
+		if got := Transliterate(tc.name); got != tc.expected {
+			t.Fatalf("expected %q transliterated to %q, got %q", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"kick":      true,
+		"café":      true,
+		"hat\x00":   false,
+		"snare\xff": false,
+		"ハイハット":     true,
+	} {
+		if err := ValidateName(name); (err == nil) != valid {
+			t.Fatalf("unexpected validation of %q: %v", name, err)
+		}
+	}
+}
+
+func TestDecodeTransliteration(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: stepsFromString("x---x---x---x---")},
+			{ID: 1, Name: "caisse claire é\xff", Steps: stepsFromString("----x-------x---")},
+		},
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DecodeBytes(data, WithStrict(true))
+	if err == nil || !strings.Contains(err.Error(), "isn't valid UTF-8") {
+		t.Fatalf("expected invalid name error, got %v", err)
+	}
+
+	decoded, err := DecodeBytes(data, WithTransliteration(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := decoded.Tracks[1]
+	if track.Name != "caisse claire e?" || track.RawName() != p.Tracks[1].Name {
+		t.Fatalf("unexpected names %q, raw %q", track.Name, track.RawName())
+	}
+
+	// Raw names are encoded back unless the track is renamed
+	encoded, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != string(data) {
+		t.Fatalf("raw name not encoded back")
+	}
+
+	encoded, _, err = EncodeBytes(decoded, WithNameTransliteration(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reencoded, err := DecodeBytes(encoded, WithStrict(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reencoded.Tracks[1].Name != "caisse claire e?" {
+		t.Fatalf("name not transliterated on encode: %q", reencoded.Tracks[1].Name)
+	}
+
+	decoded.Tracks[1].Name = "snare"
+	if name := decoded.Tracks[1].RawName(); name != "snare" {
+		t.Fatalf("raw name of renamed track: %q", name)
+	}
+}
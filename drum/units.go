@@ -0,0 +1,113 @@
+package drum
+
+import (
+	"fmt"
+	"time"
+)
+
+// BPM is a tempo in beats per minute, a beat being four steps.
+type BPM float32
+
+// StepDuration returns duration of a single step at the tempo,
+// or zero if the tempo isn't positive.
+func (b BPM) StepDuration() time.Duration {
+	if b <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(time.Minute) / float64(b) / beatSteps)
+}
+
+// BPMFromStepDuration returns the tempo at which a single step lasts d,
+// or zero if d isn't positive.
+func BPMFromStepDuration(d time.Duration) BPM {
+	if d <= 0 {
+		return 0
+	}
+
+	return BPM(float64(time.Minute) / float64(d) / beatSteps)
+}
+
+// BPM returns the pattern's tempo as a BPM.
+func (p *Pattern) BPM() BPM {
+	return BPM(p.Tempo)
+}
+
+// SetBPM sets the pattern's tempo.
+func (p *Pattern) SetBPM(tempo BPM) {
+	p.Tempo = float32(tempo)
+}
+
+// BPMAt returns the tempo in effect at the step as a BPM, see TempoAt.
+func (p *Pattern) BPMAt(step int) BPM {
+	return BPM(p.TempoAt(step))
+}
+
+// BPM returns the tempo of the change as a BPM.
+func (c TempoChange) BPM() BPM {
+	return BPM(c.Tempo)
+}
+
+// StepIndex is the position of a step in a track, starting at 0.
+type StepIndex int
+
+// Step is the value of a single step of a track.
+type Step uint8
+
+// Typed values of steps, matching StepOff, StepOn and StepFlam.
+const (
+	Off  = Step(StepOff)
+	On   = Step(StepOn)
+	Flam = Step(StepFlam)
+)
+
+// IsHit returns true if the step is played.
+func (s Step) IsHit() bool {
+	return isHit(byte(s))
+}
+
+// Valid returns true if the step is Off, On or Flam.
+func (s Step) Valid() bool {
+	return s <= Flam
+}
+
+// Step returns the value of the step at index i, or Off if there's
+// no such step. The track's offset isn't applied.
+func (t Track) Step(i StepIndex) Step {
+	if i < 0 || int(i) >= t.Len() {
+		return Off
+	}
+
+	if t.IsPacked() {
+		if t.packed[i/8]&(1<<uint(i%8)) != 0 {
+			return On
+		}
+		return Off
+	}
+
+	return Step(t.Steps[i])
+}
+
+// SetStep sets the value of the step at index i. Flams are set as hits
+// in packed tracks.
+func (t *Track) SetStep(i StepIndex, s Step) error {
+	if i < 0 || int(i) >= t.Len() {
+		return fmt.Errorf("no step at index %d in track %q", i, t.Name)
+	}
+	if !s.Valid() {
+		return fmt.Errorf("invalid step value %d", s)
+	}
+
+	if t.IsPacked() {
+		if s.IsHit() {
+			t.packed[i/8] |= 1 << uint(i%8)
+		} else {
+			t.packed[i/8] &^= 1 << uint(i%8)
+		}
+		return nil
+	}
+
+	t.Steps[i] = byte(s)
+
+	return nil
+}
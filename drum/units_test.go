@@ -0,0 +1,51 @@
+package drum
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBPM(t *testing.T) {
+	if d := BPM(120).StepDuration(); d != 125*time.Millisecond {
+		t.Errorf("unexpected step duration %v at 120 BPM", d)
+	}
+	if d := BPM(0).StepDuration(); d != 0 {
+		t.Errorf("unexpected step duration %v at 0 BPM", d)
+	}
+	if b := BPMFromStepDuration(125 * time.Millisecond); b != 120 {
+		t.Errorf("unexpected tempo %v of 125ms steps", b)
+	}
+
+	p := &Pattern{Tempo: 120, TempoChanges: []TempoChange{{Step: 4, Tempo: 60}}}
+	if b := p.BPM(); b != 120 {
+		t.Errorf("unexpected pattern tempo %v", b)
+	}
+	if b := p.BPMAt(4); b != 60 || b != p.TempoChanges[0].BPM() {
+		t.Errorf("unexpected tempo %v at step 4", b)
+	}
+	p.SetBPM(90)
+	if p.Tempo != 90 {
+		t.Errorf("tempo wasn't set, got %v", p.Tempo)
+	}
+}
+
+func TestTrackStep(t *testing.T) {
+	track := Track{Name: "kick", Steps: []byte{1, 0, 2}}
+
+	if s := track.Step(2); s != Flam || !s.IsHit() {
+		t.Errorf("unexpected step %v", s)
+	}
+	if s := track.Step(3); s != Off {
+		t.Errorf("unexpected step %v out of range", s)
+	}
+
+	if err := track.SetStep(1, On); err != nil || track.Steps[1] != StepOn {
+		t.Errorf("step wasn't set: %v", err)
+	}
+	if err := track.SetStep(3, On); err == nil {
+		t.Error("expected error setting step out of range")
+	}
+	if err := track.SetStep(0, Step(3)); err == nil {
+		t.Error("expected error setting invalid step")
+	}
+}
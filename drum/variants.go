@@ -0,0 +1,89 @@
+package drum
+
+// Names of variants returned by DeriveVariants.
+const (
+	VariantIntro  = "intro"
+	VariantVerse  = "verse"
+	VariantChorus = "chorus"
+)
+
+// DeriveVariants returns variants of the pattern for a basic arrangement,
+// keyed by VariantIntro, VariantVerse and VariantChorus. The verse is the
+// pattern itself, the intro is a sparser version and the chorus a denser
+// one. Variants are derived by fixed rules per instrument, guessed from
+// track names, so they're always the same:
+//
+//   - intro: kicks, hi-hats and cymbals only on beats, snares only on
+//     backbeats, toms and other percussion muted
+//   - chorus: hi-hats on every eighth note, a cymbal on the downbeat and
+//     kicks pushed with an extra hit an eighth note before those on beats
+func DeriveVariants(p *Pattern) map[string]*Pattern {
+	p.ensureTracks()
+
+	return map[string]*Pattern{
+		VariantIntro:  thin(p),
+		VariantVerse:  p.Clone(),
+		VariantChorus: densify(p),
+	}
+}
+
+// thin returns a sparser copy of the pattern.
+func thin(p *Pattern) *Pattern {
+	c := p.Clone()
+	c.Unpack()
+
+	for _, track := range c.Tracks {
+		var keep func(step int) bool
+
+		switch classify(track.Name) {
+		case kickInstrument:
+			keep = func(step int) bool { return step%beatSteps == 0 }
+		case snareInstrument:
+			keep = func(step int) bool { return step%(2*beatSteps) == beatSteps }
+		case hihatInstrument, cymbalInstrument:
+			keep = func(step int) bool { return step%beatSteps == 0 }
+		case percussionInstrument, tomInstrument:
+			keep = func(int) bool { return false }
+		default:
+			continue
+		}
+
+		for i := range track.Steps {
+			if !keep(i) {
+				track.Steps[i] = StepOff
+			}
+		}
+	}
+
+	return c
+}
+
+// densify returns a denser copy of the pattern.
+func densify(p *Pattern) *Pattern {
+	c := p.Clone()
+	c.Unpack()
+
+	for _, track := range c.Tracks {
+		switch classify(track.Name) {
+		case kickInstrument:
+			original := append([]byte(nil), track.Steps...)
+			for i := beatSteps; i < len(original); i += beatSteps {
+				if isHit(original[i]) && !isHit(original[i-2]) {
+					track.Steps[i-2] = StepOn
+				}
+			}
+		case hihatInstrument:
+			for i := 0; i < len(track.Steps); i += 2 {
+				if !isHit(track.Steps[i]) {
+					track.Steps[i] = StepOn
+				}
+			}
+		case cymbalInstrument:
+			if len(track.Steps) > 0 && !isHit(track.Steps[0]) {
+				track.Steps[0] = StepOn
+			}
+		}
+	}
+
+	return c
+}
@@ -0,0 +1,50 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestDeriveVariants(t *testing.T) {
+	p, err := DecodeFile(path.Join("fixtures", tData[0].path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	variants := DeriveVariants(p)
+	if out := variants[VariantVerse].String(); out != p.String() {
+		t.Fatalf("verse isn't equal to the pattern:\n%s", out)
+	}
+
+	intro, chorus := variants[VariantIntro], variants[VariantChorus]
+	if intro.Density() >= p.Density() || chorus.Density() <= p.Density() {
+		t.Fatalf("unexpected densities of intro %v, verse %v and chorus %v",
+			intro.Density(), p.Density(), chorus.Density())
+	}
+
+	expected := `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(0) kick	|x---|x---|x---|x---|
+(1) snare	|----|x---|----|x---|
+(2) clap	|----|x---|----|----|
+(3) hh-open	|----|----|x---|----|
+(4) hh-close	|x---|x---|----|x---|
+(5) cowbell	|----|----|----|----|
+`
+	if out := intro.String(); out != expected {
+		t.Fatalf("wrong intro.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+
+	expected = `Saved with HW Version: 0.808-alpha
+Tempo: 120
+(0) kick	|x-x-|x-x-|x-x-|x---|
+(1) snare	|----|x---|----|x---|
+(2) clap	|----|x-x-|----|----|
+(3) hh-open	|x-x-|x-x-|x-x-|x-x-|
+(4) hh-close	|x-x-|x-x-|x-x-|x-xx|
+(5) cowbell	|----|----|--x-|----|
+`
+	if out := chorus.String(); out != expected {
+		t.Fatalf("wrong chorus.\nGot:\n%s\nExpected:\n%s", out, expected)
+	}
+}
@@ -0,0 +1,151 @@
+package drum
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Model is a variation engine learning per-track step transitions from
+// a corpus of patterns. It generates new patterns in the style of the corpus.
+type Model struct {
+	Version string
+
+	tracks []*trackModel
+	tempos []float32
+}
+
+// trackModel holds transition statistics of all tracks sharing a name.
+type trackModel struct {
+	id   byte
+	name string
+
+	// Counts of the first step's values
+	first [2]int
+	// Counts of transitions, indexed by position within a beat,
+	// previous step and next step
+	transitions [beatSteps][2][2]int
+}
+
+// GenerateConfig configures pattern generation.
+type GenerateConfig struct {
+	// Seed makes generation deterministic
+	Seed int64
+	// Steps per track, defaults to 16
+	Steps int
+	// Tempo of the generated pattern, defaults to the corpus' average
+	Tempo float32
+}
+
+// Train updates model statistics with steps of all tracks in patterns.
+// It can be called multiple times to extend the corpus.
+func (m *Model) Train(patterns []*Pattern) {
+	for _, p := range patterns {
+		p.ensureTracks()
+	}
+
+	for _, p := range patterns {
+		if m.Version == "" {
+			m.Version = p.Version
+		}
+		m.tempos = append(m.tempos, p.Tempo)
+
+		for _, track := range p.Tracks {
+			track.Unpack()
+			if len(track.Steps) == 0 {
+				continue
+			}
+
+			tm := m.track(track)
+			tm.first[stepValue(track.Steps[0])]++
+
+			for i := 1; i < len(track.Steps); i++ {
+				prev := stepValue(track.Steps[i-1])
+				next := stepValue(track.Steps[i])
+				tm.transitions[i%beatSteps][prev][next]++
+			}
+		}
+	}
+}
+
+// Generate returns a new pattern with a track for every track name seen
+// in the corpus. The same config always produces the same pattern.
+func (m *Model) Generate(cfg GenerateConfig) (*Pattern, error) {
+	if len(m.tracks) == 0 {
+		return nil, errors.New("model is not trained")
+	}
+
+	if cfg.Steps <= 0 {
+		cfg.Steps = trackSteps
+	}
+
+	if cfg.Tempo <= 0 {
+		var sum float32
+		for _, tempo := range m.tempos {
+			sum += tempo
+		}
+		cfg.Tempo = sum / float32(len(m.tempos))
+	}
+
+	rnd := rand.New(rand.NewSource(cfg.Seed))
+
+	p := &Pattern{
+		Version: m.Version,
+		Tempo:   cfg.Tempo,
+		Tracks:  make([]Track, 0, len(m.tracks)),
+	}
+
+	for _, tm := range m.tracks {
+		steps := make([]byte, cfg.Steps)
+		steps[0] = sample(rnd, tm.first)
+
+		for i := 1; i < cfg.Steps; i++ {
+			steps[i] = sample(rnd, tm.transitions[i%beatSteps][steps[i-1]])
+		}
+
+		p.Tracks = append(p.Tracks, Track{
+			ID:    tm.id,
+			Name:  tm.name,
+			Steps: steps,
+		})
+	}
+
+	return p, nil
+}
+
+// track returns statistics of the track with the same name,
+// creating them if the name wasn't seen yet.
+func (m *Model) track(track Track) *trackModel {
+	for _, tm := range m.tracks {
+		if tm.name == track.Name {
+			return tm
+		}
+	}
+
+	tm := &trackModel{id: track.ID, name: track.Name}
+	m.tracks = append(m.tracks, tm)
+
+	return tm
+}
+
+// stepValue returns 1 for any step that is on and 0 otherwise.
+func stepValue(step byte) byte {
+	if step == 0 {
+		return 0
+	}
+
+	return 1
+}
+
+// sample picks a step value with probability proportional to its count.
+func sample(rnd *rand.Rand, counts [2]int) byte {
+	total := counts[0] + counts[1]
+	if total == 0 {
+		return 0
+	}
+
+	if rnd.Intn(total) < counts[1] {
+		return 1
+	}
+
+	return 0
+}
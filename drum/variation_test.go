@@ -0,0 +1,52 @@
+package drum
+
+import (
+	"path"
+	"testing"
+)
+
+func TestModel(t *testing.T) {
+	var corpus []*Pattern
+	for _, exp := range tData {
+		decoded, err := DecodeFile(path.Join("fixtures", exp.path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		corpus = append(corpus, decoded)
+	}
+
+	model := &Model{}
+	if _, err := model.Generate(GenerateConfig{}); err == nil {
+		t.Fatal("expected error from untrained model")
+	}
+
+	model.Train(corpus)
+
+	generated, err := model.Generate(GenerateConfig{Seed: 1, Steps: 32, Tempo: 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, _ := model.Generate(GenerateConfig{Seed: 1, Steps: 32, Tempo: 128})
+	if generated.String() != again.String() {
+		t.Fatal("generation is not deterministic for the same seed")
+	}
+
+	if generated.Tempo != 128 {
+		t.Errorf("expected tempo 128, got %v", generated.Tempo)
+	}
+
+	names := map[string]bool{}
+	for _, track := range generated.Tracks {
+		if len(track.Steps) != 32 {
+			t.Errorf("expected 32 steps in %s, got %d", track.Name, len(track.Steps))
+		}
+		names[track.Name] = true
+	}
+
+	for _, name := range []string{"kick", "snare", "Maracas", "HiHat"} {
+		if !names[name] {
+			t.Errorf("generated pattern is missing track %s", name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package drum
+
+import "math"
+
+// maxVelocity is the highest velocity of a hit.
+const maxVelocity = 127
+
+// Curve maps a velocity in range from 0 to 1 to a new velocity
+// in the same range.
+type Curve func(v float64) float64
+
+// Velocity curves.
+var (
+	// LinearCurve keeps velocities unchanged
+	LinearCurve Curve = func(v float64) float64 { return v }
+	// ExponentialCurve expands dynamics, making soft hits softer
+	ExponentialCurve Curve = func(v float64) float64 { return v * v }
+	// CompressiveCurve reduces dynamics, making soft hits louder
+	CompressiveCurve Curve = math.Sqrt
+)
+
+// ApplyVelocityCurve sets the curve applied to velocities of all hits,
+// as returned by Velocity and used by exports.
+func (p *Pattern) ApplyVelocityCurve(curve Curve) {
+	p.ensureTracks()
+
+	p.velocityCurve = curve
+}
+
+// SetVelocityScale sets the factor velocities of the track's hits are
+// multiplied by, after the pattern's velocity curve is applied.
+// Velocities are clipped at 127.
+func (t *Track) SetVelocityScale(scale float64) {
+	t.velocityScale = scale
+}
+
+// apply returns velocity mapped through the curve. A nil curve is linear.
+func (c Curve) apply(velocity byte) byte {
+	if c == nil {
+		return velocity
+	}
+
+	return toVelocity(c(float64(velocity) / maxVelocity))
+}
+
+// scaleVelocity returns velocity multiplied by the track's scale.
+// Unset scale keeps velocity unchanged.
+func (t Track) scaleVelocity(velocity byte) byte {
+	if t.velocityScale == 0 {
+		return velocity
+	}
+
+	return toVelocity(float64(velocity) / maxVelocity * t.velocityScale)
+}
+
+// toVelocity converts velocity in range from 0 to 1 to MIDI-like velocity.
+// Hits never become silent, so the lowest returned velocity is 1.
+func toVelocity(v float64) byte {
+	return byte(math.Max(1, math.Min(maxVelocity, math.Round(v*maxVelocity))))
+}
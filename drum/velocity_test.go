@@ -0,0 +1,43 @@
+package drum
+
+import "testing"
+
+func TestVelocityCurve(t *testing.T) {
+	p := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: []byte{1, 1}},
+			{ID: 1, Name: "hh-close", Steps: []byte{1, 1}},
+			{ID: 2, Name: "accent", Steps: []byte{1, 0}},
+		},
+	}
+
+	p.Tracks[1].SetVelocityScale(0.5)
+
+	for _, c := range []struct {
+		curve    Curve
+		expected [2][2]byte
+	}{
+		{nil, [2][2]byte{{127, 100}, {64, 50}}},
+		{LinearCurve, [2][2]byte{{127, 100}, {64, 50}}},
+		{ExponentialCurve, [2][2]byte{{127, 79}, {64, 40}}},
+		{CompressiveCurve, [2][2]byte{{127, 113}, {64, 57}}},
+	} {
+		p.ApplyVelocityCurve(c.curve)
+
+		for track := 0; track < 2; track++ {
+			for step := 0; step < 2; step++ {
+				if v := p.Velocity(track, step); v != c.expected[track][step] {
+					t.Errorf("track %d step %d: expected velocity %d, got %d",
+						track, step, c.expected[track][step], v)
+				}
+			}
+		}
+	}
+
+	p.Tracks[0].SetVelocityScale(2)
+	if v := p.Velocity(0, 1); v != 127 {
+		t.Errorf("expected velocity clipped at 127, got %d", v)
+	}
+}
@@ -0,0 +1,129 @@
+package drum
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// TimedEvent is a hit of a track in a timeline for web sequencers, e.g.
+// parts of Tone.js, which read time and duration in seconds. Times are
+// also given in milliseconds for plain WebAudio scheduling.
+type TimedEvent struct {
+	// Track is the index of the track in EventsDocument.Tracks
+	Track  int     `json:"track"`
+	TimeMs float64 `json:"timeMs"`
+	// Time is TimeMs in seconds
+	Time float64 `json:"time"`
+	// Velocity is from 0 to 1
+	Velocity   float64 `json:"velocity"`
+	DurationMs float64 `json:"durationMs"`
+	// Duration is DurationMs in seconds
+	Duration float64 `json:"duration"`
+	// Grace is true for grace notes preceding flams
+	Grace bool `json:"grace,omitempty"`
+}
+
+// EventsDocument is a pattern's timeline for web sequencers,
+// see ExportEventsJSON.
+type EventsDocument struct {
+	// BPM is the pattern's initial tempo. Times of events follow
+	// tempo changes, so it's informative only.
+	BPM    BPM          `json:"bpm"`
+	Tracks []EventTrack `json:"tracks"`
+	// LoopMs is the length of the pattern, after which it repeats
+	LoopMs float64 `json:"loopMs"`
+	// LoopEnd is LoopMs in seconds
+	LoopEnd float64      `json:"loopEnd"`
+	Events  []TimedEvent `json:"events"`
+}
+
+// EventTrack is a track of a timeline.
+type EventTrack struct {
+	ID   byte   `json:"id"`
+	Name string `json:"name"`
+	// Note is the General MIDI note of the track, if it's known
+	Note byte `json:"note,omitempty"`
+}
+
+// ExportEvents returns hits of the pattern as events timed in milliseconds,
+// see Pattern.Events. Each hit lasts a step. Flams are preceded by grace
+// notes at half velocity, earlier by the flam spacing, but not before the
+// start of the pattern. Of the options, only WithFlamSpacing is used.
+func ExportEvents(p *Pattern, opts ...ExportOption) []TimedEvent {
+	p.ensureTracks()
+
+	config := newExportConfig(opts)
+
+	events := []TimedEvent{}
+	for _, e := range p.Events() {
+		velocity := float64(e.Velocity) / float64(AccentVelocity)
+		duration := p.StepDurationAt(e.Step)
+
+		if e.Flam {
+			events = append(events, timedEvent(e.TrackIndex, max(e.Time-config.flamSpacing, 0), velocity/2, duration, true))
+		}
+		events = append(events, timedEvent(e.TrackIndex, e.Time, velocity, duration, false))
+	}
+
+	return events
+}
+
+// timedEvent returns an event with times in milliseconds and seconds.
+func timedEvent(track int, t time.Duration, velocity float64, duration time.Duration, grace bool) TimedEvent {
+	return TimedEvent{
+		Track:      track,
+		TimeMs:     milliseconds(t),
+		Time:       t.Seconds(),
+		Velocity:   velocity,
+		DurationMs: milliseconds(duration),
+		Duration:   duration.Seconds(),
+		Grace:      grace,
+	}
+}
+
+// milliseconds returns the duration in milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ExportEventsJSON writes the pattern's timeline as an EventsDocument,
+// so web apps can play patterns without computing times themselves, e.g.
+// with Tone.js:
+//
+//	const part = new Tone.Part((time, e) => {
+//		players[e.track].start(time, 0, e.duration);
+//	}, doc.events);
+//	part.loop = true;
+//	part.loopEnd = doc.loopEnd;
+func ExportEventsJSON(w io.Writer, p *Pattern, opts ...ExportOption) error {
+	if err := p.ensureTracks(); err != nil {
+		return err
+	}
+
+	doc := EventsDocument{
+		BPM:    p.BPM(),
+		Tracks: make([]EventTrack, len(p.Tracks)),
+		Events: ExportEvents(p, opts...),
+	}
+
+	length := 0
+	for i, track := range p.Tracks {
+		doc.Tracks[i] = EventTrack{ID: track.ID, Name: track.Name}
+		if note, ok := GMNote(track.Name); ok {
+			doc.Tracks[i].Note = note
+		}
+		length = max(length, track.Len())
+	}
+
+	loop := p.stepTime(length)
+	doc.LoopMs, doc.LoopEnd = milliseconds(loop), loop.Seconds()
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
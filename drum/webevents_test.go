@@ -0,0 +1,47 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportEvents(t *testing.T) {
+	p := &Pattern{
+		Tempo: 120,
+		Tracks: []Track{
+			{ID: 0, Name: "kick", Steps: stepsFromString("x---")},
+			{ID: 1, Name: "snare", Steps: []byte{StepOff, StepOff, StepFlam, StepOff}},
+		},
+	}
+
+	events := ExportEvents(p, WithFlamSpacing(20*time.Millisecond))
+
+	velocity := float64(DefaultVelocity) / float64(AccentVelocity)
+	expected := []TimedEvent{
+		{Track: 0, TimeMs: 0, Time: 0, Velocity: velocity, DurationMs: 125, Duration: 0.125},
+		{Track: 1, TimeMs: 230, Time: 0.23, Velocity: velocity / 2, DurationMs: 125, Duration: 0.125, Grace: true},
+		{Track: 1, TimeMs: 250, Time: 0.25, Velocity: velocity, DurationMs: 125, Duration: 0.125},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportEventsJSON(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc EventsDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.LoopMs != 500 || doc.LoopEnd != 0.5 || len(doc.Events) != 3 {
+		t.Errorf("unexpected document %+v", doc)
+	}
+	if doc.Tracks[0] != (EventTrack{ID: 0, Name: "kick", Note: 36}) {
+		t.Errorf("unexpected track %+v", doc.Tracks[0])
+	}
+}